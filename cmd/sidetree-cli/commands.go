@@ -0,0 +1,528 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
+	"github.com/trustbloc/sidetree-core-go/pkg/commitment"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/encoder"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/restclient"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/privkey"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/client"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/model"
+)
+
+const (
+	defaultNamespace     = "did:sidetree"
+	defaultMultihashCode = 18 // SHA2-256
+	submitTimeout        = 30 * time.Second
+	keyFilePerm          = 0o600
+
+	keyTypeP256      = "P-256"
+	keyTypeP384      = "P-384"
+	keyTypeP521      = "P-521"
+	keyTypeSecp256k1 = "secp256k1"
+	keyTypeEd25519   = "Ed25519"
+)
+
+// createResult is the output of the create command.
+type createResult struct {
+	DID         string                     `json:"did"`
+	LongFormDID string                     `json:"longFormDid"`
+	Request     json.RawMessage            `json:"request"`
+	Response    *document.ResolutionResult `json:"response,omitempty"`
+}
+
+func runKeygen(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("keygen", stderr)
+	keyType := fs.String("type", keyTypeP256, "key type: P-256, P-384, P-521, secp256k1 or Ed25519")
+	out := fs.String("out", "", "file to which the private key is written (default stdout); "+
+		"the public key is written to stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	privateKey, err := generateKey(*keyType)
+	if err != nil {
+		return err
+	}
+
+	privateKeyJWK, err := privkey.GetPrivateKeyJWK(privateKey)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return writeLine(stdout, privateKeyJWK)
+	}
+
+	err = os.WriteFile(*out, append(privateKeyJWK, '\n'), keyFilePerm)
+	if err != nil {
+		return fmt.Errorf("write key file: %w", err)
+	}
+
+	publicKey, err := getPublicKeyJWK(privateKey)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(stdout, publicKey)
+}
+
+func runCreate(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("create", stderr)
+	namespace := fs.String("namespace", defaultNamespace, "DID namespace")
+	docFile := fs.String("document", "", "file containing the opaque document")
+	patchesFile := fs.String("patches", "", "file containing a JSON array of patches (instead of -document)")
+	updateKeyFile := fs.String("update-key", "", "file containing the next update key in JWK format")
+	recoveryKeyFile := fs.String("recovery-key", "", "file containing the next recovery key in JWK format")
+	multihashCode := fs.Uint("multihash", defaultMultihashCode, "multihash code of the hashing algorithm")
+	node, authToken := addSubmitFlags(fs)
+
+	if err := parseFlags(fs, args, "update-key", "recovery-key"); err != nil {
+		return err
+	}
+
+	opaqueDoc, patches, err := readDocumentOrPatches(*docFile, *patchesFile)
+	if err != nil {
+		return err
+	}
+
+	updateCommitment, err := getCommitment(*updateKeyFile, *multihashCode)
+	if err != nil {
+		return err
+	}
+
+	recoveryCommitment, err := getCommitment(*recoveryKeyFile, *multihashCode)
+	if err != nil {
+		return err
+	}
+
+	request, err := client.NewCreateRequest(&client.CreateRequestInfo{
+		OpaqueDocument:     opaqueDoc,
+		Patches:            patches,
+		RecoveryCommitment: recoveryCommitment,
+		UpdateCommitment:   updateCommitment,
+		MultihashCode:      *multihashCode,
+	})
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	did, longFormDID, err := getDIDs(*namespace, request, *multihashCode)
+	if err != nil {
+		return err
+	}
+
+	result := &createResult{
+		DID:         did,
+		LongFormDID: longFormDID,
+		Request:     request,
+	}
+
+	if *node != "" {
+		result.Response, err = submit(*node, *authToken, request)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeJSON(stdout, result)
+}
+
+func runUpdate(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("update", stderr)
+	didSuffix := fs.String("did-suffix", "", "unique suffix of the DID")
+	patchesFile := fs.String("patches", "", "file containing a JSON array of patches")
+	updateKeyFile := fs.String("update-key", "", "file containing the current update private key in JWK format")
+	nextUpdateKeyFile := fs.String("next-update-key", "", "file containing the next update key in JWK format")
+	multihashCode := fs.Uint("multihash", defaultMultihashCode, "multihash code of the hashing algorithm")
+	node, authToken := addSubmitFlags(fs)
+
+	if err := parseFlags(fs, args, "did-suffix", "patches", "update-key", "next-update-key"); err != nil {
+		return err
+	}
+
+	patches, err := readPatches(*patchesFile)
+	if err != nil {
+		return err
+	}
+
+	updateKey, err := readSigningKey(*updateKeyFile, *multihashCode)
+	if err != nil {
+		return err
+	}
+
+	nextUpdateCommitment, err := getCommitment(*nextUpdateKeyFile, *multihashCode)
+	if err != nil {
+		return err
+	}
+
+	request, err := client.NewUpdateRequest(&client.UpdateRequestInfo{
+		DidSuffix:        *didSuffix,
+		Patches:          patches,
+		UpdateCommitment: nextUpdateCommitment,
+		UpdateKey:        updateKey.publicKey,
+		MultihashCode:    *multihashCode,
+		Signer:           updateKey.signer,
+		RevealValue:      updateKey.revealValue,
+	})
+	if err != nil {
+		return fmt.Errorf("update request: %w", err)
+	}
+
+	return output(stdout, *node, *authToken, request)
+}
+
+func runRecover(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("recover", stderr)
+	didSuffix := fs.String("did-suffix", "", "unique suffix of the DID")
+	docFile := fs.String("document", "", "file containing the opaque document")
+	patchesFile := fs.String("patches", "", "file containing a JSON array of patches (instead of -document)")
+	recoveryKeyFile := fs.String("recovery-key", "", "file containing the current recovery private key in JWK format")
+	nextRecoveryKeyFile := fs.String("next-recovery-key", "", "file containing the next recovery key in JWK format")
+	nextUpdateKeyFile := fs.String("next-update-key", "", "file containing the next update key in JWK format")
+	multihashCode := fs.Uint("multihash", defaultMultihashCode, "multihash code of the hashing algorithm")
+	node, authToken := addSubmitFlags(fs)
+
+	err := parseFlags(fs, args, "did-suffix", "recovery-key", "next-recovery-key", "next-update-key")
+	if err != nil {
+		return err
+	}
+
+	opaqueDoc, patches, err := readDocumentOrPatches(*docFile, *patchesFile)
+	if err != nil {
+		return err
+	}
+
+	recoveryKey, err := readSigningKey(*recoveryKeyFile, *multihashCode)
+	if err != nil {
+		return err
+	}
+
+	nextRecoveryCommitment, err := getCommitment(*nextRecoveryKeyFile, *multihashCode)
+	if err != nil {
+		return err
+	}
+
+	nextUpdateCommitment, err := getCommitment(*nextUpdateKeyFile, *multihashCode)
+	if err != nil {
+		return err
+	}
+
+	request, err := client.NewRecoverRequest(&client.RecoverRequestInfo{
+		DidSuffix:          *didSuffix,
+		RecoveryKey:        recoveryKey.publicKey,
+		OpaqueDocument:     opaqueDoc,
+		Patches:            patches,
+		RecoveryCommitment: nextRecoveryCommitment,
+		UpdateCommitment:   nextUpdateCommitment,
+		MultihashCode:      *multihashCode,
+		Signer:             recoveryKey.signer,
+		RevealValue:        recoveryKey.revealValue,
+	})
+	if err != nil {
+		return fmt.Errorf("recover request: %w", err)
+	}
+
+	return output(stdout, *node, *authToken, request)
+}
+
+func runDeactivate(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("deactivate", stderr)
+	didSuffix := fs.String("did-suffix", "", "unique suffix of the DID")
+	recoveryKeyFile := fs.String("recovery-key", "", "file containing the current recovery private key in JWK format")
+	multihashCode := fs.Uint("multihash", defaultMultihashCode, "multihash code of the hashing algorithm")
+	node, authToken := addSubmitFlags(fs)
+
+	if err := parseFlags(fs, args, "did-suffix", "recovery-key"); err != nil {
+		return err
+	}
+
+	recoveryKey, err := readSigningKey(*recoveryKeyFile, *multihashCode)
+	if err != nil {
+		return err
+	}
+
+	request, err := client.NewDeactivateRequest(&client.DeactivateRequestInfo{
+		DidSuffix:   *didSuffix,
+		RecoveryKey: recoveryKey.publicKey,
+		Signer:      recoveryKey.signer,
+		RevealValue: recoveryKey.revealValue,
+	})
+	if err != nil {
+		return fmt.Errorf("deactivate request: %w", err)
+	}
+
+	return output(stdout, *node, *authToken, request)
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	return fs
+}
+
+func addSubmitFlags(fs *flag.FlagSet) (*string, *string) {
+	node := fs.String("node", "", "URL of the operations endpoint of a Sidetree node; if set then the request is submitted")
+	authToken := fs.String("auth-token", "", "bearer token for the Sidetree node")
+
+	return node, authToken
+}
+
+// parseFlags parses the given arguments and ensures that the required flags are set.
+func parseFlags(fs *flag.FlagSet, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	set := make(map[string]bool)
+
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var missing []string
+
+	for _, name := range required {
+		if !set[name] {
+			missing = append(missing, "-"+name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%s: missing required flags: %s", fs.Name(), strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func generateKey(keyType string) (crypto.PrivateKey, error) {
+	switch keyType {
+	case keyTypeP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case keyTypeP384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case keyTypeP521:
+		return ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case keyTypeSecp256k1:
+		return ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+	case keyTypeEd25519:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+
+		return privateKey, err
+	default:
+		return nil, fmt.Errorf("unsupported key type '%s'", keyType)
+	}
+}
+
+func getPublicKeyJWK(privateKey crypto.PrivateKey) (*jws.JWK, error) {
+	publicKey, err := privkey.GetPublicKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pubkey.GetPublicKeyJWK(publicKey)
+}
+
+// getCommitment returns the commitment for the key in the given file. The file may contain either a public
+// or a private key in JWK format; only the public key is used.
+func getCommitment(keyFile string, multihashCode uint) (string, error) {
+	keyBytes, err := os.ReadFile(keyFile) //nolint:gosec
+	if err != nil {
+		return "", fmt.Errorf("read key file: %w", err)
+	}
+
+	publicKey := &jws.JWK{}
+
+	err = json.Unmarshal(keyBytes, publicKey)
+	if err != nil {
+		return "", fmt.Errorf("parse key file %s: %w", keyFile, err)
+	}
+
+	return commitment.GetCommitment(publicKey, multihashCode)
+}
+
+type signingKey struct {
+	publicKey   *jws.JWK
+	signer      client.Signer
+	revealValue string
+}
+
+func readSigningKey(keyFile string, multihashCode uint) (*signingKey, error) {
+	keyBytes, err := os.ReadFile(keyFile) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+
+	privateKey, err := privkey.ParsePrivateKeyJWK(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key file %s: %w", keyFile, err)
+	}
+
+	publicKey, err := getPublicKeyJWK(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := privkey.NewSigner(privateKey, "")
+	if err != nil {
+		return nil, err
+	}
+
+	revealValue, err := commitment.GetRevealValue(publicKey, multihashCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signingKey{
+		publicKey:   publicKey,
+		signer:      signer,
+		revealValue: revealValue,
+	}, nil
+}
+
+func readDocumentOrPatches(docFile, patchesFile string) (string, []patch.Patch, error) {
+	if (docFile == "") == (patchesFile == "") {
+		return "", nil, errors.New("either -document or -patches must be specified")
+	}
+
+	if patchesFile != "" {
+		patches, err := readPatches(patchesFile)
+
+		return "", patches, err
+	}
+
+	doc, err := os.ReadFile(docFile) //nolint:gosec
+	if err != nil {
+		return "", nil, fmt.Errorf("read document file: %w", err)
+	}
+
+	return strings.TrimSpace(string(doc)), nil, nil
+}
+
+func readPatches(patchesFile string) ([]patch.Patch, error) {
+	patchesBytes, err := os.ReadFile(patchesFile) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("read patches file: %w", err)
+	}
+
+	var rawPatches []json.RawMessage
+
+	err = json.Unmarshal(patchesBytes, &rawPatches)
+	if err != nil {
+		return nil, fmt.Errorf("parse patches file: %w", err)
+	}
+
+	patches := make([]patch.Patch, len(rawPatches))
+
+	for i, rawPatch := range rawPatches {
+		patches[i], err = patch.FromBytes(rawPatch)
+		if err != nil {
+			return nil, fmt.Errorf("parse patch %d: %w", i, err)
+		}
+	}
+
+	return patches, nil
+}
+
+// getDIDs returns the short-form DID and the long-form DID (i.e. the DID along with its initial state) for
+// the given create request.
+func getDIDs(namespace string, request []byte, multihashCode uint) (string, string, error) {
+	createRequest := &model.CreateRequest{}
+
+	err := json.Unmarshal(request, createRequest)
+	if err != nil {
+		return "", "", err
+	}
+
+	suffix, err := model.GetUniqueSuffix(createRequest.SuffixData, []uint{multihashCode})
+	if err != nil {
+		return "", "", err
+	}
+
+	initialState, err := canonicalizer.MarshalCanonical(&model.CreateRequest{
+		SuffixData: createRequest.SuffixData,
+		Delta:      createRequest.Delta,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	did := namespace + ":" + suffix
+
+	return did, did + ":" + encoder.EncodeToString(initialState), nil
+}
+
+// output writes the request to stdout or, if a node is specified, submits the request and writes the response
+// of the node (if any).
+func output(stdout io.Writer, node, authToken string, request []byte) error {
+	if node == "" {
+		return writeLine(stdout, request)
+	}
+
+	result, err := submit(node, authToken, request)
+	if err != nil {
+		return err
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return writeJSON(stdout, result)
+}
+
+func submit(node, authToken string, request []byte) (*document.ResolutionResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), submitTimeout)
+	defer cancel()
+
+	result, err := restclient.New(node, "", restclient.WithAuthToken(authToken)).Submit(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("submit request: %w", err)
+	}
+
+	return result, nil
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return writeLine(w, b)
+}
+
+func writeLine(w io.Writer, b []byte) error {
+	_, err := w.Write(append(b, '\n'))
+
+	return err
+}
@@ -0,0 +1,212 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationparser"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+
+	// Protocol parameters from the Sidetree v1.0 specification.
+	maxCoreIndexFileSize         = 1000000
+	maxProvisionalIndexFileSize  = 1000000
+	maxProofFileSize             = 2500000
+	maxChunkFileSize             = 10000000
+	maxDeltaSize                 = 1000
+	maxOperationSize             = 2500
+	maxOperationCount            = 10000
+	maxOperationHashLength       = 100
+	maxCasURILength              = 100
+	maxOperationTimeDelta        = 2 * 60 * 60 // two hours
+	nonceSize                    = 16
+	maxMemoryDecompressionFactor = 3
+
+	tabPadding = 2
+)
+
+var errValidationFailed = errors.New("batch files failed validation")
+
+func runInspect(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("inspect", stderr)
+	anchorString := fs.String("anchor", "", "anchor string (<number of operations>.<core index file URI>)")
+	casDir := fs.String("cas", "", "directory containing the (compressed) batch files, each named after its CAS URI")
+	protocolFile := fs.String("protocol", "", "file containing the protocol parameters in JSON format "+
+		"(default Sidetree v1.0 parameters)")
+	format := fs.String("format", formatText, "output format: text or json")
+
+	if err := parseFlags(fs, args, "anchor", "cas"); err != nil {
+		return err
+	}
+
+	if *format != formatText && *format != formatJSON {
+		return fmt.Errorf("unsupported format '%s'", *format)
+	}
+
+	p, err := readProtocol(*protocolFile)
+	if err != nil {
+		return err
+	}
+
+	provider := txnprovider.NewOperationProvider(p, operationparser.New(p), &dirCAS{dir: *casDir},
+		compression.New(compression.WithDefaultAlgorithms()))
+
+	report := provider.Inspect(&txn.SidetreeTxn{AnchorString: *anchorString})
+
+	if *format == formatJSON {
+		err = writeJSON(stdout, report)
+	} else {
+		err = writeReport(stdout, report)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !report.Valid() {
+		return errValidationFailed
+	}
+
+	return nil
+}
+
+func readProtocol(protocolFile string) (protocol.Protocol, error) {
+	if protocolFile == "" {
+		return defaultProtocol(), nil
+	}
+
+	b, err := os.ReadFile(protocolFile) //nolint:gosec
+	if err != nil {
+		return protocol.Protocol{}, fmt.Errorf("read protocol file: %w", err)
+	}
+
+	p := defaultProtocol()
+
+	err = json.Unmarshal(b, &p)
+	if err != nil {
+		return protocol.Protocol{}, fmt.Errorf("parse protocol file %s: %w", protocolFile, err)
+	}
+
+	return p, nil
+}
+
+func defaultProtocol() protocol.Protocol {
+	return protocol.Protocol{
+		MultihashAlgorithms:          []uint{defaultMultihashCode},
+		MaxOperationCount:            maxOperationCount,
+		MaxOperationSize:             maxOperationSize,
+		MaxOperationHashLength:       maxOperationHashLength,
+		MaxDeltaSize:                 maxDeltaSize,
+		MaxCasURILength:              maxCasURILength,
+		CompressionAlgorithm:         "GZIP",
+		MaxCoreIndexFileSize:         maxCoreIndexFileSize,
+		MaxProofFileSize:             maxProofFileSize,
+		MaxProvisionalIndexFileSize:  maxProvisionalIndexFileSize,
+		MaxChunkFileSize:             maxChunkFileSize,
+		SignatureAlgorithms:          []string{"EdDSA", "ES256", "ES256K", "ES384", "ES512"},
+		KeyAlgorithms:                []string{keyTypeEd25519, keyTypeP256, keyTypeSecp256k1, keyTypeP384, keyTypeP521},
+		MaxOperationTimeDelta:        maxOperationTimeDelta,
+		NonceSize:                    nonceSize,
+		MaxMemoryDecompressionFactor: maxMemoryDecompressionFactor,
+		Patches: []string{
+			string(patch.AddPublicKeys), string(patch.RemovePublicKeys),
+			string(patch.AddServiceEndpoints), string(patch.RemoveServiceEndpoints),
+			string(patch.JSONPatch), string(patch.Replace),
+			string(patch.AddAlsoKnownAs), string(patch.RemoveAlsoKnownAs),
+		},
+	}
+}
+
+func writeReport(w io.Writer, report *txnprovider.InspectionReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, tabPadding, ' ', 0)
+
+	fmt.Fprintf(tw, "Anchor string:\t%s\n", report.AnchorString)
+	fmt.Fprintf(tw, "Operations:\t%d\n", report.NumberOfOperations)
+
+	if len(report.Files) > 0 {
+		fmt.Fprint(tw, "\nFILE\tURI\tSIZE\tSTATUS\n")
+
+		for _, f := range report.Files {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", f.Type, f.URI, f.Size, status(f.Error))
+		}
+	}
+
+	if len(report.Operations) > 0 {
+		fmt.Fprint(tw, "\nOPERATION\tDID SUFFIX\tSTATUS\n")
+
+		for _, op := range report.Operations {
+			fmt.Fprintf(tw, "%s[%d]\t%s\t%s\n", op.Type, op.Index, op.UniqueSuffix, status(op.Errors...))
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Fprint(tw, "\nERRORS\n")
+
+		for _, e := range report.Errors {
+			fmt.Fprintln(tw, e)
+		}
+	}
+
+	result := "valid"
+	if !report.Valid() {
+		result = "invalid"
+	}
+
+	fmt.Fprintf(tw, "\nResult:\t%s\n", result)
+
+	return tw.Flush()
+}
+
+func status(errs ...string) string {
+	var nonEmpty []string
+
+	for _, e := range errs {
+		if e != "" {
+			nonEmpty = append(nonEmpty, e)
+		}
+	}
+
+	if len(nonEmpty) == 0 {
+		return "OK"
+	}
+
+	return "ERROR: " + strings.Join(nonEmpty, "; ")
+}
+
+// dirCAS reads CAS content from a local directory in which each file is named after its CAS URI.
+type dirCAS struct {
+	dir string
+}
+
+func (c *dirCAS) Read(uri string) ([]byte, error) {
+	if uri == "" || uri != filepath.Base(uri) || uri == "." || uri == ".." {
+		return nil, fmt.Errorf("invalid CAS URI [%s]", uri)
+	}
+
+	b, err := os.ReadFile(filepath.Join(c.dir, uri)) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
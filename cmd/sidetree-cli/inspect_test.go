@@ -0,0 +1,184 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/encoder"
+	"github.com/trustbloc/sidetree-core-go/pkg/hashing"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/client/keymanager"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationparser"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider"
+)
+
+func TestInspect(t *testing.T) {
+	casDir := t.TempDir()
+
+	anchorString := writeBatchFiles(t, casDir)
+
+	t.Run("success - text", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+
+		require.NoError(t, run([]string{"inspect", "-anchor", anchorString, "-cas", casDir}, stdout, io.Discard))
+
+		out := stdout.String()
+		require.Contains(t, out, "Anchor string:  "+anchorString)
+		require.Contains(t, out, "Operations:     5")
+		require.Contains(t, out, "create[1]")
+		require.Contains(t, out, "update[0]")
+		require.Contains(t, out, "recover[0]")
+		require.Contains(t, out, "deactivate[0]")
+		require.Contains(t, out, "Result:  valid")
+		require.NotContains(t, out, "ERROR")
+	})
+
+	t.Run("success - JSON", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+
+		err := run([]string{"inspect", "-anchor", anchorString, "-cas", casDir, "-format", "json"}, stdout, io.Discard)
+		require.NoError(t, err)
+
+		report := &txnprovider.InspectionReport{}
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), report))
+		require.True(t, report.Valid())
+		require.Len(t, report.Files, 5)
+		require.Len(t, report.Operations, 5)
+	})
+
+	t.Run("error - validation failed", func(t *testing.T) {
+		protocolFile := filepath.Join(t.TempDir(), "protocol.json")
+		require.NoError(t, os.WriteFile(protocolFile, []byte(`{"maxDeltaSize":10}`), 0o600))
+
+		stdout := &bytes.Buffer{}
+
+		err := run([]string{"inspect", "-anchor", anchorString, "-cas", casDir, "-protocol", protocolFile},
+			stdout, io.Discard)
+		require.ErrorIs(t, err, errValidationFailed)
+
+		out := stdout.String()
+		require.Contains(t, out, "ERROR: failed to validate delta[0]: delta size")
+		require.Contains(t, out, "Result:  invalid")
+	})
+
+	t.Run("error - file not found", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+
+		err := run([]string{"inspect", "-anchor", "1.missing", "-cas", casDir}, stdout, io.Discard)
+		require.ErrorIs(t, err, errValidationFailed)
+		require.Contains(t, stdout.String(), "no such file or directory")
+	})
+
+	t.Run("error - invalid CAS URI", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+
+		err := run([]string{"inspect", "-anchor", "1.dir/file", "-cas", casDir}, stdout, io.Discard)
+		require.ErrorIs(t, err, errValidationFailed)
+		require.Contains(t, stdout.String(), "invalid CAS URI [dir/file]")
+	})
+
+	t.Run("error - invalid anchor string", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+
+		err := run([]string{"inspect", "-anchor", "invalid", "-cas", casDir}, stdout, io.Discard)
+		require.ErrorIs(t, err, errValidationFailed)
+		require.Contains(t, stdout.String(), "ERRORS\nparse anchor data[invalid] failed")
+	})
+
+	t.Run("error - unsupported format", func(t *testing.T) {
+		err := run([]string{"inspect", "-anchor", anchorString, "-cas", casDir, "-format", "xml"}, io.Discard, io.Discard)
+		require.EqualError(t, err, "unsupported format 'xml'")
+	})
+
+	t.Run("error - invalid protocol file", func(t *testing.T) {
+		err := run([]string{"inspect", "-anchor", anchorString, "-cas", casDir, "-protocol", "testdata/missing.json"},
+			io.Discard, io.Discard)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "read protocol file")
+
+		err = run([]string{"inspect", "-anchor", anchorString, "-cas", casDir, "-protocol", "testdata/patches.json"},
+			io.Discard, io.Discard)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse protocol file testdata/patches.json")
+	})
+}
+
+// writeBatchFiles writes the batch files for a create, update, recover and deactivate operation to
+// the given directory and returns the anchor string.
+func writeBatchFiles(t *testing.T, dir string) string {
+	t.Helper()
+
+	km := keymanager.New(keymanager.NewMemStore(), defaultMultihashCode)
+
+	suffixes := make([]string, 5)
+	requests := make([][]byte, 5)
+
+	for i := range suffixes {
+		var err error
+
+		suffixes[i], requests[i], err = km.Create(`{"test":"value"}`)
+		require.NoError(t, err)
+	}
+
+	jsonPatch, err := patch.NewJSONPatch(`[{"op":"replace","path":"/test","value":"updated"}]`)
+	require.NoError(t, err)
+
+	requests[2], err = km.Update(suffixes[2], []patch.Patch{jsonPatch})
+	require.NoError(t, err)
+
+	requests[3], err = km.Recover(suffixes[3], `{"test":"recovered"}`)
+	require.NoError(t, err)
+
+	requests[4], err = km.Deactivate(suffixes[4])
+	require.NoError(t, err)
+
+	ops := make([]*operation.QueuedOperation, len(requests))
+
+	for i, request := range requests {
+		ops[i] = &operation.QueuedOperation{
+			OperationRequest: request,
+			UniqueSuffix:     suffixes[i],
+			Namespace:        defaultNamespace,
+		}
+	}
+
+	p := defaultProtocol()
+
+	handler := txnprovider.NewOperationHandler(p, &dirCASWriter{dirCAS: dirCAS{dir: dir}},
+		compression.New(compression.WithDefaultAlgorithms()), operationparser.New(p), &mocks.MetricsProvider{})
+
+	anchoringInfo, err := handler.PrepareTxnFiles(ops)
+	require.NoError(t, err)
+
+	return anchoringInfo.AnchorString
+}
+
+type dirCASWriter struct {
+	dirCAS
+}
+
+func (c *dirCASWriter) Write(content []byte) (string, error) {
+	hash, err := hashing.ComputeMultihash(defaultMultihashCode, content)
+	if err != nil {
+		return "", err
+	}
+
+	uri := encoder.EncodeToString(hash)
+
+	return uri, os.WriteFile(filepath.Join(c.dir, uri), content, 0o600)
+}
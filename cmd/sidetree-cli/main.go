@@ -0,0 +1,83 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command sidetree-cli generates keys, builds and signs Sidetree operation requests and optionally submits them
+// to a Sidetree node. It also inspects the batch files of an anchor offline.
+//
+// Usage:
+//
+//	sidetree-cli keygen -type Ed25519 -out update-key.json
+//	sidetree-cli create -document doc.json -update-key update-key.json -recovery-key recovery-key.json
+//	sidetree-cli update -did-suffix <suffix> -patches patches.json -update-key update-key.json \
+//	  -next-update-key next-update-key.json -node https://example.com/sidetree/v1/operations
+//	sidetree-cli inspect -anchor 9.<core index file URI> -cas ./cas -format json
+//
+// Run 'sidetree-cli <command> -h' for the flags of a command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type command struct {
+	name        string
+	description string
+	run         func(args []string, stdout, stderr io.Writer) error
+}
+
+var commands = []command{
+	{name: "keygen", description: "generate a private key and write it in JWK format", run: runKeygen},
+	{name: "create", description: "build a create request along with the short and long-form DIDs", run: runCreate},
+	{name: "update", description: "build and sign an update request", run: runUpdate},
+	{name: "recover", description: "build and sign a recover request", run: runRecover},
+	{name: "deactivate", description: "build and sign a deactivate request", run: runDeactivate},
+	{name: "inspect", description: "read and validate the batch files of an anchor from a local CAS directory", run: runInspect},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage())
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			err := cmd.run(args[1:], stdout, stderr)
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+
+			return err
+		}
+	}
+
+	return fmt.Errorf("unknown command '%s'\n\n%s", args[0], usage())
+}
+
+func usage() string {
+	b := &strings.Builder{}
+
+	b.WriteString("Usage: sidetree-cli <command> [flags]\n\nCommands:\n")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(b, "  %-12s%s\n", cmd.name, cmd.description)
+	}
+
+	b.WriteString("\nRun 'sidetree-cli <command> -h' for the flags of a command.")
+
+	return b.String()
+}
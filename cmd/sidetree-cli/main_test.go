@@ -0,0 +1,311 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/diddochandler"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/privkey"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationparser"
+)
+
+// Run 'go test ./cmd/sidetree-cli -update' to regenerate the golden files.
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+const (
+	didSuffix = "EiAOgz6SRD7NIeZmMhxlBtvJr00VKLV4pDkwBLy-lVOhrg"
+
+	operationsPath = "/sidetree/v1/operations"
+)
+
+var p = protocol.Protocol{
+	MultihashAlgorithms:    []uint{defaultMultihashCode},
+	MaxOperationSize:       2500,
+	MaxOperationHashLength: 100,
+	MaxDeltaSize:           2000,
+	SignatureAlgorithms:    []string{"EdDSA"},
+	KeyAlgorithms:          []string{"Ed25519"},
+	Patches:                []string{"add-public-keys", "add-services", "ietf-json-patch"},
+}
+
+// The keys in testdata are Ed25519 keys so that the signatures (and therefore the golden files) are deterministic.
+func TestCommands(t *testing.T) {
+	parser := operationparser.New(p)
+
+	for _, tc := range []struct {
+		name   string
+		args   []string
+		opType operation.Type
+	}{
+		{
+			name: "create",
+			args: []string{"create", "-document", "testdata/document.json",
+				"-update-key", "testdata/update-key.json", "-recovery-key", "testdata/recovery-key.json"},
+			opType: operation.TypeCreate,
+		},
+		{
+			name: "create-patches",
+			args: []string{"create", "-patches", "testdata/patches.json", "-namespace", "did:example",
+				"-update-key", "testdata/update-key.json", "-recovery-key", "testdata/recovery-key.json"},
+			opType: operation.TypeCreate,
+		},
+		{
+			name: "update",
+			args: []string{"update", "-did-suffix", didSuffix, "-patches", "testdata/patches.json",
+				"-update-key", "testdata/update-key.json", "-next-update-key", "testdata/next-update-key.json"},
+			opType: operation.TypeUpdate,
+		},
+		{
+			name: "recover",
+			args: []string{"recover", "-did-suffix", didSuffix, "-document", "testdata/document.json",
+				"-recovery-key", "testdata/recovery-key.json", "-next-recovery-key", "testdata/next-recovery-key.json",
+				"-next-update-key", "testdata/next-update-key.json"},
+			opType: operation.TypeRecover,
+		},
+		{
+			name:   "deactivate",
+			args:   []string{"deactivate", "-did-suffix", didSuffix, "-recovery-key", "testdata/recovery-key.json"},
+			opType: operation.TypeDeactivate,
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+
+			require.NoError(t, run(tc.args, stdout, io.Discard))
+
+			requireGolden(t, tc.name, stdout.Bytes())
+
+			request := stdout.Bytes()
+
+			if tc.opType == operation.TypeCreate {
+				result := &createResult{}
+				require.NoError(t, json.Unmarshal(stdout.Bytes(), result))
+
+				namespace := result.DID[:strings.LastIndex(result.DID, ":")]
+
+				did, initialState, err := parser.ParseDID(namespace, result.LongFormDID)
+				require.NoError(t, err)
+				require.Equal(t, result.DID, did)
+
+				_, err = parser.ParseOperation(namespace, initialState, false)
+				require.NoError(t, err)
+
+				request = result.Request
+			}
+
+			op, err := parser.ParseOperation("did:sidetree", request, false)
+			require.NoError(t, err)
+			require.Equal(t, tc.opType, op.Type)
+
+			if tc.opType != operation.TypeCreate {
+				require.Equal(t, didSuffix, op.UniqueSuffix)
+			}
+		})
+	}
+}
+
+func TestKeygen(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		for _, keyType := range []string{"P-256", "P-384", "P-521", "secp256k1", "Ed25519"} {
+			keyFile := filepath.Join(t.TempDir(), "key.json")
+
+			stdout := &bytes.Buffer{}
+
+			require.NoError(t, run([]string{"keygen", "-type", keyType, "-out", keyFile}, stdout, io.Discard))
+
+			publicKey := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal(stdout.Bytes(), &publicKey))
+			require.Equal(t, keyType, publicKey["crv"])
+			require.NotContains(t, publicKey, "d")
+
+			keyBytes, err := os.ReadFile(filepath.Clean(keyFile))
+			require.NoError(t, err)
+
+			privateKey, err := privkey.ParsePrivateKeyJWK(keyBytes)
+			require.NoError(t, err)
+			require.NotNil(t, privateKey)
+		}
+	})
+
+	t.Run("success - stdout", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+
+		require.NoError(t, run([]string{"keygen"}, stdout, io.Discard))
+
+		privateKey, err := privkey.ParsePrivateKeyJWK(stdout.Bytes())
+		require.NoError(t, err)
+		require.NotNil(t, privateKey)
+	})
+
+	t.Run("error - unsupported key type", func(t *testing.T) {
+		err := run([]string{"keygen", "-type", "RSA"}, io.Discard, io.Discard)
+		require.EqualError(t, err, "unsupported key type 'RSA'")
+	})
+
+	t.Run("error - invalid output file", func(t *testing.T) {
+		err := run([]string{"keygen", "-out", filepath.Join(t.TempDir(), "missing", "key.json")}, io.Discard, io.Discard)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "write key file")
+	})
+}
+
+func TestSubmit(t *testing.T) {
+	pc := mocks.NewMockProtocolClient()
+	pc.CurrentVersion.OperationParserReturns(operationparser.New(pc.Protocol))
+
+	docHandler := mocks.NewMockDocumentHandler().WithNamespace("did:sidetree").WithProtocolClient(pc)
+	handler := diddochandler.NewUpdateHandler(operationsPath, docHandler, pc, &mocks.MetricsProvider{})
+
+	router := mux.NewRouter()
+	router.HandleFunc(handler.Path(), handler.Handler()).Methods(handler.Method())
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	t.Run("success", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+
+		err := run([]string{"create", "-document", "testdata/document.json", "-node", server.URL + operationsPath,
+			"-update-key", "testdata/update-key.json", "-recovery-key", "testdata/recovery-key.json"}, stdout, io.Discard)
+		require.NoError(t, err)
+
+		result := &createResult{}
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), result))
+		require.NotNil(t, result.Response)
+		require.Equal(t, result.DID, result.Response.Document["id"])
+
+		stdout.Reset()
+
+		err = run([]string{"update", "-did-suffix", didSuffix, "-patches", "testdata/patches.json",
+			"-node", server.URL + operationsPath, "-update-key", "testdata/update-key.json",
+			"-next-update-key", "testdata/next-update-key.json"}, stdout, io.Discard)
+		require.NoError(t, err)
+		require.Contains(t, stdout.String(), `"svc2"`)
+	})
+
+	t.Run("error - node error", func(t *testing.T) {
+		err := run([]string{"deactivate", "-did-suffix", didSuffix, "-recovery-key", "testdata/recovery-key.json",
+			"-node", server.URL + "/invalid"}, io.Discard, io.Discard)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "submit request: server returned status 404")
+	})
+}
+
+func TestRun_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{
+			name: "no command",
+			err:  "Usage: sidetree-cli <command> [flags]",
+		},
+		{
+			name: "unknown command",
+			args: []string{"resolve"},
+			err:  "unknown command 'resolve'",
+		},
+		{
+			name: "invalid flag",
+			args: []string{"create", "-invalid"},
+			err:  "flag provided but not defined: -invalid",
+		},
+		{
+			name: "missing required flags",
+			args: []string{"update", "-did-suffix", didSuffix},
+			err:  "update: missing required flags: -patches, -update-key, -next-update-key",
+		},
+		{
+			name: "document and patches",
+			args: []string{"create", "-document", "testdata/document.json", "-patches", "testdata/patches.json",
+				"-update-key", "testdata/update-key.json", "-recovery-key", "testdata/recovery-key.json"},
+			err: "either -document or -patches must be specified",
+		},
+		{
+			name: "missing key file",
+			args: []string{"deactivate", "-did-suffix", didSuffix, "-recovery-key", "testdata/missing.json"},
+			err:  "read key file",
+		},
+		{
+			name: "invalid private key file",
+			args: []string{"deactivate", "-did-suffix", didSuffix, "-recovery-key", "testdata/patches.json"},
+			err:  "parse private key file testdata/patches.json",
+		},
+		{
+			name: "invalid public key file",
+			args: []string{"create", "-document", "testdata/document.json",
+				"-update-key", "testdata/patches.json", "-recovery-key", "testdata/recovery-key.json"},
+			err: "parse key file testdata/patches.json",
+		},
+		{
+			name: "invalid patches file",
+			args: []string{"update", "-did-suffix", didSuffix, "-patches", "testdata/document.json",
+				"-update-key", "testdata/update-key.json", "-next-update-key", "testdata/next-update-key.json"},
+			err: "parse patches file",
+		},
+		{
+			name: "invalid patch",
+			args: []string{"recover", "-did-suffix", didSuffix, "-patches", "testdata/update-key.json",
+				"-recovery-key", "testdata/recovery-key.json", "-next-recovery-key", "testdata/next-recovery-key.json",
+				"-next-update-key", "testdata/next-update-key.json"},
+			err: "parse patches file",
+		},
+		{
+			name: "invalid request",
+			args: []string{"create", "-document", "testdata/document.json", "-multihash", "0",
+				"-update-key", "testdata/update-key.json", "-recovery-key", "testdata/recovery-key.json"},
+			err: "not supported",
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := run(tc.args, io.Discard, io.Discard)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+
+	t.Run("help", func(t *testing.T) {
+		stderr := &bytes.Buffer{}
+
+		require.NoError(t, run([]string{"recover", "-h"}, io.Discard, stderr))
+		require.Contains(t, stderr.String(), "-next-recovery-key")
+	})
+}
+
+func requireGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+
+	goldenFile := filepath.Join("testdata", name+".golden")
+
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(goldenFile, actual, 0o600))
+	}
+
+	expected, err := os.ReadFile(filepath.Clean(goldenFile))
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(actual))
+}
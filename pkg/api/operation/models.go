@@ -88,12 +88,49 @@ const (
 	TypeRecover Type = "recover"
 )
 
+// Priority defines the priority class of a queued operation. Operations with a higher priority are batched
+// before operations with a lower priority (if the operation queue supports priorities).
+type Priority int
+
+const (
+	// PriorityUnspecified means that the priority is derived from the operation type.
+	PriorityUnspecified Priority = iota
+
+	// PriorityLow captures the low priority class.
+	PriorityLow
+
+	// PriorityNormal captures the normal priority class.
+	PriorityNormal
+
+	// PriorityHigh captures the high priority class.
+	PriorityHigh
+)
+
+// String returns the name of the priority class.
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "unspecified"
+	}
+}
+
 // QueuedOperation stores minimum required operation info for operations queue.
 type QueuedOperation struct {
 	OperationRequest []byte
 	UniqueSuffix     string
 	Namespace        string
 	AnchorOrigin     interface{}
+	// Type is the type of the operation (optional).
+	Type Type
+	// Priority is the priority class of the operation (optional). If not specified then the priority is
+	// derived from the operation type.
+	Priority Priority
 }
 
 // QueuedOperationAtTime contains queued operation info with protocol genesis time.
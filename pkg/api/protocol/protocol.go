@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package protocol
 
 import (
+	"errors"
+
 	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
@@ -68,6 +70,7 @@ type Protocol struct {
 	Patches []string `json:"patches"`
 
 	// SignatureAlgorithms contain supported signature algorithms for signed operations (e.g. EdDSA, ES256, ES384, ES512, ES256K).
+	// If signature suites are configured (see package signaturesuite), each algorithm must also be supported by a suite.
 	SignatureAlgorithms []string `json:"signatureAlgorithms"`
 
 	// KeyAlgorithms contain supported key algorithms for signed operations (e.g. secp256k1, P-256, P-384, P-512, Ed25519).
@@ -113,6 +116,22 @@ type ResolutionModel struct {
 	VersionID                      string
 	PublishedOperations            []*operation.AnchoredOperation
 	UnpublishedOperations          []*operation.AnchoredOperation
+	Trace                          []*OperationTrace
+}
+
+// OperationTrace holds the outcome of applying an operation during resolution. A trace is only
+// provided if it was requested with the resolution options (see document.WithTrace).
+type OperationTrace struct {
+	Operation *operation.AnchoredOperation
+
+	// Published is true if the operation has been anchored.
+	Published bool
+
+	// Applied is true if the operation was applied to the document.
+	Applied bool
+
+	// Reason holds the reason why the operation was not applied.
+	Reason string
 }
 
 // OperationApplier applies the given operation to the document.
@@ -153,9 +172,14 @@ type AnchoringInfo struct {
 	ExpiredOperations    []*operation.QueuedOperation
 }
 
+// ErrInvalidOperation is returned (wrapped) by OperationHandler.PrepareTxnFiles if the error is caused by an
+// operation in the batch (e.g. the operation can't be parsed) rather than by the system (e.g. CAS is unavailable).
+var ErrInvalidOperation = errors.New("invalid operation")
+
 // OperationHandler defines an interface for creating batch files.
 type OperationHandler interface {
 	// PrepareTxnFiles operations will create relevant batch files, store them in CAS and return anchor string.
+	// An error that wraps ErrInvalidOperation is returned if one of the operations is invalid.
 	PrepareTxnFiles(ops []*operation.QueuedOperation) (*AnchoringInfo, error)
 }
 
@@ -0,0 +1,361 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+)
+
+const defaultConfirmationDepth = 6
+
+// ErrPendingAnchorNotFound is returned by the pending anchor store if the anchor is not in the store.
+var ErrPendingAnchorNotFound = errors.New("pending anchor not found")
+
+// PendingAnchor holds an anchor that was written to the anchoring system but has not yet been observed
+// on the ledger.
+type PendingAnchor struct {
+	AnchorString        string                     `json:"anchorString"`
+	Artifacts           []*protocol.AnchorDocument `json:"artifacts,omitempty"`
+	OperationReferences []*operation.Reference     `json:"operationReferences"`
+	ProtocolVersion     uint64                     `json:"protocolVersion"`
+	Attempts            int                        `json:"attempts"`
+	LastWritten         time.Time                  `json:"lastWritten"`
+	// Observed is true if the anchor transaction was observed on the ledger. An observed anchor is kept until the
+	// confirmation depth is reached so that it can be written again if the transaction is orphaned.
+	Observed bool `json:"observed,omitempty"`
+	// TransactionTime is the transaction time of the observed anchor transaction.
+	TransactionTime uint64 `json:"transactionTime,omitempty"`
+}
+
+// PendingAnchorStore persists the anchors that are awaiting confirmation.
+type PendingAnchorStore interface {
+	// Put saves the pending anchor.
+	Put(anchor *PendingAnchor) error
+	// Get returns the pending anchor for the given anchor string or ErrPendingAnchorNotFound if the anchor
+	// is not in the store.
+	Get(anchorString string) (*PendingAnchor, error)
+	// GetAll returns all pending anchors.
+	GetAll() ([]*PendingAnchor, error)
+	// Delete deletes the pending anchor for the given anchor string.
+	Delete(anchorString string) error
+}
+
+// AnchorTracker tracks the anchors written by the batch writer until the anchor transactions are observed on the
+// ledger. If an anchor is not observed within the confirmation timeout then the batch writer writes the same anchor
+// again (the batch files have already been stored in CAS).
+//
+// An observed anchor is kept until its transaction has reached the confirmation depth. If the transaction is
+// orphaned by a ledger reorganization before then, the anchor is tracked again (and written again if it is not
+// observed within the confirmation timeout).
+//
+// The tracker must be notified of the transactions that are received from the ledger (see observer.WithTxnListeners).
+// Since the original anchor transaction may still appear on the ledger after the anchor was written again, an
+// operation filter that discards duplicate operations (see opfilter.DuplicateFilter) should be configured on the
+// transaction processor.
+type AnchorTracker struct {
+	store               PendingAnchorStore
+	confirmationTimeout time.Duration
+	confirmationDepth   uint64
+	maxAttempts         int
+	logger              *log.Log
+	mutex               sync.Mutex
+	// latestTxnTime is the latest transaction time that was observed.
+	latestTxnTime uint64
+}
+
+// AnchorTrackerOption is an option for the anchor tracker.
+type AnchorTrackerOption func(t *AnchorTracker)
+
+// WithPendingAnchorStore sets the store that holds the anchors that are awaiting confirmation. If not set then
+// an in-memory store is used, i.e. anchors are no longer tracked after a restart.
+func WithPendingAnchorStore(store PendingAnchorStore) AnchorTrackerOption {
+	return func(t *AnchorTracker) {
+		t.store = store
+	}
+}
+
+// WithMaxAnchorAttempts sets the number of times that an anchor is written (including the initial write) before
+// the anchor is no longer tracked. Zero (the default) means that the anchor is written until it is confirmed.
+func WithMaxAnchorAttempts(maxAttempts int) AnchorTrackerOption {
+	return func(t *AnchorTracker) {
+		t.maxAttempts = maxAttempts
+	}
+}
+
+// WithConfirmationDepth sets the number of transaction times (e.g. blocks) after which an observed anchor
+// transaction is considered final, i.e. the anchor is no longer tracked. Until then, the anchor is tracked again if
+// its transaction is orphaned by a ledger reorganization. Defaults to 6.
+func WithConfirmationDepth(depth uint64) AnchorTrackerOption {
+	return func(t *AnchorTracker) {
+		t.confirmationDepth = depth
+	}
+}
+
+// NewAnchorTracker returns a new anchor tracker. An anchor is written again if it has not been observed on the
+// ledger within the given confirmation timeout.
+func NewAnchorTracker(confirmationTimeout time.Duration, opts ...AnchorTrackerOption) *AnchorTracker {
+	t := &AnchorTracker{
+		store:               newMemPendingAnchorStore(),
+		confirmationTimeout: confirmationTimeout,
+		confirmationDepth:   defaultConfirmationDepth,
+		logger:              log.New(loggerModule),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// TxnObserved marks the anchor of the given transaction as observed (if it is being tracked) and stops tracking
+// the observed anchors whose transactions have reached the confirmation depth.
+func (t *AnchorTracker) TxnObserved(sidetreeTxn *txn.SidetreeTxn) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	defer t.pruneConfirmed(sidetreeTxn.TransactionTime)
+
+	anchor, err := t.store.Get(sidetreeTxn.AnchorString)
+	if err != nil {
+		if !errors.Is(err, ErrPendingAnchorNotFound) {
+			t.logger.Warn("Failed to retrieve pending anchor", log.WithAnchorString(sidetreeTxn.AnchorString),
+				log.WithError(err))
+		}
+
+		return
+	}
+
+	anchor.Observed = true
+	anchor.TransactionTime = sidetreeTxn.TransactionTime
+
+	if err := t.store.Put(anchor); err != nil {
+		t.logger.Warn("Failed to save observed anchor", log.WithAnchorString(sidetreeTxn.AnchorString),
+			log.WithError(err))
+
+		return
+	}
+
+	t.logger.Debug("Anchor observed", log.WithAnchorString(sidetreeTxn.AnchorString),
+		log.WithTransactionTime(sidetreeTxn.TransactionTime), log.WithTransactionNumber(sidetreeTxn.TransactionNumber))
+}
+
+// TxnReverted tracks the anchor of the given transaction, which was orphaned by a ledger reorganization, again (if the
+// anchor was observed and has not yet reached the confirmation depth). The anchor is written again if it is not
+// observed within the confirmation timeout.
+func (t *AnchorTracker) TxnReverted(sidetreeTxn *txn.SidetreeTxn) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	anchor, err := t.store.Get(sidetreeTxn.AnchorString)
+	if err != nil {
+		if !errors.Is(err, ErrPendingAnchorNotFound) {
+			t.logger.Warn("Failed to retrieve pending anchor", log.WithAnchorString(sidetreeTxn.AnchorString),
+				log.WithError(err))
+		}
+
+		return
+	}
+
+	if !anchor.Observed {
+		return
+	}
+
+	anchor.Observed = false
+	anchor.TransactionTime = 0
+	anchor.LastWritten = time.Now()
+
+	if err := t.store.Put(anchor); err != nil {
+		t.logger.Warn("Failed to track orphaned anchor", log.WithAnchorString(sidetreeTxn.AnchorString),
+			log.WithError(err))
+
+		return
+	}
+
+	t.logger.Info("Anchor transaction was orphaned by a ledger reorganization. Tracking anchor again.",
+		log.WithAnchorString(sidetreeTxn.AnchorString), log.WithTransactionTime(sidetreeTxn.TransactionTime))
+}
+
+// pruneConfirmed deletes the observed anchors whose transactions have reached the confirmation depth. The store
+// is only checked when a later transaction time is observed.
+func (t *AnchorTracker) pruneConfirmed(txnTime uint64) {
+	if txnTime <= t.latestTxnTime {
+		return
+	}
+
+	t.latestTxnTime = txnTime
+
+	anchors, err := t.store.GetAll()
+	if err != nil {
+		t.logger.Warn("Failed to retrieve pending anchors", log.WithError(err))
+
+		return
+	}
+
+	for _, anchor := range anchors {
+		if !anchor.Observed || anchor.TransactionTime+t.confirmationDepth > txnTime {
+			continue
+		}
+
+		if err := t.store.Delete(anchor.AnchorString); err != nil {
+			t.logger.Warn("Failed to delete confirmed anchor from pending anchor store",
+				log.WithAnchorString(anchor.AnchorString), log.WithError(err))
+
+			continue
+		}
+
+		t.logger.Debug("Anchor confirmed", log.WithAnchorString(anchor.AnchorString),
+			log.WithTransactionTime(anchor.TransactionTime))
+	}
+}
+
+// PendingAnchors returns the anchors that have not yet been observed.
+func (t *AnchorTracker) PendingAnchors() ([]*PendingAnchor, error) {
+	anchors, err := t.store.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*PendingAnchor
+
+	for _, anchor := range anchors {
+		if !anchor.Observed {
+			pending = append(pending, anchor)
+		}
+	}
+
+	return pending, nil
+}
+
+func (t *AnchorTracker) track(anchor *PendingAnchor) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.store.Put(anchor)
+}
+
+// expired returns the anchors that have not been observed within the confirmation timeout (the
+// anchor that was written first is returned first).
+func (t *AnchorTracker) expired(now time.Time) ([]*PendingAnchor, error) {
+	anchors, err := t.store.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get pending anchors: %w", err)
+	}
+
+	var expired []*PendingAnchor
+
+	for _, anchor := range anchors {
+		if !anchor.Observed && now.Sub(anchor.LastWritten) >= t.confirmationTimeout {
+			expired = append(expired, anchor)
+		}
+	}
+
+	sort.SliceStable(expired, func(i, j int) bool {
+		return expired[i].LastWritten.Before(expired[j].LastWritten)
+	})
+
+	return expired, nil
+}
+
+// attemptsExhausted returns true if the given anchor has been written the maximum number of times.
+func (t *AnchorTracker) attemptsExhausted(anchor *PendingAnchor) bool {
+	return t.maxAttempts > 0 && anchor.Attempts >= t.maxAttempts
+}
+
+// update saves the given anchor unless the anchor was observed (or confirmed) in the meantime.
+func (t *AnchorTracker) update(anchor *PendingAnchor) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	current, err := t.store.Get(anchor.AnchorString)
+	if err != nil {
+		if errors.Is(err, ErrPendingAnchorNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	if current.Observed {
+		return nil
+	}
+
+	return t.store.Put(anchor)
+}
+
+func (t *AnchorTracker) remove(anchorString string) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.store.Delete(anchorString)
+}
+
+// memPendingAnchorStore is the default, in-memory pending anchor store.
+type memPendingAnchorStore struct {
+	mutex   sync.RWMutex
+	anchors map[string]*PendingAnchor
+}
+
+func newMemPendingAnchorStore() *memPendingAnchorStore {
+	return &memPendingAnchorStore{anchors: make(map[string]*PendingAnchor)}
+}
+
+func (s *memPendingAnchorStore) Put(anchor *PendingAnchor) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	anchorCopy := *anchor
+	s.anchors[anchor.AnchorString] = &anchorCopy
+
+	return nil
+}
+
+func (s *memPendingAnchorStore) Get(anchorString string) (*PendingAnchor, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	anchor, ok := s.anchors[anchorString]
+	if !ok {
+		return nil, ErrPendingAnchorNotFound
+	}
+
+	anchorCopy := *anchor
+
+	return &anchorCopy, nil
+}
+
+func (s *memPendingAnchorStore) GetAll() ([]*PendingAnchor, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	anchors := make([]*PendingAnchor, 0, len(s.anchors))
+
+	for _, anchor := range s.anchors {
+		anchorCopy := *anchor
+		anchors = append(anchors, &anchorCopy)
+	}
+
+	return anchors, nil
+}
+
+func (s *memPendingAnchorStore) Delete(anchorString string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.anchors, anchorString)
+
+	return nil
+}
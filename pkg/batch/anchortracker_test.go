@@ -0,0 +1,234 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package batch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+)
+
+var _ observer.TxnListener = (*AnchorTracker)(nil)
+
+func TestAnchorTracker(t *testing.T) {
+	now := time.Now()
+
+	anchor1 := &PendingAnchor{AnchorString: "1.anchor1", Attempts: 1, LastWritten: now.Add(-time.Minute)}
+	anchor2 := &PendingAnchor{AnchorString: "1.anchor2", Attempts: 1, LastWritten: now.Add(-2 * time.Minute)}
+	anchor3 := &PendingAnchor{AnchorString: "1.anchor3", Attempts: 1, LastWritten: now}
+
+	t.Run("success", func(t *testing.T) {
+		tracker := NewAnchorTracker(30*time.Second, WithMaxAnchorAttempts(2))
+
+		require.NoError(t, tracker.track(anchor1))
+		require.NoError(t, tracker.track(anchor2))
+		require.NoError(t, tracker.track(anchor3))
+
+		expired, err := tracker.expired(now)
+		require.NoError(t, err)
+		require.Len(t, expired, 2)
+		require.Equal(t, anchor2.AnchorString, expired[0].AnchorString)
+		require.Equal(t, anchor1.AnchorString, expired[1].AnchorString)
+		require.False(t, tracker.attemptsExhausted(expired[0]))
+
+		expired[0].Attempts++
+		expired[0].LastWritten = now
+
+		require.NoError(t, tracker.update(expired[0]))
+		require.True(t, tracker.attemptsExhausted(expired[0]))
+
+		expired, err = tracker.expired(now)
+		require.NoError(t, err)
+		require.Len(t, expired, 1)
+		require.Equal(t, anchor1.AnchorString, expired[0].AnchorString)
+
+		tracker.TxnObserved(&txn.SidetreeTxn{AnchorString: anchor1.AnchorString})
+		tracker.TxnObserved(&txn.SidetreeTxn{AnchorString: "1.unknown"})
+
+		// A confirmed anchor is not added back by an update.
+		require.NoError(t, tracker.update(anchor1))
+
+		pending, err := tracker.PendingAnchors()
+		require.NoError(t, err)
+		require.Len(t, pending, 2)
+
+		require.NoError(t, tracker.remove(anchor2.AnchorString))
+
+		pending, err = tracker.PendingAnchors()
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		require.Equal(t, anchor3.AnchorString, pending[0].AnchorString)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		errExpected := errors.New("injected store error")
+
+		tracker := NewAnchorTracker(time.Second, WithPendingAnchorStore(&mockPendingAnchorStore{err: errExpected}))
+
+		_, err := tracker.expired(now)
+		require.True(t, errors.Is(err, errExpected))
+
+		err = tracker.update(anchor1)
+		require.True(t, errors.Is(err, errExpected))
+
+		tracker.TxnObserved(&txn.SidetreeTxn{AnchorString: anchor1.AnchorString})
+		tracker.TxnReverted(&txn.SidetreeTxn{AnchorString: anchor1.AnchorString})
+	})
+
+	t.Run("delete error", func(t *testing.T) {
+		store := &mockPendingAnchorStore{
+			memPendingAnchorStore: newMemPendingAnchorStore(),
+			deleteErr:             errors.New("injected delete error"),
+		}
+
+		tracker := NewAnchorTracker(time.Second, WithPendingAnchorStore(store), WithConfirmationDepth(1))
+
+		require.NoError(t, tracker.track(anchor1))
+
+		tracker.TxnObserved(&txn.SidetreeTxn{AnchorString: anchor1.AnchorString, TransactionTime: 1})
+		tracker.TxnObserved(&txn.SidetreeTxn{AnchorString: "1.other", TransactionTime: 2})
+
+		pending, err := tracker.PendingAnchors()
+		require.NoError(t, err)
+		require.Empty(t, pending)
+
+		// The confirmed anchor could not be deleted.
+		a, err := store.Get(anchor1.AnchorString)
+		require.NoError(t, err)
+		require.True(t, a.Observed)
+	})
+
+	t.Run("reorg", func(t *testing.T) {
+		store := newMemPendingAnchorStore()
+
+		tracker := NewAnchorTracker(30*time.Second, WithPendingAnchorStore(store), WithConfirmationDepth(2))
+
+		require.NoError(t, tracker.track(anchor1))
+		require.NoError(t, tracker.track(anchor2))
+
+		tracker.TxnObserved(&txn.SidetreeTxn{AnchorString: anchor1.AnchorString, TransactionTime: 10})
+
+		// An observed anchor is not written again.
+		expired, err := tracker.expired(now)
+		require.NoError(t, err)
+		require.Len(t, expired, 1)
+		require.Equal(t, anchor2.AnchorString, expired[0].AnchorString)
+
+		// A tracked anchor that wasn't observed is not affected by a revert.
+		tracker.TxnReverted(&txn.SidetreeTxn{AnchorString: anchor2.AnchorString, TransactionTime: 9})
+
+		a, err := store.Get(anchor2.AnchorString)
+		require.NoError(t, err)
+		require.Equal(t, anchor2.LastWritten.UnixNano(), a.LastWritten.UnixNano())
+
+		// The transaction of the observed anchor is orphaned so the anchor is tracked again.
+		tracker.TxnReverted(&txn.SidetreeTxn{AnchorString: anchor1.AnchorString, TransactionTime: 10})
+
+		pending, err := tracker.PendingAnchors()
+		require.NoError(t, err)
+		require.Len(t, pending, 2)
+
+		expired, err = tracker.expired(time.Now().Add(30 * time.Second))
+		require.NoError(t, err)
+		require.Len(t, expired, 2)
+		require.Equal(t, anchor1.AnchorString, expired[1].AnchorString)
+
+		// The anchor is observed again and is confirmed once the confirmation depth is reached.
+		tracker.TxnObserved(&txn.SidetreeTxn{AnchorString: anchor1.AnchorString, TransactionTime: 11})
+		tracker.TxnObserved(&txn.SidetreeTxn{AnchorString: "1.other", TransactionTime: 12})
+
+		a, err = store.Get(anchor1.AnchorString)
+		require.NoError(t, err)
+		require.True(t, a.Observed)
+
+		tracker.TxnObserved(&txn.SidetreeTxn{AnchorString: "1.other", TransactionTime: 13})
+
+		_, err = store.Get(anchor1.AnchorString)
+		require.True(t, errors.Is(err, ErrPendingAnchorNotFound))
+
+		// A confirmed anchor is no longer tracked after a revert.
+		tracker.TxnReverted(&txn.SidetreeTxn{AnchorString: anchor1.AnchorString, TransactionTime: 11})
+
+		pending, err = tracker.PendingAnchors()
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		require.Equal(t, anchor2.AnchorString, pending[0].AnchorString)
+	})
+}
+
+func TestMemPendingAnchorStore(t *testing.T) {
+	s := newMemPendingAnchorStore()
+
+	anchor := &PendingAnchor{
+		AnchorString:        "1.anchor",
+		OperationReferences: []*operation.Reference{{UniqueSuffix: "abc", Type: operation.TypeCreate}},
+		Attempts:            1,
+	}
+
+	require.NoError(t, s.Put(anchor))
+
+	// Changes to the anchor after it was stored should not affect the store.
+	anchor.Attempts = 2
+
+	a, err := s.Get("1.anchor")
+	require.NoError(t, err)
+	require.Equal(t, 1, a.Attempts)
+
+	anchors, err := s.GetAll()
+	require.NoError(t, err)
+	require.Len(t, anchors, 1)
+
+	require.NoError(t, s.Delete("1.anchor"))
+
+	_, err = s.Get("1.anchor")
+	require.True(t, errors.Is(err, ErrPendingAnchorNotFound))
+}
+
+type mockPendingAnchorStore struct {
+	*memPendingAnchorStore
+
+	err       error
+	deleteErr error
+}
+
+func (m *mockPendingAnchorStore) Put(anchor *PendingAnchor) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	return m.memPendingAnchorStore.Put(anchor)
+}
+
+func (m *mockPendingAnchorStore) Get(anchorString string) (*PendingAnchor, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return m.memPendingAnchorStore.Get(anchorString)
+}
+
+func (m *mockPendingAnchorStore) GetAll() ([]*PendingAnchor, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return m.memPendingAnchorStore.GetAll()
+}
+
+func (m *mockPendingAnchorStore) Delete(anchorString string) error {
+	if m.deleteErr != nil {
+		return m.deleteErr
+	}
+
+	return m.memPendingAnchorStore.Delete(anchorString)
+}
@@ -7,7 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package cutter
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"sort"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
@@ -31,6 +33,15 @@ type OperationQueue interface {
 	Len() uint
 }
 
+// batchFileSizeChecker is implemented by operation handlers that are able to determine whether the batch files
+// created for the given operations would exceed the maximum file sizes allowed by the protocol.
+type batchFileSizeChecker interface {
+	ExceedsMaxFileSize(ops []*operation.QueuedOperation) (bool, error)
+	// MaxFileSizeRatio returns the fraction of the maximum file size that the given operation adds (at most) to
+	// the batch files.
+	MaxFileSizeRatio(op *operation.QueuedOperation) (float64, error)
+}
+
 // Committer is invoked to commit a batch Cut. The new number of pending items
 // in the queue is returned.
 type Committer = func() (pending uint, err error)
@@ -53,6 +64,18 @@ type Result struct {
 type BatchCutter struct {
 	pendingBatch OperationQueue
 	client       protocol.Client
+	sizes        sizeTracker
+}
+
+// sizeTracker tracks the size of the batch files for the pending operations so that the batch files don't have to
+// be estimated for all pending operations each time a batch is cut.
+type sizeTracker struct {
+	protocolVersion uint64
+	// ratios holds the file size ratios (see MaxFileSizeRatio) of the pending operations keyed by the hash
+	// of the operation request.
+	ratios map[[sha256.Size]byte]float64
+	// withinLimits holds the hash of the last batch for which the batch files were found to be within the limits.
+	withinLimits [sha256.Size]byte
 }
 
 // New creates a Cutter implementation.
@@ -73,6 +96,8 @@ func (r *BatchCutter) Add(op *operation.QueuedOperation, protocolVersion uint64)
 // Cut returns the current batch along with number of items that should be remaining in the queue after the committer is called.
 // If force is false then the batch will be cut only if it has reached the max batch size (as specified in the protocol)
 // If force is true then the batch will be cut if there is at least one Data in the batch
+// The batch is also cut (even if force is false) as soon as the batch files would exceed the maximum file size allowed
+// by the protocol (if the operation handler of the protocol version is able to estimate the size of the batch files).
 // Note that the operations are removed from the queue when Result.Ack is invoked, otherwise Result.Nack should be called
 // in order to place the operations back in the queue so that they be processed again.
 func (r *BatchCutter) Cut(force bool) (Result, error) {
@@ -84,7 +109,11 @@ func (r *BatchCutter) Cut(force bool) (Result, error) {
 	}
 
 	maxOperationsPerBatch := currentProtocol.Protocol().MaxOperationCount
-	if !force && pending < maxOperationsPerBatch {
+	full := pending >= maxOperationsPerBatch
+
+	_, checkSize := currentProtocol.OperationHandler().(batchFileSizeChecker)
+
+	if pending == 0 || !force && !full && !checkSize {
 		return Result{Pending: pending}, nil
 	}
 
@@ -96,9 +125,13 @@ func (r *BatchCutter) Cut(force bool) (Result, error) {
 
 	operations, protocolVersion := getOperationsAtProtocolVersion(ops)
 
-	batchSize = uint(len(operations))
+	if len(operations) == 0 {
+		return Result{Pending: pending}, nil
+	}
 
-	if batchSize == 0 {
+	batchSize, exceeds := r.maxOperationsWithinFileSize(operations, protocolVersion)
+
+	if !force && !full && !exceeds {
 		return Result{Pending: pending}, nil
 	}
 
@@ -121,6 +154,136 @@ func (r *BatchCutter) Cut(force bool) (Result, error) {
 	}, nil
 }
 
+// maxOperationsWithinFileSize returns the largest number of operations (from the head of the given operations) for
+// which none of the batch files exceed the maximum file size allowed by the protocol, along with true if the batch
+// files for all of the given operations would exceed the maximum file size. At least one operation is returned.
+func (r *BatchCutter) maxOperationsWithinFileSize(ops []*operation.QueuedOperation,
+	protocolVersion uint64) (uint, bool) {
+	pv, err := r.client.Get(protocolVersion)
+	if err != nil {
+		logger.Warn("Unable to check batch file sizes since the protocol version could not be retrieved",
+			log.WithGenesisTime(protocolVersion), log.WithError(err))
+
+		return uint(len(ops)), false
+	}
+
+	checker, ok := pv.OperationHandler().(batchFileSizeChecker)
+	if !ok {
+		return uint(len(ops)), false
+	}
+
+	within, batchHash, err := r.sizes.update(checker, ops, protocolVersion)
+	if err != nil {
+		// The batch writer rejects the batch (or the invalid operations) when it prepares the batch files.
+		logger.Warn("Unable to check batch file sizes", log.WithTotal(len(ops)), log.WithError(err))
+
+		return uint(len(ops)), false
+	}
+
+	if within == len(ops) || batchHash == r.sizes.withinLimits {
+		return uint(len(ops)), false
+	}
+
+	// The batch files are estimated only if the tracked sizes are close to the limits.
+	exceeds, err := checker.ExceedsMaxFileSize(ops)
+	if err != nil {
+		logger.Warn("Unable to check batch file sizes", log.WithTotal(len(ops)), log.WithError(err))
+
+		return uint(len(ops)), false
+	}
+
+	if !exceeds {
+		r.sizes.withinLimits = batchHash
+
+		return uint(len(ops)), false
+	}
+
+	// Find the first operation that causes a batch file to exceed its maximum size. (We already know that the batch
+	// files for all operations exceed the maximum size and that the batch files for the first 'within' operations
+	// don't.)
+	num := within + sort.Search(len(ops)-1-within, func(i int) bool {
+		exceeds, err := checker.ExceedsMaxFileSize(ops[:within+i+1])
+
+		return err != nil || exceeds
+	})
+
+	if num == 0 {
+		logger.Warn("Batch files for a single operation exceed the maximum file size allowed by the protocol",
+			log.WithSuffix(ops[0].UniqueSuffix))
+
+		return 1, true
+	}
+
+	logger.Info("Reducing batch size so that batch files do not exceed the maximum file size allowed by the protocol",
+		log.WithTotal(len(ops)), log.WithSize(num))
+
+	return uint(num), true
+}
+
+// update updates the tracked file size ratios for the given operations and returns the number of operations (from
+// the head of the given operations) for which the batch files are certain to be within the limits, along with a
+// hash of the given operations. The file size ratio is only computed for operations that weren't seen before.
+func (t *sizeTracker) update(checker batchFileSizeChecker, ops []*operation.QueuedOperation,
+	protocolVersion uint64) (int, [sha256.Size]byte, error) {
+	if t.protocolVersion != protocolVersion {
+		t.protocolVersion = protocolVersion
+		t.ratios = nil
+		t.withinLimits = [sha256.Size]byte{}
+	}
+
+	// Only the ratios of the given operations are kept so that the ratios of operations that were
+	// removed from the queue are discarded.
+	ratios := make(map[[sha256.Size]byte]float64, len(ops))
+	batchHash := sha256.New()
+
+	var total float64
+
+	within := 0
+
+	for i, op := range ops {
+		key := sha256.Sum256(op.OperationRequest)
+
+		ratio, ok := t.ratios[key]
+		if !ok {
+			var err error
+
+			ratio, err = checker.MaxFileSizeRatio(op)
+			if err != nil {
+				t.add(ratios)
+
+				return 0, [sha256.Size]byte{}, err
+			}
+		}
+
+		ratios[key] = ratio
+		_, _ = batchHash.Write(key[:])
+
+		total += ratio
+		if total <= 1 && within == i {
+			within = i + 1
+		}
+	}
+
+	t.ratios = ratios
+
+	var h [sha256.Size]byte
+
+	copy(h[:], batchHash.Sum(nil))
+
+	return within, h, nil
+}
+
+// add adds the given ratios to the tracked ratios.
+func (t *sizeTracker) add(ratios map[[sha256.Size]byte]float64) {
+	if t.ratios == nil {
+		t.ratios = make(map[[sha256.Size]byte]float64, len(ratios))
+	}
+
+	for key, ratio := range ratios {
+		t.ratios[key] = ratio
+	}
+}
+
 // getOperationsAtProtocolVersion iterates through the operations and returns the operations which are at the same protocol genesis time.
 func getOperationsAtProtocolVersion(opsAtTime []*operation.QueuedOperationAtTime) ([]*operation.QueuedOperation, uint64) {
 	var ops []*operation.QueuedOperation
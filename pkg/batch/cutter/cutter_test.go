@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package cutter
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -123,3 +124,261 @@ func TestBatchCutter(t *testing.T) {
 
 	require.Zero(t, result.Ack())
 }
+
+func TestBatchCutter_MaxFileSize(t *testing.T) {
+	newCutter := func(handler *mockSizeCheckingOperationHandler) *BatchCutter {
+		c := mocks.NewMockProtocolClient()
+		c.Protocol.MaxOperationCount = 3
+		c.CurrentVersion.ProtocolReturns(c.Protocol)
+		c.CurrentVersion.OperationHandlerReturns(handler)
+
+		r := New(c, &opqueue.MemQueue{})
+
+		for _, op := range []*operation.QueuedOperation{operation1, operation2, operation3} {
+			_, err := r.Add(op, 10)
+			require.NoError(t, err)
+		}
+
+		return r
+	}
+
+	t.Run("batch files at maximum size", func(t *testing.T) {
+		handler := &mockSizeCheckingOperationHandler{OperationHandler: &mocks.OperationHandler{}, maxOps: 3}
+
+		result, err := newCutter(handler).Cut(false)
+		require.NoError(t, err)
+		require.Len(t, result.Operations, 3)
+		require.Zero(t, result.Pending)
+	})
+
+	t.Run("batch cut before maximum file size is exceeded", func(t *testing.T) {
+		handler := &mockSizeCheckingOperationHandler{OperationHandler: &mocks.OperationHandler{}, maxOps: 2}
+
+		r := newCutter(handler)
+
+		result, err := r.Cut(false)
+		require.NoError(t, err)
+		require.Len(t, result.Operations, 2)
+		require.Equal(t, operation1, result.Operations[0])
+		require.Equal(t, operation2, result.Operations[1])
+		require.Equal(t, uint(1), result.Pending)
+
+		require.Equal(t, uint(1), result.Ack())
+
+		result, err = r.Cut(true)
+		require.NoError(t, err)
+		require.Len(t, result.Operations, 1)
+		require.Equal(t, operation3, result.Operations[0])
+		require.Zero(t, result.Pending)
+	})
+
+	t.Run("single operation exceeds maximum file size", func(t *testing.T) {
+		handler := &mockSizeCheckingOperationHandler{OperationHandler: &mocks.OperationHandler{}, maxOps: 0}
+
+		result, err := newCutter(handler).Cut(false)
+		require.NoError(t, err)
+		require.Len(t, result.Operations, 1)
+		require.Equal(t, operation1, result.Operations[0])
+		require.Equal(t, uint(2), result.Pending)
+	})
+
+	t.Run("batch cut when maximum file size is reached before maximum operation count", func(t *testing.T) {
+		c := mocks.NewMockProtocolClient()
+		c.Protocol.MaxOperationCount = 10
+		c.CurrentVersion.ProtocolReturns(c.Protocol)
+
+		handler := &mockSizeCheckingOperationHandler{OperationHandler: &mocks.OperationHandler{}, maxOps: 3}
+		c.CurrentVersion.OperationHandlerReturns(handler)
+
+		r := New(c, &opqueue.MemQueue{})
+
+		for _, op := range []*operation.QueuedOperation{operation1, operation2, operation3} {
+			_, err := r.Add(op, 10)
+			require.NoError(t, err)
+
+			result, err := r.Cut(false)
+			require.NoError(t, err)
+			require.Empty(t, result.Operations)
+		}
+
+		_, err := r.Add(operation4, 10)
+		require.NoError(t, err)
+
+		result, err := r.Cut(false)
+		require.NoError(t, err)
+		require.Len(t, result.Operations, 3)
+		require.Equal(t, operation3, result.Operations[2])
+		require.Equal(t, uint(1), result.Pending)
+
+		require.Equal(t, uint(1), result.Ack())
+
+		result, err = r.Cut(false)
+		require.NoError(t, err)
+		require.Empty(t, result.Operations)
+		require.Equal(t, uint(1), result.Pending)
+	})
+
+	t.Run("batch sizes are tracked incrementally", func(t *testing.T) {
+		c := mocks.NewMockProtocolClient()
+		c.Protocol.MaxOperationCount = 10
+		c.CurrentVersion.ProtocolReturns(c.Protocol)
+
+		// The tracked sizes exceed the limit before the batch files do.
+		handler := &mockSizeCheckingOperationHandler{OperationHandler: &mocks.OperationHandler{}, maxOps: 4, ratio: 0.5}
+		c.CurrentVersion.OperationHandlerReturns(handler)
+
+		r := New(c, &opqueue.MemQueue{})
+
+		for _, op := range []*operation.QueuedOperation{operation1, operation2} {
+			_, err := r.Add(op, 10)
+			require.NoError(t, err)
+		}
+
+		for i := 0; i < 3; i++ {
+			result, err := r.Cut(false)
+			require.NoError(t, err)
+			require.Empty(t, result.Operations)
+		}
+
+		// The ratio is computed once per operation and the batch files are not estimated while the
+		// tracked sizes are within the limit.
+		require.Equal(t, 2, handler.ratioCalls)
+		require.Zero(t, handler.exceedsCalls)
+
+		_, err := r.Add(operation3, 10)
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			result, err := r.Cut(false)
+			require.NoError(t, err)
+			require.Empty(t, result.Operations)
+		}
+
+		// The batch files are estimated once for the new batch.
+		require.Equal(t, 3, handler.ratioCalls)
+		require.Equal(t, 1, handler.exceedsCalls)
+
+		for _, op := range []*operation.QueuedOperation{operation4, operation5} {
+			_, err = r.Add(op, 10)
+			require.NoError(t, err)
+		}
+
+		result, err := r.Cut(false)
+		require.NoError(t, err)
+		require.Len(t, result.Operations, 4)
+		require.Equal(t, uint(1), result.Pending)
+
+		// Only the operations beyond the tracked limit are searched.
+		require.Equal(t, 5, handler.ratioCalls)
+		require.Equal(t, 3, handler.exceedsCalls)
+	})
+
+	t.Run("size check error", func(t *testing.T) {
+		handler := &mockSizeCheckingOperationHandler{
+			OperationHandler: &mocks.OperationHandler{},
+			err:              errors.New("injected size check error"),
+		}
+
+		result, err := newCutter(handler).Cut(false)
+		require.NoError(t, err)
+		require.Len(t, result.Operations, 3)
+		require.Zero(t, result.Pending)
+	})
+
+	t.Run("protocol version error", func(t *testing.T) {
+		c := mocks.NewMockProtocolClient()
+		c.Protocol.MaxOperationCount = 3
+		c.Protocol.GenesisTime = 20
+		c.CurrentVersion.ProtocolReturns(c.Protocol)
+		c.CurrentVersion.OperationHandlerReturns(
+			&mockSizeCheckingOperationHandler{OperationHandler: &mocks.OperationHandler{}, maxOps: 1},
+		)
+
+		r := New(c, &opqueue.MemQueue{})
+
+		_, err := r.Add(operation1, 10)
+		require.NoError(t, err)
+		_, err = r.Add(operation2, 10)
+		require.NoError(t, err)
+
+		result, err := r.Cut(true)
+		require.NoError(t, err)
+		require.Len(t, result.Operations, 2)
+	})
+}
+
+// mockSizeCheckingOperationHandler reports that the batch files exceed the maximum size if there are more than
+// maxOps operations. The file size ratio of each operation is ratio (if set) or 1/maxOps.
+type mockSizeCheckingOperationHandler struct {
+	*mocks.OperationHandler
+
+	maxOps int
+	ratio  float64
+	err    error
+
+	exceedsCalls int
+	ratioCalls   int
+}
+
+func (m *mockSizeCheckingOperationHandler) ExceedsMaxFileSize(ops []*operation.QueuedOperation) (bool, error) {
+	m.exceedsCalls++
+
+	if m.err != nil {
+		return false, m.err
+	}
+
+	return len(ops) > m.maxOps, nil
+}
+
+func (m *mockSizeCheckingOperationHandler) MaxFileSizeRatio(*operation.QueuedOperation) (float64, error) {
+	m.ratioCalls++
+
+	if m.err != nil {
+		return 0, m.err
+	}
+
+	if m.ratio > 0 {
+		return m.ratio, nil
+	}
+
+	if m.maxOps == 0 {
+		return 2, nil
+	}
+
+	return 1 / float64(m.maxOps), nil
+}
+
+func TestBatchCutter_PriorityQueue(t *testing.T) {
+	c := mocks.NewMockProtocolClient()
+	c.Protocol.MaxOperationCount = 2
+	c.CurrentVersion.ProtocolReturns(c.Protocol)
+
+	q, err := opqueue.NewPriorityQueue(func(operation.Priority) (opqueue.Queue, error) {
+		return &opqueue.MemQueue{}, nil
+	})
+	require.NoError(t, err)
+
+	r := New(c, q)
+
+	update1 := &operation.QueuedOperation{UniqueSuffix: "1", OperationRequest: []byte("update1"), Type: operation.TypeUpdate}
+	update2 := &operation.QueuedOperation{UniqueSuffix: "2", OperationRequest: []byte("update2"), Type: operation.TypeUpdate}
+	recover3 := &operation.QueuedOperation{UniqueSuffix: "3", OperationRequest: []byte("recover3"), Type: operation.TypeRecover}
+
+	for _, op := range []*operation.QueuedOperation{update1, update2, recover3} {
+		_, err = r.Add(op, 10)
+		require.NoError(t, err)
+	}
+
+	result, err := r.Cut(false)
+	require.NoError(t, err)
+	require.Len(t, result.Operations, 2)
+	require.Equal(t, recover3, result.Operations[0])
+	require.Equal(t, update1, result.Operations[1])
+	require.Equal(t, uint(1), result.Pending)
+	require.Equal(t, uint(1), result.Ack())
+
+	result, err = r.Cut(true)
+	require.NoError(t, err)
+	require.Len(t, result.Operations, 1)
+	require.Equal(t, update2, result.Operations[0])
+}
@@ -0,0 +1,609 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opqueue
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+)
+
+var logger = log.New("sidetree-core-opqueue")
+
+const (
+	defaultMaxSegmentSize = 16 * 1024 * 1024
+
+	segmentPrefix = "segment-"
+	segmentSuffix = ".log"
+
+	// recordHeaderSize is the size of the record header: payload length (4 bytes) followed by CRC32 of the payload (4 bytes).
+	recordHeaderSize = 8
+
+	// maxRecordSize is the maximum size of a record payload. A record header with a larger payload length is treated
+	// as corrupt so that a damaged length doesn't cause a huge allocation.
+	maxRecordSize = 4 * 1024 * 1024
+)
+
+type recordType string
+
+const (
+	recordTypeAdd recordType = "add"
+	recordTypeAck recordType = "ack"
+)
+
+// fileRecord is a single entry in a segment file. An 'add' record holds a queued operation along with its
+// sequence number and an 'ack' record holds the sequence numbers of operations that have been committed.
+type fileRecord struct {
+	Type            recordType                 `json:"type"`
+	Seq             uint64                     `json:"seq,omitempty"`
+	Operation       *operation.QueuedOperation `json:"operation,omitempty"`
+	ProtocolVersion uint64                     `json:"protocolVersion,omitempty"`
+	Acked           []uint64                   `json:"acked,omitempty"`
+}
+
+type fileQueueItem struct {
+	seq     uint64
+	segment uint64
+	op      *operation.QueuedOperationAtTime
+}
+
+// FileQueueOption is an option for the file-backed operation queue.
+type FileQueueOption func(q *FileQueue)
+
+// WithMaxSegmentSize sets the size (in bytes) after which a new segment file is started.
+func WithMaxSegmentSize(size int64) FileQueueOption {
+	return func(q *FileQueue) {
+		q.maxSegmentSize = size
+	}
+}
+
+// FileQueue implements an operation queue that is persisted to append-only segment files in the given directory
+// so that pending operations survive a restart.
+//
+// Every Add is written (and synced) to the active segment before it returns. Remove does not touch the
+// files - operations are only committed when 'Ack' is called, at which point an 'ack' record is written.
+// Operations that were removed but not acknowledged before a crash are therefore returned to the queue
+// when the queue is re-opened. Segments whose operations have all been acknowledged are deleted.
+//
+// Note that QueuedOperation.AnchorOrigin is persisted as JSON, so after a restart it holds the
+// JSON-decoded value of the original anchor origin.
+type FileQueue struct {
+	dir            string
+	maxSegmentSize int64
+
+	mutex      sync.RWMutex
+	items      []*fileQueueItem
+	segments   []uint64
+	live       map[uint64]int
+	active     *os.File
+	activeID   uint64
+	activeSize int64
+	nextSeq    uint64
+
+	write func(f *os.File, b []byte) (int, error)
+}
+
+// NewFileQueue opens (or creates) a file-backed operation queue in the given directory. Any operations that
+// were added but not acknowledged are restored to the queue in the order in which they were originally added.
+func NewFileQueue(dir string, opts ...FileQueueOption) (*FileQueue, error) {
+	q := &FileQueue{
+		dir:            dir,
+		maxSegmentSize: defaultMaxSegmentSize,
+		live:           make(map[uint64]int),
+		nextSeq:        1,
+		write: func(f *os.File, b []byte) (int, error) {
+			return f.Write(b)
+		},
+	}
+
+	// apply options
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create queue directory [%s]: %w", dir, err)
+	}
+
+	if err := q.recover(); err != nil {
+		return nil, fmt.Errorf("recover queue from directory [%s]: %w", dir, err)
+	}
+
+	logger.Info("Opened file-backed operation queue", log.WithPath(dir), log.WithTotalPending(uint(len(q.items))))
+
+	return q, nil
+}
+
+// Add adds the given data to the tail of the queue and returns the new length of the queue.
+func (q *FileQueue) Add(data *operation.QueuedOperation, protocolVersion uint64) (uint, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.active == nil {
+		return 0, errors.New("queue is closed")
+	}
+
+	if q.activeSize >= q.maxSegmentSize {
+		if err := q.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	seq := q.nextSeq
+
+	err := q.append(&fileRecord{
+		Type:            recordTypeAdd,
+		Seq:             seq,
+		Operation:       data,
+		ProtocolVersion: protocolVersion,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("persist operation for suffix[%s]: %w", data.UniqueSuffix, err)
+	}
+
+	q.nextSeq++
+	q.live[q.activeID]++
+
+	q.items = append(q.items, &fileQueueItem{
+		seq:     seq,
+		segment: q.activeID,
+		op: &operation.QueuedOperationAtTime{
+			QueuedOperation: *data,
+			ProtocolVersion: protocolVersion,
+		},
+	})
+
+	return uint(len(q.items)), nil
+}
+
+// Peek returns (up to) the given number of operations from the head of the queue but does not remove them.
+func (q *FileQueue) Peek(num uint) (operation.QueuedOperationsAtTime, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return getOperations(q.items, num), nil
+}
+
+// Remove removes (up to) the given number of items from the head of the queue. The removal is only persisted
+// when 'ack' is called; if the process terminates before then, the items are restored on the next start.
+func (q *FileQueue) Remove(num uint) (ops operation.QueuedOperationsAtTime, ack func() uint, nack func(), err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	n := int(num)
+	if len(q.items) < n {
+		n = len(q.items)
+	}
+
+	items := q.items[0:n]
+	q.items = q.items[n:]
+
+	return getOperations(items, num),
+		func() uint {
+			q.mutex.Lock()
+			defer q.mutex.Unlock()
+
+			q.ack(items)
+
+			return uint(len(q.items))
+		},
+		func() {
+			q.mutex.Lock()
+			defer q.mutex.Unlock()
+
+			// Add the items to the head of the queue.
+			q.items = append(items, q.items...)
+		}, nil
+}
+
+// Len returns the length of the queue.
+func (q *FileQueue) Len() uint {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return uint(len(q.items))
+}
+
+// Close closes the active segment file. The queue may not be used after it is closed.
+func (q *FileQueue) Close() error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.active == nil {
+		return nil
+	}
+
+	err := q.active.Close()
+	q.active = nil
+
+	return err
+}
+
+func (q *FileQueue) ack(items []*fileQueueItem) {
+	if len(items) == 0 {
+		return
+	}
+
+	if q.active == nil {
+		logger.Warn("Unable to persist acknowledgement since the queue is closed", log.WithTotal(len(items)))
+
+		return
+	}
+
+	seqs := make([]uint64, len(items))
+	for i, item := range items {
+		seqs[i] = item.seq
+	}
+
+	err := q.append(&fileRecord{Type: recordTypeAck, Acked: seqs})
+	if err != nil {
+		// The operations are still in the log so they will be queued again after a restart.
+		logger.Error("Failed to persist acknowledgement of operations", log.WithTotal(len(items)), log.WithError(err))
+
+		return
+	}
+
+	for _, item := range items {
+		q.live[item.segment]--
+	}
+
+	if err := q.compact(); err != nil {
+		logger.Warn("Failed to compact operation queue", log.WithError(err))
+	}
+}
+
+// compact deletes all segments at the head of the log whose operations have all been acknowledged. If there are
+// no unacknowledged operations left then the active segment is also sealed so that it may be deleted.
+func (q *FileQueue) compact() error {
+	if q.totalLive() == 0 && q.activeSize > 0 {
+		if err := q.rotate(); err != nil {
+			return err
+		}
+	}
+
+	removed := false
+
+	for len(q.segments) > 1 && q.live[q.segments[0]] == 0 {
+		id := q.segments[0]
+
+		if err := os.Remove(q.segmentPath(id)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove segment %d: %w", id, err)
+		}
+
+		delete(q.live, id)
+		q.segments = q.segments[1:]
+		removed = true
+
+		logger.Debug("Removed acknowledged queue segment", log.WithPath(q.segmentPath(id)))
+	}
+
+	if !removed {
+		return nil
+	}
+
+	return syncDir(q.dir)
+}
+
+func (q *FileQueue) totalLive() int {
+	total := 0
+
+	for _, n := range q.live {
+		total += n
+	}
+
+	return total
+}
+
+// append writes the record to the active segment and syncs the file. If the write fails then the segment is
+// truncated back to its previous size so that a partially written record doesn't corrupt subsequent records.
+func (q *FileQueue) append(r *fileRecord) error {
+	b, err := encodeRecord(r)
+	if err != nil {
+		return err
+	}
+
+	n, err := q.write(q.active, b)
+	if err == nil {
+		err = q.active.Sync()
+	}
+
+	if err != nil {
+		if n > 0 {
+			if e := q.active.Truncate(q.activeSize); e != nil {
+				logger.Error("Failed to truncate partially written record", log.WithError(e))
+			}
+		}
+
+		return fmt.Errorf("write record: %w", err)
+	}
+
+	q.activeSize += int64(n)
+
+	return nil
+}
+
+// rotate seals the active segment and starts a new one.
+func (q *FileQueue) rotate() error {
+	if err := q.active.Close(); err != nil {
+		return fmt.Errorf("close segment %d: %w", q.activeID, err)
+	}
+
+	q.active = nil
+
+	return q.openSegment(q.activeID + 1)
+}
+
+func (q *FileQueue) openSegment(id uint64) error {
+	f, err := os.OpenFile(q.segmentPath(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open segment %d: %w", id, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close() //nolint:errcheck
+
+		return fmt.Errorf("stat segment %d: %w", id, err)
+	}
+
+	if len(q.segments) == 0 || q.segments[len(q.segments)-1] != id {
+		q.segments = append(q.segments, id)
+	}
+
+	q.active = f
+	q.activeID = id
+	q.activeSize = info.Size()
+
+	return syncDir(q.dir)
+}
+
+// recover replays all segment files in order to rebuild the queue. A torn record at the tail of the last segment
+// (e.g. due to a crash during a write) is truncated.
+func (q *FileQueue) recover() error {
+	ids, err := q.listSegments()
+	if err != nil {
+		return err
+	}
+
+	pending := make(map[uint64]*fileQueueItem)
+
+	for i, id := range ids {
+		isLast := i == len(ids)-1
+
+		validSize, err := q.replaySegment(id, pending, isLast)
+		if err != nil {
+			return err
+		}
+
+		if isLast {
+			if err := os.Truncate(q.segmentPath(id), validSize); err != nil {
+				return fmt.Errorf("truncate segment %d: %w", id, err)
+			}
+		}
+
+		q.segments = append(q.segments, id)
+		q.live[id] = 0
+	}
+
+	for _, item := range pending {
+		q.items = append(q.items, item)
+		q.live[item.segment]++
+	}
+
+	sort.Slice(q.items, func(i, j int) bool {
+		return q.items[i].seq < q.items[j].seq
+	})
+
+	activeID := uint64(1)
+	if len(ids) > 0 {
+		activeID = ids[len(ids)-1]
+	}
+
+	if err := q.openSegment(activeID); err != nil {
+		return err
+	}
+
+	return q.compact()
+}
+
+func (q *FileQueue) replaySegment(id uint64, pending map[uint64]*fileQueueItem, isLast bool) (int64, error) {
+	f, err := os.Open(q.segmentPath(id))
+	if err != nil {
+		return 0, fmt.Errorf("open segment %d: %w", id, err)
+	}
+
+	defer func() {
+		_ = f.Close() //nolint:errcheck
+	}()
+
+	var offset int64
+
+	for {
+		r, n, err := readRecord(f)
+		if errors.Is(err, io.EOF) {
+			return offset, nil
+		}
+
+		if err != nil {
+			if !isLast {
+				return 0, fmt.Errorf("segment %d is corrupt at offset %d: %w", id, offset, err)
+			}
+
+			logger.Warn("Discarding incomplete record at the tail of the operation queue", log.WithSize(int(offset)),
+				log.WithError(err))
+
+			return offset, nil
+		}
+
+		offset += n
+
+		switch r.Type {
+		case recordTypeAdd:
+			if r.Operation == nil {
+				return 0, fmt.Errorf("segment %d: 'add' record %d is missing operation", id, r.Seq)
+			}
+
+			pending[r.Seq] = &fileQueueItem{
+				seq:     r.Seq,
+				segment: id,
+				op: &operation.QueuedOperationAtTime{
+					QueuedOperation: *r.Operation,
+					ProtocolVersion: r.ProtocolVersion,
+				},
+			}
+
+			q.updateNextSeq(r.Seq)
+		case recordTypeAck:
+			for _, seq := range r.Acked {
+				delete(pending, seq)
+
+				q.updateNextSeq(seq)
+			}
+		default:
+			return 0, fmt.Errorf("segment %d: unsupported record type [%s]", id, r.Type)
+		}
+	}
+}
+
+func (q *FileQueue) updateNextSeq(seq uint64) {
+	if seq >= q.nextSeq {
+		q.nextSeq = seq + 1
+	}
+}
+
+func (q *FileQueue) listSegments() ([]uint64, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read queue directory: %w", err)
+	}
+
+	var ids []uint64
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+
+		var id uint64
+
+		_, err := fmt.Sscanf(strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix), "%d", &id)
+		if err != nil {
+			logger.Warn("Ignoring unrecognized file in queue directory", log.WithPath(name), log.WithError(err))
+
+			continue
+		}
+
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids, nil
+}
+
+func (q *FileQueue) segmentPath(id uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%s%020d%s", segmentPrefix, id, segmentSuffix))
+}
+
+func getOperations(items []*fileQueueItem, num uint) operation.QueuedOperationsAtTime {
+	n := int(num)
+	if len(items) < n {
+		n = len(items)
+	}
+
+	ops := make(operation.QueuedOperationsAtTime, n)
+	for i := 0; i < n; i++ {
+		ops[i] = items[i].op
+	}
+
+	return ops
+}
+
+func encodeRecord(r *fileRecord) ([]byte, error) {
+	payload, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("marshal record: %w", err)
+	}
+
+	if len(payload) > maxRecordSize {
+		return nil, fmt.Errorf("record size %d exceeds maximum record size %d", len(payload), maxRecordSize)
+	}
+
+	b := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(b[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(b[4:8], crc32.ChecksumIEEE(payload))
+	copy(b[recordHeaderSize:], payload)
+
+	return b, nil
+}
+
+// readRecord reads the next record from the reader. io.EOF is returned only if there are no more bytes to read.
+func readRecord(r io.Reader) (*fileRecord, int64, error) {
+	header := make([]byte, recordHeaderSize)
+
+	n, err := io.ReadFull(r, header)
+	if err != nil {
+		if n == 0 && errors.Is(err, io.EOF) {
+			return nil, 0, io.EOF
+		}
+
+		return nil, 0, fmt.Errorf("read record header: %w", err)
+	}
+
+	size := binary.BigEndian.Uint32(header[0:4])
+	if size > maxRecordSize {
+		return nil, 0, fmt.Errorf("record size %d exceeds maximum record size %d", size, maxRecordSize)
+	}
+
+	payload := make([]byte, size)
+
+	_, err = io.ReadFull(r, payload)
+	if err != nil {
+		return nil, 0, fmt.Errorf("read record payload: %w", err)
+	}
+
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, 0, errors.New("record checksum mismatch")
+	}
+
+	rec := &fileRecord{}
+
+	if err := json.Unmarshal(payload, rec); err != nil {
+		return nil, 0, fmt.Errorf("unmarshal record: %w", err)
+	}
+
+	return rec, int64(recordHeaderSize + len(payload)), nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("open directory: %w", err)
+	}
+
+	defer func() {
+		_ = d.Close() //nolint:errcheck
+	}()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("sync directory: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,581 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opqueue
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+)
+
+func TestFileQueue(t *testing.T) {
+	q, err := NewFileQueue(t.TempDir())
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, q.Close())
+	}()
+
+	require.Zero(t, q.Len())
+
+	ops, err := q.Peek(1)
+	require.NoError(t, err)
+	require.Empty(t, ops)
+
+	l, err := q.Add(op1, 10)
+	require.NoError(t, err)
+	require.Equal(t, uint(1), l)
+
+	l, err = q.Add(op2, 10)
+	require.NoError(t, err)
+	require.Equal(t, uint(2), l)
+
+	l, err = q.Add(op3, 10)
+	require.NoError(t, err)
+	require.Equal(t, uint(3), l)
+	require.Equal(t, uint(3), q.Len())
+
+	ops, err = q.Peek(4)
+	require.NoError(t, err)
+	require.Len(t, ops, 3)
+	require.Equal(t, *op1, ops[0].QueuedOperation)
+	require.Equal(t, *op2, ops[1].QueuedOperation)
+	require.Equal(t, *op3, ops[2].QueuedOperation)
+
+	ops, ack, nack, err := q.Remove(1)
+	require.NoError(t, err)
+	require.NotNil(t, nack)
+	require.Len(t, ops, 1)
+	require.Equal(t, *op1, ops[0].QueuedOperation)
+
+	require.Equal(t, uint(2), ack())
+
+	ops, _, nack, err = q.Remove(5)
+	require.NoError(t, err)
+	require.Len(t, ops, 2)
+
+	nack()
+
+	ops, ack, _, err = q.Remove(5)
+	require.NoError(t, err)
+	require.Len(t, ops, 2)
+	require.Equal(t, *op2, ops[0].QueuedOperation)
+	require.Equal(t, *op3, ops[1].QueuedOperation)
+
+	require.Zero(t, ack())
+}
+
+func TestFileQueue_Restart(t *testing.T) {
+	t.Run("pending operations are restored", func(t *testing.T) {
+		dir := t.TempDir()
+
+		q, err := NewFileQueue(dir)
+		require.NoError(t, err)
+
+		_, err = q.Add(op1, 10)
+		require.NoError(t, err)
+		_, err = q.Add(op2, 20)
+		require.NoError(t, err)
+
+		require.NoError(t, q.Close())
+
+		q, err = NewFileQueue(dir)
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, q.Close())
+		}()
+
+		ops, err := q.Peek(5)
+		require.NoError(t, err)
+		require.Len(t, ops, 2)
+		require.Equal(t, *op1, ops[0].QueuedOperation)
+		require.Equal(t, uint64(10), ops[0].ProtocolVersion)
+		require.Equal(t, *op2, ops[1].QueuedOperation)
+		require.Equal(t, uint64(20), ops[1].ProtocolVersion)
+
+		// New operations are appended after the restored operations.
+		l, err := q.Add(op3, 20)
+		require.NoError(t, err)
+		require.Equal(t, uint(3), l)
+
+		ops, err = q.Peek(5)
+		require.NoError(t, err)
+		require.Equal(t, *op3, ops[2].QueuedOperation)
+	})
+
+	t.Run("acknowledged operations are not restored", func(t *testing.T) {
+		dir := t.TempDir()
+
+		q, err := NewFileQueue(dir)
+		require.NoError(t, err)
+
+		addAll(t, q, op1, op2, op3)
+
+		_, ack, _, err := q.Remove(2)
+		require.NoError(t, err)
+		require.Equal(t, uint(1), ack())
+
+		require.NoError(t, q.Close())
+
+		q, err = NewFileQueue(dir)
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, q.Close())
+		}()
+
+		ops, err := q.Peek(5)
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+		require.Equal(t, *op3, ops[0].QueuedOperation)
+	})
+
+	t.Run("crash before ack - removed operations are restored", func(t *testing.T) {
+		dir := t.TempDir()
+
+		q, err := NewFileQueue(dir)
+		require.NoError(t, err)
+
+		addAll(t, q, op1, op2, op3)
+
+		ops, _, _, err := q.Remove(2)
+		require.NoError(t, err)
+		require.Len(t, ops, 2)
+		require.Equal(t, uint(1), q.Len())
+
+		// Simulate a crash by re-opening the queue without acknowledging or closing.
+		q2, err := NewFileQueue(dir)
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, q2.Close())
+		}()
+
+		ops, err = q2.Peek(5)
+		require.NoError(t, err)
+		require.Len(t, ops, 3)
+		require.Equal(t, *op1, ops[0].QueuedOperation)
+		require.Equal(t, *op2, ops[1].QueuedOperation)
+		require.Equal(t, *op3, ops[2].QueuedOperation)
+	})
+
+	t.Run("crash after nack - original order is restored", func(t *testing.T) {
+		dir := t.TempDir()
+
+		q, err := NewFileQueue(dir)
+		require.NoError(t, err)
+
+		addAll(t, q, op1, op2, op3)
+
+		_, _, nack1, err := q.Remove(1)
+		require.NoError(t, err)
+
+		_, _, nack2, err := q.Remove(1)
+		require.NoError(t, err)
+
+		nack1()
+		nack2()
+
+		q2, err := NewFileQueue(dir)
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, q2.Close())
+		}()
+
+		ops, err := q2.Peek(5)
+		require.NoError(t, err)
+		require.Len(t, ops, 3)
+		require.Equal(t, *op1, ops[0].QueuedOperation)
+		require.Equal(t, *op2, ops[1].QueuedOperation)
+		require.Equal(t, *op3, ops[2].QueuedOperation)
+	})
+}
+
+func TestFileQueue_Compaction(t *testing.T) {
+	dir := t.TempDir()
+
+	// Each record is larger than the segment size so every Add starts a new segment.
+	q, err := NewFileQueue(dir, WithMaxSegmentSize(1))
+	require.NoError(t, err)
+
+	addAll(t, q, op1, op2, op3)
+	require.Len(t, segmentFiles(t, dir), 3)
+
+	// The first segment can't be removed until op1 is acknowledged.
+	_, _, nack, err := q.Remove(1)
+	require.NoError(t, err)
+	nack()
+
+	require.Len(t, segmentFiles(t, dir), 3)
+
+	_, ack, _, err := q.Remove(2)
+	require.NoError(t, err)
+	require.Equal(t, uint(1), ack())
+
+	require.Len(t, segmentFiles(t, dir), 1)
+
+	_, ack, _, err = q.Remove(1)
+	require.NoError(t, err)
+	require.Zero(t, ack())
+
+	// All operations were acknowledged so only a new, empty segment should remain.
+	files := segmentFiles(t, dir)
+	require.Len(t, files, 1)
+
+	info, err := os.Stat(files[0])
+	require.NoError(t, err)
+	require.Zero(t, info.Size())
+
+	require.NoError(t, q.Close())
+
+	q, err = NewFileQueue(dir)
+	require.NoError(t, err)
+	require.Zero(t, q.Len())
+
+	l, err := q.Add(op1, 10)
+	require.NoError(t, err)
+	require.Equal(t, uint(1), l)
+
+	require.NoError(t, q.Close())
+}
+
+func TestFileQueue_CrashInjection(t *testing.T) {
+	t.Run("torn record at tail is discarded", func(t *testing.T) {
+		dir := t.TempDir()
+
+		q, err := NewFileQueue(dir)
+		require.NoError(t, err)
+
+		addAll(t, q, op1, op2)
+		require.NoError(t, q.Close())
+
+		files := segmentFiles(t, dir)
+		require.Len(t, files, 1)
+
+		info, err := os.Stat(files[0])
+		require.NoError(t, err)
+
+		// Simulate a crash in the middle of writing the next record.
+		b, err := encodeRecord(&fileRecord{Type: recordTypeAdd, Seq: 3, Operation: op3})
+		require.NoError(t, err)
+
+		appendToFile(t, files[0], b[:len(b)/2])
+
+		q, err = NewFileQueue(dir)
+		require.NoError(t, err)
+		require.Equal(t, uint(2), q.Len())
+
+		info2, err := os.Stat(files[0])
+		require.NoError(t, err)
+		require.Equal(t, info.Size(), info2.Size())
+
+		addAll(t, q, op3)
+		require.NoError(t, q.Close())
+
+		q, err = NewFileQueue(dir)
+		require.NoError(t, err)
+
+		ops, err := q.Peek(5)
+		require.NoError(t, err)
+		require.Len(t, ops, 3)
+		require.Equal(t, *op3, ops[2].QueuedOperation)
+
+		require.NoError(t, q.Close())
+	})
+
+	t.Run("torn header at tail is discarded", func(t *testing.T) {
+		dir := t.TempDir()
+
+		q, err := NewFileQueue(dir)
+		require.NoError(t, err)
+
+		addAll(t, q, op1)
+		require.NoError(t, q.Close())
+
+		appendToFile(t, segmentFiles(t, dir)[0], []byte{0, 0})
+
+		q, err = NewFileQueue(dir)
+		require.NoError(t, err)
+		require.Equal(t, uint(1), q.Len())
+		require.NoError(t, q.Close())
+	})
+
+	t.Run("checksum mismatch at tail is discarded", func(t *testing.T) {
+		dir := t.TempDir()
+
+		q, err := NewFileQueue(dir)
+		require.NoError(t, err)
+
+		addAll(t, q, op1)
+		require.NoError(t, q.Close())
+
+		b, err := encodeRecord(&fileRecord{Type: recordTypeAdd, Seq: 2, Operation: op2})
+		require.NoError(t, err)
+
+		b[len(b)-2] ^= 0xff
+
+		appendToFile(t, segmentFiles(t, dir)[0], b)
+
+		q, err = NewFileQueue(dir)
+		require.NoError(t, err)
+		require.Equal(t, uint(1), q.Len())
+		require.NoError(t, q.Close())
+	})
+
+	t.Run("oversized record at tail is discarded", func(t *testing.T) {
+		dir := t.TempDir()
+
+		q, err := NewFileQueue(dir)
+		require.NoError(t, err)
+
+		addAll(t, q, op1)
+		require.NoError(t, q.Close())
+
+		header := make([]byte, recordHeaderSize)
+		binary.BigEndian.PutUint32(header[0:4], math.MaxUint32)
+
+		appendToFile(t, segmentFiles(t, dir)[0], header)
+
+		q, err = NewFileQueue(dir)
+		require.NoError(t, err)
+		require.Equal(t, uint(1), q.Len())
+
+		addAll(t, q, op2)
+		require.NoError(t, q.Close())
+
+		q, err = NewFileQueue(dir)
+		require.NoError(t, err)
+		require.Equal(t, uint(2), q.Len())
+		require.NoError(t, q.Close())
+	})
+
+	t.Run("oversized record in sealed segment", func(t *testing.T) {
+		dir := t.TempDir()
+
+		q, err := NewFileQueue(dir, WithMaxSegmentSize(1))
+		require.NoError(t, err)
+
+		addAll(t, q, op1, op2)
+		require.NoError(t, q.Close())
+
+		files := segmentFiles(t, dir)
+		require.Len(t, files, 2)
+
+		header := make([]byte, recordHeaderSize)
+		binary.BigEndian.PutUint32(header[0:4], maxRecordSize+1)
+
+		appendToFile(t, files[0], header)
+
+		_, err = NewFileQueue(dir)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "exceeds maximum record size")
+	})
+
+	t.Run("partial write during add", func(t *testing.T) {
+		dir := t.TempDir()
+
+		q, err := NewFileQueue(dir)
+		require.NoError(t, err)
+
+		addAll(t, q, op1)
+
+		errExpected := errors.New("injected write error")
+
+		q.write = func(f *os.File, b []byte) (int, error) {
+			n, err := f.Write(b[:len(b)/2])
+			require.NoError(t, err)
+
+			return n, errExpected
+		}
+
+		_, err = q.Add(op2, 10)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.Equal(t, uint(1), q.Len())
+
+		q.write = func(f *os.File, b []byte) (int, error) {
+			return f.Write(b)
+		}
+
+		addAll(t, q, op3)
+
+		// Simulate a crash.
+		q2, err := NewFileQueue(dir)
+		require.NoError(t, err)
+
+		ops, err := q2.Peek(5)
+		require.NoError(t, err)
+		require.Len(t, ops, 2)
+		require.Equal(t, *op1, ops[0].QueuedOperation)
+		require.Equal(t, *op3, ops[1].QueuedOperation)
+
+		require.NoError(t, q2.Close())
+	})
+
+	t.Run("write error during ack", func(t *testing.T) {
+		dir := t.TempDir()
+
+		q, err := NewFileQueue(dir)
+		require.NoError(t, err)
+
+		addAll(t, q, op1, op2)
+
+		q.write = func(f *os.File, b []byte) (int, error) {
+			return 0, errors.New("injected write error")
+		}
+
+		_, ack, _, err := q.Remove(1)
+		require.NoError(t, err)
+		require.Equal(t, uint(1), ack())
+
+		// Simulate a crash. The acknowledgement wasn't persisted so the operation is restored.
+		q2, err := NewFileQueue(dir)
+		require.NoError(t, err)
+		require.Equal(t, uint(2), q2.Len())
+		require.NoError(t, q2.Close())
+	})
+
+	t.Run("corrupt sealed segment", func(t *testing.T) {
+		dir := t.TempDir()
+
+		q, err := NewFileQueue(dir, WithMaxSegmentSize(1))
+		require.NoError(t, err)
+
+		addAll(t, q, op1, op2)
+		require.NoError(t, q.Close())
+
+		files := segmentFiles(t, dir)
+		require.Len(t, files, 2)
+
+		appendToFile(t, files[0], []byte("garbage"))
+
+		_, err = NewFileQueue(dir)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is corrupt")
+	})
+
+	t.Run("invalid record type", func(t *testing.T) {
+		dir := t.TempDir()
+
+		b, err := encodeRecord(&fileRecord{Type: "unknown"})
+		require.NoError(t, err)
+
+		appendToFile(t, filepath.Join(dir, "segment-00000000000000000001.log"), b)
+
+		_, err = NewFileQueue(dir)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported record type")
+	})
+
+	t.Run("add record without operation", func(t *testing.T) {
+		dir := t.TempDir()
+
+		b, err := encodeRecord(&fileRecord{Type: recordTypeAdd, Seq: 1})
+		require.NoError(t, err)
+
+		appendToFile(t, filepath.Join(dir, "segment-00000000000000000001.log"), b)
+
+		_, err = NewFileQueue(dir)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing operation")
+	})
+}
+
+func TestFileQueue_Error(t *testing.T) {
+	t.Run("invalid directory", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file")
+		appendToFile(t, file, []byte("data"))
+
+		_, err := NewFileQueue(file)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create queue directory")
+	})
+
+	t.Run("unrecognized files are ignored", func(t *testing.T) {
+		dir := t.TempDir()
+
+		appendToFile(t, filepath.Join(dir, "segment-abc.log"), []byte("data"))
+		appendToFile(t, filepath.Join(dir, "other.txt"), []byte("data"))
+
+		q, err := NewFileQueue(dir)
+		require.NoError(t, err)
+		require.Zero(t, q.Len())
+		require.NoError(t, q.Close())
+	})
+
+	t.Run("operation exceeds maximum record size", func(t *testing.T) {
+		q, err := NewFileQueue(t.TempDir())
+		require.NoError(t, err)
+
+		_, err = q.Add(&operation.QueuedOperation{
+			OperationRequest: make([]byte, maxRecordSize),
+			UniqueSuffix:     "large",
+		}, 10)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "exceeds maximum record size")
+		require.Zero(t, q.Len())
+		require.NoError(t, q.Close())
+	})
+
+	t.Run("closed queue", func(t *testing.T) {
+		q, err := NewFileQueue(t.TempDir())
+		require.NoError(t, err)
+
+		addAll(t, q, op1)
+
+		_, ack, _, err := q.Remove(1)
+		require.NoError(t, err)
+
+		require.NoError(t, q.Close())
+		require.NoError(t, q.Close())
+
+		_, err = q.Add(op2, 10)
+		require.EqualError(t, err, "queue is closed")
+
+		require.Zero(t, ack())
+	})
+}
+
+func addAll(t *testing.T, q *FileQueue, ops ...*operation.QueuedOperation) {
+	t.Helper()
+
+	for _, op := range ops {
+		_, err := q.Add(op, 10)
+		require.NoError(t, err)
+	}
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("%s*%s", segmentPrefix, segmentSuffix)))
+	require.NoError(t, err)
+
+	return files
+}
+
+func appendToFile(t *testing.T, path string, b []byte) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec
+	require.NoError(t, err)
+
+	_, err = f.Write(b)
+	require.NoError(t, err)
+
+	require.NoError(t, f.Close())
+}
@@ -0,0 +1,371 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opqueue
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+)
+
+const defaultStarvationBound = 10
+
+// priorities holds the priority classes (one lane per class), highest priority first.
+var priorities = []operation.Priority{operation.PriorityHigh, operation.PriorityNormal, operation.PriorityLow}
+
+// Queue defines the functions of a FIFO operation queue (e.g. MemQueue or FileQueue) that holds the operations
+// of a single priority class.
+type Queue interface {
+	Add(data *operation.QueuedOperation, protocolVersion uint64) (uint, error)
+	Remove(num uint) (ops operation.QueuedOperationsAtTime, ack func() uint, nack func(), err error)
+	Peek(num uint) (operation.QueuedOperationsAtTime, error)
+	Len() uint
+}
+
+// PriorityQueueOption is an option for the priority queue.
+type PriorityQueueOption func(q *PriorityQueue)
+
+// WithTypePriority sets the priority class of operations of the given type. The type priority is only used for
+// operations that don't specify a priority. By default, recover and deactivate operations have high priority and
+// all other operations have normal priority.
+func WithTypePriority(opType operation.Type, priority operation.Priority) PriorityQueueOption {
+	return func(q *PriorityQueue) {
+		q.typePriorities[opType] = priority
+	}
+}
+
+// WithStarvationBound sets the maximum number of operations that are taken from higher priority lanes while
+// operations are waiting in a lower priority lane. Once the bound is reached, the next operation is taken from
+// the waiting lane. Zero means that operations are taken in strict priority order, i.e. lower priority operations
+// may starve. Defaults to 10.
+func WithStarvationBound(bound uint) PriorityQueueOption {
+	return func(q *PriorityQueue) {
+		q.starvationBound = bound
+	}
+}
+
+// PriorityQueue implements an operation queue with a separate FIFO lane for each priority class (high, normal
+// and low). Operations are taken from the highest priority lane that has operations, except that an operation
+// is taken from a lower priority lane once the starvation bound has been reached for that lane.
+//
+// The operations returned by Remove are the same as the operations returned by a preceding Peek (up to the
+// number of operations that were peeked) even if operations are added in between.
+type PriorityQueue struct {
+	lanes           []Queue
+	typePriorities  map[operation.Type]operation.Priority
+	starvationBound uint
+
+	mutex sync.Mutex
+	// skipped holds (for each lane) the number of operations that were taken from higher priority lanes while
+	// operations were waiting in the lane.
+	skipped []uint
+	// plan holds the lanes of the operations returned by the last Peek.
+	plan []int
+}
+
+// NewPriorityQueue returns a new priority queue. The given function is invoked for each priority class
+// to create the queue (lane) that holds the operations of that class.
+func NewPriorityQueue(newQueue func(priority operation.Priority) (Queue, error),
+	opts ...PriorityQueueOption) (*PriorityQueue, error) {
+	q := &PriorityQueue{
+		typePriorities: map[operation.Type]operation.Priority{
+			operation.TypeRecover:    operation.PriorityHigh,
+			operation.TypeDeactivate: operation.PriorityHigh,
+		},
+		starvationBound: defaultStarvationBound,
+		skipped:         make([]uint, len(priorities)),
+	}
+
+	// apply options
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	for _, priority := range priorities {
+		lane, err := newQueue(priority)
+		if err != nil {
+			return nil, fmt.Errorf("create %s priority lane: %w", priority, err)
+		}
+
+		q.lanes = append(q.lanes, lane)
+	}
+
+	return q, nil
+}
+
+// Add adds the given operation to the tail of the lane of its priority class and returns the new length
+// of the queue.
+func (q *PriorityQueue) Add(data *operation.QueuedOperation, protocolVersion uint64) (uint, error) {
+	_, err := q.lanes[q.laneFor(data)].Add(data, protocolVersion)
+	if err != nil {
+		return 0, err
+	}
+
+	return q.Len(), nil
+}
+
+// Peek returns (up to) the given number of operations in priority order but does not remove them.
+func (q *PriorityQueue) Peek(num uint) (operation.QueuedOperationsAtTime, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	plan, _ := q.selectLanes(num)
+
+	counts := laneCounts(plan, len(q.lanes))
+	laneOps := make([]operation.QueuedOperationsAtTime, len(q.lanes))
+
+	for i, lane := range q.lanes {
+		if counts[i] == 0 {
+			continue
+		}
+
+		ops, err := lane.Peek(counts[i])
+		if err != nil {
+			return nil, fmt.Errorf("peek operations in %s priority lane: %w", priorities[i], err)
+		}
+
+		laneOps[i] = ops
+	}
+
+	q.plan = plan
+
+	return merge(plan, laneOps), nil
+}
+
+// Remove removes (up to) the given number of operations in priority order.
+func (q *PriorityQueue) Remove(num uint) (ops operation.QueuedOperationsAtTime, ack func() uint, nack func(), err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var plan []int
+
+	var skipped []uint
+
+	if uint(len(q.plan)) >= num {
+		// Remove the operations that were returned by Peek, even if operations were added to
+		// a higher priority lane in the meantime.
+		plan, skipped = q.replay(q.plan[:num])
+	} else {
+		plan, skipped = q.selectLanes(num)
+	}
+
+	counts := laneCounts(plan, len(q.lanes))
+	laneOps := make([]operation.QueuedOperationsAtTime, len(q.lanes))
+
+	var acks []func() uint
+
+	var nacks []func()
+
+	for i, lane := range q.lanes {
+		if counts[i] == 0 {
+			continue
+		}
+
+		removed, laneAck, laneNack, e := lane.Remove(counts[i])
+		if e != nil {
+			for _, n := range nacks {
+				n()
+			}
+
+			q.plan = nil
+
+			return nil, nil, nil, fmt.Errorf("remove operations from %s priority lane: %w", priorities[i], e)
+		}
+
+		laneOps[i] = removed
+		acks = append(acks, laneAck)
+		nacks = append(nacks, laneNack)
+	}
+
+	previousSkipped := q.skipped
+
+	q.skipped = skipped
+	q.plan = nil
+
+	logger.Debug("Removed operations from priority queue", log.WithTotal(len(plan)),
+		log.WithTotalPending(q.Len()))
+
+	return merge(plan, laneOps),
+		func() uint {
+			for _, a := range acks {
+				a()
+			}
+
+			return q.Len()
+		},
+		func() {
+			q.mutex.Lock()
+			defer q.mutex.Unlock()
+
+			// Add the operations back to the head of their lanes.
+			for _, n := range nacks {
+				n()
+			}
+
+			q.skipped = previousSkipped
+			q.plan = nil
+		}, nil
+}
+
+// Len returns the number of operations in all lanes.
+func (q *PriorityQueue) Len() uint {
+	var l uint
+
+	for _, lane := range q.lanes {
+		l += lane.Len()
+	}
+
+	return l
+}
+
+// Close closes the lanes that need to be closed (e.g. FileQueue). The first error is returned.
+func (q *PriorityQueue) Close() error {
+	var err error
+
+	for _, lane := range q.lanes {
+		if c, ok := lane.(io.Closer); ok {
+			if e := c.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+
+	return err
+}
+
+// laneFor returns the index of the lane for the given operation.
+func (q *PriorityQueue) laneFor(op *operation.QueuedOperation) int {
+	priority := op.Priority
+	if priority == operation.PriorityUnspecified {
+		priority = q.typePriorities[op.Type]
+		if priority == operation.PriorityUnspecified {
+			priority = operation.PriorityNormal
+		}
+	}
+
+	switch {
+	case priority > operation.PriorityHigh:
+		priority = operation.PriorityHigh
+	case priority < operation.PriorityLow:
+		priority = operation.PriorityLow
+	}
+
+	return int(operation.PriorityHigh - priority)
+}
+
+// selectLanes returns the lanes from which (up to) the given number of operations are taken, along with the
+// skipped counts after the operations are taken.
+func (q *PriorityQueue) selectLanes(num uint) ([]int, []uint) {
+	remaining := q.laneLengths()
+
+	skipped := make([]uint, len(q.skipped))
+	copy(skipped, q.skipped)
+
+	var plan []int
+
+	for uint(len(plan)) < num {
+		i := q.next(skipped, remaining)
+		if i < 0 {
+			break
+		}
+
+		served(skipped, remaining, i)
+
+		plan = append(plan, i)
+	}
+
+	return plan, skipped
+}
+
+// replay returns the given plan along with the skipped counts after the operations of the plan are taken.
+func (q *PriorityQueue) replay(plan []int) ([]int, []uint) {
+	remaining := q.laneLengths()
+
+	skipped := make([]uint, len(q.skipped))
+	copy(skipped, q.skipped)
+
+	for _, i := range plan {
+		served(skipped, remaining, i)
+	}
+
+	return plan, skipped
+}
+
+// next returns the lane from which the next operation is taken or -1 if all lanes are empty.
+func (q *PriorityQueue) next(skipped, remaining []uint) int {
+	if q.starvationBound > 0 {
+		for i := range remaining {
+			if remaining[i] > 0 && skipped[i] >= q.starvationBound {
+				return i
+			}
+		}
+	}
+
+	for i := range remaining {
+		if remaining[i] > 0 {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (q *PriorityQueue) laneLengths() []uint {
+	lengths := make([]uint, len(q.lanes))
+
+	for i, lane := range q.lanes {
+		lengths[i] = lane.Len()
+	}
+
+	return lengths
+}
+
+// served updates the skipped and remaining counts after an operation is taken from the given lane.
+func served(skipped, remaining []uint, lane int) {
+	skipped[lane] = 0
+
+	if remaining[lane] > 0 {
+		remaining[lane]--
+	}
+
+	for i := lane + 1; i < len(remaining); i++ {
+		if remaining[i] > 0 {
+			skipped[i]++
+		}
+	}
+}
+
+func laneCounts(plan []int, numLanes int) []uint {
+	counts := make([]uint, numLanes)
+
+	for _, i := range plan {
+		counts[i]++
+	}
+
+	return counts
+}
+
+// merge returns the operations of the lanes in the order of the given plan.
+func merge(plan []int, laneOps []operation.QueuedOperationsAtTime) operation.QueuedOperationsAtTime {
+	next := make([]int, len(laneOps))
+
+	ops := make(operation.QueuedOperationsAtTime, 0, len(plan))
+
+	for _, i := range plan {
+		if next[i] >= len(laneOps[i]) {
+			continue
+		}
+
+		ops = append(ops, laneOps[i][next[i]])
+		next[i]++
+	}
+
+	return ops
+}
@@ -0,0 +1,379 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opqueue
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	t.Run("operations are taken in priority order", func(t *testing.T) {
+		q := newMemPriorityQueue(t)
+
+		update1 := newQueuedOp("update1", operation.TypeUpdate, operation.PriorityUnspecified)
+		create1 := newQueuedOp("create1", operation.TypeCreate, operation.PriorityUnspecified)
+		recover1 := newQueuedOp("recover1", operation.TypeRecover, operation.PriorityUnspecified)
+		deactivate1 := newQueuedOp("deactivate1", operation.TypeDeactivate, operation.PriorityUnspecified)
+		lowRecover := newQueuedOp("recover2", operation.TypeRecover, operation.PriorityLow)
+		highUpdate := newQueuedOp("update2", operation.TypeUpdate, operation.PriorityHigh)
+		untyped := newQueuedOp("untyped", "", operation.PriorityUnspecified)
+
+		for i, op := range []*operation.QueuedOperation{update1, create1, lowRecover, recover1, untyped, deactivate1, highUpdate} {
+			l, err := q.Add(op, 10)
+			require.NoError(t, err)
+			require.Equal(t, uint(i+1), l)
+		}
+
+		require.Equal(t, uint(7), q.Len())
+
+		ops, err := q.Peek(10)
+		require.NoError(t, err)
+		requireSuffixes(t, ops, "recover1", "deactivate1", "update2", "update1", "create1", "untyped", "recover2")
+
+		ops, err = q.Peek(2)
+		require.NoError(t, err)
+		requireSuffixes(t, ops, "recover1", "deactivate1")
+
+		ops, ack, _, err := q.Remove(4)
+		require.NoError(t, err)
+		requireSuffixes(t, ops, "recover1", "deactivate1", "update2", "update1")
+		require.Equal(t, uint(3), ack())
+
+		ops, ack, _, err = q.Remove(10)
+		require.NoError(t, err)
+		requireSuffixes(t, ops, "create1", "untyped", "recover2")
+		require.Zero(t, ack())
+
+		ops, err = q.Peek(10)
+		require.NoError(t, err)
+		require.Empty(t, ops)
+	})
+
+	t.Run("type priority option", func(t *testing.T) {
+		q := newMemPriorityQueue(t,
+			WithTypePriority(operation.TypeCreate, operation.PriorityLow),
+			WithTypePriority(operation.TypeDeactivate, operation.PriorityUnspecified),
+		)
+
+		addOps(t, q,
+			newQueuedOp("create1", operation.TypeCreate, operation.PriorityUnspecified),
+			newQueuedOp("deactivate1", operation.TypeDeactivate, operation.PriorityUnspecified),
+			newQueuedOp("update1", operation.TypeUpdate, operation.PriorityUnspecified),
+			newQueuedOp("recover1", operation.TypeRecover, operation.PriorityUnspecified),
+		)
+
+		ops, err := q.Peek(10)
+		require.NoError(t, err)
+		requireSuffixes(t, ops, "recover1", "deactivate1", "update1", "create1")
+	})
+
+	t.Run("out of range priorities", func(t *testing.T) {
+		q := newMemPriorityQueue(t)
+
+		addOps(t, q,
+			newQueuedOp("update1", operation.TypeUpdate, operation.Priority(-1)),
+			newQueuedOp("update2", operation.TypeUpdate, operation.PriorityUnspecified),
+			newQueuedOp("update3", operation.TypeUpdate, operation.PriorityHigh+1),
+		)
+
+		ops, err := q.Peek(10)
+		require.NoError(t, err)
+		requireSuffixes(t, ops, "update3", "update2", "update1")
+	})
+
+	t.Run("starvation bound", func(t *testing.T) {
+		q := newMemPriorityQueue(t, WithStarvationBound(2))
+
+		addOps(t, q,
+			newQueuedOp("l1", operation.TypeUpdate, operation.PriorityLow),
+			newQueuedOp("n1", operation.TypeUpdate, operation.PriorityNormal),
+			newQueuedOp("n2", operation.TypeUpdate, operation.PriorityNormal),
+			newQueuedOp("h1", operation.TypeUpdate, operation.PriorityHigh),
+			newQueuedOp("h2", operation.TypeUpdate, operation.PriorityHigh),
+			newQueuedOp("h3", operation.TypeUpdate, operation.PriorityHigh),
+			newQueuedOp("h4", operation.TypeUpdate, operation.PriorityHigh),
+			newQueuedOp("h5", operation.TypeUpdate, operation.PriorityHigh),
+		)
+
+		expected := []string{"h1", "h2", "n1", "l1", "h3", "h4", "n2", "h5"}
+
+		ops, err := q.Peek(10)
+		require.NoError(t, err)
+		requireSuffixes(t, ops, expected...)
+
+		// The starvation counts are carried over to the next batch.
+		for i := 0; i < len(expected); i += 3 {
+			ops, ack, _, err := q.Remove(3)
+			require.NoError(t, err)
+
+			end := i + 3
+			if end > len(expected) {
+				end = len(expected)
+			}
+
+			requireSuffixes(t, ops, expected[i:end]...)
+
+			ack()
+		}
+
+		require.Zero(t, q.Len())
+	})
+
+	t.Run("strict priority", func(t *testing.T) {
+		q := newMemPriorityQueue(t, WithStarvationBound(0))
+
+		addOps(t, q, newQueuedOp("n1", operation.TypeUpdate, operation.PriorityNormal))
+
+		for i := 1; i <= 20; i++ {
+			addOps(t, q, newQueuedOp(fmt.Sprintf("h%d", i), operation.TypeUpdate, operation.PriorityHigh))
+		}
+
+		ops, err := q.Peek(21)
+		require.NoError(t, err)
+		require.Len(t, ops, 21)
+		require.Equal(t, "n1", ops[20].UniqueSuffix)
+	})
+
+	t.Run("remove returns peeked operations", func(t *testing.T) {
+		q := newMemPriorityQueue(t)
+
+		addOps(t, q,
+			newQueuedOp("update1", operation.TypeUpdate, operation.PriorityUnspecified),
+			newQueuedOp("update2", operation.TypeUpdate, operation.PriorityUnspecified),
+		)
+
+		ops, err := q.Peek(2)
+		require.NoError(t, err)
+		requireSuffixes(t, ops, "update1", "update2")
+
+		// A high priority operation is added after the operations were peeked.
+		addOps(t, q, newQueuedOp("recover1", operation.TypeRecover, operation.PriorityUnspecified))
+
+		ops, ack, _, err := q.Remove(2)
+		require.NoError(t, err)
+		requireSuffixes(t, ops, "update1", "update2")
+		require.Equal(t, uint(1), ack())
+
+		ops, _, _, err = q.Remove(2)
+		require.NoError(t, err)
+		requireSuffixes(t, ops, "recover1")
+	})
+
+	t.Run("nack", func(t *testing.T) {
+		q := newMemPriorityQueue(t, WithStarvationBound(1))
+
+		addOps(t, q,
+			newQueuedOp("n1", operation.TypeUpdate, operation.PriorityNormal),
+			newQueuedOp("n2", operation.TypeUpdate, operation.PriorityNormal),
+			newQueuedOp("h1", operation.TypeUpdate, operation.PriorityHigh),
+			newQueuedOp("h2", operation.TypeUpdate, operation.PriorityHigh),
+		)
+
+		ops, _, nack, err := q.Remove(3)
+		require.NoError(t, err)
+		requireSuffixes(t, ops, "h1", "n1", "h2")
+		require.Equal(t, uint(1), q.Len())
+
+		nack()
+
+		require.Equal(t, uint(4), q.Len())
+
+		// The operations are returned to their lanes and the starvation counts are restored.
+		ops, _, _, err = q.Remove(4)
+		require.NoError(t, err)
+		requireSuffixes(t, ops, "h1", "n1", "h2", "n2")
+	})
+
+	t.Run("with file queue lanes", func(t *testing.T) {
+		dir := t.TempDir()
+
+		newQueue := func(priority operation.Priority) (Queue, error) {
+			return NewFileQueue(filepath.Join(dir, priority.String()))
+		}
+
+		q, err := NewPriorityQueue(newQueue)
+		require.NoError(t, err)
+
+		addOps(t, q,
+			newQueuedOp("update1", operation.TypeUpdate, operation.PriorityUnspecified),
+			newQueuedOp("update2", operation.TypeUpdate, operation.PriorityLow),
+			newQueuedOp("deactivate1", operation.TypeDeactivate, operation.PriorityUnspecified),
+		)
+
+		require.NoError(t, q.Close())
+
+		// The operations are restored to their lanes when the queue is re-opened.
+		q, err = NewPriorityQueue(newQueue)
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, q.Close())
+		}()
+
+		ops, err := q.Peek(10)
+		require.NoError(t, err)
+		requireSuffixes(t, ops, "deactivate1", "update1", "update2")
+		require.Equal(t, operation.TypeDeactivate, ops[0].Type)
+		require.Equal(t, operation.PriorityLow, ops[2].Priority)
+	})
+
+	t.Run("lane creation error", func(t *testing.T) {
+		errExpected := errors.New("injected lane error")
+
+		q, err := NewPriorityQueue(func(priority operation.Priority) (Queue, error) {
+			if priority == operation.PriorityLow {
+				return nil, errExpected
+			}
+
+			return &MemQueue{}, nil
+		})
+		require.Error(t, err)
+		require.Nil(t, q)
+		require.True(t, errors.Is(err, errExpected))
+		require.Contains(t, err.Error(), "create low priority lane")
+	})
+
+	t.Run("lane errors", func(t *testing.T) {
+		errExpected := errors.New("injected lane error")
+
+		lowLane := &mockQueue{MemQueue: &MemQueue{}}
+
+		q, err := NewPriorityQueue(func(priority operation.Priority) (Queue, error) {
+			if priority == operation.PriorityLow {
+				return lowLane, nil
+			}
+
+			return &MemQueue{}, nil
+		})
+		require.NoError(t, err)
+
+		addOps(t, q,
+			newQueuedOp("update1", operation.TypeUpdate, operation.PriorityUnspecified),
+			newQueuedOp("update2", operation.TypeUpdate, operation.PriorityLow),
+		)
+
+		lowLane.err = errExpected
+
+		_, err = q.Add(newQueuedOp("update3", operation.TypeUpdate, operation.PriorityLow), 10)
+		require.True(t, errors.Is(err, errExpected))
+
+		ops, err := q.Peek(10)
+		require.True(t, errors.Is(err, errExpected))
+		require.Contains(t, err.Error(), "peek operations in low priority lane")
+		require.Empty(t, ops)
+
+		ops, _, _, err = q.Remove(10)
+		require.True(t, errors.Is(err, errExpected))
+		require.Contains(t, err.Error(), "remove operations from low priority lane")
+		require.Empty(t, ops)
+
+		// The operations that were removed from the other lanes are returned to their lanes.
+		require.Equal(t, uint(2), q.Len())
+
+		lowLane.err = nil
+
+		ops, err = q.Peek(10)
+		require.NoError(t, err)
+		requireSuffixes(t, ops, "update1", "update2")
+
+		require.NoError(t, q.Close())
+	})
+
+	t.Run("close error", func(t *testing.T) {
+		errExpected := errors.New("injected close error")
+
+		q, err := NewPriorityQueue(func(priority operation.Priority) (Queue, error) {
+			return &mockQueue{MemQueue: &MemQueue{}, closeErr: errExpected}, nil
+		})
+		require.NoError(t, err)
+
+		require.True(t, errors.Is(q.Close(), errExpected))
+	})
+}
+
+func newMemPriorityQueue(t *testing.T, opts ...PriorityQueueOption) *PriorityQueue {
+	t.Helper()
+
+	q, err := NewPriorityQueue(func(operation.Priority) (Queue, error) {
+		return &MemQueue{}, nil
+	}, opts...)
+	require.NoError(t, err)
+
+	return q
+}
+
+func newQueuedOp(suffix string, opType operation.Type, priority operation.Priority) *operation.QueuedOperation {
+	return &operation.QueuedOperation{
+		Namespace:        "ns",
+		UniqueSuffix:     suffix,
+		OperationRequest: []byte(suffix),
+		Type:             opType,
+		Priority:         priority,
+	}
+}
+
+func addOps(t *testing.T, q *PriorityQueue, ops ...*operation.QueuedOperation) {
+	t.Helper()
+
+	for _, op := range ops {
+		_, err := q.Add(op, 10)
+		require.NoError(t, err)
+	}
+}
+
+func requireSuffixes(t *testing.T, ops operation.QueuedOperationsAtTime, suffixes ...string) {
+	t.Helper()
+
+	actual := make([]string, len(ops))
+	for i, op := range ops {
+		actual[i] = op.UniqueSuffix
+	}
+
+	require.Equal(t, suffixes, actual)
+}
+
+type mockQueue struct {
+	*MemQueue
+
+	err      error
+	closeErr error
+}
+
+func (m *mockQueue) Add(data *operation.QueuedOperation, protocolVersion uint64) (uint, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+
+	return m.MemQueue.Add(data, protocolVersion)
+}
+
+func (m *mockQueue) Peek(num uint) (operation.QueuedOperationsAtTime, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return m.MemQueue.Peek(num)
+}
+
+func (m *mockQueue) Remove(num uint) (ops operation.QueuedOperationsAtTime, ack func() uint, nack func(), err error) {
+	if m.err != nil {
+		return nil, nil, nil, m.err
+	}
+
+	return m.MemQueue.Remove(num)
+}
+
+func (m *mockQueue) Close() error {
+	return m.closeErr
+}
@@ -0,0 +1,87 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package batch
+
+import (
+	"crypto/sha256"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+)
+
+// batchRetries holds the retry state of failed batches. The state is kept for each operation of a failed batch
+// so that it follows the operations (e.g. if the batch is cut again along with operations that were added in the
+// meantime) and so that it doesn't affect other batches. The state of a batch is the state of the operation with
+// the most failed attempts.
+type batchRetries struct {
+	policy RetryPolicy
+	ops    map[[sha256.Size]byte]*retryState
+}
+
+type retryState struct {
+	attempts   int
+	retryAfter time.Time
+}
+
+func newBatchRetries(policy RetryPolicy) *batchRetries {
+	return &batchRetries{
+		policy: policy,
+		ops:    make(map[[sha256.Size]byte]*retryState),
+	}
+}
+
+// get returns the number of failed attempts of the given batch and the time before which the batch should
+// not be retried.
+func (b *batchRetries) get(ops []*operation.QueuedOperation) (attempts int, retryAfter time.Time) {
+	for _, op := range ops {
+		state, ok := b.ops[retryKey(op)]
+		if !ok {
+			continue
+		}
+
+		if state.attempts > attempts {
+			attempts = state.attempts
+		}
+
+		if state.retryAfter.After(retryAfter) {
+			retryAfter = state.retryAfter
+		}
+	}
+
+	return attempts, retryAfter
+}
+
+// failed records a failed attempt of the given batch and returns the number of failed attempts of the batch
+// along with the backoff before the next attempt.
+func (b *batchRetries) failed(ops []*operation.QueuedOperation, now time.Time) (attempts int, backoff time.Duration) {
+	attempts, _ = b.get(ops)
+	attempts++
+
+	backoff = b.policy.backoff(attempts)
+
+	state := &retryState{attempts: attempts}
+	if backoff > 0 {
+		state.retryAfter = now.Add(backoff)
+	}
+
+	for _, op := range ops {
+		b.ops[retryKey(op)] = state
+	}
+
+	return attempts, backoff
+}
+
+// reset removes the retry state of the given operations.
+func (b *batchRetries) reset(ops []*operation.QueuedOperation) {
+	for _, op := range ops {
+		delete(b.ops, retryKey(op))
+	}
+}
+
+func retryKey(op *operation.QueuedOperation) [sha256.Size]byte {
+	return sha256.Sum256(op.OperationRequest)
+}
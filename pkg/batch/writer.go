@@ -17,6 +17,7 @@ SPDX-License-Identifier: Apache-2.0
 package batch
 
 import (
+	"encoding/json"
 	"fmt"
 	"sync/atomic"
 	"time"
@@ -28,6 +29,7 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
 	"github.com/trustbloc/sidetree-core-go/pkg/batch/cutter"
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/opstatus"
 )
 
 const (
@@ -35,6 +37,7 @@ const (
 
 	defaultBatchTimeout    = 2 * time.Second
 	defaultMonitorInterval = time.Second
+	defaultBackoffFactor   = 2
 )
 
 // Option defines Writer options such as batch timeout.
@@ -56,6 +59,15 @@ type Writer struct {
 	monitorTicker      *time.Ticker
 	batchTimeoutTicker *time.Ticker
 	logger             *log.Log
+	retryPolicy        RetryPolicy
+	retries            *batchRetries
+	isOperationError   OperationErrorClassifier
+	deadLetterSink     DeadLetterSink
+	statusUpdater      OperationStatusUpdater
+	expiredOpHandler   ExpiredOperationHandler
+	unpublishedOpStore UnpublishedOperationStore
+	cacheInvalidator   ResolutionCacheInvalidator
+	anchorTracker      *AnchorTracker
 }
 
 // Context contains batch writer context.
@@ -70,7 +82,9 @@ type Context interface {
 
 // AnchorWriter defines an interface to access the underlying anchoring system.
 type AnchorWriter interface {
-	// WriteAnchor writes the anchor string as a transaction to anchoring system
+	// WriteAnchor writes the anchor string as a transaction to anchoring system. If the anchor was rejected
+	// because of one of the operations then the returned error should wrap protocol.ErrInvalidOperation
+	// (see OperationErrorClassifier).
 	WriteAnchor(anchor string, artifacts []*protocol.AnchorDocument, ops []*operation.Reference, protocolVersion uint64) error
 	// Read ledger transaction
 	Read(sinceTransactionNumber int) (bool, *txn.SidetreeTxn)
@@ -83,6 +97,66 @@ type CompressionProvider interface {
 	Compress(alg string, data []byte) ([]byte, error)
 }
 
+// DeadLetterSink receives invalid operations that could not be anchored after all retries have been exhausted.
+type DeadLetterSink interface {
+	// Put is invoked with an invalid operation that was isolated from a failing batch along with the error that
+	// caused the operation to fail.
+	Put(op *operation.QueuedOperation, protocolVersion uint64, cause error) error
+}
+
+// OperationStatusUpdater is notified as operations progress through the batch writer.
+type OperationStatusUpdater interface {
+	Update(request []byte, suffix string, status opstatus.Status, opts ...opstatus.UpdateOption)
+}
+
+// ExpiredOperationHandler is notified of operations that were discarded from a batch since their
+// 'anchorUntil' time has passed.
+type ExpiredOperationHandler interface {
+	OperationExpired(op *operation.QueuedOperation, protocolVersion uint64)
+}
+
+// UnpublishedOperationStore holds operations that have not yet been anchored. Expired operations are
+// deleted from this store since they will never be anchored.
+type UnpublishedOperationStore interface {
+	// Delete deletes operation from unpublished operation store.
+	Delete(op *operation.AnchoredOperation) error
+}
+
+// ResolutionCacheInvalidator invalidates cached resolution models. It is invoked when an expired operation
+// is deleted from the unpublished operation store.
+type ResolutionCacheInvalidator interface {
+	Invalidate(uniqueSuffixes ...string)
+}
+
+// OperationErrorClassifier returns true if the given error, which was returned while processing a batch, may have
+// been caused by one of the operations in the batch (e.g. an operation is invalid, or CAS or the anchoring system
+// rejected a batch file or the anchor because of an operation) rather than by the system (e.g. CAS or the
+// anchoring system is unavailable). The default classifier returns true for errors that wrap
+// protocol.ErrInvalidOperation.
+type OperationErrorClassifier func(err error) bool
+
+// RetryPolicy defines how a failed batch is retried.
+//
+// After a batch fails, it is returned to the queue and is not retried until the backoff period has elapsed.
+// The backoff starts at InitialBackoff and is multiplied by BackoffFactor after every failed attempt (up to
+// MaxBackoff). The attempts are counted for the batch (i.e. for the operations in the batch) so a failed batch
+// doesn't delay other batches. Once the batch has failed MaxAttempts times because of an error that may have been
+// caused by an operation (see OperationErrorClassifier), it is repeatedly split in half and each half is processed
+// separately in order to isolate the failing operations. The isolated operations are passed to the dead-letter
+// sink and the remaining operations are anchored. A batch that fails for any other reason (e.g. CAS or the
+// anchoring system is unavailable) is retried until it succeeds.
+type RetryPolicy struct {
+	// InitialBackoff is the time to wait before retrying a failed batch for the first time.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum time to wait between retries. Zero means that there is no maximum.
+	MaxBackoff time.Duration
+	// BackoffFactor is the multiplier applied to the backoff after every failed attempt (defaults to 2).
+	BackoffFactor float64
+	// MaxAttempts is the number of times that a batch is attempted before the invalid operations are isolated.
+	// Zero means that the batch is retried indefinitely.
+	MaxAttempts int
+}
+
 // New creates a new Writer with the given namespace.
 // Writer accepts operations being delivered via Add, orders them, and then uses the batch
 // cutter to form the operations batch files. The URI of main batch file (index core)
@@ -103,6 +177,38 @@ func New(namespace string, context Context, options ...Option) (*Writer, error)
 		monitorInterval = rOpts.MonitorInterval
 	}
 
+	logger := log.New(loggerModule, log.WithFields(log.WithNamespace(namespace)))
+
+	deadLetterSink := rOpts.DeadLetterSink
+	if deadLetterSink == nil {
+		deadLetterSink = &logDeadLetterSink{logger: logger}
+	}
+
+	statusUpdater := rOpts.OperationStatusUpdater
+	if statusUpdater == nil {
+		statusUpdater = &noopOperationStatusUpdater{}
+	}
+
+	expiredOpHandler := rOpts.ExpiredOperationHandler
+	if expiredOpHandler == nil {
+		expiredOpHandler = &logExpiredOperationHandler{logger: logger}
+	}
+
+	unpublishedOpStore := rOpts.UnpublishedOperationStore
+	if unpublishedOpStore == nil {
+		unpublishedOpStore = &noopUnpublishedOpsStore{}
+	}
+
+	cacheInvalidator := rOpts.ResolutionCacheInvalidator
+	if cacheInvalidator == nil {
+		cacheInvalidator = &noopResolutionCacheInvalidator{}
+	}
+
+	isOperationError := rOpts.OperationErrorClassifier
+	if isOperationError == nil {
+		isOperationError = isInvalidOperationError
+	}
+
 	return &Writer{
 		namespace:          namespace,
 		batchCutter:        cutter.New(context.Protocol(), context.OperationQueue()),
@@ -111,7 +217,16 @@ func New(namespace string, context Context, options ...Option) (*Writer, error)
 		protocol:           context.Protocol(),
 		batchTimeoutTicker: time.NewTicker(batchTimeout),
 		monitorTicker:      time.NewTicker(monitorInterval),
-		logger:             log.New(loggerModule, log.WithFields(log.WithNamespace(namespace))),
+		logger:             logger,
+		retryPolicy:        rOpts.RetryPolicy,
+		retries:            newBatchRetries(rOpts.RetryPolicy),
+		isOperationError:   isOperationError,
+		deadLetterSink:     deadLetterSink,
+		statusUpdater:      statusUpdater,
+		expiredOpHandler:   expiredOpHandler,
+		unpublishedOpStore: unpublishedOpStore,
+		cacheInvalidator:   cacheInvalidator,
+		anchorTracker:      rOpts.AnchorTracker,
 	}, nil
 }
 
@@ -161,6 +276,7 @@ func (r *Writer) main() {
 	for {
 		select {
 		case <-r.monitorTicker.C:
+			r.rewriteUnconfirmedAnchors()
 			r.processAvailable(false)
 
 		case <-r.batchTimeoutTicker.C:
@@ -231,16 +347,30 @@ func (r *Writer) cutAndProcess(forceCut bool) (numProcessed int, pending uint, e
 		return 0, result.Pending, nil
 	}
 
+	if attempts, retryAfter := r.retries.get(result.Operations); time.Now().Before(retryAfter) {
+		r.logger.Debug("Waiting for retry backoff to elapse before processing batch",
+			log.WithTotal(len(result.Operations)), log.WithAttempt(attempts))
+
+		result.Nack()
+
+		return 0, result.Pending + uint(len(result.Operations)), nil
+	}
+
 	r.logger.Info("Processing batch operations for protocol genesis time...",
 		log.WithTotal(len(result.Operations)), log.WithGenesisTime(result.ProtocolVersion))
 
 	err = r.process(result.Operations, result.ProtocolVersion)
-	if err != nil {
+	if err == nil {
+		r.retries.reset(result.Operations)
+	} else {
 		r.logger.Error("Error processing batch operations", log.WithTotal(len(result.Operations)), log.WithError(err))
 
-		result.Nack()
+		if !r.retriesExhausted(result.Operations) || !r.isOperationError(err) ||
+			!r.isolateFailedOperations(result.Operations, result.ProtocolVersion) {
+			result.Nack()
 
-		return 0, result.Pending + uint(len(result.Operations)), err
+			return 0, result.Pending + uint(len(result.Operations)), err
+		}
 	}
 
 	r.logger.Info("Successfully processed batch operations. Committing to batch cutter ...",
@@ -253,6 +383,109 @@ func (r *Writer) cutAndProcess(forceCut bool) (numProcessed int, pending uint, e
 	return len(result.Operations), pending, nil
 }
 
+// retriesExhausted records a failed attempt of the given batch, delays the next attempt of the batch by the
+// backoff and returns true if the maximum number of attempts (as specified by the retry policy) has been reached.
+func (r *Writer) retriesExhausted(ops []*operation.QueuedOperation) bool {
+	attempts, backoff := r.retries.failed(ops, time.Now())
+	if backoff > 0 {
+		r.logger.Info("Batch will be retried after backoff", log.WithAttempt(attempts), log.WithBackoff(backoff))
+	}
+
+	if r.retryPolicy.MaxAttempts > 0 && attempts >= r.retryPolicy.MaxAttempts {
+		r.logger.Warn("Maximum number of attempts reached for batch.", log.WithAttempt(attempts))
+
+		return true
+	}
+
+	return false
+}
+
+// isolateFailedOperations splits the failed batch in half and processes each half separately (recursively) until
+// the failing operations are isolated. The failing operations are sent to the dead-letter sink. The operations of
+// a half that fails for any other reason (e.g. CAS became unavailable) are added back to the queue so that they
+// are processed in a later batch (after the backoff). False is returned if none of the operations could be
+// processed or isolated, in which case the batch should be retried.
+func (r *Writer) isolateFailedOperations(ops []*operation.QueuedOperation, protocolVersion uint64) bool {
+	var deadLetters []*deadLetter
+
+	var unprocessed []*operation.QueuedOperation
+
+	numProcessed := r.bisect(ops, protocolVersion, &deadLetters, &unprocessed)
+
+	if numProcessed == 0 && len(deadLetters) == 0 {
+		r.logger.Warn("None of the operations in the batch could be processed. The batch will be retried.",
+			log.WithTotal(len(ops)))
+
+		return false
+	}
+
+	// The unprocessed operations keep their retry state so that they're retried after the backoff.
+	r.retries.reset(excludeOperations(ops, unprocessed))
+
+	for _, op := range unprocessed {
+		r.logger.Info("Adding unprocessed operation back to the queue", log.WithSuffix(op.UniqueSuffix))
+
+		if _, err := r.batchCutter.Add(op, protocolVersion); err != nil {
+			r.logger.Error("Unable to add unprocessed operation back to the queue", log.WithSuffix(op.UniqueSuffix),
+				log.WithError(err))
+		}
+	}
+
+	for _, dl := range deadLetters {
+		r.logger.Warn("Sending operation to dead-letter sink", log.WithSuffix(dl.op.UniqueSuffix), log.WithError(dl.err))
+
+		r.statusUpdater.Update(dl.op.OperationRequest, dl.op.UniqueSuffix, opstatus.StatusFailed,
+			opstatus.WithReason(dl.err.Error()))
+
+		if err := r.deadLetterSink.Put(dl.op, protocolVersion, dl.err); err != nil {
+			r.logger.Error("Failed to add operation to dead-letter sink", log.WithSuffix(dl.op.UniqueSuffix),
+				log.WithError(err))
+		}
+	}
+
+	return true
+}
+
+type deadLetter struct {
+	op  *operation.QueuedOperation
+	err error
+}
+
+// bisect processes each half of the given (failed) operations and returns the number of operations
+// that were successfully processed. A half that fails because of an operation (see OperationErrorClassifier) is
+// bisected further (or, if it contains a single operation, added to the dead letters). The operations of a half
+// that fails for any other reason are added to the unprocessed operations.
+func (r *Writer) bisect(ops []*operation.QueuedOperation, protocolVersion uint64, deadLetters *[]*deadLetter,
+	unprocessed *[]*operation.QueuedOperation) int {
+	mid := len(ops) / 2
+
+	numProcessed := 0
+
+	for _, half := range [][]*operation.QueuedOperation{ops[:mid], ops[mid:]} {
+		if len(half) == 0 {
+			continue
+		}
+
+		err := r.process(half, protocolVersion)
+
+		switch {
+		case err == nil:
+			numProcessed += len(half)
+		case !r.isOperationError(err):
+			r.logger.Warn("Error processing operations while isolating failing operations",
+				log.WithTotal(len(half)), log.WithError(err))
+
+			*unprocessed = append(*unprocessed, half...)
+		case len(half) == 1:
+			*deadLetters = append(*deadLetters, &deadLetter{op: half[0], err: err})
+		default:
+			numProcessed += r.bisect(half, protocolVersion, deadLetters, unprocessed)
+		}
+	}
+
+	return numProcessed
+}
+
 func (r *Writer) process(ops []*operation.QueuedOperation, protocolVersion uint64) error {
 	if len(ops) == 0 {
 		return errors.New("create batch called with no pending operations, should not happen")
@@ -268,8 +501,29 @@ func (r *Writer) process(ops []*operation.QueuedOperation, protocolVersion uint6
 		return err
 	}
 
+	r.logger.Info("Writing anchor string", log.WithAnchorString(anchoringInfo.AnchorString))
+
+	// Create Sidetree transaction in anchoring system (write anchor string)
+	err = r.context.Anchor().WriteAnchor(anchoringInfo.AnchorString, anchoringInfo.Artifacts,
+		anchoringInfo.OperationReferences, protocolVersion)
+	if err != nil {
+		return err
+	}
+
+	// The status is only updated once the anchor has been written since, otherwise, the operations would be
+	// reported as batched even though the batch is retried (possibly with different batch files).
+	batchedOps := r.updateBatchedStatus(ops, anchoringInfo)
+
+	for _, op := range batchedOps {
+		r.statusUpdater.Update(op.OperationRequest, op.UniqueSuffix, opstatus.StatusAnchored,
+			opstatus.WithAnchorString(anchoringInfo.AnchorString))
+	}
+
+	r.trackAnchor(anchoringInfo, protocolVersion)
+
 	// Sidetree spec allows for one operation per suffix in the batch
-	// Process additional operations for suffix in the next batch
+	// Process additional operations for suffix in the next batch. (This is done after the anchor has been
+	// written since, otherwise, the additional operations would be queued twice if the batch is retried.)
 	for _, op := range anchoringInfo.AdditionalOperations {
 		err = r.Add(op, protocolVersion)
 		if err != nil {
@@ -279,11 +533,155 @@ func (r *Writer) process(ops []*operation.QueuedOperation, protocolVersion uint6
 		}
 	}
 
-	r.logger.Info("Writing anchor string", log.WithAnchorString(anchoringInfo.AnchorString))
+	// Expired operations are also handled after the anchor has been written so that they're only reported once.
+	for _, op := range anchoringInfo.ExpiredOperations {
+		r.handleExpiredOperation(op, protocolVersion)
+	}
 
-	// Create Sidetree transaction in anchoring system (write anchor string)
-	return r.context.Anchor().WriteAnchor(anchoringInfo.AnchorString, anchoringInfo.Artifacts,
-		anchoringInfo.OperationReferences, protocolVersion)
+	return nil
+}
+
+func (r *Writer) trackAnchor(anchoringInfo *protocol.AnchoringInfo, protocolVersion uint64) {
+	if r.anchorTracker == nil {
+		return
+	}
+
+	err := r.anchorTracker.track(&PendingAnchor{
+		AnchorString:        anchoringInfo.AnchorString,
+		Artifacts:           anchoringInfo.Artifacts,
+		OperationReferences: anchoringInfo.OperationReferences,
+		ProtocolVersion:     protocolVersion,
+		Attempts:            1,
+		LastWritten:         time.Now(),
+	})
+	if err != nil {
+		// The anchor was written so the batch should not be retried.
+		r.logger.Warn("Failed to track anchor", log.WithAnchorString(anchoringInfo.AnchorString), log.WithError(err))
+	}
+}
+
+// rewriteUnconfirmedAnchors writes the anchors that have not been observed on the ledger within the confirmation
+// timeout again. The batch files of the anchors are already stored in CAS.
+func (r *Writer) rewriteUnconfirmedAnchors() {
+	if r.anchorTracker == nil {
+		return
+	}
+
+	now := time.Now()
+
+	anchors, err := r.anchorTracker.expired(now)
+	if err != nil {
+		r.logger.Warn("Failed to retrieve unconfirmed anchors", log.WithError(err))
+
+		return
+	}
+
+	for _, anchor := range anchors {
+		r.rewriteAnchor(anchor, now)
+	}
+}
+
+func (r *Writer) rewriteAnchor(anchor *PendingAnchor, now time.Time) {
+	if r.anchorTracker.attemptsExhausted(anchor) {
+		r.logger.Error("Anchor was not confirmed after the maximum number of attempts. It will no longer be tracked.",
+			log.WithAnchorString(anchor.AnchorString), log.WithAttempt(anchor.Attempts),
+			log.WithTotal(len(anchor.OperationReferences)))
+
+		if err := r.anchorTracker.remove(anchor.AnchorString); err != nil {
+			r.logger.Warn("Failed to delete anchor from pending anchor store", log.WithAnchorString(anchor.AnchorString),
+				log.WithError(err))
+		}
+
+		return
+	}
+
+	r.logger.Warn("Anchor was not confirmed within the confirmation timeout. Writing anchor again.",
+		log.WithAnchorString(anchor.AnchorString), log.WithAttempt(anchor.Attempts+1))
+
+	err := r.context.Anchor().WriteAnchor(anchor.AnchorString, anchor.Artifacts, anchor.OperationReferences,
+		anchor.ProtocolVersion)
+	if err != nil {
+		r.logger.Warn("Failed to write unconfirmed anchor. The anchor will be written again after the confirmation timeout.",
+			log.WithAnchorString(anchor.AnchorString), log.WithError(err))
+	}
+
+	anchor.Attempts++
+	anchor.LastWritten = now
+
+	if err := r.anchorTracker.update(anchor); err != nil {
+		r.logger.Warn("Failed to update pending anchor", log.WithAnchorString(anchor.AnchorString), log.WithError(err))
+	}
+}
+
+func (r *Writer) handleExpiredOperation(op *operation.QueuedOperation, protocolVersion uint64) {
+	r.statusUpdater.Update(op.OperationRequest, op.UniqueSuffix, opstatus.StatusFailed,
+		opstatus.WithReason("operation expired"))
+
+	err := r.unpublishedOpStore.Delete(&operation.AnchoredOperation{
+		Type:             getOperationType(op.OperationRequest),
+		UniqueSuffix:     op.UniqueSuffix,
+		OperationRequest: op.OperationRequest,
+		ProtocolVersion:  protocolVersion,
+		AnchorOrigin:     op.AnchorOrigin,
+	})
+	if err != nil {
+		r.logger.Warn("Failed to delete expired operation from unpublished operation store",
+			log.WithSuffix(op.UniqueSuffix), log.WithError(err))
+	}
+
+	r.cacheInvalidator.Invalidate(op.UniqueSuffix)
+
+	r.expiredOpHandler.OperationExpired(op, protocolVersion)
+}
+
+// getOperationType returns the type of the given operation request. The request was already validated
+// when it was added to the queue so an empty type is returned if the request can't be unmarshalled.
+func getOperationType(request []byte) operation.Type {
+	opType := &struct {
+		Type operation.Type `json:"type"`
+	}{}
+
+	if err := json.Unmarshal(request, opType); err != nil {
+		return ""
+	}
+
+	return opType.Type
+}
+
+// updateBatchedStatus marks the operations that were written to batch files as 'batched'. The operations that
+// were included in the batch files are returned.
+func (r *Writer) updateBatchedStatus(ops []*operation.QueuedOperation,
+	anchoringInfo *protocol.AnchoringInfo) []*operation.QueuedOperation {
+	excluded := append(append([]*operation.QueuedOperation{}, anchoringInfo.AdditionalOperations...),
+		anchoringInfo.ExpiredOperations...)
+
+	batchedOps := excludeOperations(ops, excluded)
+
+	for _, op := range batchedOps {
+		r.statusUpdater.Update(op.OperationRequest, op.UniqueSuffix, opstatus.StatusBatched,
+			opstatus.WithAnchorString(anchoringInfo.AnchorString))
+	}
+
+	return batchedOps
+}
+
+// excludeOperations returns the given operations without the excluded operations.
+func excludeOperations(ops, excluded []*operation.QueuedOperation) []*operation.QueuedOperation {
+	excludedMap := make(map[*operation.QueuedOperation]struct{}, len(excluded))
+
+	for _, op := range excluded {
+		excludedMap[op] = struct{}{}
+	}
+
+	var result []*operation.QueuedOperation
+
+	for _, op := range ops {
+		if _, ok := excludedMap[op]; !ok {
+			result = append(result, op)
+		}
+	}
+
+	return result
 }
 
 // WithBatchTimeout allows for specifying batch timeout.
@@ -305,10 +703,100 @@ func WithMonitorInterval(interval time.Duration) Option {
 	}
 }
 
+// WithRetryPolicy sets the policy for retrying failed batches.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *Options) error {
+		if policy.InitialBackoff < 0 || policy.MaxBackoff < 0 || policy.BackoffFactor < 0 || policy.MaxAttempts < 0 {
+			return errors.New("retry policy values must not be negative")
+		}
+
+		o.RetryPolicy = policy
+
+		return nil
+	}
+}
+
+// WithOperationErrorClassifier sets the classifier that determines whether a batch failure may have been caused
+// by one of the operations in the batch, in which case the failing operations are isolated once the retries
+// are exhausted. If not set then only errors that wrap protocol.ErrInvalidOperation are classified as such.
+func WithOperationErrorClassifier(classifier OperationErrorClassifier) Option {
+	return func(o *Options) error {
+		o.OperationErrorClassifier = classifier
+
+		return nil
+	}
+}
+
+// WithDeadLetterSink sets the sink that receives operations that could not be anchored. If not set then
+// the failed operations are logged.
+func WithDeadLetterSink(sink DeadLetterSink) Option {
+	return func(o *Options) error {
+		o.DeadLetterSink = sink
+
+		return nil
+	}
+}
+
+// WithOperationStatusUpdater sets an optional updater which is notified when operations are
+// batched, anchored or discarded.
+func WithOperationStatusUpdater(updater OperationStatusUpdater) Option {
+	return func(o *Options) error {
+		o.OperationStatusUpdater = updater
+
+		return nil
+	}
+}
+
+// WithExpiredOperationHandler sets the handler that is notified of operations that were discarded since
+// they expired before being anchored. If not set then the expired operations are logged.
+func WithExpiredOperationHandler(handler ExpiredOperationHandler) Option {
+	return func(o *Options) error {
+		o.ExpiredOperationHandler = handler
+
+		return nil
+	}
+}
+
+// WithUnpublishedOperationStore sets the unpublished operation store from which expired operations are deleted.
+func WithUnpublishedOperationStore(store UnpublishedOperationStore) Option {
+	return func(o *Options) error {
+		o.UnpublishedOperationStore = store
+
+		return nil
+	}
+}
+
+// WithResolutionCacheInvalidator sets an optional resolution cache invalidator.
+func WithResolutionCacheInvalidator(invalidator ResolutionCacheInvalidator) Option {
+	return func(o *Options) error {
+		o.ResolutionCacheInvalidator = invalidator
+
+		return nil
+	}
+}
+
+// WithAnchorTracker sets an optional tracker for the anchors that are written by the writer. Anchors that are not
+// observed on the ledger within the tracker's confirmation timeout are written again.
+func WithAnchorTracker(tracker *AnchorTracker) Option {
+	return func(o *Options) error {
+		o.AnchorTracker = tracker
+
+		return nil
+	}
+}
+
 // Options allows the user to specify more advanced options.
 type Options struct {
-	BatchTimeout    time.Duration
-	MonitorInterval time.Duration
+	BatchTimeout               time.Duration
+	MonitorInterval            time.Duration
+	RetryPolicy                RetryPolicy
+	OperationErrorClassifier   OperationErrorClassifier
+	DeadLetterSink             DeadLetterSink
+	OperationStatusUpdater     OperationStatusUpdater
+	ExpiredOperationHandler    ExpiredOperationHandler
+	UnpublishedOperationStore  UnpublishedOperationStore
+	ResolutionCacheInvalidator ResolutionCacheInvalidator
+	AnchorTracker              *AnchorTracker
 }
 
 // prepareOptsFromOptions reads options.
@@ -323,3 +811,66 @@ func prepareOptsFromOptions(options ...Option) (Options, error) {
 
 	return rOpts, nil
 }
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.InitialBackoff == 0 {
+		return 0
+	}
+
+	factor := p.BackoffFactor
+	if factor == 0 {
+		factor = defaultBackoffFactor
+	}
+
+	backoff := float64(p.InitialBackoff)
+
+	for i := 1; i < attempt; i++ {
+		backoff *= factor
+
+		if p.MaxBackoff > 0 && backoff >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+
+	return time.Duration(backoff)
+}
+
+func isInvalidOperationError(err error) bool {
+	return errors.Is(err, protocol.ErrInvalidOperation)
+}
+
+type logDeadLetterSink struct {
+	logger *log.Log
+}
+
+func (s *logDeadLetterSink) Put(op *operation.QueuedOperation, protocolVersion uint64, cause error) error {
+	s.logger.Error("Discarding operation that could not be anchored", log.WithSuffix(op.UniqueSuffix),
+		log.WithGenesisTime(protocolVersion), log.WithRequestBody(op.OperationRequest), log.WithError(cause))
+
+	return nil
+}
+
+type noopOperationStatusUpdater struct{}
+
+func (noop *noopOperationStatusUpdater) Update([]byte, string, opstatus.Status, ...opstatus.UpdateOption) {
+}
+
+type logExpiredOperationHandler struct {
+	logger *log.Log
+}
+
+func (h *logExpiredOperationHandler) OperationExpired(op *operation.QueuedOperation, protocolVersion uint64) {
+	h.logger.Warn("Discarding expired operation", log.WithSuffix(op.UniqueSuffix),
+		log.WithGenesisTime(protocolVersion), log.WithRequestBody(op.OperationRequest))
+}
+
+type noopUnpublishedOpsStore struct{}
+
+func (noop *noopUnpublishedOpsStore) Delete(_ *operation.AnchoredOperation) error {
+	return nil
+}
+
+type noopResolutionCacheInvalidator struct{}
+
+func (noop *noopResolutionCacheInvalidator) Invalidate(...string) {
+}
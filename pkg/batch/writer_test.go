@@ -10,6 +10,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -18,14 +20,17 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/api/cas"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
 	"github.com/trustbloc/sidetree-core-go/pkg/batch/cutter"
 	"github.com/trustbloc/sidetree-core-go/pkg/batch/opqueue"
 	"github.com/trustbloc/sidetree-core-go/pkg/commitment"
 	"github.com/trustbloc/sidetree-core-go/pkg/compression"
 	"github.com/trustbloc/sidetree-core-go/pkg/jws"
 	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/opstatus"
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/client"
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/doccomposer"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/model"
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationapplier"
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationparser"
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider"
@@ -440,6 +445,719 @@ func TestProcessError(t *testing.T) {
 	})
 }
 
+func TestRetryPolicy(t *testing.T) {
+	t.Run("backoff", func(t *testing.T) {
+		ctx := newMockContext()
+
+		aw := newMockFailingAnchorWriter(func([]*operation.Reference) error {
+			return errors.New("injected anchor error")
+		})
+		ctx.anchorWriter = aw
+
+		writer, err := New(namespace, ctx,
+			WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond),
+			WithRetryPolicy(RetryPolicy{InitialBackoff: 300 * time.Millisecond, BackoffFactor: 2}),
+		)
+		require.NoError(t, err)
+
+		writer.Start()
+		defer writer.Stop()
+
+		require.NoError(t, writer.Add(generateOperations(1)[0], 0))
+
+		time.Sleep(time.Second)
+
+		// Attempts should be made at approximately 0ms, 300ms and 900ms.
+		require.GreaterOrEqual(t, aw.Attempts(), 2)
+		require.LessOrEqual(t, aw.Attempts(), 4)
+	})
+
+	t.Run("dead letter", func(t *testing.T) {
+		ctx := newMockContext()
+		ctx.ProtocolClient.Protocol.MaxOperationCount = 4
+		ctx.ProtocolClient.CurrentVersion.ProtocolReturns(ctx.ProtocolClient.Protocol)
+
+		ops := generateOperations(4)
+		ops[2] = generateInvalidOperation(3)
+
+		sink := &mockDeadLetterSink{}
+
+		writer, err := New(namespace, ctx,
+			WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond),
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 2}), WithDeadLetterSink(sink),
+		)
+		require.NoError(t, err)
+
+		for _, op := range ops {
+			require.NoError(t, writer.Add(op, 0))
+		}
+
+		writer.Start()
+		defer writer.Stop()
+
+		require.Eventually(t, func() bool {
+			deadOps, _ := sink.Get()
+
+			return len(deadOps) == 1 && len(ctx.AnchorWriter.GetAnchors()) == 2
+		}, time.Second, 10*time.Millisecond)
+
+		deadOps, errs := sink.Get()
+		require.Equal(t, "3", deadOps[0].UniqueSuffix)
+		require.True(t, errors.Is(errs[0], protocol.ErrInvalidOperation))
+
+		// Operations 1 and 2 are anchored together and operation 4 is anchored on its own.
+		require.Zero(t, ctx.OpQueue.Len())
+	})
+
+	t.Run("dead letter sink error", func(t *testing.T) {
+		ctx := newMockContext()
+
+		writer, err := New(namespace, ctx,
+			WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond),
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+			WithDeadLetterSink(&mockDeadLetterSink{err: errors.New("injected sink error")}),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, writer.Add(generateInvalidOperation(1), 0))
+
+		writer.Start()
+		defer writer.Stop()
+
+		require.Eventually(t, func() bool { return ctx.OpQueue.Len() == 0 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("default dead letter sink", func(t *testing.T) {
+		ctx := newMockContext()
+
+		writer, err := New(namespace, ctx,
+			WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond),
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, writer.Add(generateInvalidOperation(1), 0))
+
+		writer.Start()
+		defer writer.Stop()
+
+		require.Eventually(t, func() bool { return ctx.OpQueue.Len() == 0 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("systemic failure - batch is not dead-lettered", func(t *testing.T) {
+		ctx := newMockContext()
+		ctx.ProtocolClient.CasClient.SetError(errors.New("injected CAS error"))
+
+		sink := &mockDeadLetterSink{}
+
+		writer, err := New(namespace, ctx,
+			WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond),
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 1}), WithDeadLetterSink(sink),
+		)
+		require.NoError(t, err)
+
+		for _, op := range generateOperations(2) {
+			require.NoError(t, writer.Add(op, 0))
+		}
+
+		writer.Start()
+		defer writer.Stop()
+
+		time.Sleep(100 * time.Millisecond)
+
+		deadOps, _ := sink.Get()
+		require.Empty(t, deadOps)
+		require.Empty(t, ctx.AnchorWriter.GetAnchors())
+
+		ctx.ProtocolClient.CasClient.SetError(nil)
+
+		require.Eventually(t, func() bool { return len(ctx.AnchorWriter.GetAnchors()) == 1 },
+			time.Second, 10*time.Millisecond)
+		require.Eventually(t, func() bool { return ctx.OpQueue.Len() == 0 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("anchor failure - operation is not dead-lettered", func(t *testing.T) {
+		ctx := newMockContext()
+
+		var fail atomic.Value
+
+		fail.Store(true)
+
+		aw := newMockFailingAnchorWriter(func([]*operation.Reference) error {
+			if fail.Load().(bool) {
+				return errors.New("injected anchor error")
+			}
+
+			return nil
+		})
+		ctx.anchorWriter = aw
+
+		sink := &mockDeadLetterSink{}
+
+		writer, err := New(namespace, ctx,
+			WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond),
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 1}), WithDeadLetterSink(sink),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, writer.Add(generateOperations(1)[0], 0))
+
+		writer.Start()
+		defer writer.Stop()
+
+		require.Eventually(t, func() bool { return aw.Attempts() > 2 }, time.Second, 10*time.Millisecond)
+
+		deadOps, _ := sink.Get()
+		require.Empty(t, deadOps)
+
+		fail.Store(false)
+
+		require.Eventually(t, func() bool { return len(aw.GetAnchors()) == 1 }, time.Second, 10*time.Millisecond)
+		require.Eventually(t, func() bool { return ctx.OpQueue.Len() == 0 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("systemic failure while isolating invalid operations", func(t *testing.T) {
+		ctx := newMockContext()
+
+		var fail atomic.Value
+
+		fail.Store(true)
+
+		aw := newMockFailingAnchorWriter(func([]*operation.Reference) error {
+			if fail.Load().(bool) {
+				return errors.New("injected anchor error")
+			}
+
+			return nil
+		})
+		ctx.anchorWriter = aw
+
+		sink := &mockDeadLetterSink{}
+
+		writer, err := New(namespace, ctx, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}), WithDeadLetterSink(sink))
+		require.NoError(t, err)
+
+		require.NoError(t, writer.Add(generateOperations(1)[0], 0))
+		require.NoError(t, writer.Add(generateInvalidOperation(2), 0))
+
+		// The invalid operation is dead-lettered and the other operation, which could not be anchored,
+		// is added back to the queue.
+		n, _, err := writer.cutAndProcess(true)
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+
+		deadOps, _ := sink.Get()
+		require.Len(t, deadOps, 1)
+		require.Equal(t, "2", deadOps[0].UniqueSuffix)
+		require.Equal(t, uint(1), ctx.OpQueue.Len())
+		require.Empty(t, aw.GetAnchors())
+
+		fail.Store(false)
+
+		n, _, err = writer.cutAndProcess(true)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Len(t, aw.GetAnchors(), 1)
+		require.Zero(t, ctx.OpQueue.Len())
+	})
+
+	t.Run("backoff is kept when no operations are isolated", func(t *testing.T) {
+		ctx := newMockContext()
+
+		oh := &mocks.OperationHandler{}
+		oh.PrepareTxnFilesStub = func(ops []*operation.QueuedOperation) (*protocol.AnchoringInfo, error) {
+			if len(ops) > 1 {
+				return nil, fmt.Errorf("%w: injected parse error", protocol.ErrInvalidOperation)
+			}
+
+			return nil, errors.New("injected CAS error")
+		}
+
+		ctx.ProtocolClient.CurrentVersion.OperationHandlerReturns(oh)
+
+		sink := &mockDeadLetterSink{}
+
+		writer, err := New(namespace, ctx,
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 1, InitialBackoff: time.Hour}), WithDeadLetterSink(sink))
+		require.NoError(t, err)
+
+		for _, op := range generateOperations(2) {
+			require.NoError(t, writer.Add(op, 0))
+		}
+
+		_, _, err = writer.cutAndProcess(true)
+		require.Error(t, err)
+
+		deadOps, _ := sink.Get()
+		require.Empty(t, deadOps)
+		require.Equal(t, uint(2), ctx.OpQueue.Len())
+
+		ops, err := ctx.OpQueue.Peek(2)
+		require.NoError(t, err)
+
+		attempts, retryAfter := writer.retries.get(ops.QueuedOperations())
+		require.Equal(t, 1, attempts)
+		require.True(t, retryAfter.After(time.Now()))
+
+		// The batch isn't retried until the backoff has elapsed.
+		n, pending, err := writer.cutAndProcess(true)
+		require.NoError(t, err)
+		require.Zero(t, n)
+		require.Equal(t, uint(2), pending)
+		require.Equal(t, uint(2), ctx.OpQueue.Len())
+		require.Equal(t, 3, oh.PrepareTxnFilesCallCount())
+	})
+
+	t.Run("retries are counted per batch", func(t *testing.T) {
+		ctx := newMockContext()
+		ctx.anchorWriter = newMockFailingAnchorWriter(func([]*operation.Reference) error {
+			return fmt.Errorf("%w: injected anchor rejection", protocol.ErrInvalidOperation)
+		})
+
+		sink := &mockDeadLetterSink{}
+
+		writer, err := New(namespace, ctx, WithRetryPolicy(RetryPolicy{MaxAttempts: 2}), WithDeadLetterSink(sink))
+		require.NoError(t, err)
+
+		ops := generateOperations(2)
+
+		require.NoError(t, writer.Add(ops[0], 0))
+
+		_, _, err = writer.cutAndProcess(true)
+		require.Error(t, err)
+
+		// Replace the failed batch with another batch.
+		_, ack, _, err := ctx.OpQueue.Remove(1)
+		require.NoError(t, err)
+		ack()
+
+		require.NoError(t, writer.Add(ops[1], 0))
+
+		// The failed attempt of the first batch doesn't count towards the attempts of the other batch.
+		_, _, err = writer.cutAndProcess(true)
+		require.Error(t, err)
+
+		deadOps, _ := sink.Get()
+		require.Empty(t, deadOps)
+
+		attempts, _ := writer.retries.get([]*operation.QueuedOperation{ops[1]})
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("custom operation error classifier", func(t *testing.T) {
+		ctx := newMockContext()
+
+		ops := generateOperations(2)
+		rejectedSuffix := getUniqueSuffix(t, ops[1])
+
+		errRejected := errors.New("injected anchor rejection")
+
+		aw := newMockFailingAnchorWriter(func(refs []*operation.Reference) error {
+			for _, ref := range refs {
+				if ref.UniqueSuffix == rejectedSuffix {
+					return errRejected
+				}
+			}
+
+			return nil
+		})
+		ctx.anchorWriter = aw
+
+		sink := &mockDeadLetterSink{}
+
+		writer, err := New(namespace, ctx,
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 1}), WithDeadLetterSink(sink),
+			WithOperationErrorClassifier(func(err error) bool { return errors.Is(err, errRejected) }),
+		)
+		require.NoError(t, err)
+
+		for _, op := range ops {
+			require.NoError(t, writer.Add(op, 0))
+		}
+
+		n, _, err := writer.cutAndProcess(true)
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+
+		deadOps, _ := sink.Get()
+		require.Len(t, deadOps, 1)
+		require.Equal(t, "2", deadOps[0].UniqueSuffix)
+		require.Len(t, aw.GetAnchors(), 1)
+		require.Zero(t, ctx.OpQueue.Len())
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := New(namespace, newMockContext(), WithRetryPolicy(RetryPolicy{MaxAttempts: -1}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "retry policy values must not be negative")
+	})
+}
+
+func TestOperationStatus(t *testing.T) {
+	t.Run("anchored", func(t *testing.T) {
+		tracker := opstatus.New(opstatus.NewMemStore())
+
+		writer, err := New(namespace, newMockContext(),
+			WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond),
+			WithOperationStatusUpdater(tracker),
+		)
+		require.NoError(t, err)
+
+		writer.Start()
+		defer writer.Stop()
+
+		ops := generateOperations(2)
+
+		for _, op := range ops {
+			require.NoError(t, writer.Add(op, 0))
+		}
+
+		time.Sleep(100 * time.Millisecond)
+
+		for _, op := range ops {
+			status := getOperationStatus(t, tracker, op)
+			require.Equal(t, opstatus.StatusAnchored, status.Status)
+			require.NotEmpty(t, status.AnchorString)
+		}
+	})
+
+	t.Run("anchor failure - not batched", func(t *testing.T) {
+		ctx := newMockContext()
+		ctx.anchorWriter = newMockFailingAnchorWriter(func([]*operation.Reference) error {
+			return errors.New("injected anchor error")
+		})
+
+		tracker := opstatus.New(opstatus.NewMemStore())
+
+		writer, err := New(namespace, ctx, WithOperationStatusUpdater(tracker))
+		require.NoError(t, err)
+
+		op := generateOperations(1)[0]
+
+		tracker.Update(op.OperationRequest, op.UniqueSuffix, opstatus.StatusQueued)
+
+		require.NoError(t, writer.Add(op, 0))
+
+		_, _, err = writer.cutAndProcess(true)
+		require.Error(t, err)
+
+		status := getOperationStatus(t, tracker, op)
+		require.Equal(t, opstatus.StatusQueued, status.Status)
+		require.Empty(t, status.AnchorString)
+	})
+
+	t.Run("failed - dead letter", func(t *testing.T) {
+		ctx := newMockContext()
+
+		tracker := opstatus.New(opstatus.NewMemStore())
+
+		writer, err := New(namespace, ctx,
+			WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond),
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 1}), WithOperationStatusUpdater(tracker),
+		)
+		require.NoError(t, err)
+
+		writer.Start()
+		defer writer.Stop()
+
+		op := generateInvalidOperation(1)
+
+		require.NoError(t, writer.Add(op, 0))
+
+		hash, err := opstatus.GetOperationHash(op.OperationRequest)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			status, e := tracker.Get(hash)
+
+			return e == nil && status.Status == opstatus.StatusFailed
+		}, time.Second, 10*time.Millisecond)
+
+		status := getOperationStatus(t, tracker, op)
+		require.Contains(t, status.Reason, protocol.ErrInvalidOperation.Error())
+	})
+}
+
+func TestAnchorConfirmation(t *testing.T) {
+	t.Run("unconfirmed anchor is written again", func(t *testing.T) {
+		ctx := newMockContext()
+
+		tracker := NewAnchorTracker(200 * time.Millisecond)
+
+		writer, err := New(namespace, ctx,
+			WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond),
+			WithAnchorTracker(tracker),
+		)
+		require.NoError(t, err)
+
+		writer.Start()
+		defer writer.Stop()
+
+		for _, op := range generateOperations(2) {
+			require.NoError(t, writer.Add(op, 0))
+		}
+
+		require.Eventually(t, func() bool { return len(ctx.AnchorWriter.GetAnchors()) == 1 },
+			time.Second, 10*time.Millisecond)
+
+		pending, err := tracker.PendingAnchors()
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		require.Len(t, pending[0].OperationReferences, 2)
+		require.NotEmpty(t, pending[0].Artifacts)
+
+		anchorString := ctx.AnchorWriter.GetAnchors()[0]
+		require.Equal(t, anchorString, pending[0].AnchorString)
+
+		// The same anchor is written again since it wasn't observed on the ledger.
+		require.Eventually(t, func() bool { return len(ctx.AnchorWriter.GetAnchors()) >= 2 },
+			time.Second, 10*time.Millisecond)
+		require.Equal(t, anchorString, ctx.AnchorWriter.GetAnchors()[1])
+
+		tracker.TxnObserved(&txn.SidetreeTxn{AnchorString: anchorString})
+
+		pending, err = tracker.PendingAnchors()
+		require.NoError(t, err)
+		require.Empty(t, pending)
+
+		time.Sleep(300 * time.Millisecond)
+
+		require.Len(t, ctx.AnchorWriter.GetAnchors(), 2)
+	})
+
+	t.Run("maximum attempts reached", func(t *testing.T) {
+		ctx := newMockContext()
+
+		tracker := NewAnchorTracker(20*time.Millisecond, WithMaxAnchorAttempts(2))
+
+		writer, err := New(namespace, ctx,
+			WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond),
+			WithAnchorTracker(tracker),
+		)
+		require.NoError(t, err)
+
+		writer.Start()
+		defer writer.Stop()
+
+		require.NoError(t, writer.Add(generateOperations(1)[0], 0))
+
+		require.Eventually(t, func() bool {
+			pending, err := tracker.PendingAnchors()
+
+			return err == nil && len(pending) == 0 && len(ctx.AnchorWriter.GetAnchors()) == 2
+		}, time.Second, 10*time.Millisecond)
+
+		time.Sleep(50 * time.Millisecond)
+
+		require.Len(t, ctx.AnchorWriter.GetAnchors(), 2)
+	})
+
+	t.Run("error writing unconfirmed anchor", func(t *testing.T) {
+		ctx := newMockContext()
+
+		anchorWriter := newMockFailingAnchorWriter(func([]*operation.Reference) error { return nil })
+		ctx.anchorWriter = anchorWriter
+
+		tracker := NewAnchorTracker(20 * time.Millisecond)
+
+		writer, err := New(namespace, ctx,
+			WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond),
+			WithAnchorTracker(tracker),
+		)
+		require.NoError(t, err)
+
+		writer.Start()
+		defer writer.Stop()
+
+		require.NoError(t, writer.Add(generateOperations(1)[0], 0))
+
+		require.Eventually(t, func() bool { return anchorWriter.Attempts() == 1 }, time.Second, 10*time.Millisecond)
+
+		anchorWriter.setFail(func([]*operation.Reference) error { return errors.New("injected anchor error") })
+
+		require.Eventually(t, func() bool {
+			pending, err := tracker.PendingAnchors()
+
+			return err == nil && len(pending) == 1 && pending[0].Attempts >= 3
+		}, time.Second, 10*time.Millisecond)
+
+		// The anchor is still tracked after failed attempts.
+		require.Len(t, anchorWriter.GetAnchors(), 1)
+	})
+
+	t.Run("tracker store error", func(t *testing.T) {
+		ctx := newMockContext()
+
+		tracker := NewAnchorTracker(time.Millisecond,
+			WithPendingAnchorStore(&mockPendingAnchorStore{err: errors.New("injected store error")}))
+
+		writer, err := New(namespace, ctx,
+			WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond),
+			WithAnchorTracker(tracker),
+		)
+		require.NoError(t, err)
+
+		writer.Start()
+		defer writer.Stop()
+
+		require.NoError(t, writer.Add(generateOperations(1)[0], 0))
+
+		// The batch is not retried if the anchor can't be tracked.
+		require.Eventually(t, func() bool { return len(ctx.AnchorWriter.GetAnchors()) == 1 },
+			time.Second, 10*time.Millisecond)
+
+		time.Sleep(50 * time.Millisecond)
+
+		require.Len(t, ctx.AnchorWriter.GetAnchors(), 1)
+	})
+}
+
+func getOperationStatus(t *testing.T, tracker *opstatus.Tracker, op *operation.QueuedOperation) *opstatus.OperationStatus {
+	t.Helper()
+
+	hash, err := opstatus.GetOperationHash(op.OperationRequest)
+	require.NoError(t, err)
+
+	status, err := tracker.Get(hash)
+	require.NoError(t, err)
+
+	return status
+}
+
+func TestExpiredOperations(t *testing.T) {
+	newContext := func() *mockContext {
+		ctx := newMockContext()
+
+		// The last operation in each batch is reported as expired.
+		opHandler := ctx.ProtocolClient.CurrentVersion.OperationHandler()
+
+		fakeOpHandler := &mocks.OperationHandler{}
+		fakeOpHandler.PrepareTxnFilesCalls(func(ops []*operation.QueuedOperation) (*protocol.AnchoringInfo, error) {
+			info, err := opHandler.PrepareTxnFiles(ops[:len(ops)-1])
+			if err != nil {
+				return nil, err
+			}
+
+			info.ExpiredOperations = ops[len(ops)-1:]
+
+			return info, nil
+		})
+
+		ctx.ProtocolClient.CurrentVersion.OperationHandlerReturns(fakeOpHandler)
+
+		return ctx
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx := newContext()
+
+		expiredOpHandler := &mockExpiredOperationHandler{}
+		unpublishedOpStore := &mockUnpublishedOpsStore{}
+		cacheInvalidator := &mockResolutionCacheInvalidator{}
+		tracker := opstatus.New(opstatus.NewMemStore())
+
+		writer, err := New(namespace, ctx,
+			WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond),
+			WithExpiredOperationHandler(expiredOpHandler), WithUnpublishedOperationStore(unpublishedOpStore),
+			WithOperationStatusUpdater(tracker), WithResolutionCacheInvalidator(cacheInvalidator),
+		)
+		require.NoError(t, err)
+
+		writer.Start()
+		defer writer.Stop()
+
+		ops := generateOperations(2)
+
+		for _, op := range ops {
+			require.NoError(t, writer.Add(op, 0))
+		}
+
+		time.Sleep(100 * time.Millisecond)
+
+		require.Len(t, ctx.AnchorWriter.GetAnchors(), 1)
+
+		expiredOps := expiredOpHandler.Get()
+		require.Len(t, expiredOps, 1)
+		require.Equal(t, ops[1], expiredOps[0])
+
+		deletedOps := unpublishedOpStore.Get()
+		require.Len(t, deletedOps, 1)
+		require.Equal(t, ops[1].UniqueSuffix, deletedOps[0].UniqueSuffix)
+		require.Equal(t, operation.TypeCreate, deletedOps[0].Type)
+		require.Equal(t, ops[1].OperationRequest, deletedOps[0].OperationRequest)
+
+		require.Equal(t, []string{ops[1].UniqueSuffix}, cacheInvalidator.Get())
+
+		require.Equal(t, opstatus.StatusAnchored, getOperationStatus(t, tracker, ops[0]).Status)
+
+		status := getOperationStatus(t, tracker, ops[1])
+		require.Equal(t, opstatus.StatusFailed, status.Status)
+		require.Equal(t, "operation expired", status.Reason)
+	})
+
+	t.Run("unpublished operation store error", func(t *testing.T) {
+		ctx := newContext()
+
+		expiredOpHandler := &mockExpiredOperationHandler{}
+
+		writer, err := New(namespace, ctx,
+			WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond),
+			WithExpiredOperationHandler(expiredOpHandler),
+			WithUnpublishedOperationStore(&mockUnpublishedOpsStore{err: errors.New("injected delete error")}),
+		)
+		require.NoError(t, err)
+
+		writer.Start()
+		defer writer.Stop()
+
+		for _, op := range generateOperations(2) {
+			require.NoError(t, writer.Add(op, 0))
+		}
+
+		time.Sleep(100 * time.Millisecond)
+
+		require.Len(t, expiredOpHandler.Get(), 1)
+	})
+
+	t.Run("default handler", func(t *testing.T) {
+		ctx := newContext()
+
+		writer, err := New(namespace, ctx, WithBatchTimeout(10*time.Millisecond), WithMonitorInterval(10*time.Millisecond))
+		require.NoError(t, err)
+
+		writer.Start()
+		defer writer.Stop()
+
+		for _, op := range generateOperations(2) {
+			require.NoError(t, writer.Add(op, 0))
+		}
+
+		time.Sleep(100 * time.Millisecond)
+
+		require.Len(t, ctx.AnchorWriter.GetAnchors(), 1)
+	})
+}
+
+func TestGetOperationType(t *testing.T) {
+	require.Equal(t, operation.TypeUpdate, getOperationType([]byte(`{"type":"update"}`)))
+	require.Empty(t, getOperationType([]byte("invalid")))
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	require.Zero(t, RetryPolicy{}.backoff(3))
+
+	p := RetryPolicy{InitialBackoff: time.Second}
+	require.Equal(t, time.Second, p.backoff(1))
+	require.Equal(t, 2*time.Second, p.backoff(2))
+	require.Equal(t, 4*time.Second, p.backoff(3))
+
+	p = RetryPolicy{InitialBackoff: time.Second, BackoffFactor: 3, MaxBackoff: 5 * time.Second}
+	require.Equal(t, time.Second, p.backoff(1))
+	require.Equal(t, 3*time.Second, p.backoff(2))
+	require.Equal(t, 5*time.Second, p.backoff(3))
+	require.Equal(t, 5*time.Second, p.backoff(10))
+}
+
 // withError allows for testing an error in options.
 func withError() Option {
 	return func(o *Options) error {
@@ -447,6 +1165,20 @@ func withError() Option {
 	}
 }
 
+// getUniqueSuffix returns the unique suffix that is computed from the given create operation (as opposed to the
+// unique suffix that the operation was generated with).
+func getUniqueSuffix(t *testing.T, op *operation.QueuedOperation) string {
+	t.Helper()
+
+	req := &model.CreateRequest{}
+	require.NoError(t, json.Unmarshal(op.OperationRequest, req))
+
+	suffix, err := model.GetUniqueSuffix(req.SuffixData, []uint{sha2_256})
+	require.NoError(t, err)
+
+	return suffix
+}
+
 func generateOperations(numOfOperations int) (ops []*operation.QueuedOperation) {
 	for j := 1; j <= numOfOperations; j++ {
 		op, err := generateOperation(j)
@@ -522,11 +1254,22 @@ func generateOperation(num int) (*operation.QueuedOperation, error) {
 	return op, nil
 }
 
+// generateInvalidOperation returns an operation that can't be parsed.
+func generateInvalidOperation(num int) *operation.QueuedOperation {
+	return &operation.QueuedOperation{
+		Namespace:        "did:sidetree",
+		UniqueSuffix:     fmt.Sprint(num),
+		OperationRequest: []byte(fmt.Sprintf(`{"type":"invalid","didSuffix":"%d"}`, num)),
+	}
+}
+
 // mockContext implements mock batch writer context.
 type mockContext struct {
 	ProtocolClient *mocks.MockProtocolClient
 	AnchorWriter   *mocks.MockAnchorWriter
 	OpQueue        cutter.OperationQueue
+
+	anchorWriter AnchorWriter
 }
 
 // newMockContext returns a new mockContext object.
@@ -545,6 +1288,10 @@ func (m *mockContext) Protocol() protocol.Client {
 
 // Anchor returns the block chain client.
 func (m *mockContext) Anchor() AnchorWriter {
+	if m.anchorWriter != nil {
+		return m.anchorWriter
+	}
+
 	return m.AnchorWriter
 }
 
@@ -576,3 +1323,130 @@ func newMockProtocolClient() *mocks.MockProtocolClient {
 
 	return pc
 }
+
+type mockFailingAnchorWriter struct {
+	*mocks.MockAnchorWriter
+
+	mutex    sync.Mutex
+	attempts int
+	fail     func(refs []*operation.Reference) error
+}
+
+func newMockFailingAnchorWriter(fail func(refs []*operation.Reference) error) *mockFailingAnchorWriter {
+	return &mockFailingAnchorWriter{
+		MockAnchorWriter: mocks.NewMockAnchorWriter(nil),
+		fail:             fail,
+	}
+}
+
+func (m *mockFailingAnchorWriter) WriteAnchor(anchor string, artifacts []*protocol.AnchorDocument,
+	refs []*operation.Reference, protocolVersion uint64) error {
+	m.mutex.Lock()
+	m.attempts++
+	fail := m.fail
+	m.mutex.Unlock()
+
+	if err := fail(refs); err != nil {
+		return err
+	}
+
+	return m.MockAnchorWriter.WriteAnchor(anchor, artifacts, refs, protocolVersion)
+}
+
+func (m *mockFailingAnchorWriter) setFail(fail func(refs []*operation.Reference) error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.fail = fail
+}
+
+func (m *mockFailingAnchorWriter) Attempts() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.attempts
+}
+
+type mockDeadLetterSink struct {
+	mutex sync.Mutex
+	ops   []*operation.QueuedOperation
+	errs  []error
+	err   error
+}
+
+func (m *mockDeadLetterSink) Put(op *operation.QueuedOperation, _ uint64, cause error) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.ops = append(m.ops, op)
+	m.errs = append(m.errs, cause)
+
+	return m.err
+}
+
+func (m *mockDeadLetterSink) Get() ([]*operation.QueuedOperation, []error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.ops, m.errs
+}
+
+type mockExpiredOperationHandler struct {
+	mutex sync.Mutex
+	ops   []*operation.QueuedOperation
+}
+
+func (m *mockExpiredOperationHandler) OperationExpired(op *operation.QueuedOperation, _ uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.ops = append(m.ops, op)
+}
+
+func (m *mockExpiredOperationHandler) Get() []*operation.QueuedOperation {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.ops
+}
+
+type mockUnpublishedOpsStore struct {
+	mutex sync.Mutex
+	ops   []*operation.AnchoredOperation
+	err   error
+}
+
+func (m *mockUnpublishedOpsStore) Delete(op *operation.AnchoredOperation) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.ops = append(m.ops, op)
+
+	return m.err
+}
+
+func (m *mockUnpublishedOpsStore) Get() []*operation.AnchoredOperation {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.ops
+}
+
+type mockResolutionCacheInvalidator struct {
+	mutex    sync.Mutex
+	suffixes []string
+}
+
+func (m *mockResolutionCacheInvalidator) Invalidate(uniqueSuffixes ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.suffixes = append(m.suffixes, uniqueSuffixes...)
+}
+
+func (m *mockResolutionCacheInvalidator) Get() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.suffixes
+}
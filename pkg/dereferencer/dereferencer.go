@@ -0,0 +1,314 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package dereferencer dereferences DID URLs (see https://www.w3.org/TR/did-core/#did-url-dereferencing).
+//
+// The DID is resolved using the configured resolver and the resource identified by the DID URL is
+// selected from the resolved DID document:
+//
+//	did:sidetree:abc                                -> DID document
+//	did:sidetree:abc#key-1                          -> verification method (or service) with ID 'key-1'
+//	did:sidetree:abc?service=hub                    -> service endpoint URL(s) of service 'hub'
+//	did:sidetree:abc?service=hub&relativeRef=/foo   -> service endpoint URL resolved against '/foo'
+//
+// The 'versionId' and 'versionTime' DID parameters are passed to the resolver.
+package dereferencer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+)
+
+var logger = log.New("sidetree-core-dereferencer")
+
+const (
+	// ServiceParam is the DID parameter that selects a service from the DID document.
+	ServiceParam = "service"
+	// RelativeRefParam is the DID parameter that holds a relative URI reference that is resolved
+	// against the selected service endpoint.
+	RelativeRefParam = "relativeRef"
+	// VersionIDParam is the DID parameter that selects a specific version of the DID document.
+	VersionIDParam = "versionId"
+	// VersionTimeParam is the DID parameter that selects the version of the DID document at the given time.
+	VersionTimeParam = "versionTime"
+
+	// ContentTypeDIDLDJSON is the content type of a DID document or a resource within a DID document.
+	ContentTypeDIDLDJSON = "application/did+ld+json"
+	// ContentTypeURIList is the content type of service endpoint URLs.
+	ContentTypeURIList = "text/uri-list"
+
+	didResolutionContext = "https://w3id.org/did-resolution/v1"
+)
+
+var (
+	// ErrInvalidDIDURL is returned if the DID URL is not valid.
+	ErrInvalidDIDURL = errors.New("invalid DID URL")
+
+	// ErrNotFound is returned if the resource identified by the DID URL is not found in the DID document.
+	ErrNotFound = errors.New("not found")
+)
+
+// Resolver resolves DID documents.
+type Resolver interface {
+	ResolveDocument(idOrDocument string, opts ...document.ResolutionOption) (*document.ResolutionResult, error)
+}
+
+// Dereferencer dereferences DID URLs.
+type Dereferencer struct {
+	resolver Resolver
+}
+
+// New returns a new DID URL dereferencer.
+func New(resolver Resolver) *Dereferencer {
+	return &Dereferencer{resolver: resolver}
+}
+
+// DIDURL holds the components of a DID URL.
+type DIDURL struct {
+	DID      string
+	Path     string
+	Query    url.Values
+	Fragment string
+}
+
+// ParseDIDURL parses the given DID URL. The DID is the portion of the DID URL up to the first
+// path ('/'), query ('?') or fragment ('#') delimiter.
+func ParseDIDURL(didURL string) (*DIDURL, error) {
+	result := &DIDURL{}
+
+	remaining := didURL
+
+	if i := strings.Index(remaining, "#"); i >= 0 {
+		fragment, err := url.PathUnescape(remaining[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid fragment: %s", ErrInvalidDIDURL, err.Error())
+		}
+
+		result.Fragment = fragment
+		remaining = remaining[:i]
+	}
+
+	if i := strings.Index(remaining, "?"); i >= 0 {
+		query, err := url.ParseQuery(remaining[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid query: %s", ErrInvalidDIDURL, err.Error())
+		}
+
+		result.Query = query
+		remaining = remaining[:i]
+	}
+
+	if i := strings.Index(remaining, "/"); i >= 0 {
+		result.Path = remaining[i:]
+		remaining = remaining[:i]
+	}
+
+	if !strings.HasPrefix(remaining, "did:") || strings.Count(remaining, ":") < 2 || strings.HasSuffix(remaining, ":") {
+		return nil, fmt.Errorf("%w: [%s] does not contain a valid DID", ErrInvalidDIDURL, didURL)
+	}
+
+	result.DID = remaining
+
+	return result, nil
+}
+
+// Dereference dereferences the given DID URL. The content stream of the result is the DID document, a verification
+// method or service (for a fragment), or the service endpoint URL(s) (for the 'service' DID parameter).
+func (d *Dereferencer) Dereference(didURL string) (*document.DereferencingResult, error) {
+	parsedURL, err := ParseDIDURL(didURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsedURL.Path != "" {
+		return nil, fmt.Errorf("%w: DID URL paths are not supported", ErrInvalidDIDURL)
+	}
+
+	if parsedURL.Query.Get(VersionIDParam) != "" && parsedURL.Query.Get(VersionTimeParam) != "" {
+		return nil, fmt.Errorf("%w: cannot specify both '%s' and '%s'", ErrInvalidDIDURL, VersionIDParam, VersionTimeParam)
+	}
+
+	resolutionResult, err := d.resolver.ResolveDocument(parsedURL.DID, getResolutionOptions(parsedURL.Query)...)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug("Dereferencing DID URL", log.WithID(didURL))
+
+	serviceID := parsedURL.Query.Get(ServiceParam)
+	if serviceID != "" {
+		return dereferenceService(resolutionResult, serviceID, parsedURL.Query.Get(RelativeRefParam), parsedURL.Fragment)
+	}
+
+	if parsedURL.Query.Get(RelativeRefParam) != "" {
+		return nil, fmt.Errorf("%w: '%s' requires the '%s' parameter", ErrInvalidDIDURL, RelativeRefParam, ServiceParam)
+	}
+
+	if parsedURL.Fragment != "" {
+		return dereferenceFragment(resolutionResult, parsedURL.Fragment)
+	}
+
+	return newResult(resolutionResult.Document, ContentTypeDIDLDJSON, resolutionResult.DocumentMetadata), nil
+}
+
+func dereferenceFragment(resolutionResult *document.ResolutionResult,
+	fragment string) (*document.DereferencingResult, error) {
+	doc, err := toDIDDocument(resolutionResult.Document)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vm := range doc.VerificationMethods() {
+		if matchesFragment(vm.ID(), fragment) {
+			return newResult(vm.JSONLdObject(), ContentTypeDIDLDJSON, resolutionResult.DocumentMetadata), nil
+		}
+	}
+
+	for _, svc := range doc.Services() {
+		if matchesFragment(svc.ID(), fragment) {
+			return newResult(svc.JSONLdObject(), ContentTypeDIDLDJSON, resolutionResult.DocumentMetadata), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: fragment [%s] not found in DID document", ErrNotFound, fragment)
+}
+
+// dereferenceService selects the given service and constructs the service endpoint URL(s) as described in
+// https://www.w3.org/TR/did-core/#example-a-resource-external-to-a-did-document.
+func dereferenceService(resolutionResult *document.ResolutionResult, serviceID, relativeRef,
+	fragment string) (*document.DereferencingResult, error) {
+	doc, err := toDIDDocument(resolutionResult.Document)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, svc := range doc.Services() {
+		if !matchesFragment(svc.ID(), serviceID) {
+			continue
+		}
+
+		endpoints := getEndpointURLs(svc.ServiceEndpoint())
+		if len(endpoints) == 0 {
+			return nil, fmt.Errorf("%w: service [%s] does not have a URL service endpoint", ErrNotFound, serviceID)
+		}
+
+		urls := make([]string, len(endpoints))
+
+		for i, endpoint := range endpoints {
+			u, err := getServiceURL(endpoint, relativeRef, fragment)
+			if err != nil {
+				return nil, err
+			}
+
+			urls[i] = u
+		}
+
+		var contentStream interface{} = urls
+		if len(urls) == 1 {
+			contentStream = urls[0]
+		}
+
+		return newResult(contentStream, ContentTypeURIList, resolutionResult.DocumentMetadata), nil
+	}
+
+	return nil, fmt.Errorf("%w: service [%s] not found in DID document", ErrNotFound, serviceID)
+}
+
+// toDIDDocument converts the resolved document to a DID document. The resolved document may contain typed
+// verification methods and services (e.g. []document.PublicKey) so the document is marshalled and
+// unmarshalled in order to access these values generically.
+func toDIDDocument(doc document.Document) (document.DIDDocument, error) {
+	docBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal resolved document: %w", err)
+	}
+
+	return document.DidDocumentFromBytes(docBytes)
+}
+
+func getServiceURL(endpoint, relativeRef, fragment string) (string, error) {
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid service endpoint [%s]: %s", ErrNotFound, endpoint, err.Error())
+	}
+
+	if relativeRef != "" {
+		ref, err := url.Parse(relativeRef)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid %s [%s]: %s", ErrInvalidDIDURL, RelativeRefParam, relativeRef, err.Error())
+		}
+
+		base = base.ResolveReference(ref)
+	}
+
+	if fragment != "" {
+		base.Fragment = fragment
+	}
+
+	return base.String(), nil
+}
+
+// getEndpointURLs returns the URLs of the given service endpoint which may be a URL or a set of URLs.
+// Endpoints defined as maps are not supported.
+func getEndpointURLs(endpoint interface{}) []string {
+	switch e := endpoint.(type) {
+	case string:
+		return []string{e}
+	case []string:
+		return e
+	case []interface{}:
+		var urls []string
+
+		for _, v := range e {
+			if s, ok := v.(string); ok {
+				urls = append(urls, s)
+			}
+		}
+
+		return urls
+	default:
+		return nil
+	}
+}
+
+// matchesFragment returns true if the given ID (which may be absolute or relative) has the given fragment.
+func matchesFragment(id, fragment string) bool {
+	i := strings.LastIndex(id, "#")
+	if i < 0 {
+		return id == fragment
+	}
+
+	return id[i+1:] == fragment
+}
+
+func getResolutionOptions(query url.Values) []document.ResolutionOption {
+	var opts []document.ResolutionOption
+
+	if versionID := query.Get(VersionIDParam); versionID != "" {
+		opts = append(opts, document.WithVersionID(versionID))
+	}
+
+	if versionTime := query.Get(VersionTimeParam); versionTime != "" {
+		opts = append(opts, document.WithVersionTime(versionTime))
+	}
+
+	return opts
+}
+
+func newResult(content interface{}, contentType string, metadata document.Metadata) *document.DereferencingResult {
+	return &document.DereferencingResult{
+		Context:               didResolutionContext,
+		ContentStream:         content,
+		ContentMetadata:       metadata,
+		DereferencingMetadata: document.Metadata{document.ContentTypeProperty: contentType},
+	}
+}
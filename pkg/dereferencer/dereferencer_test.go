@@ -0,0 +1,189 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dereferencer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+const did = "did:sidetree:abc"
+
+func TestParseDIDURL(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		u, err := ParseDIDURL(did + "/path?service=hub&relativeRef=%2Ffoo#frag%201")
+		require.NoError(t, err)
+		require.Equal(t, did, u.DID)
+		require.Equal(t, "/path", u.Path)
+		require.Equal(t, "hub", u.Query.Get(ServiceParam))
+		require.Equal(t, "/foo", u.Query.Get(RelativeRefParam))
+		require.Equal(t, "frag 1", u.Fragment)
+	})
+
+	t.Run("DID only", func(t *testing.T) {
+		u, err := ParseDIDURL(did)
+		require.NoError(t, err)
+		require.Equal(t, did, u.DID)
+		require.Empty(t, u.Path)
+		require.Empty(t, u.Query)
+		require.Empty(t, u.Fragment)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		for _, didURL := range []string{"", "abc#key-1", "did:sidetree", "did:sidetree:", "did:sidetree:abc#%zz", "did:sidetree:abc?a=%zz"} {
+			u, err := ParseDIDURL(didURL)
+			require.Error(t, err, didURL)
+			require.True(t, errors.Is(err, ErrInvalidDIDURL), didURL)
+			require.Nil(t, u)
+		}
+	})
+}
+
+func TestDereferencer_Dereference(t *testing.T) {
+	resolver := &mockResolver{result: &document.ResolutionResult{
+		Document: document.Document{
+			"id": did,
+			"verificationMethod": []interface{}{
+				map[string]interface{}{"id": "#key-1", "type": "JsonWebKey2020", "controller": did},
+			},
+			"service": []document.Service{
+				document.NewService(map[string]interface{}{
+					"id": did + "#hub", "type": "IdentityHub", "serviceEndpoint": "https://hub.example.com/base/",
+				}),
+				document.NewService(map[string]interface{}{
+					"id": "#multi", "type": "Multi", "serviceEndpoint": []interface{}{"https://a.example.com", "https://b.example.com"},
+				}),
+				document.NewService(map[string]interface{}{
+					"id": "#map", "type": "Map", "serviceEndpoint": map[string]interface{}{"origins": []string{"https://a.example.com"}},
+				}),
+			},
+		},
+		DocumentMetadata: document.Metadata{document.DeactivatedProperty: false},
+	}}
+
+	d := New(resolver)
+
+	t.Run("DID document", func(t *testing.T) {
+		result, err := d.Dereference(did)
+		require.NoError(t, err)
+		require.Equal(t, resolver.result.Document, result.ContentStream)
+		require.Equal(t, ContentTypeDIDLDJSON, result.DereferencingMetadata[document.ContentTypeProperty])
+		require.Equal(t, resolver.result.DocumentMetadata, result.ContentMetadata)
+	})
+
+	t.Run("verification method", func(t *testing.T) {
+		result, err := d.Dereference(did + "#key-1")
+		require.NoError(t, err)
+
+		vm, ok := result.ContentStream.(map[string]interface{})
+		require.True(t, ok)
+		require.Equal(t, "#key-1", vm["id"])
+		require.Equal(t, ContentTypeDIDLDJSON, result.DereferencingMetadata[document.ContentTypeProperty])
+	})
+
+	t.Run("service fragment", func(t *testing.T) {
+		result, err := d.Dereference(did + "#hub")
+		require.NoError(t, err)
+
+		svc, ok := result.ContentStream.(map[string]interface{})
+		require.True(t, ok)
+		require.Equal(t, "IdentityHub", svc["type"])
+	})
+
+	t.Run("service", func(t *testing.T) {
+		result, err := d.Dereference(did + "?service=hub")
+		require.NoError(t, err)
+		require.Equal(t, "https://hub.example.com/base/", result.ContentStream)
+		require.Equal(t, ContentTypeURIList, result.DereferencingMetadata[document.ContentTypeProperty])
+	})
+
+	t.Run("service with relative reference and fragment", func(t *testing.T) {
+		result, err := d.Dereference(did + "?service=hub&relativeRef=%2Fpath%2Fto%3Fq%3D1#frag")
+		require.NoError(t, err)
+		require.Equal(t, "https://hub.example.com/path/to?q=1#frag", result.ContentStream)
+
+		result, err = d.Dereference(did + "?service=hub&relativeRef=resource")
+		require.NoError(t, err)
+		require.Equal(t, "https://hub.example.com/base/resource", result.ContentStream)
+	})
+
+	t.Run("service with multiple endpoints", func(t *testing.T) {
+		result, err := d.Dereference(did + "?service=multi&relativeRef=%2Ffoo")
+		require.NoError(t, err)
+		require.Equal(t, []string{"https://a.example.com/foo", "https://b.example.com/foo"}, result.ContentStream)
+	})
+
+	t.Run("version parameters", func(t *testing.T) {
+		_, err := d.Dereference(did + "?versionId=v1")
+		require.NoError(t, err)
+		require.Len(t, resolver.opts, 1)
+
+		_, err = d.Dereference(did + "?versionTime=2021-05-10T17:00:00Z")
+		require.NoError(t, err)
+		require.Len(t, resolver.opts, 1)
+
+		_, err = d.Dereference(did)
+		require.NoError(t, err)
+		require.Empty(t, resolver.opts)
+	})
+
+	t.Run("invalid DID URL", func(t *testing.T) {
+		for _, didURL := range []string{
+			"abc",
+			did + "/path",
+			did + "?versionId=v1&versionTime=2021-05-10T17:00:00Z",
+			did + "?relativeRef=%2Ffoo",
+			did + "?service=hub&relativeRef=%25zz:",
+		} {
+			result, err := d.Dereference(didURL)
+			require.Error(t, err, didURL)
+			require.True(t, errors.Is(err, ErrInvalidDIDURL), didURL)
+			require.Nil(t, result)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		for _, didURL := range []string{
+			did + "#key-2",
+			did + "?service=other",
+			did + "?service=map",
+		} {
+			result, err := d.Dereference(didURL)
+			require.Error(t, err, didURL)
+			require.True(t, errors.Is(err, ErrNotFound), didURL)
+			require.Nil(t, result)
+		}
+	})
+
+	t.Run("resolver error", func(t *testing.T) {
+		errExpected := errors.New("injected resolver error")
+
+		result, err := New(&mockResolver{err: errExpected}).Dereference(did + "#key-1")
+		require.True(t, errors.Is(err, errExpected))
+		require.Nil(t, result)
+	})
+}
+
+type mockResolver struct {
+	result *document.ResolutionResult
+	err    error
+	opts   []document.ResolutionOption
+}
+
+func (m *mockResolver) ResolveDocument(_ string, opts ...document.ResolutionOption) (*document.ResolutionResult, error) {
+	m.opts = opts
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return m.result, nil
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/opstatus"
 )
 
 var logger = log.New("sidetree-core-dochandler")
@@ -54,6 +55,9 @@ type DocumentHandler struct {
 	unpublishedOperationStore unpublishedOperationStore
 	unpublishedOperationTypes []operation.Type
 
+	statusUpdater    operationStatusUpdater
+	cacheInvalidator resolutionCacheInvalidator
+
 	metrics metricsProvider
 }
 
@@ -64,6 +68,16 @@ type unpublishedOperationStore interface {
 	Delete(op *operation.AnchoredOperation) error
 }
 
+// operationStatusUpdater is an interface to update the status of an operation.
+type operationStatusUpdater interface {
+	Update(request []byte, suffix string, status opstatus.Status, opts ...opstatus.UpdateOption)
+}
+
+// resolutionCacheInvalidator is an interface to invalidate cached resolution models.
+type resolutionCacheInvalidator interface {
+	Invalidate(uniqueSuffixes ...string)
+}
+
 // operationDecorator is an interface for validating/pre-processing operations.
 type operationDecorator interface {
 	Decorate(operation *operation.Operation) (*operation.Operation, error)
@@ -111,6 +125,22 @@ func WithOperationDecorator(decorator operationDecorator) Option {
 	}
 }
 
+// WithOperationStatusUpdater sets an optional operation status updater which is notified
+// when an operation is added to the batch.
+func WithOperationStatusUpdater(updater operationStatusUpdater) Option {
+	return func(opts *DocumentHandler) {
+		opts.statusUpdater = updater
+	}
+}
+
+// WithResolutionCacheInvalidator sets an optional resolution cache invalidator which is invoked when
+// an operation is added to (or removed from) the unpublished operation store.
+func WithResolutionCacheInvalidator(invalidator resolutionCacheInvalidator) Option {
+	return func(opts *DocumentHandler) {
+		opts.cacheInvalidator = invalidator
+	}
+}
+
 type metricsProvider interface {
 	ProcessOperation(duration time.Duration)
 	GetProtocolVersionTime(since time.Duration)
@@ -135,6 +165,8 @@ func New(namespace string, aliases []string, pc protocol.Client, writer batchWri
 		metrics:                   metrics,
 		unpublishedOperationStore: &noopUnpublishedOpsStore{},
 		unpublishedOperationTypes: []operation.Type{},
+		statusUpdater:             &noopOperationStatusUpdater{},
+		cacheInvalidator:          &noopResolutionCacheInvalidator{},
 	}
 
 	// apply options
@@ -208,12 +240,17 @@ func (r *DocumentHandler) ProcessOperation(operationBuffer []byte, protocolVersi
 
 	addToBatchStartTime := time.Now()
 
+	r.statusUpdater.Update(op.OperationRequest, op.UniqueSuffix, opstatus.StatusQueued, opstatus.WithType(op.Type))
+
 	// validated operation will be added to the batch
 	if err := r.addToBatch(op, pv.Protocol().GenesisTime); err != nil {
 		logger.Error("Failed to add operation to batch", log.WithError(err))
 
 		r.deleteOperationFromUnpublishedOpsStore(unpublishedOp)
 
+		r.statusUpdater.Update(op.OperationRequest, op.UniqueSuffix, opstatus.StatusFailed,
+			opstatus.WithType(op.Type), opstatus.WithReason(err.Error()))
+
 		return nil, err
 	}
 
@@ -250,6 +287,8 @@ func (r *DocumentHandler) addOperationToUnpublishedOpsStore(unpublishedOp *opera
 		return nil
 	}
 
+	defer r.cacheInvalidator.Invalidate(unpublishedOp.UniqueSuffix)
+
 	return r.unpublishedOperationStore.Put(unpublishedOp)
 }
 
@@ -259,6 +298,8 @@ func (r *DocumentHandler) deleteOperationFromUnpublishedOpsStore(unpublishedOp *
 		return
 	}
 
+	defer r.cacheInvalidator.Invalidate(unpublishedOp.UniqueSuffix)
+
 	err := r.unpublishedOperationStore.Delete(unpublishedOp)
 	if err != nil {
 		logger.Warn("Failed to delete operation from unpublished store", log.WithError(err))
@@ -372,6 +413,10 @@ func GetTransformationInfoForUnpublished(namespace, domain, label, suffix, creat
 // are subject to the same validation as during processing create operation.
 func (r *DocumentHandler) ResolveDocument(shortOrLongFormDID string,
 	opts ...document.ResolutionOption) (*document.ResolutionResult, error) {
+	if strings.ContainsAny(shortOrLongFormDID, "/?#") {
+		return nil, fmt.Errorf("%s: DID URLs are not supported by resolution (use a DID URL dereferencer)", badRequest)
+	}
+
 	ns, err := r.getNamespace(shortOrLongFormDID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %s", badRequest, err.Error())
@@ -542,6 +587,7 @@ func (r *DocumentHandler) addToBatch(op *operation.Operation, versionTime uint64
 			UniqueSuffix:     op.UniqueSuffix,
 			OperationRequest: op.OperationRequest,
 			AnchorOrigin:     op.AnchorOrigin,
+			Type:             op.Type,
 		}, versionTime)
 }
 
@@ -596,6 +642,16 @@ func (noop *noopUnpublishedOpsStore) Delete(_ *operation.AnchoredOperation) erro
 	return nil
 }
 
+type noopOperationStatusUpdater struct{}
+
+func (noop *noopOperationStatusUpdater) Update([]byte, string, opstatus.Status, ...opstatus.UpdateOption) {
+}
+
+type noopResolutionCacheInvalidator struct{}
+
+func (noop *noopResolutionCacheInvalidator) Invalidate(...string) {
+}
+
 type defaultOperationDecorator struct {
 	processor operationProcessor
 }
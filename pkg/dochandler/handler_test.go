@@ -33,6 +33,7 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/hashing"
 	"github.com/trustbloc/sidetree-core-go/pkg/jws"
 	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/opstatus"
 	"github.com/trustbloc/sidetree-core-go/pkg/patch"
 	"github.com/trustbloc/sidetree-core-go/pkg/processor"
 	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
@@ -41,6 +42,7 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/doccomposer"
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/doctransformer/didtransformer"
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/doctransformer/doctransformer"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/doctransformer/metadata"
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/model"
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationapplier"
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationparser"
@@ -94,6 +96,55 @@ func TestDocumentHandler_ProcessOperation_Create(t *testing.T) {
 	require.NotNil(t, doc)
 }
 
+func TestDocumentHandler_ProcessOperation_Status(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		tracker := opstatus.New(opstatus.NewMemStore())
+
+		dochandler, cleanup := getDocumentHandler(mocks.NewMockOperationStore(nil), WithOperationStatusUpdater(tracker))
+		require.NotNil(t, dochandler)
+		defer cleanup()
+
+		createOp := getCreateOperation()
+
+		doc, err := dochandler.ProcessOperation(createOp.OperationRequest, 0)
+		require.NoError(t, err)
+		require.NotNil(t, doc)
+
+		hash, err := opstatus.GetOperationHash(createOp.OperationRequest)
+		require.NoError(t, err)
+
+		status, err := tracker.Get(hash)
+		require.NoError(t, err)
+		require.Equal(t, createOp.UniqueSuffix, status.UniqueSuffix)
+		require.Equal(t, operation.TypeCreate, status.Type)
+		require.NotEqual(t, opstatus.StatusFailed, status.Status)
+	})
+
+	t.Run("error - add to batch", func(t *testing.T) {
+		tracker := opstatus.New(opstatus.NewMemStore())
+
+		writer := &mockBatchWriter{Err: errors.New("batch error")}
+
+		pc := newMockProtocolClient()
+		dochandler := New(namespace, []string{alias}, pc, writer, processor.New("test", mocks.NewMockOperationStore(nil), pc),
+			&mocks.MetricsProvider{}, WithOperationStatusUpdater(tracker))
+
+		createOp := getCreateOperation()
+
+		doc, err := dochandler.ProcessOperation(createOp.OperationRequest, 0)
+		require.EqualError(t, err, writer.Err.Error())
+		require.Nil(t, doc)
+
+		hash, err := opstatus.GetOperationHash(createOp.OperationRequest)
+		require.NoError(t, err)
+
+		status, err := tracker.Get(hash)
+		require.NoError(t, err)
+		require.Equal(t, opstatus.StatusFailed, status.Status)
+		require.Equal(t, writer.Err.Error(), status.Reason)
+	})
+}
+
 func TestDocumentHandler_DefaultDecorator(t *testing.T) {
 	t.Run("success - create", func(t *testing.T) {
 		processor := processor.New("test", mocks.NewMockOperationStore(nil), newMockProtocolClient())
@@ -203,7 +254,9 @@ func TestDocumentHandler_ProcessOperation_Update(t *testing.T) {
 
 		opt := WithUnpublishedOperationStore(&mockUnpublishedOpsStore{}, []operation.Type{operation.TypeUpdate})
 
-		dochandler, cleanup := getDocumentHandler(store, opt)
+		cacheInvalidator := &mockResolutionCacheInvalidator{}
+
+		dochandler, cleanup := getDocumentHandler(store, opt, WithResolutionCacheInvalidator(cacheInvalidator))
 		require.NotNil(t, dochandler)
 		defer cleanup()
 
@@ -221,6 +274,7 @@ func TestDocumentHandler_ProcessOperation_Update(t *testing.T) {
 		doc, err := dochandler.ProcessOperation(updateOp, 0)
 		require.NoError(t, err)
 		require.Nil(t, doc)
+		require.Equal(t, []string{createOp.UniqueSuffix}, cacheInvalidator.suffixes)
 	})
 
 	t.Run("success - unpublished operation store option(create and update)", func(t *testing.T) {
@@ -499,6 +553,20 @@ func TestDocumentHandler_ResolveDocument_DID(t *testing.T) {
 	require.True(t, ok)
 
 	require.Equal(t, true, methodMetadata[document.PublishedProperty])
+	require.Nil(t, methodMetadata[document.TraceProperty])
+
+	// scenario: resolved document with trace (success)
+	result, err = dochandler.ResolveDocument(docID, document.WithTrace())
+	require.NoError(t, err)
+
+	traceMethodMetadata, ok := result.DocumentMetadata[document.MethodProperty].(document.Metadata)
+	require.True(t, ok)
+
+	trace, ok := traceMethodMetadata[document.TraceProperty].([]*metadata.OperationTrace)
+	require.True(t, ok)
+	require.Len(t, trace, 1)
+	require.Equal(t, operation.TypeCreate, trace[0].Type)
+	require.Equal(t, metadata.VerdictApplied, trace[0].Verdict)
 
 	// scenario: resolve document with alias namespace (success)
 	aliasID := alias + ":" + uniqueSuffix
@@ -520,6 +588,15 @@ func TestDocumentHandler_ResolveDocument_DID(t *testing.T) {
 	require.Error(t, err)
 	require.Nil(t, result)
 	require.Contains(t, err.Error(), "did suffix is empty")
+
+	// scenario: DID URL
+	for _, didURL := range []string{docID + "#key-1", docID + "?service=hub", docID + "/path"} {
+		result, err = dochandler.ResolveDocument(didURL)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "bad request")
+		require.Contains(t, err.Error(), "DID URLs are not supported by resolution")
+	}
 }
 
 func TestDocumentHandler_ResolveDocument_DID_With_References(t *testing.T) {
@@ -1167,6 +1244,14 @@ func (m *mockUnpublishedOpsStore) Get(uniqueSuffix string) ([]*operation.Anchore
 	return m.Ops, nil
 }
 
+type mockResolutionCacheInvalidator struct {
+	suffixes []string
+}
+
+func (m *mockResolutionCacheInvalidator) Invalidate(uniqueSuffixes ...string) {
+	m.suffixes = append(m.suffixes, uniqueSuffixes...)
+}
+
 type mockOperationDecorator struct {
 	Err error
 }
@@ -1186,3 +1271,66 @@ type mockBatchWriter struct {
 func (mbw *mockBatchWriter) Add(_ *operation.QueuedOperation, _ uint64) error {
 	return mbw.Err
 }
+
+func TestDocumentHandler_ResolveDocument_Cache(t *testing.T) {
+	store := mocks.NewMockOperationStore(nil)
+	pc := newMockProtocolClient()
+
+	ctx := &BatchContext{
+		ProtocolClient: pc,
+		CasClient:      mocks.NewMockCasClient(nil),
+		AnchorWriter:   mocks.NewMockAnchorWriter(nil),
+		OpQueue:        &opqueue.MemQueue{},
+	}
+
+	writer, err := batch.New("test", ctx)
+	require.NoError(t, err)
+
+	p := processor.New("test", store, pc, processor.WithResolutionCache(processor.NewResolutionCache(10)))
+
+	dochandler := New(namespace, []string{alias}, pc, writer, p, &mocks.MetricsProvider{})
+
+	createOp := getCreateOperation()
+
+	require.NoError(t, store.Put(getAnchoredCreateOperation()))
+
+	createReq, err := canonicalizer.MarshalCanonical(model.CreateRequest{
+		Delta:      createOp.Delta,
+		SuffixData: createOp.SuffixData,
+	})
+	require.NoError(t, err)
+
+	shortFormID := createOp.ID
+	longFormID := createOp.ID + ":" + encoder.EncodeToString(createReq)
+
+	const numResolutions = 20
+
+	// Resolve the same suffix concurrently (using the short and long form IDs) so that the cached resolution
+	// model is shared. Since the document is published, each result must contain the short form ID.
+	errCh := make(chan error, 2*numResolutions)
+
+	for i := 0; i < numResolutions; i++ {
+		for _, id := range []string{shortFormID, longFormID} {
+			go func(id string) {
+				result, e := dochandler.ResolveDocument(id)
+				if e != nil {
+					errCh <- e
+
+					return
+				}
+
+				if result.Document.ID() != shortFormID {
+					errCh <- fmt.Errorf("expecting ID [%s] but got [%s]", shortFormID, result.Document.ID())
+
+					return
+				}
+
+				errCh <- nil
+			}(id)
+		}
+	}
+
+	for i := 0; i < 2*numResolutions; i++ {
+		require.NoError(t, <-errCh)
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/processor"
+)
+
+// operationHistoryProvider is implemented by operation processors that are able to return
+// the operation history of a document.
+type operationHistoryProvider interface {
+	History(uniqueSuffix string) ([]*processor.OperationOutcome, error)
+}
+
+// GetOperationHistory returns a page of the operation history of the given DID. The history contains every
+// published and unpublished operation of the DID (in the order in which they are applied) along with the outcome
+// of applying the operation. Offset is the index of the first operation to return and limit is the maximum number
+// of operations to return (zero means no limit).
+func (r *DocumentHandler) GetOperationHistory(shortOrLongFormDID string, offset, limit int) (*document.OperationHistory, error) {
+	if offset < 0 || limit < 0 {
+		return nil, fmt.Errorf("%s: offset and limit must not be negative", badRequest)
+	}
+
+	historyProvider, ok := r.processor.(operationHistoryProvider)
+	if !ok {
+		return nil, errors.New("operation history is not supported by the operation processor")
+	}
+
+	ns, err := r.getNamespace(shortOrLongFormDID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", badRequest, err.Error())
+	}
+
+	pv, err := r.protocol.Current()
+	if err != nil {
+		return nil, err
+	}
+
+	shortFormDID, _, err := pv.OperationParser().ParseDID(ns, shortOrLongFormDID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", badRequest, err.Error())
+	}
+
+	uniquePortion, err := getSuffix(ns, shortFormDID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", badRequest, err.Error())
+	}
+
+	outcomes, err := historyProvider.History(uniquePortion)
+	if err != nil {
+		logger.Debug("Failed to retrieve operation history", log.WithSuffix(uniquePortion), log.WithError(err))
+
+		return nil, err
+	}
+
+	history := &document.OperationHistory{
+		ID:         shortFormDID,
+		Total:      len(outcomes),
+		Offset:     offset,
+		Operations: []*document.OperationHistoryEntry{},
+	}
+
+	if offset >= len(outcomes) {
+		return history, nil
+	}
+
+	end := len(outcomes)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+
+	for _, outcome := range outcomes[offset:end] {
+		history.Operations = append(history.Operations, newOperationHistoryEntry(outcome))
+	}
+
+	return history, nil
+}
+
+func newOperationHistoryEntry(outcome *processor.OperationOutcome) *document.OperationHistoryEntry {
+	op := outcome.Operation
+
+	return &document.OperationHistoryEntry{
+		Type:               op.Type,
+		Published:          outcome.Published,
+		TransactionTime:    op.TransactionTime,
+		TransactionNumber:  op.TransactionNumber,
+		ProtocolVersion:    op.ProtocolVersion,
+		CanonicalReference: op.CanonicalReference,
+		Patches:            getPatches(op.OperationRequest),
+		Applied:            outcome.Applied,
+		Reason:             outcome.Reason,
+	}
+}
+
+// getPatches returns the patches of the delta in the given operation request. Nil is returned if the operation
+// request doesn't have a delta.
+func getPatches(operationRequest []byte) []map[string]interface{} {
+	request := &struct {
+		Delta *struct {
+			Patches []map[string]interface{} `json:"patches"`
+		} `json:"delta"`
+	}{}
+
+	if err := json.Unmarshal(operationRequest, request); err != nil {
+		logger.Debug("Unable to decode patches from operation request", log.WithError(err))
+
+		return nil
+	}
+
+	if request.Delta == nil {
+		return nil
+	}
+
+	return request.Delta.Patches
+}
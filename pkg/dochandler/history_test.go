@@ -0,0 +1,132 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	docmocks "github.com/trustbloc/sidetree-core-go/pkg/dochandler/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+)
+
+func TestDocumentHandler_GetOperationHistory(t *testing.T) {
+	store := mocks.NewMockOperationStore(nil)
+	dochandler, cleanup := getDocumentHandler(store)
+	defer cleanup()
+
+	docID := getCreateOperation().ID
+	uniqueSuffix := getCreateOperation().UniqueSuffix
+
+	createOp := getAnchoredCreateOperation()
+	createOp.TransactionTime = 1
+	createOp.CanonicalReference = "ref-1"
+	require.NoError(t, store.Put(createOp))
+
+	updateRequest, err := generateUpdateOperation(uniqueSuffix)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(&operation.AnchoredOperation{
+		Type:               operation.TypeUpdate,
+		UniqueSuffix:       uniqueSuffix,
+		OperationRequest:   updateRequest,
+		TransactionTime:    2,
+		TransactionNumber:  1,
+		CanonicalReference: "ref-2",
+	}))
+
+	t.Run("success", func(t *testing.T) {
+		history, err := dochandler.GetOperationHistory(docID, 0, 0)
+		require.NoError(t, err)
+		require.Equal(t, docID, history.ID)
+		require.Equal(t, 2, history.Total)
+		require.Equal(t, 0, history.Offset)
+		require.Len(t, history.Operations, 2)
+
+		create := history.Operations[0]
+		require.Equal(t, operation.TypeCreate, create.Type)
+		require.True(t, create.Published)
+		require.True(t, create.Applied)
+		require.Empty(t, create.Reason)
+		require.Equal(t, "ref-1", create.CanonicalReference)
+		require.Equal(t, uint64(1), create.TransactionTime)
+		require.NotEmpty(t, create.Patches)
+
+		// The update operation is signed with a key that doesn't match the update commitment.
+		update := history.Operations[1]
+		require.Equal(t, operation.TypeUpdate, update.Type)
+		require.True(t, update.Published)
+		require.False(t, update.Applied)
+		require.NotEmpty(t, update.Reason)
+		require.Equal(t, uint64(2), update.TransactionTime)
+		require.Equal(t, uint64(1), update.TransactionNumber)
+		require.Len(t, update.Patches, 1)
+		require.Equal(t, "ietf-json-patch", update.Patches[0]["action"])
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		history, err := dochandler.GetOperationHistory(docID, 1, 1)
+		require.NoError(t, err)
+		require.Equal(t, 2, history.Total)
+		require.Equal(t, 1, history.Offset)
+		require.Len(t, history.Operations, 1)
+		require.Equal(t, operation.TypeUpdate, history.Operations[0].Type)
+
+		history, err = dochandler.GetOperationHistory(docID, 0, 1)
+		require.NoError(t, err)
+		require.Len(t, history.Operations, 1)
+		require.Equal(t, operation.TypeCreate, history.Operations[0].Type)
+
+		history, err = dochandler.GetOperationHistory(docID, 5, 10)
+		require.NoError(t, err)
+		require.Equal(t, 2, history.Total)
+		require.Empty(t, history.Operations)
+	})
+
+	t.Run("alias", func(t *testing.T) {
+		history, err := dochandler.GetOperationHistory(alias+":"+uniqueSuffix, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, history.Operations, 2)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		history, err := dochandler.GetOperationHistory(namespace+":someid", 0, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+		require.Nil(t, history)
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		history, err := dochandler.GetOperationHistory(docID, -1, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad request")
+		require.Nil(t, history)
+
+		history, err = dochandler.GetOperationHistory("doc:invalid", 0, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad request")
+		require.Nil(t, history)
+
+		history, err = dochandler.GetOperationHistory(namespace+":", 0, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad request")
+		require.Nil(t, history)
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		pc := newMockProtocolClient()
+
+		handler := New(namespace, nil, pc, &mockBatchWriter{}, &docmocks.OperationProcessor{}, &mocks.MetricsProvider{})
+
+		history, err := handler.GetOperationHistory(docID, 0, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "operation history is not supported")
+		require.Nil(t, history)
+	})
+}
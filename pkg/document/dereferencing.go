@@ -0,0 +1,15 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package document
+
+// DereferencingResult describes the result of dereferencing a DID URL.
+type DereferencingResult struct {
+	Context               interface{} `json:"@context"`
+	ContentStream         interface{} `json:"contentStream"`
+	ContentMetadata       Metadata    `json:"contentMetadata,omitempty"`
+	DereferencingMetadata Metadata    `json:"dereferencingMetadata,omitempty"`
+}
@@ -0,0 +1,54 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package document
+
+import "github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+
+// OperationHistory holds a page of the operation history of a DID.
+type OperationHistory struct {
+	// ID is the DID.
+	ID string `json:"id"`
+
+	// Total is the total number of operations in the history.
+	Total int `json:"total"`
+
+	// Offset is the index of the first operation of this page within the history.
+	Offset int `json:"offset"`
+
+	// Operations holds the operations of this page.
+	Operations []*OperationHistoryEntry `json:"operations"`
+}
+
+// OperationHistoryEntry holds a decoded operation along with the outcome of applying the operation.
+type OperationHistoryEntry struct {
+	// Type defines operation type.
+	Type operation.Type `json:"type"`
+
+	// Published is true if the operation has been anchored.
+	Published bool `json:"published"`
+
+	// TransactionTime is the logical anchoring time (or the time of the request for unpublished operations).
+	TransactionTime uint64 `json:"transactionTime"`
+
+	// TransactionNumber is the transaction number of the transaction this operation was batched within.
+	TransactionNumber uint64 `json:"transactionNumber"`
+
+	// ProtocolVersion is the genesis time of the protocol that was used for this operation.
+	ProtocolVersion uint64 `json:"protocolVersion"`
+
+	// CanonicalReference contains canonical reference that applies to this operation.
+	CanonicalReference string `json:"canonicalReference,omitempty"`
+
+	// Patches holds the patches of the operation delta (if any).
+	Patches []map[string]interface{} `json:"patches,omitempty"`
+
+	// Applied is true if the operation was applied to the document.
+	Applied bool `json:"applied"`
+
+	// Reason holds the reason why the operation was not applied.
+	Reason string `json:"reason,omitempty"`
+}
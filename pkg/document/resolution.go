@@ -10,9 +10,10 @@ import "github.com/trustbloc/sidetree-core-go/pkg/api/operation"
 
 // ResolutionResult describes resolution result.
 type ResolutionResult struct {
-	Context          interface{} `json:"@context"`
-	Document         Document    `json:"didDocument"`
-	DocumentMetadata Metadata    `json:"didDocumentMetadata,omitempty"`
+	Context            interface{} `json:"@context"`
+	Document           Document    `json:"didDocument"`
+	DocumentMetadata   Metadata    `json:"didDocumentMetadata,omitempty"`
+	ResolutionMetadata Metadata    `json:"didResolutionMetadata,omitempty"`
 }
 
 // Metadata can contains various metadata such as document metadata and method metadata..
@@ -57,6 +58,42 @@ const (
 
 	// PublishedOperationsProperty holds published did operations.
 	PublishedOperationsProperty = "publishedOperations"
+
+	// ContentTypeProperty is the media type of the returned document (resolution metadata).
+	ContentTypeProperty = "contentType"
+
+	// ErrorProperty is the resolution error code (resolution metadata).
+	ErrorProperty = "error"
+
+	// ErrorMessageProperty is the human-readable resolution error message (resolution metadata).
+	ErrorMessageProperty = "errorMessage"
+
+	// TraceProperty holds the outcome of each operation considered during resolution (method metadata).
+	TraceProperty = "trace"
+)
+
+// DID resolution error codes as defined in the DID Resolution specification.
+const (
+	// InvalidDIDError indicates that the supplied DID is not a conformant DID.
+	InvalidDIDError = "invalidDid"
+
+	// InvalidDIDURLError indicates that the supplied DID URL is not a conformant DID URL.
+	InvalidDIDURLError = "invalidDidUrl"
+
+	// NotFoundError indicates that the DID doesn't exist.
+	NotFoundError = "notFound"
+
+	// MethodNotSupportedError indicates that the DID method (or namespace) is not supported by the resolver.
+	MethodNotSupportedError = "methodNotSupported"
+
+	// RepresentationNotSupportedError indicates that the requested representation is not supported.
+	RepresentationNotSupportedError = "representationNotSupported"
+
+	// InvalidOptionsError indicates that the supplied resolution options are invalid.
+	InvalidOptionsError = "invalidOptions"
+
+	// InternalError indicates that an unexpected error occurred during resolution.
+	InternalError = "internalError"
 )
 
 // ResolutionOption is an option for specifying the resolution options for various resolvers.
@@ -67,6 +104,7 @@ type ResolutionOptions struct {
 	AdditionalOperations []*operation.AnchoredOperation
 	VersionID            string
 	VersionTime          string
+	Trace                bool
 }
 
 // WithAdditionalOperations sets the additional operations to be used in a Resolve call.
@@ -92,6 +130,14 @@ func WithVersionTime(versionTime string) ResolutionOption {
 	}
 }
 
+// WithTrace requests a trace of the resolution, i.e. the outcome (applied or rejected, along with the reason)
+// of each operation that was considered during a Resolve call.
+func WithTrace() ResolutionOption {
+	return func(opts *ResolutionOptions) {
+		opts.Trace = true
+	}
+}
+
 // GetResolutionOptions returns resolution options.
 func GetResolutionOptions(opts ...ResolutionOption) (ResolutionOptions, error) {
 	options := ResolutionOptions{}
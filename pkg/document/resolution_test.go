@@ -19,9 +19,10 @@ func TestGetOptions(t *testing.T) {
 	const verID = "ver"
 
 	opts, err := GetResolutionOptions(WithAdditionalOperations([]*operation.AnchoredOperation{{Type: "create"}}),
-		WithVersionID(verID), WithVersionTime(verTime))
+		WithVersionID(verID), WithVersionTime(verTime), WithTrace())
 	require.NoError(t, err)
 	require.Equal(t, 1, len(opts.AdditionalOperations))
 	require.Equal(t, verID, opts.VersionID)
 	require.Equal(t, verTime, opts.VersionTime)
+	require.True(t, opts.Trace)
 }
@@ -0,0 +1,72 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ReplaceFile replaces the content of the file at the given path. The content is written to a temporary file
+// which is synced and then renamed over the file, so the file always contains either the previous or the
+// new content.
+func ReplaceFile(path string, b []byte) error {
+	tmpPath := path + ".tmp"
+
+	if err := writeFile(tmpPath, b); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("rename temporary file: %w", err)
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+func writeFile(path string, b []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("open temporary file: %w", err)
+	}
+
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close() //nolint:errcheck
+
+		return fmt.Errorf("write temporary file: %w", err)
+	}
+
+	if err := f.Sync(); err != nil {
+		_ = f.Close() //nolint:errcheck
+
+		return fmt.Errorf("sync temporary file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close temporary file: %w", err)
+	}
+
+	return nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("open directory: %w", err)
+	}
+
+	defer func() {
+		_ = d.Close() //nolint:errcheck
+	}()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("sync directory: %w", err)
+	}
+
+	return nil
+}
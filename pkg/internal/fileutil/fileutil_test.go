@@ -0,0 +1,53 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceFile(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "file.json")
+
+		require.NoError(t, ReplaceFile(path, []byte("1")))
+		require.NoError(t, ReplaceFile(path, []byte("2")))
+
+		b, err := os.ReadFile(path) //nolint:gosec
+		require.NoError(t, err)
+		require.Equal(t, "2", string(b))
+
+		_, err = os.Stat(path + ".tmp")
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("open error", func(t *testing.T) {
+		dir := t.TempDir()
+
+		// A directory in place of the temporary file results in an open error.
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "file.json.tmp"), 0o700))
+
+		err := ReplaceFile(filepath.Join(dir, "file.json"), []byte("1"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open temporary file")
+	})
+
+	t.Run("rename error", func(t *testing.T) {
+		dir := t.TempDir()
+
+		// A non-empty directory in place of the file results in a rename error.
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "file.json", "dir"), 0o700))
+
+		err := ReplaceFile(filepath.Join(dir, "file.json"), []byte("1"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "rename temporary file")
+	})
+}
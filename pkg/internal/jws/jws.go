@@ -130,9 +130,15 @@ func sign(joseHeaders jws.Headers, payload []byte, signer Signer) ([]byte, error
 	return signature, nil
 }
 
+// SignatureVerifier verifies a signature that was created with the given JWS algorithm.
+type SignatureVerifier interface {
+	Verify(alg string, jwk *jws.JWK, signature, msg []byte) error
+}
+
 // jwsParseOpts holds options for the JWS Parsing.
 type jwsParseOpts struct {
 	detachedPayload []byte
+	verifier        SignatureVerifier
 }
 
 // ParseOpt is the JWS Parser option.
@@ -145,6 +151,14 @@ func WithJWSDetachedPayload(payload []byte) ParseOpt {
 	}
 }
 
+// WithSignatureVerifier option sets the verifier used by VerifyJWS. The verifier is given the algorithm from
+// the protected headers. If this option isn't set then the signature is verified according to the key type.
+func WithSignatureVerifier(verifier SignatureVerifier) ParseOpt {
+	return func(opts *jwsParseOpts) {
+		opts.verifier = verifier
+	}
+}
+
 // ParseJWS parses serialized JWS. Currently only JWS Compact Serialization parsing is supported.
 func ParseJWS(jwsStr string, opts ...ParseOpt) (*JSONWebSignature, error) {
 	pOpts := &jwsParseOpts{}
@@ -174,7 +188,20 @@ func VerifyJWS(jwsStr string, jwk *jws.JWK, opts ...ParseOpt) (*JSONWebSignature
 		return nil, fmt.Errorf("build signing input: %w", err)
 	}
 
-	err = VerifySignature(jwk, parsedJWS.signature, sInput)
+	pOpts := &jwsParseOpts{}
+
+	for _, opt := range opts {
+		opt(pOpts)
+	}
+
+	if pOpts.verifier != nil {
+		alg, _ := parsedJWS.ProtectedHeaders.Algorithm()
+
+		err = pOpts.verifier.Verify(alg, jwk, parsedJWS.signature, sInput)
+	} else {
+		err = VerifySignature(jwk, parsedJWS.signature, sInput)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -253,6 +253,49 @@ func TestParseJWS_ED25519(t *testing.T) {
 	require.Equal(t, jws, parsedJWS)
 }
 
+func TestVerifyJWS_WithSignatureVerifier(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwk, err := getPublicKeyJWK(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	signer := ecsigner.New(privateKey, "ES256", "key-1")
+	jws, err := NewJWS(signer.Headers(), nil, []byte("payload"), signer)
+	require.NoError(t, err)
+
+	jwsCompact, err := jws.SerializeCompact(false)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		verifier := &mockSignatureVerifier{}
+
+		parsedJWS, err := VerifyJWS(jwsCompact, jwk, WithSignatureVerifier(verifier))
+		require.NoError(t, err)
+		require.Equal(t, jws, parsedJWS)
+		require.Equal(t, "ES256", verifier.alg)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		errExpected := errors.New("injected verifier error")
+
+		parsedJWS, err := VerifyJWS(jwsCompact, jwk, WithSignatureVerifier(&mockSignatureVerifier{err: errExpected}))
+		require.True(t, errors.Is(err, errExpected))
+		require.Nil(t, parsedJWS)
+	})
+}
+
+type mockSignatureVerifier struct {
+	alg string
+	err error
+}
+
+func (m *mockSignatureVerifier) Verify(alg string, _ *jws.JWK, _, _ []byte) error {
+	m.alg = alg
+
+	return m.err
+}
+
 func TestIsCompactJWS(t *testing.T) {
 	require.True(t, IsCompactJWS("a.b.c"))
 	require.False(t, IsCompactJWS("a.b"))
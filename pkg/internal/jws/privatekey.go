@@ -0,0 +1,54 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jws
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/square/go-jose/v3"
+)
+
+// MarshalPrivateKey marshals the given private key (*ecdsa.PrivateKey or ed25519.PrivateKey) to JWK format.
+func MarshalPrivateKey(privateKey crypto.PrivateKey) ([]byte, error) {
+	jwk := &JWK{JSONWebKey: jose.JSONWebKey{Key: privateKey}}
+
+	switch key := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		if key.Curve == btcec.S256() {
+			jwk.Kty = secp256k1Kty
+			jwk.Crv = secp256k1Crv
+		}
+	case ed25519.PrivateKey:
+	default:
+		return nil, fmt.Errorf("unsupported private key type: %T", privateKey)
+	}
+
+	return jwk.MarshalJSON()
+}
+
+// UnmarshalPrivateKey unmarshals a private key (*ecdsa.PrivateKey or ed25519.PrivateKey) from JWK format.
+func UnmarshalPrivateKey(jwkBytes []byte) (crypto.PrivateKey, error) {
+	jwk := &JWK{}
+
+	err := jwk.UnmarshalJSON(jwkBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := jwk.Key.(type) {
+	case *ecdsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("JWK doesn't contain a private key: %T", jwk.Key)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jws
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalPrivateKey(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		_, edKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		secp256k1Key, err := ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+		require.NoError(t, err)
+
+		for _, privateKey := range []crypto.PrivateKey{edKey, p256Key, secp256k1Key} {
+			jwkBytes, err := MarshalPrivateKey(privateKey)
+			require.NoError(t, err)
+
+			key, err := UnmarshalPrivateKey(jwkBytes)
+			require.NoError(t, err)
+			require.Equal(t, privateKey, key)
+		}
+	})
+
+	t.Run("error - unsupported key type", func(t *testing.T) {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+
+		jwkBytes, err := MarshalPrivateKey(rsaKey)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported private key type")
+		require.Nil(t, jwkBytes)
+	})
+
+	t.Run("error - public key", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		jwkBytes, err := (&JWK{JSONWebKey: jose.JSONWebKey{Key: &privateKey.PublicKey}}).MarshalJSON()
+		require.NoError(t, err)
+
+		key, err := UnmarshalPrivateKey(jwkBytes)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "JWK doesn't contain a private key")
+		require.Nil(t, key)
+	})
+
+	t.Run("error - invalid JWK", func(t *testing.T) {
+		key, err := UnmarshalPrivateKey([]byte("{"))
+		require.Error(t, err)
+		require.Nil(t, key)
+	})
+}
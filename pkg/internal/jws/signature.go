@@ -86,26 +86,11 @@ func verifyECSignature(jwk *jws.JWK, signature, msg []byte) error {
 		return fmt.Errorf("ecdsa: unsupported elliptic curve '%s'", jwk.Crv)
 	}
 
-	jwkBytes, err := json.Marshal(jwk)
-	if err != nil {
-		return err
-	}
-
-	internalJWK := JWK{
-		Kty: jwk.Kty,
-		Crv: jwk.Crv,
-	}
-
-	err = internalJWK.UnmarshalJSON(jwkBytes)
+	ecdsaPubKey, err := GetECDSAPublicKey(jwk)
 	if err != nil {
 		return err
 	}
 
-	ecdsaPubKey, ok := internalJWK.JSONWebKey.Key.(*ecdsa.PublicKey)
-	if !ok {
-		return errors.New("not an EC public key")
-	}
-
 	if len(signature) != 2*ec.keySize {
 		return errors.New("ecdsa: invalid signature size")
 	}
@@ -130,6 +115,31 @@ func verifyECSignature(jwk *jws.JWK, signature, msg []byte) error {
 	return nil
 }
 
+// GetECDSAPublicKey returns the EC public key of the given JWK.
+func GetECDSAPublicKey(jwk *jws.JWK) (*ecdsa.PublicKey, error) {
+	jwkBytes, err := json.Marshal(jwk)
+	if err != nil {
+		return nil, err
+	}
+
+	internalJWK := JWK{
+		Kty: jwk.Kty,
+		Crv: jwk.Crv,
+	}
+
+	err = internalJWK.UnmarshalJSON(jwkBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	ecdsaPubKey, ok := internalJWK.JSONWebKey.Key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an EC public key")
+	}
+
+	return ecdsaPubKey, nil
+}
+
 type ellipticCurve struct {
 	curve   elliptic.Curve
 	keySize int
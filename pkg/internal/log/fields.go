@@ -9,6 +9,7 @@ package log
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -61,6 +62,10 @@ const (
 	FieldContent                   = "content"
 	FieldSources                   = "sources"
 	FieldAlias                     = "alias"
+	FieldPath                      = "path"
+	FieldAttempt                   = "attempt"
+	FieldBackoff                   = "backoff"
+	FieldStatus                    = "status"
 )
 
 // WithError sets the error field.
@@ -293,6 +298,26 @@ func WithAlias(value string) zap.Field {
 	return zap.String(FieldAlias, value)
 }
 
+// WithPath sets the path field.
+func WithPath(value string) zap.Field {
+	return zap.String(FieldPath, value)
+}
+
+// WithAttempt sets the attempt field.
+func WithAttempt(value int) zap.Field {
+	return zap.Int(FieldAttempt, value)
+}
+
+// WithBackoff sets the backoff field.
+func WithBackoff(value time.Duration) zap.Field {
+	return zap.Duration(FieldBackoff, value)
+}
+
+// WithStatus sets the status field.
+func WithStatus(value string) zap.Field {
+	return zap.String(FieldStatus, value)
+}
+
 type jsonMarshaller struct {
 	key string
 	obj interface{}
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -111,7 +112,8 @@ func TestStandardFields(t *testing.T) {
 			WithTotalUpdateOperations(87), WithTotalRecoverOperations(12), WithTotalDeactivateOperations(3),
 			WithDocument(map[string]interface{}{"field1": 1234}), WithDeactivated(true), WithOperations([]*mockObject{op}),
 			WithVersionTime("12"), WithPatch(patch), WithIsBatch(true), WithContent([]byte("content1")),
-			WithSources("source1", "source2"), WithAlias("alias1"),
+			WithSources("source1", "source2"), WithAlias("alias1"), WithPath("/tmp/path1"),
+			WithAttempt(3), WithBackoff(2*time.Second), WithStatus("queued"),
 		)
 
 		l := unmarshalLogData(t, stdOut.Bytes())
@@ -145,6 +147,10 @@ func TestStandardFields(t *testing.T) {
 		require.Equal(t, "content1", l.Content)
 		require.Equal(t, []string{"source1", "source2"}, l.Sources)
 		require.Equal(t, "alias1", l.Alias)
+		require.Equal(t, "/tmp/path1", l.Path)
+		require.Equal(t, 3, l.Attempt)
+		require.Equal(t, "2s", l.Backoff)
+		require.Equal(t, "queued", l.Status)
 	})
 }
 
@@ -206,6 +212,10 @@ type logData struct {
 	Content                   string        `json:"content"`
 	Sources                   []string      `json:"sources"`
 	Alias                     string        `json:"alias"`
+	Path                      string        `json:"path"`
+	Attempt                   int           `json:"attempt"`
+	Backoff                   string        `json:"backoff"`
+	Status                    string        `json:"status"`
 }
 
 func unmarshalLogData(t *testing.T, b []byte) *logData {
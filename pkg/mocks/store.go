@@ -65,3 +65,35 @@ func (m *MockOperationStore) Get(uniqueSuffix string) ([]*operation.AnchoredOper
 
 	return nil, errors.New("uniqueSuffix not found in the store")
 }
+
+// DeleteByTransaction mocks deleting the operations that were anchored in the given transaction.
+func (m *MockOperationStore) DeleteByTransaction(txnTime, txnNumber uint64) ([]*operation.AnchoredOperation, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var deleted []*operation.AnchoredOperation
+
+	for uniqueSuffix, ops := range m.operations {
+		var remaining []*operation.AnchoredOperation
+
+		for _, op := range ops {
+			if op.TransactionTime == txnTime && op.TransactionNumber == txnNumber {
+				deleted = append(deleted, op)
+			} else {
+				remaining = append(remaining, op)
+			}
+		}
+
+		if len(remaining) == 0 {
+			delete(m.operations, uniqueSuffix)
+		} else {
+			m.operations[uniqueSuffix] = remaining
+		}
+	}
+
+	return deleted, nil
+}
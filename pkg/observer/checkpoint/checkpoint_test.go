@@ -0,0 +1,109 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+)
+
+func TestMemStore(t *testing.T) {
+	s := NewMemStore()
+
+	_, err := s.Get()
+	require.ErrorIs(t, err, observer.ErrCheckpointNotFound)
+
+	checkpoint := &observer.Checkpoint{TransactionTime: 10, TransactionNumber: 2}
+	require.NoError(t, s.Put(checkpoint))
+
+	checkpoint.TransactionNumber = 3
+
+	c, err := s.Get()
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), c.TransactionTime)
+	require.Equal(t, uint64(2), c.TransactionNumber)
+}
+
+func TestFileStore(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "observer", "checkpoint.json")
+
+		s, err := NewFileStore(path)
+		require.NoError(t, err)
+
+		_, err = s.Get()
+		require.ErrorIs(t, err, observer.ErrCheckpointNotFound)
+
+		require.NoError(t, s.Put(&observer.Checkpoint{TransactionTime: 10, TransactionNumber: 2}))
+		require.NoError(t, s.Put(&observer.Checkpoint{TransactionTime: 11, TransactionNumber: 3}))
+
+		// Re-open the store.
+		s, err = NewFileStore(path)
+		require.NoError(t, err)
+
+		c, err := s.Get()
+		require.NoError(t, err)
+		require.Equal(t, uint64(11), c.TransactionTime)
+		require.Equal(t, uint64(3), c.TransactionNumber)
+
+		_, err = os.Stat(path + ".tmp")
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+		s, err := NewFileStore(path)
+		require.NoError(t, err)
+
+		_, err = s.Get()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal checkpoint file")
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		dir := t.TempDir()
+
+		// The checkpoint path is a directory.
+		s, err := NewFileStore(dir)
+		require.NoError(t, err)
+
+		_, err = s.Get()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "read checkpoint file")
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s, err := NewFileStore(filepath.Join(dir, "checkpoint.json"))
+		require.NoError(t, err)
+
+		// A directory in place of the temporary file results in a write error.
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "checkpoint.json.tmp"), 0o700))
+
+		err = s.Put(&observer.Checkpoint{TransactionTime: 10})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "write checkpoint file")
+	})
+
+	t.Run("create directory error", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+		_, err := NewFileStore(filepath.Join(file, "dir", "checkpoint.json"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create checkpoint directory")
+	})
+}
@@ -0,0 +1,78 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/fileutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+)
+
+// FileStore is an observer checkpoint store that persists the checkpoint to a JSON file.
+//
+// The checkpoint is written to a temporary file which is synced and then renamed over the checkpoint file,
+// so the checkpoint file always contains either the previous or the new checkpoint.
+type FileStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileStore returns a new checkpoint store that persists the checkpoint to the given file. The parent
+// directory is created if it doesn't exist.
+func NewFileStore(path string) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create checkpoint directory: %w", err)
+	}
+
+	return &FileStore{path: path}, nil
+}
+
+// Get returns the last saved checkpoint.
+func (s *FileStore) Get() (*observer.Checkpoint, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, observer.ErrCheckpointNotFound
+		}
+
+		return nil, fmt.Errorf("read checkpoint file [%s]: %w", s.path, err)
+	}
+
+	checkpoint := &observer.Checkpoint{}
+
+	if err := json.Unmarshal(b, checkpoint); err != nil {
+		return nil, fmt.Errorf("unmarshal checkpoint file [%s]: %w", s.path, err)
+	}
+
+	return checkpoint, nil
+}
+
+// Put saves the checkpoint.
+func (s *FileStore) Put(checkpoint *observer.Checkpoint) error {
+	b, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := fileutil.ReplaceFile(s.path, b); err != nil {
+		return fmt.Errorf("write checkpoint file [%s]: %w", s.path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package checkpoint
+
+import (
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+)
+
+// MemStore is an in-memory observer checkpoint store.
+type MemStore struct {
+	mutex      sync.RWMutex
+	checkpoint *observer.Checkpoint
+}
+
+// NewMemStore returns a new in-memory checkpoint store.
+func NewMemStore() *MemStore {
+	return &MemStore{}
+}
+
+// Get returns the last saved checkpoint.
+func (s *MemStore) Get() (*observer.Checkpoint, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.checkpoint == nil {
+		return nil, observer.ErrCheckpointNotFound
+	}
+
+	checkpoint := *s.checkpoint
+
+	return &checkpoint, nil
+}
+
+// Put saves the checkpoint.
+func (s *MemStore) Put(checkpoint *observer.Checkpoint) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	checkpointCopy := *checkpoint
+	s.checkpoint = &checkpointCopy
+
+	return nil
+}
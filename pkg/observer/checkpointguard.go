@@ -0,0 +1,121 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+)
+
+// checkpointGuard is a checkpoint store that never persists a checkpoint past a transaction which is in the
+// failed transaction store. It is used when failed transactions are held in memory: a failed transaction that
+// is before the persisted checkpoint would be lost on a restart, whereas a failed transaction after the
+// checkpoint is simply processed again.
+type checkpointGuard struct {
+	CheckpointStore
+
+	failedTxns FailedTxnStore
+	mutex      sync.Mutex
+	latest     *Checkpoint // latest checkpoint saved by the observer
+}
+
+func newCheckpointGuard(store CheckpointStore, failedTxns FailedTxnStore) *checkpointGuard {
+	return &checkpointGuard{
+		CheckpointStore: store,
+		failedTxns:      failedTxns,
+	}
+}
+
+// Put saves the given checkpoint or, if there are failed transactions up to the given checkpoint, the
+// checkpoint just before the earliest of those transactions.
+func (g *checkpointGuard) Put(checkpoint *Checkpoint) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.latest = checkpoint
+
+	return g.persist()
+}
+
+// failedTxnDeleted is invoked after a failed transaction was deleted so that the persisted checkpoint may be
+// advanced.
+func (g *checkpointGuard) failedTxnDeleted() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.persist()
+}
+
+func (g *checkpointGuard) persist() error {
+	if g.latest == nil {
+		return nil
+	}
+
+	failedTxns, err := g.failedTxns.GetAll()
+	if err != nil {
+		return fmt.Errorf("get failed transactions: %w", err)
+	}
+
+	checkpoint := g.latest
+
+	for _, failedTxn := range failedTxns {
+		if checkpoint.before(&failedTxn.Txn) {
+			continue
+		}
+
+		if failedTxn.Txn.TransactionTime == 0 && failedTxn.Txn.TransactionNumber == 0 {
+			// No checkpoint is before the zero transaction.
+			return nil
+		}
+
+		checkpoint = checkpointBefore(&failedTxn.Txn)
+	}
+
+	return g.CheckpointStore.Put(checkpoint)
+}
+
+// guardedFailedTxnStore notifies the checkpoint guard whenever a failed transaction is deleted.
+type guardedFailedTxnStore struct {
+	FailedTxnStore
+
+	guard *checkpointGuard
+}
+
+func (s *guardedFailedTxnStore) Delete(id string) error {
+	if err := s.FailedTxnStore.Delete(id); err != nil {
+		return err
+	}
+
+	if err := s.guard.failedTxnDeleted(); err != nil {
+		logger.Warn("Failed to save checkpoint", log.WithError(err))
+	}
+
+	return nil
+}
+
+// checkpointBefore returns the latest checkpoint that is before the given transaction (or the zero checkpoint
+// if the given transaction is the zero transaction).
+func checkpointBefore(sidetreeTxn *txn.SidetreeTxn) *Checkpoint {
+	switch {
+	case sidetreeTxn.TransactionNumber > 0:
+		return &Checkpoint{
+			TransactionTime:   sidetreeTxn.TransactionTime,
+			TransactionNumber: sidetreeTxn.TransactionNumber - 1,
+		}
+	case sidetreeTxn.TransactionTime > 0:
+		return &Checkpoint{
+			TransactionTime:   sidetreeTxn.TransactionTime - 1,
+			TransactionNumber: math.MaxUint64,
+		}
+	default:
+		return &Checkpoint{}
+	}
+}
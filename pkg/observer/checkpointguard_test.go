@@ -0,0 +1,85 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+)
+
+func TestCheckpointGuard(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		store := &mockCheckpointStore{}
+		failedTxns := newMemFailedTxnStore()
+
+		g := newCheckpointGuard(store, failedTxns)
+		failedTxnStore := &guardedFailedTxnStore{FailedTxnStore: failedTxns, guard: g}
+
+		failedTxn1 := &FailedTxn{Txn: txn.SidetreeTxn{TransactionTime: 10, TransactionNumber: 0}}
+		failedTxn2 := &FailedTxn{Txn: txn.SidetreeTxn{TransactionTime: 12, TransactionNumber: 3}}
+
+		require.NoError(t, failedTxnStore.Put(failedTxn1))
+		require.NoError(t, failedTxnStore.Put(failedTxn2))
+
+		require.NoError(t, g.Put(&Checkpoint{TransactionTime: 9, TransactionNumber: 1}))
+		require.Equal(t, &Checkpoint{TransactionTime: 9, TransactionNumber: 1}, store.checkpoint)
+
+		require.NoError(t, g.Put(&Checkpoint{TransactionTime: 13, TransactionNumber: 4}))
+		require.Equal(t, &Checkpoint{TransactionTime: 9, TransactionNumber: math.MaxUint64}, store.checkpoint)
+
+		require.NoError(t, failedTxnStore.Delete(failedTxn1.ID()))
+		require.Equal(t, &Checkpoint{TransactionTime: 12, TransactionNumber: 2}, store.checkpoint)
+
+		require.NoError(t, failedTxnStore.Delete(failedTxn2.ID()))
+		require.Equal(t, &Checkpoint{TransactionTime: 13, TransactionNumber: 4}, store.checkpoint)
+
+		checkpoint, err := g.Get()
+		require.NoError(t, err)
+		require.Equal(t, &Checkpoint{TransactionTime: 13, TransactionNumber: 4}, checkpoint)
+	})
+
+	t.Run("zero transaction failed", func(t *testing.T) {
+		store := &mockCheckpointStore{}
+		failedTxns := newMemFailedTxnStore()
+
+		g := newCheckpointGuard(store, failedTxns)
+
+		require.NoError(t, failedTxns.Put(&FailedTxn{}))
+		require.NoError(t, g.Put(&Checkpoint{TransactionTime: 1}))
+		require.Nil(t, store.checkpoint)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		store := &mockCheckpointStore{}
+
+		g := newCheckpointGuard(store, &mockFailedTxnStore{err: errors.New("injected store error")})
+		failedTxnStore := &guardedFailedTxnStore{FailedTxnStore: &mockFailedTxnStore{}, guard: g}
+
+		err := g.Put(&Checkpoint{TransactionTime: 1})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected store error")
+
+		// The checkpoint error is only logged.
+		require.NoError(t, failedTxnStore.Delete("id"))
+
+		failedTxnStore.FailedTxnStore = &mockFailedTxnStore{deleteErr: errors.New("injected delete error")}
+		require.Error(t, failedTxnStore.Delete("id"))
+	})
+}
+
+func TestCheckpointBefore(t *testing.T) {
+	require.Equal(t, &Checkpoint{TransactionTime: 10, TransactionNumber: 2},
+		checkpointBefore(&txn.SidetreeTxn{TransactionTime: 10, TransactionNumber: 3}))
+	require.Equal(t, &Checkpoint{TransactionTime: 9, TransactionNumber: math.MaxUint64},
+		checkpointBefore(&txn.SidetreeTxn{TransactionTime: 10}))
+	require.Equal(t, &Checkpoint{}, checkpointBefore(&txn.SidetreeTxn{}))
+}
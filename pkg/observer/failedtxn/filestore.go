@@ -0,0 +1,147 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package failedtxn
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/fileutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+)
+
+// FileStore is an observer failed transaction store that persists the failed transactions to a JSON file,
+// so that transactions which failed before a restart are still retried after the restart.
+//
+// The failed transactions are held in memory and the file is replaced whenever a failed transaction is saved
+// or deleted.
+type FileStore struct {
+	path  string
+	mutex sync.RWMutex
+	txns  map[string]*observer.FailedTxn
+}
+
+// NewFileStore returns a new failed transaction store that persists the failed transactions to the
+// given file. The failed transactions are loaded from the file if it exists. The parent directory is created
+// if it doesn't exist.
+func NewFileStore(path string) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create failed transaction directory: %w", err)
+	}
+
+	s := &FileStore{path: path, txns: make(map[string]*observer.FailedTxn)}
+
+	b, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+
+		return nil, fmt.Errorf("read failed transaction file [%s]: %w", path, err)
+	}
+
+	var failedTxns []*observer.FailedTxn
+
+	if err := json.Unmarshal(b, &failedTxns); err != nil {
+		return nil, fmt.Errorf("unmarshal failed transaction file [%s]: %w", path, err)
+	}
+
+	for _, failedTxn := range failedTxns {
+		s.txns[failedTxn.ID()] = failedTxn
+	}
+
+	return s, nil
+}
+
+// Put saves the failed transaction.
+func (s *FileStore) Put(failedTxn *observer.FailedTxn) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id := failedTxn.ID()
+	previous, exists := s.txns[id]
+
+	txnCopy := *failedTxn
+	s.txns[id] = &txnCopy
+
+	if err := s.persist(); err != nil {
+		if exists {
+			s.txns[id] = previous
+		} else {
+			delete(s.txns, id)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// GetAll returns all failed transactions.
+func (s *FileStore) GetAll() ([]*observer.FailedTxn, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	failedTxns := make([]*observer.FailedTxn, 0, len(s.txns))
+
+	for _, failedTxn := range s.txns {
+		txnCopy := *failedTxn
+		failedTxns = append(failedTxns, &txnCopy)
+	}
+
+	return failedTxns, nil
+}
+
+// Delete deletes the failed transaction with the given ID.
+func (s *FileStore) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, exists := s.txns[id]
+	if !exists {
+		return nil
+	}
+
+	delete(s.txns, id)
+
+	if err := s.persist(); err != nil {
+		s.txns[id] = previous
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *FileStore) persist() error {
+	failedTxns := make([]*observer.FailedTxn, 0, len(s.txns))
+
+	for _, failedTxn := range s.txns {
+		failedTxns = append(failedTxns, failedTxn)
+	}
+
+	// Sorted so that the content of the file doesn't depend on the map order.
+	sort.Slice(failedTxns, func(i, j int) bool {
+		return failedTxns[i].ID() < failedTxns[j].ID()
+	})
+
+	b, err := json.Marshal(failedTxns)
+	if err != nil {
+		return fmt.Errorf("marshal failed transactions: %w", err)
+	}
+
+	if err := fileutil.ReplaceFile(s.path, b); err != nil {
+		return fmt.Errorf("write failed transaction file [%s]: %w", s.path, err)
+	}
+
+	return nil
+}
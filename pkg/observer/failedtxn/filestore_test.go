@@ -0,0 +1,150 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package failedtxn
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+)
+
+var _ observer.FailedTxnStore = (*FileStore)(nil)
+
+func TestFileStore(t *testing.T) {
+	failedTxn1 := &observer.FailedTxn{
+		Txn:      txn.SidetreeTxn{Namespace: "ns", TransactionTime: 10, TransactionNumber: 1, AnchorString: "1.a"},
+		Status:   observer.FailedTxnStatusPending,
+		Attempts: 1,
+	}
+
+	failedTxn2 := &observer.FailedTxn{
+		Txn:      txn.SidetreeTxn{Namespace: "ns", TransactionTime: 11, TransactionNumber: 2, AnchorString: "1.b"},
+		Status:   observer.FailedTxnStatusPending,
+		Attempts: 1,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "observer", "failedtxns.json")
+
+		s, err := NewFileStore(path)
+		require.NoError(t, err)
+
+		failedTxns, err := s.GetAll()
+		require.NoError(t, err)
+		require.Empty(t, failedTxns)
+
+		require.NoError(t, s.Put(failedTxn1))
+		require.NoError(t, s.Put(failedTxn2))
+
+		failedTxn := *failedTxn1
+		failedTxn.Attempts = 2
+		failedTxn.Status = observer.FailedTxnStatusFailed
+		failedTxn.LastError = "injected error"
+
+		require.NoError(t, s.Put(&failedTxn))
+
+		// Changes to the failed transaction after it was stored should not affect the store.
+		failedTxn.Attempts = 3
+
+		// Re-open the store.
+		s, err = NewFileStore(path)
+		require.NoError(t, err)
+
+		failedTxns, err = s.GetAll()
+		require.NoError(t, err)
+		require.Len(t, failedTxns, 2)
+
+		for _, ft := range failedTxns {
+			switch ft.ID() {
+			case failedTxn1.ID():
+				require.Equal(t, 2, ft.Attempts)
+				require.Equal(t, observer.FailedTxnStatusFailed, ft.Status)
+				require.Equal(t, "injected error", ft.LastError)
+			case failedTxn2.ID():
+				require.Equal(t, 1, ft.Attempts)
+			default:
+				require.Fail(t, "unexpected failed transaction", ft.ID())
+			}
+		}
+
+		require.NoError(t, s.Delete(failedTxn1.ID()))
+		require.NoError(t, s.Delete("unknown"))
+
+		s, err = NewFileStore(path)
+		require.NoError(t, err)
+
+		failedTxns, err = s.GetAll()
+		require.NoError(t, err)
+		require.Len(t, failedTxns, 1)
+		require.Equal(t, failedTxn2.ID(), failedTxns[0].ID())
+
+		_, err = os.Stat(path + ".tmp")
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "failedtxns.json")
+
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+		_, err := NewFileStore(path)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal failed transaction file")
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		// The path is a directory.
+		_, err := NewFileStore(t.TempDir())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "read failed transaction file")
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s, err := NewFileStore(filepath.Join(dir, "failedtxns.json"))
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put(failedTxn1))
+
+		// A directory in place of the temporary file results in a write error.
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "failedtxns.json.tmp"), 0o700))
+
+		err = s.Put(failedTxn2)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "write failed transaction file")
+
+		failedTxn := *failedTxn1
+		failedTxn.Attempts = 2
+
+		require.Error(t, s.Put(&failedTxn))
+
+		err = s.Delete(failedTxn1.ID())
+		require.Error(t, err)
+
+		// The store is unchanged.
+		failedTxns, err := s.GetAll()
+		require.NoError(t, err)
+		require.Len(t, failedTxns, 1)
+		require.Equal(t, failedTxn1.ID(), failedTxns[0].ID())
+		require.Equal(t, 1, failedTxns[0].Attempts)
+	})
+
+	t.Run("create directory error", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+		_, err := NewFileStore(filepath.Join(file, "dir", "failedtxns.json"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create failed transaction directory")
+	})
+}
@@ -7,6 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package observer
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
@@ -20,16 +24,71 @@ type Ledger interface {
 	RegisterForSidetreeTxn() <-chan []txn.SidetreeTxn
 }
 
+// ReplayLedger is a Ledger that is able to replay transactions from a checkpoint. The returned channel first
+// delivers all of the transactions that were anchored after the given checkpoint and then continues with new
+// transactions as they are anchored.
+type ReplayLedger interface {
+	Ledger
+	RegisterForSidetreeTxnSince(checkpoint *Checkpoint) <-chan []txn.SidetreeTxn
+}
+
+// ReorgLedger is a Ledger that is able to detect ledger reorganizations (forks). The returned channel delivers the
+// transactions that were orphaned by a reorganization. The observer removes the operations of the reverted
+// transactions from the operation store and rewinds the checkpoint so that the transactions on the new fork are
+// processed. Reverted transactions must be delivered before the transactions that replace them are delivered on
+// the Sidetree transaction channel. If a transaction can't be reverted then it is retried (see WithRetryPolicy) and
+// the transactions that are received in the meantime are processed after it has been reverted.
+type ReorgLedger interface {
+	Ledger
+	RegisterForRevertedSidetreeTxn() <-chan []txn.SidetreeTxn
+}
+
+// ErrCheckpointNotFound is returned by the checkpoint store if a checkpoint has not yet been saved.
+var ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+// Checkpoint identifies the last transaction that was successfully processed by the observer.
+type Checkpoint struct {
+	TransactionTime   uint64 `json:"transactionTime"`
+	TransactionNumber uint64 `json:"transactionNumber"`
+}
+
+// CheckpointStore persists the observer checkpoint.
+type CheckpointStore interface {
+	// Get returns the last saved checkpoint or ErrCheckpointNotFound if no checkpoint has been saved.
+	Get() (*Checkpoint, error)
+	// Put saves the checkpoint.
+	Put(checkpoint *Checkpoint) error
+}
+
 // OperationStore interface to access operation store.
 type OperationStore interface {
 	Put(ops []*operation.AnchoredOperation) error
 }
 
-// OperationFilter filters out operations before they are persisted.
+// RevertibleOperationStore is an OperationStore that is able to delete the operations of a transaction. The
+// operation store must implement this interface in order for the operations of reverted transactions (see
+// ReorgLedger) to be removed.
+type RevertibleOperationStore interface {
+	OperationStore
+	// DeleteByTransaction deletes the operations that were anchored in the transaction with the given time
+	// and number and returns the deleted operations.
+	DeleteByTransaction(txnTime, txnNumber uint64) ([]*operation.AnchoredOperation, error)
+}
+
+// OperationFilter filters out operations before they are persisted. Filter is invoked with the operations of
+// a single suffix and returns the subset of those operations that should be persisted. Filters are configured
+// on the transaction processor (see package opfilter for the built-in filters).
 type OperationFilter interface {
 	Filter(uniqueSuffix string, ops []*operation.AnchoredOperation) ([]*operation.AnchoredOperation, error)
 }
 
+// TxnListener is notified of the transactions that are received from the ledger and of the transactions that
+// are orphaned by a ledger reorganization.
+type TxnListener interface {
+	TxnObserved(sidetreeTxn *txn.SidetreeTxn)
+	TxnReverted(sidetreeTxn *txn.SidetreeTxn)
+}
+
 // Providers contains all of the providers required by the TxnProcessor.
 type Providers struct {
 	Ledger                 Ledger
@@ -40,20 +99,118 @@ type Providers struct {
 type Observer struct {
 	*Providers
 
-	stopCh chan struct{}
+	stopCh          chan struct{}
+	checkpointStore CheckpointStore
+	checkpoint      *Checkpoint // checkpoint loaded at startup
+	failedTxnStore  FailedTxnStore
+	retryPolicy     RetryPolicy
+	retryInterval   time.Duration
+	maxConcurrency  int
+	txnListeners    []TxnListener
+}
+
+// Option is an option for observer.
+type Option func(opts *Observer)
+
+// WithCheckpointStore sets the store that is used to persist the last processed transaction. On startup,
+// transactions up to and including the checkpoint are skipped and, if the ledger implements ReplayLedger,
+// the ledger is asked to replay transactions from the checkpoint.
+func WithCheckpointStore(store CheckpointStore) Option {
+	return func(opts *Observer) {
+		opts.checkpointStore = store
+	}
+}
+
+// WithMaxConcurrency sets the maximum number of transactions whose batch files are retrieved concurrently.
+// Regardless of the concurrency, transactions are committed to the operation store in ledger order. (The default
+// is 1, i.e. transactions are processed sequentially.)
+func WithMaxConcurrency(maxConcurrency int) Option {
+	return func(opts *Observer) {
+		opts.maxConcurrency = maxConcurrency
+	}
+}
+
+// WithTxnListeners adds listeners that are notified of every transaction that is received from the ledger,
+// including transactions that were already processed and transactions that fail to be processed, and of every
+// transaction that is orphaned by a ledger reorganization (whether or not the transaction could be reverted).
+func WithTxnListeners(listeners ...TxnListener) Option {
+	return func(opts *Observer) {
+		opts.txnListeners = append(opts.txnListeners, listeners...)
+	}
 }
 
 // New returns a new observer.
-func New(providers *Providers) *Observer {
-	return &Observer{
-		Providers: providers,
-		stopCh:    make(chan struct{}, 1),
+func New(providers *Providers, opts ...Option) *Observer {
+	o := &Observer{
+		Providers:       providers,
+		stopCh:          make(chan struct{}, 1),
+		checkpointStore: &noopCheckpointStore{},
+		retryInterval:   defaultRetryInterval,
+	}
+
+	// apply options
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.failedTxnStore == nil {
+		// Failed transactions are only held in memory, so the persisted checkpoint must not be advanced past them.
+		failedTxnStore := newMemFailedTxnStore()
+		guard := newCheckpointGuard(o.checkpointStore, failedTxnStore)
+
+		o.checkpointStore = guard
+		o.failedTxnStore = &guardedFailedTxnStore{FailedTxnStore: failedTxnStore, guard: guard}
+	}
+
+	o.retryPolicy = o.retryPolicy.withDefaults()
+
+	if o.retryInterval <= 0 {
+		o.retryInterval = defaultRetryInterval
 	}
+
+	return o
 }
 
 // Start starts observer routines.
 func (o *Observer) Start() {
-	go o.listen(o.Ledger.RegisterForSidetreeTxn())
+	go o.listen(o.register(), o.registerForReverted())
+}
+
+func (o *Observer) register() <-chan []txn.SidetreeTxn {
+	checkpoint, err := o.checkpointStore.Get()
+	if err != nil {
+		if !errors.Is(err, ErrCheckpointNotFound) {
+			logger.Warn("Failed to load checkpoint. Transactions will be processed from the start.", log.WithError(err))
+		}
+
+		return o.Ledger.RegisterForSidetreeTxn()
+	}
+
+	o.checkpoint = checkpoint
+
+	replayLedger, ok := o.Ledger.(ReplayLedger)
+	if !ok {
+		logger.Info("Ledger does not support replay. Transactions up to the checkpoint will be skipped.",
+			log.WithTransactionTime(checkpoint.TransactionTime), log.WithTransactionNumber(checkpoint.TransactionNumber))
+
+		return o.Ledger.RegisterForSidetreeTxn()
+	}
+
+	logger.Info("Resuming transaction processing from checkpoint",
+		log.WithTransactionTime(checkpoint.TransactionTime), log.WithTransactionNumber(checkpoint.TransactionNumber))
+
+	return replayLedger.RegisterForSidetreeTxnSince(checkpoint)
+}
+
+// registerForReverted returns the channel on which reverted transactions are delivered or nil if the
+// ledger does not support reorganizations.
+func (o *Observer) registerForReverted() <-chan []txn.SidetreeTxn {
+	reorgLedger, ok := o.Ledger.(ReorgLedger)
+	if !ok {
+		return nil
+	}
+
+	return reorgLedger.RegisterForRevertedSidetreeTxn()
 }
 
 // Stop stops the observer.
@@ -61,7 +218,10 @@ func (o *Observer) Stop() {
 	o.stopCh <- struct{}{}
 }
 
-func (o *Observer) listen(txnsCh <-chan []txn.SidetreeTxn) {
+func (o *Observer) listen(txnsCh, revertedCh <-chan []txn.SidetreeTxn) {
+	retryTicker := time.NewTicker(o.retryInterval)
+	defer retryTicker.Stop()
+
 	for {
 		select {
 		case <-o.stopCh:
@@ -69,6 +229,9 @@ func (o *Observer) listen(txnsCh <-chan []txn.SidetreeTxn) {
 
 			return
 
+		case <-retryTicker.C:
+			o.retryFailedTxns()
+
 		case txns, ok := <-txnsCh:
 			if !ok {
 				logger.Warn("Notification channel was closed. Exiting.")
@@ -76,35 +239,211 @@ func (o *Observer) listen(txnsCh <-chan []txn.SidetreeTxn) {
 				return
 			}
 
+			// Revert any orphaned transactions before processing the transactions that replace them.
+			o.revertPending(revertedCh)
+
 			o.process(txns)
+
+		case txns, ok := <-revertedCh:
+			if !ok {
+				logger.Warn("Reverted transaction notification channel was closed.")
+
+				revertedCh = nil
+
+				continue
+			}
+
+			o.revert(txns)
 		}
 	}
 }
 
 func (o *Observer) process(txns []txn.SidetreeTxn) {
+	o.notifyTxnListeners(txns)
+
+	pending := o.getPendingTxns(txns)
+
+	if len(pending) > 0 && o.hasPendingRevert() {
+		// The transactions may replace the orphaned transaction, so they are processed (retried) only after
+		// the orphaned transaction has been reverted.
+		for _, p := range pending {
+			o.addFailedTxn(&p.txn, errRevertPending)
+		}
+
+		return
+	}
+
+	if o.maxConcurrency <= 1 {
+		for _, p := range pending {
+			o.prepare(p)
+			o.commit(p)
+		}
+
+		return
+	}
+
+	// Prepare (i.e. retrieve the batch files for) up to maxConcurrency transactions concurrently. The
+	// transactions are committed in ledger order so that the operations for a suffix are persisted in the
+	// order in which they were anchored. A slot is released only after the transaction has been committed
+	// so that no more than maxConcurrency prepared transactions are held in memory.
+	slots := make(chan struct{}, o.maxConcurrency)
+
+	go func() {
+		for _, p := range pending {
+			slots <- struct{}{}
+
+			go func(p *pendingTxn) {
+				o.prepare(p)
+				close(p.prepared)
+			}(p)
+		}
+	}()
+
+	for _, p := range pending {
+		<-p.prepared
+
+		o.commit(p)
+
+		<-slots
+	}
+}
+
+func (o *Observer) notifyTxnListeners(txns []txn.SidetreeTxn) {
+	for i := range txns {
+		for _, listener := range o.txnListeners {
+			listener.TxnObserved(&txns[i])
+		}
+	}
+}
+
+func (o *Observer) notifyTxnReverted(txns []txn.SidetreeTxn) {
+	for i := range txns {
+		for _, listener := range o.txnListeners {
+			listener.TxnReverted(&txns[i])
+		}
+	}
+}
+
+// pendingTxn holds a transaction that is to be processed along with the operations retrieved in the
+// 'prepare' phase.
+type pendingTxn struct {
+	txn      txn.SidetreeTxn
+	tp       protocol.TxnProcessor
+	ops      []*operation.AnchoredOperation
+	err      error
+	prepared chan struct{}
+}
+
+// twoPhaseTxnProcessor is implemented by transaction processors that are able to retrieve the operations
+// for a transaction separately from persisting them.
+type twoPhaseTxnProcessor interface {
+	Prepare(sidetreeTxn *txn.SidetreeTxn) ([]*operation.AnchoredOperation, error)
+	Commit(sidetreeTxn *txn.SidetreeTxn, txnOps []*operation.AnchoredOperation) (int, error)
+}
+
+func (o *Observer) getPendingTxns(txns []txn.SidetreeTxn) []*pendingTxn {
+	var pending []*pendingTxn
+
 	for _, txn := range txns {
-		pc, err := o.ProtocolClientProvider.ForNamespace(txn.Namespace)
-		if err != nil {
-			logger.Warn("Failed to get protocol client for namespace", log.WithNamespace(txn.Namespace), log.WithError(err))
+		if o.checkpoint != nil && !o.checkpoint.before(&txn) {
+			logger.Debug("Skipping transaction that was already processed", log.WithAnchorString(txn.AnchorString),
+				log.WithTransactionTime(txn.TransactionTime), log.WithTransactionNumber(txn.TransactionNumber))
 
 			continue
 		}
 
-		v, err := pc.Get(txn.ProtocolVersion)
+		tp, err := o.getTxnProcessor(&txn)
 		if err != nil {
-			logger.Warn("Failed to get processor for transaction time", log.WithGenesisTime(txn.ProtocolVersion),
-				log.WithError(err))
+			logger.Warn("Failed to get transaction processor", log.WithNamespace(txn.Namespace),
+				log.WithGenesisTime(txn.ProtocolVersion), log.WithError(err))
 
 			continue
 		}
 
-		_, err = v.TransactionProcessor().Process(txn)
-		if err != nil {
-			logger.Warn("Failed to process anchor", log.WithAnchorString(txn.AnchorString), log.WithError(err))
+		pending = append(pending, &pendingTxn{txn: txn, tp: tp, prepared: make(chan struct{})})
+	}
 
-			continue
+	return pending
+}
+
+func (o *Observer) prepare(p *pendingTxn) {
+	if tp, ok := p.tp.(twoPhaseTxnProcessor); ok {
+		p.ops, p.err = tp.Prepare(&p.txn)
+	}
+}
+
+func (o *Observer) commit(p *pendingTxn) {
+	err := p.err
+	if err == nil {
+		if tp, ok := p.tp.(twoPhaseTxnProcessor); ok {
+			_, err = tp.Commit(&p.txn, p.ops)
+		} else {
+			_, err = p.tp.Process(p.txn)
 		}
+	}
+
+	if err != nil {
+		logger.Warn("Failed to process anchor", log.WithAnchorString(p.txn.AnchorString), log.WithError(err))
+
+		o.addFailedTxn(&p.txn, err)
+
+		return
+	}
+
+	logger.Debug("Successfully processed anchor", log.WithAnchorString(p.txn.AnchorString))
 
-		logger.Debug("Successfully processed anchor", log.WithAnchorString(txn.AnchorString))
+	o.saveCheckpoint(&p.txn)
+}
+
+func (o *Observer) processTxn(sidetreeTxn *txn.SidetreeTxn) error {
+	tp, err := o.getTxnProcessor(sidetreeTxn)
+	if err != nil {
+		return err
+	}
+
+	_, err = tp.Process(*sidetreeTxn)
+
+	return err
+}
+
+func (o *Observer) getTxnProcessor(sidetreeTxn *txn.SidetreeTxn) (protocol.TxnProcessor, error) {
+	pc, err := o.ProtocolClientProvider.ForNamespace(sidetreeTxn.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("get protocol client for namespace [%s]: %w", sidetreeTxn.Namespace, err)
 	}
+
+	v, err := pc.Get(sidetreeTxn.ProtocolVersion)
+	if err != nil {
+		return nil, fmt.Errorf("get protocol version for transaction time [%d]: %w", sidetreeTxn.ProtocolVersion, err)
+	}
+
+	return v.TransactionProcessor(), nil
+}
+
+func (o *Observer) saveCheckpoint(sidetreeTxn *txn.SidetreeTxn) {
+	checkpoint := checkpointFor(sidetreeTxn)
+
+	if err := o.checkpointStore.Put(checkpoint); err != nil {
+		logger.Warn("Failed to save checkpoint", log.WithTransactionTime(checkpoint.TransactionTime),
+			log.WithTransactionNumber(checkpoint.TransactionNumber), log.WithError(err))
+	}
+}
+
+// before returns true if the checkpoint is before the given transaction.
+func (c *Checkpoint) before(sidetreeTxn *txn.SidetreeTxn) bool {
+	if c.TransactionTime != sidetreeTxn.TransactionTime {
+		return c.TransactionTime < sidetreeTxn.TransactionTime
+	}
+
+	return c.TransactionNumber < sidetreeTxn.TransactionNumber
+}
+
+type noopCheckpointStore struct{}
+
+func (s *noopCheckpointStore) Get() (*Checkpoint, error) {
+	return nil, ErrCheckpointNotFound
+}
+
+func (s *noopCheckpointStore) Put(*Checkpoint) error {
+	return nil
 }
@@ -7,7 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package observer
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -74,6 +76,336 @@ func TestStartObserver(t *testing.T) {
 	})
 }
 
+func TestCheckpoint(t *testing.T) {
+	const namespace = "ns1"
+
+	newProviders := func(ledger Ledger, tp *mocks.TxnProcessor) *Providers {
+		pc := mocks.NewMockProtocolClient()
+		pc.Versions[0].TransactionProcessorReturns(tp)
+
+		return &Providers{
+			Ledger:                 ledger,
+			ProtocolClientProvider: mocks.NewMockProtocolClientProvider().WithProtocolClient(namespace, pc),
+		}
+	}
+
+	txns := []txn.SidetreeTxn{
+		{Namespace: namespace, TransactionTime: 10, TransactionNumber: 0, AnchorString: "1.address0"},
+		{Namespace: namespace, TransactionTime: 10, TransactionNumber: 1, AnchorString: "1.address1"},
+		{Namespace: namespace, TransactionTime: 11, TransactionNumber: 2, AnchorString: "1.address2"},
+	}
+
+	t.Run("checkpoint saved after successful processing", func(t *testing.T) {
+		sidetreeTxnCh := make(chan []txn.SidetreeTxn, 100)
+
+		tp := &mocks.TxnProcessor{}
+		tp.ProcessReturnsOnCall(2, 0, errors.New("injected processing error"))
+
+		store := &mockCheckpointStore{}
+
+		o := New(newProviders(mockLedger{registerForSidetreeTxnValue: sidetreeTxnCh}, tp), WithCheckpointStore(store))
+
+		o.Start()
+		defer o.Stop()
+
+		sidetreeTxnCh <- txns
+		time.Sleep(200 * time.Millisecond)
+
+		require.Equal(t, 3, tp.ProcessCallCount())
+
+		checkpoint, err := store.Get()
+		require.NoError(t, err)
+		require.Equal(t, uint64(10), checkpoint.TransactionTime)
+		require.Equal(t, uint64(1), checkpoint.TransactionNumber)
+	})
+
+	t.Run("resume from checkpoint - replay ledger", func(t *testing.T) {
+		ledger := &mockReplayLedger{ch: make(chan []txn.SidetreeTxn, 100)}
+
+		tp := &mocks.TxnProcessor{}
+
+		initialCheckpoint := &Checkpoint{TransactionTime: 10, TransactionNumber: 0}
+
+		store := &mockCheckpointStore{checkpoint: initialCheckpoint}
+
+		o := New(newProviders(ledger, tp), WithCheckpointStore(store))
+
+		o.Start()
+		defer o.Stop()
+
+		// The ledger may replay the checkpoint transaction itself, which should be skipped.
+		ledger.ch <- txns
+		time.Sleep(200 * time.Millisecond)
+
+		require.Equal(t, initialCheckpoint, ledger.getSince())
+		require.Equal(t, 2, tp.ProcessCallCount())
+
+		processedTxn, _ := tp.ProcessArgsForCall(0)
+		require.Equal(t, "1.address1", processedTxn.AnchorString)
+
+		checkpoint, err := store.Get()
+		require.NoError(t, err)
+		require.Equal(t, uint64(11), checkpoint.TransactionTime)
+		require.Equal(t, uint64(2), checkpoint.TransactionNumber)
+	})
+
+	t.Run("resume from checkpoint - ledger does not support replay", func(t *testing.T) {
+		sidetreeTxnCh := make(chan []txn.SidetreeTxn, 100)
+
+		tp := &mocks.TxnProcessor{}
+
+		store := &mockCheckpointStore{checkpoint: &Checkpoint{TransactionTime: 10, TransactionNumber: 1}}
+
+		o := New(newProviders(mockLedger{registerForSidetreeTxnValue: sidetreeTxnCh}, tp), WithCheckpointStore(store))
+
+		o.Start()
+		defer o.Stop()
+
+		sidetreeTxnCh <- txns
+		time.Sleep(200 * time.Millisecond)
+
+		require.Equal(t, 1, tp.ProcessCallCount())
+
+		processedTxn, _ := tp.ProcessArgsForCall(0)
+		require.Equal(t, "1.address2", processedTxn.AnchorString)
+	})
+
+	t.Run("checkpoint store errors", func(t *testing.T) {
+		ledger := &mockReplayLedger{ch: make(chan []txn.SidetreeTxn, 100)}
+
+		tp := &mocks.TxnProcessor{}
+
+		store := &mockCheckpointStore{getErr: errors.New("injected get error"), putErr: errors.New("injected put error")}
+
+		o := New(newProviders(ledger, tp), WithCheckpointStore(store))
+
+		o.Start()
+		defer o.Stop()
+
+		ledger.ch <- txns
+		time.Sleep(200 * time.Millisecond)
+
+		// All transactions are processed since the checkpoint could not be loaded.
+		require.Nil(t, ledger.getSince())
+		require.Equal(t, 3, tp.ProcessCallCount())
+	})
+}
+
+func TestTxnListeners(t *testing.T) {
+	const namespace = "ns1"
+
+	sidetreeTxnCh := make(chan []txn.SidetreeTxn, 100)
+
+	tp := &mocks.TxnProcessor{}
+	tp.ProcessReturnsOnCall(0, 0, errors.New("injected processing error"))
+
+	pc := mocks.NewMockProtocolClient()
+	pc.Versions[0].TransactionProcessorReturns(tp)
+
+	providers := &Providers{
+		Ledger:                 mockLedger{registerForSidetreeTxnValue: sidetreeTxnCh},
+		ProtocolClientProvider: mocks.NewMockProtocolClientProvider().WithProtocolClient(namespace, pc),
+	}
+
+	listener1 := &mockTxnListener{}
+	listener2 := &mockTxnListener{}
+
+	o := New(providers, WithTxnListeners(listener1), WithTxnListeners(listener2),
+		WithCheckpointStore(&mockCheckpointStore{checkpoint: &Checkpoint{TransactionTime: 0, TransactionNumber: 0}}))
+
+	o.Start()
+	defer o.Stop()
+
+	// The first transaction was already processed and the second transaction fails to be processed. Listeners
+	// are notified of all transactions.
+	sidetreeTxnCh <- generateTxns(namespace, 3)
+	sidetreeTxnCh <- []txn.SidetreeTxn{
+		{Namespace: namespace, TransactionTime: 10, TransactionNumber: 3, AnchorString: "1.address3"},
+	}
+
+	expected := []string{"1.address0", "1.address1", "1.address2", "1.address3"}
+
+	require.Eventually(t, func() bool { return len(listener2.getAnchorStrings()) == len(expected) },
+		time.Second, 10*time.Millisecond)
+
+	require.Equal(t, expected, listener1.getAnchorStrings())
+	require.Equal(t, expected, listener2.getAnchorStrings())
+
+	require.Eventually(t, func() bool { return tp.ProcessCallCount() == 3 }, time.Second, 10*time.Millisecond)
+}
+
+func TestConcurrentProcessing(t *testing.T) {
+	const (
+		namespace      = "ns1"
+		numTxns        = 20
+		maxConcurrency = 4
+	)
+
+	t.Run("success", func(t *testing.T) {
+		sidetreeTxnCh := make(chan []txn.SidetreeTxn, 100)
+
+		opStore := &mockOrderedOperationStore{}
+		opProvider := &mockDelayedTxnOpsProvider{
+			// Earlier transactions take longer to retrieve so that they complete out of order.
+			delay: func(sidetreeTxn *txn.SidetreeTxn) time.Duration {
+				return time.Duration(numTxns-sidetreeTxn.TransactionNumber) * time.Millisecond
+			},
+		}
+
+		checkpointStore := &mockCheckpointStore{}
+
+		o := New(newTxnProcessorProviders(namespace, sidetreeTxnCh, opStore, opProvider),
+			WithMaxConcurrency(maxConcurrency), WithCheckpointStore(checkpointStore))
+
+		o.Start()
+		defer o.Stop()
+
+		sidetreeTxnCh <- generateTxns(namespace, numTxns)
+
+		require.Eventually(t, func() bool { return len(opStore.getSuffixes()) == numTxns }, time.Second, 10*time.Millisecond)
+
+		for i, suffix := range opStore.getSuffixes() {
+			require.Equal(t, fmt.Sprintf("suffix-%d", i), suffix)
+		}
+
+		require.Greater(t, opProvider.getMaxInFlight(), 1)
+		require.LessOrEqual(t, opProvider.getMaxInFlight(), maxConcurrency)
+
+		checkpoint, err := checkpointStore.Get()
+		require.NoError(t, err)
+		require.Equal(t, uint64(numTxns-1), checkpoint.TransactionNumber)
+	})
+
+	t.Run("prepare error", func(t *testing.T) {
+		sidetreeTxnCh := make(chan []txn.SidetreeTxn, 100)
+
+		opStore := &mockOrderedOperationStore{}
+		opProvider := &mockDelayedTxnOpsProvider{
+			err: func(sidetreeTxn *txn.SidetreeTxn) error {
+				if sidetreeTxn.TransactionNumber == 2 {
+					return errors.New("injected CAS error")
+				}
+
+				return nil
+			},
+		}
+
+		o := New(newTxnProcessorProviders(namespace, sidetreeTxnCh, opStore, opProvider), WithMaxConcurrency(maxConcurrency))
+
+		o.Start()
+		defer o.Stop()
+
+		sidetreeTxnCh <- generateTxns(namespace, 5)
+
+		require.Eventually(t, func() bool { return len(opStore.getSuffixes()) == 4 }, time.Second, 10*time.Millisecond)
+
+		require.Equal(t, []string{"suffix-0", "suffix-1", "suffix-3", "suffix-4"}, opStore.getSuffixes())
+
+		failedTxns, err := o.FailedTransactions()
+		require.NoError(t, err)
+		require.Len(t, failedTxns, 1)
+		require.Equal(t, uint64(2), failedTxns[0].Txn.TransactionNumber)
+		require.Contains(t, failedTxns[0].LastError, "injected CAS error")
+	})
+
+	t.Run("transaction processor does not support prepare", func(t *testing.T) {
+		sidetreeTxnCh := make(chan []txn.SidetreeTxn, 100)
+
+		tp := &mocks.TxnProcessor{}
+		tp.ProcessReturnsOnCall(1, 0, errors.New("injected processing error"))
+
+		pc := mocks.NewMockProtocolClient()
+		pc.Versions[0].TransactionProcessorReturns(tp)
+
+		providers := &Providers{
+			Ledger:                 mockLedger{registerForSidetreeTxnValue: sidetreeTxnCh},
+			ProtocolClientProvider: mocks.NewMockProtocolClientProvider().WithProtocolClient(namespace, pc),
+		}
+
+		o := New(providers, WithMaxConcurrency(maxConcurrency))
+
+		o.Start()
+		defer o.Stop()
+
+		sidetreeTxnCh <- generateTxns(namespace, 3)
+
+		require.Eventually(t, func() bool { return tp.ProcessCallCount() == 3 }, time.Second, 10*time.Millisecond)
+
+		for i := 0; i < 3; i++ {
+			processedTxn, _ := tp.ProcessArgsForCall(i)
+			require.Equal(t, uint64(i), processedTxn.TransactionNumber)
+		}
+	})
+}
+
+// BenchmarkCatchUp measures the throughput of processing a backlog of transactions where retrieving the
+// batch files for each transaction takes 1ms (simulating CAS latency).
+func BenchmarkCatchUp(b *testing.B) {
+	const (
+		namespace = "ns1"
+		numTxns   = 100
+	)
+
+	for _, maxConcurrency := range []int{1, 4, 16, 64} {
+		b.Run(fmt.Sprintf("concurrency-%d", maxConcurrency), func(b *testing.B) {
+			opProvider := &mockDelayedTxnOpsProvider{
+				delay: func(*txn.SidetreeTxn) time.Duration { return time.Millisecond },
+			}
+
+			txns := generateTxns(namespace, numTxns)
+
+			b.ResetTimer()
+
+			start := time.Now()
+
+			for i := 0; i < b.N; i++ {
+				opStore := &mockOrderedOperationStore{}
+
+				o := New(newTxnProcessorProviders(namespace, nil, opStore, opProvider), WithMaxConcurrency(maxConcurrency))
+
+				o.process(txns)
+
+				if len(opStore.getSuffixes()) != numTxns {
+					b.Fatalf("expecting %d operations but got %d", numTxns, len(opStore.getSuffixes()))
+				}
+			}
+
+			b.ReportMetric(float64(b.N*numTxns)/time.Since(start).Seconds(), "txns/s")
+		})
+	}
+}
+
+func newTxnProcessorProviders(namespace string, sidetreeTxnCh chan []txn.SidetreeTxn,
+	opStore txnprocessor.OperationStore, opProvider *mockDelayedTxnOpsProvider) *Providers {
+	tp := txnprocessor.New(&txnprocessor.Providers{
+		OpStore:                   opStore,
+		OperationProtocolProvider: opProvider,
+	})
+
+	pc := mocks.NewMockProtocolClient()
+	pc.Versions[0].TransactionProcessorReturns(tp)
+
+	return &Providers{
+		Ledger:                 mockLedger{registerForSidetreeTxnValue: sidetreeTxnCh},
+		ProtocolClientProvider: mocks.NewMockProtocolClientProvider().WithProtocolClient(namespace, pc),
+	}
+}
+
+func generateTxns(namespace string, n int) []txn.SidetreeTxn {
+	txns := make([]txn.SidetreeTxn, n)
+
+	for i := range txns {
+		txns[i] = txn.SidetreeTxn{
+			Namespace:         namespace,
+			TransactionTime:   uint64(i / 2),
+			TransactionNumber: uint64(i),
+			AnchorString:      fmt.Sprintf("1.address%d", i),
+		}
+	}
+
+	return txns
+}
+
 func TestTxnProcessor_Process(t *testing.T) {
 	t.Run("test error from txn operations provider", func(t *testing.T) {
 		errExpected := fmt.Errorf("txn operations provider error")
@@ -102,6 +434,170 @@ func (m mockLedger) RegisterForSidetreeTxn() <-chan []txn.SidetreeTxn {
 	return m.registerForSidetreeTxnValue
 }
 
+type mockReplayLedger struct {
+	ch    chan []txn.SidetreeTxn
+	mutex sync.Mutex
+	since *Checkpoint
+}
+
+func (m *mockReplayLedger) RegisterForSidetreeTxn() <-chan []txn.SidetreeTxn {
+	return m.ch
+}
+
+func (m *mockReplayLedger) RegisterForSidetreeTxnSince(checkpoint *Checkpoint) <-chan []txn.SidetreeTxn {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.since = checkpoint
+
+	return m.ch
+}
+
+func (m *mockReplayLedger) getSince() *Checkpoint {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.since
+}
+
+type mockCheckpointStore struct {
+	mutex      sync.Mutex
+	checkpoint *Checkpoint
+	getErr     error
+	putErr     error
+}
+
+func (m *mockCheckpointStore) Get() (*Checkpoint, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+
+	if m.checkpoint == nil {
+		return nil, ErrCheckpointNotFound
+	}
+
+	return m.checkpoint, nil
+}
+
+func (m *mockCheckpointStore) Put(checkpoint *Checkpoint) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.putErr != nil {
+		return m.putErr
+	}
+
+	m.checkpoint = checkpoint
+
+	return nil
+}
+
+type mockTxnListener struct {
+	mutex         sync.Mutex
+	anchorStrings []string
+	reverted      []string
+}
+
+func (m *mockTxnListener) TxnObserved(sidetreeTxn *txn.SidetreeTxn) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.anchorStrings = append(m.anchorStrings, sidetreeTxn.AnchorString)
+}
+
+func (m *mockTxnListener) TxnReverted(sidetreeTxn *txn.SidetreeTxn) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.reverted = append(m.reverted, sidetreeTxn.AnchorString)
+}
+
+func (m *mockTxnListener) getAnchorStrings() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]string(nil), m.anchorStrings...)
+}
+
+func (m *mockTxnListener) getReverted() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]string(nil), m.reverted...)
+}
+
+type mockDelayedTxnOpsProvider struct {
+	delay func(sidetreeTxn *txn.SidetreeTxn) time.Duration
+	err   func(sidetreeTxn *txn.SidetreeTxn) error
+
+	mutex       sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (m *mockDelayedTxnOpsProvider) GetTxnOperations(sidetreeTxn *txn.SidetreeTxn) ([]*operation.AnchoredOperation, error) {
+	m.mutex.Lock()
+	m.inFlight++
+	if m.inFlight > m.maxInFlight {
+		m.maxInFlight = m.inFlight
+	}
+	m.mutex.Unlock()
+
+	defer func() {
+		m.mutex.Lock()
+		m.inFlight--
+		m.mutex.Unlock()
+	}()
+
+	if m.delay != nil {
+		time.Sleep(m.delay(sidetreeTxn))
+	}
+
+	if m.err != nil {
+		if err := m.err(sidetreeTxn); err != nil {
+			return nil, err
+		}
+	}
+
+	return []*operation.AnchoredOperation{
+		{UniqueSuffix: fmt.Sprintf("suffix-%d", sidetreeTxn.TransactionNumber), Type: operation.TypeUpdate},
+	}, nil
+}
+
+func (m *mockDelayedTxnOpsProvider) getMaxInFlight() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.maxInFlight
+}
+
+// mockOrderedOperationStore records the suffixes of the operations in the order in which they were stored.
+type mockOrderedOperationStore struct {
+	mutex    sync.Mutex
+	suffixes []string
+}
+
+func (m *mockOrderedOperationStore) Put(ops []*operation.AnchoredOperation) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, op := range ops {
+		m.suffixes = append(m.suffixes, op.UniqueSuffix)
+	}
+
+	return nil
+}
+
+func (m *mockOrderedOperationStore) getSuffixes() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]string(nil), m.suffixes...)
+}
+
 type mockOperationStore struct {
 	putFunc func(ops []*operation.AnchoredOperation) error
 	getFunc func(suffix string) ([]*operation.AnchoredOperation, error)
@@ -0,0 +1,69 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package opfilter provides operation filters (see observer.OperationFilter) which may be configured on the
+// transaction processor to discard operations before they are persisted.
+package opfilter
+
+import (
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+)
+
+var logger = log.New("sidetree-core-observer")
+
+// SuffixBlocklist filters out all operations for the suffixes in the blocklist. Suffixes may be blocked
+// and unblocked while the observer is running.
+type SuffixBlocklist struct {
+	mutex    sync.RWMutex
+	suffixes map[string]struct{}
+}
+
+// NewSuffixBlocklist returns a new suffix blocklist filter which blocks the given suffixes.
+func NewSuffixBlocklist(suffixes ...string) *SuffixBlocklist {
+	f := &SuffixBlocklist{suffixes: make(map[string]struct{})}
+
+	f.Block(suffixes...)
+
+	return f
+}
+
+// Block adds the given suffixes to the blocklist.
+func (f *SuffixBlocklist) Block(suffixes ...string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, suffix := range suffixes {
+		f.suffixes[suffix] = struct{}{}
+	}
+}
+
+// Unblock removes the given suffixes from the blocklist.
+func (f *SuffixBlocklist) Unblock(suffixes ...string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, suffix := range suffixes {
+		delete(f.suffixes, suffix)
+	}
+}
+
+// Filter returns no operations if the given suffix is blocked; otherwise the given operations are returned.
+func (f *SuffixBlocklist) Filter(uniqueSuffix string, ops []*operation.AnchoredOperation) ([]*operation.AnchoredOperation, error) {
+	f.mutex.RLock()
+	_, blocked := f.suffixes[uniqueSuffix]
+	f.mutex.RUnlock()
+
+	if blocked {
+		logger.Info("Suffix is blocked: discarding operations", log.WithSuffix(uniqueSuffix), log.WithTotal(len(ops)))
+
+		return nil, nil
+	}
+
+	return ops, nil
+}
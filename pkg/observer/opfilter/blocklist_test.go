@@ -0,0 +1,43 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+)
+
+var _ observer.OperationFilter = (*SuffixBlocklist)(nil)
+
+func TestSuffixBlocklist(t *testing.T) {
+	ops := []*operation.AnchoredOperation{{UniqueSuffix: "abc", Type: operation.TypeUpdate}}
+
+	f := NewSuffixBlocklist("abc", "def")
+
+	filteredOps, err := f.Filter("abc", ops)
+	require.NoError(t, err)
+	require.Empty(t, filteredOps)
+
+	filteredOps, err = f.Filter("xyz", ops)
+	require.NoError(t, err)
+	require.Equal(t, ops, filteredOps)
+
+	f.Unblock("abc")
+	f.Block("xyz")
+
+	filteredOps, err = f.Filter("abc", ops)
+	require.NoError(t, err)
+	require.Equal(t, ops, filteredOps)
+
+	filteredOps, err = f.Filter("xyz", ops)
+	require.NoError(t, err)
+	require.Empty(t, filteredOps)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opfilter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/commitment"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+)
+
+type documentResolver interface {
+	Resolve(uniqueSuffix string, opts ...document.ResolutionOption) (*protocol.ResolutionModel, error)
+}
+
+// CommitmentFilter filters out update, recover and deactivate operations whose reveal value doesn't match the
+// current update (or recovery) commitment of the document. Create operations are not filtered.
+//
+// Note that the operation processor is able to apply an operation that was anchored before the operation that
+// commits to its key. Such an operation is discarded by this filter, so the filter should only be used if
+// operations are expected to be anchored in order.
+type CommitmentFilter struct {
+	resolver documentResolver
+	pc       protocol.Client
+}
+
+// NewCommitmentFilter returns a new commitment filter. The resolver (e.g. processor.OperationProcessor) is
+// used to retrieve the current commitments of the document and the protocol client is used to parse the
+// reveal value of an operation.
+func NewCommitmentFilter(resolver documentResolver, pc protocol.Client) *CommitmentFilter {
+	return &CommitmentFilter{
+		resolver: resolver,
+		pc:       pc,
+	}
+}
+
+// Filter returns the operations whose reveal value matches the current commitment of the document.
+func (f *CommitmentFilter) Filter(uniqueSuffix string, ops []*operation.AnchoredOperation) ([]*operation.AnchoredOperation, error) {
+	var rm *protocol.ResolutionModel
+
+	var filteredOps []*operation.AnchoredOperation
+
+	for _, op := range ops {
+		if op.Type == operation.TypeCreate {
+			filteredOps = append(filteredOps, op)
+
+			continue
+		}
+
+		if rm == nil {
+			var err error
+
+			rm, err = f.resolve(uniqueSuffix)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		ok, err := f.matchesCommitment(op, rm)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			filteredOps = append(filteredOps, op)
+		}
+	}
+
+	return filteredOps, nil
+}
+
+func (f *CommitmentFilter) resolve(uniqueSuffix string) (*protocol.ResolutionModel, error) {
+	rm, err := f.resolver.Resolve(uniqueSuffix)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			// The document doesn't exist so there are no commitments to match.
+			return &protocol.ResolutionModel{}, nil
+		}
+
+		return nil, fmt.Errorf("resolve document: %w", err)
+	}
+
+	return rm, nil
+}
+
+func (f *CommitmentFilter) matchesCommitment(op *operation.AnchoredOperation, rm *protocol.ResolutionModel) (bool, error) {
+	v, err := f.pc.Get(op.ProtocolVersion)
+	if err != nil {
+		return false, fmt.Errorf("get protocol version for operation: %w", err)
+	}
+
+	rv, err := v.OperationParser().GetRevealValue(op.OperationRequest)
+	if err != nil {
+		logger.Info("Failed to get reveal value: discarding operation", log.WithSuffix(op.UniqueSuffix),
+			log.WithOperationType(string(op.Type)), log.WithError(err))
+
+		return false, nil
+	}
+
+	c, err := commitment.GetCommitmentFromRevealValue(rv)
+	if err != nil {
+		logger.Info("Failed to get commitment from reveal value: discarding operation", log.WithSuffix(op.UniqueSuffix),
+			log.WithOperationType(string(op.Type)), log.WithError(err))
+
+		return false, nil
+	}
+
+	expected := rm.RecoveryCommitment
+	if op.Type == operation.TypeUpdate {
+		expected = rm.UpdateCommitment
+	}
+
+	if c != expected {
+		logger.Info("Reveal value doesn't match the current commitment: discarding operation",
+			log.WithSuffix(op.UniqueSuffix), log.WithOperationType(string(op.Type)), log.WithCommitment(c))
+
+		return false, nil
+	}
+
+	return true, nil
+}
@@ -0,0 +1,178 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opfilter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/processor"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/client/keymanager"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/doccomposer"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/model"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationapplier"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationparser"
+)
+
+const (
+	namespace = "did:sidetree"
+	sha2_256  = 18
+)
+
+var _ observer.OperationFilter = (*CommitmentFilter)(nil)
+
+func TestCommitmentFilter(t *testing.T) {
+	pc := newProtocolClient()
+	store := mocks.NewMockOperationStore(nil)
+
+	f := NewCommitmentFilter(processor.New("test", store, pc), pc)
+
+	km := keymanager.New(keymanager.NewMemStore(), sha2_256)
+
+	suffix, request, err := km.Create(`{"test":"value"}`)
+	require.NoError(t, err)
+
+	createOp := newAnchoredOperation(t, pc, request, 1)
+
+	filteredOps, err := f.Filter(suffix, []*operation.AnchoredOperation{createOp})
+	require.NoError(t, err)
+	require.Equal(t, []*operation.AnchoredOperation{createOp}, filteredOps)
+
+	require.NoError(t, store.Put(createOp))
+
+	jsonPatch, err := patch.NewJSONPatch(`[{"op":"replace","path":"/test","value":"updated"}]`)
+	require.NoError(t, err)
+
+	request, err = km.Update(suffix, []patch.Patch{jsonPatch})
+	require.NoError(t, err)
+
+	updateOp1 := newAnchoredOperation(t, pc, request, 2)
+
+	require.NoError(t, km.Confirm(suffix))
+
+	request, err = km.Update(suffix, []patch.Patch{jsonPatch})
+	require.NoError(t, err)
+
+	updateOp2 := newAnchoredOperation(t, pc, request, 3)
+
+	require.NoError(t, km.Confirm(suffix))
+
+	request, err = km.Recover(suffix, `{"test":"recovered"}`)
+	require.NoError(t, err)
+
+	recoverOp := newAnchoredOperation(t, pc, request, 4)
+
+	require.NoError(t, km.Confirm(suffix))
+
+	request, err = km.Deactivate(suffix)
+	require.NoError(t, err)
+
+	deactivateOp := newAnchoredOperation(t, pc, request, 5)
+
+	t.Run("success", func(t *testing.T) {
+		// The second update reveals the key that the first update commits to.
+		filteredOps, err := f.Filter(suffix, []*operation.AnchoredOperation{updateOp2, updateOp1})
+		require.NoError(t, err)
+		require.Equal(t, []*operation.AnchoredOperation{updateOp1}, filteredOps)
+
+		// The deactivate operation reveals the key that the recover operation commits to.
+		filteredOps, err = f.Filter(suffix, []*operation.AnchoredOperation{recoverOp, deactivateOp})
+		require.NoError(t, err)
+		require.Equal(t, []*operation.AnchoredOperation{recoverOp}, filteredOps)
+	})
+
+	t.Run("success - document not found", func(t *testing.T) {
+		filteredOps, err := NewCommitmentFilter(processor.New("test", mocks.NewMockOperationStore(nil), pc), pc).
+			Filter(suffix, []*operation.AnchoredOperation{updateOp1, createOp})
+		require.NoError(t, err)
+		require.Equal(t, []*operation.AnchoredOperation{createOp}, filteredOps)
+	})
+
+	t.Run("success - invalid operation", func(t *testing.T) {
+		invalidOp := &operation.AnchoredOperation{
+			UniqueSuffix:     suffix,
+			Type:             operation.TypeUpdate,
+			OperationRequest: []byte(`{"type":"update"}`),
+		}
+
+		filteredOps, err := f.Filter(suffix, []*operation.AnchoredOperation{invalidOp})
+		require.NoError(t, err)
+		require.Empty(t, filteredOps)
+
+		invalidOp.OperationRequest = []byte(`{"type":"update","revealValue":"invalid"}`)
+
+		filteredOps, err = f.Filter(suffix, []*operation.AnchoredOperation{invalidOp})
+		require.NoError(t, err)
+		require.Empty(t, filteredOps)
+	})
+
+	t.Run("resolver error", func(t *testing.T) {
+		errExpected := errors.New("injected resolver error")
+
+		filteredOps, err := NewCommitmentFilter(&mockResolver{err: errExpected}, pc).
+			Filter(suffix, []*operation.AnchoredOperation{updateOp1})
+		require.True(t, errors.Is(err, errExpected))
+		require.Nil(t, filteredOps)
+	})
+
+	t.Run("protocol error", func(t *testing.T) {
+		pc := newProtocolClient()
+		pc.Versions[0].ProtocolReturns(protocol.Protocol{GenesisTime: 100})
+
+		filteredOps, err := NewCommitmentFilter(&mockResolver{rm: &protocol.ResolutionModel{}}, pc).
+			Filter(suffix, []*operation.AnchoredOperation{updateOp1})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get protocol version for operation")
+		require.Nil(t, filteredOps)
+	})
+}
+
+func newProtocolClient() *mocks.MockProtocolClient {
+	pc := mocks.NewMockProtocolClient()
+
+	parser := operationparser.New(pc.Protocol)
+	dc := doccomposer.New()
+
+	pv := pc.CurrentVersion
+	pv.OperationParserReturns(parser)
+	pv.OperationApplierReturns(operationapplier.New(pc.Protocol, parser, dc))
+	pv.DocumentComposerReturns(dc)
+
+	return pc
+}
+
+func newAnchoredOperation(t *testing.T, pc *mocks.MockProtocolClient, request []byte,
+	txnTime uint64) *operation.AnchoredOperation {
+	t.Helper()
+
+	op, err := operationparser.New(pc.Protocol).ParseOperation(namespace, request, false)
+	require.NoError(t, err)
+
+	anchoredOp, err := model.GetAnchoredOperation(op)
+	require.NoError(t, err)
+
+	anchoredOp.TransactionTime = txnTime
+
+	return anchoredOp
+}
+
+type mockResolver struct {
+	rm  *protocol.ResolutionModel
+	err error
+}
+
+func (m *mockResolver) Resolve(string, ...document.ResolutionOption) (*protocol.ResolutionModel, error) {
+	return m.rm, m.err
+}
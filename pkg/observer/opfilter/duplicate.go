@@ -0,0 +1,68 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opfilter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+)
+
+type operationStore interface {
+	Get(uniqueSuffix string) ([]*operation.AnchoredOperation, error)
+}
+
+// DuplicateFilter filters out operations that are already in the operation store, for example operations that
+// were anchored again after a failed anchor.
+type DuplicateFilter struct {
+	store operationStore
+}
+
+// NewDuplicateFilter returns a new duplicate filter which looks up existing operations in the given store.
+func NewDuplicateFilter(store operationStore) *DuplicateFilter {
+	return &DuplicateFilter{store: store}
+}
+
+// Filter returns the operations that are not already in the operation store.
+func (f *DuplicateFilter) Filter(uniqueSuffix string, ops []*operation.AnchoredOperation) ([]*operation.AnchoredOperation, error) {
+	storedOps, err := f.store.Get(uniqueSuffix)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return ops, nil
+		}
+
+		return nil, fmt.Errorf("get operations from store: %w", err)
+	}
+
+	var filteredOps []*operation.AnchoredOperation
+
+	for _, op := range ops {
+		if containsOperation(storedOps, op) {
+			logger.Info("Operation is already stored: discarding operation", log.WithSuffix(uniqueSuffix),
+				log.WithOperationType(string(op.Type)))
+
+			continue
+		}
+
+		filteredOps = append(filteredOps, op)
+	}
+
+	return filteredOps, nil
+}
+
+func containsOperation(ops []*operation.AnchoredOperation, op *operation.AnchoredOperation) bool {
+	for _, o := range ops {
+		if o.Type == op.Type && bytes.Equal(o.OperationRequest, op.OperationRequest) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,72 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opfilter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+)
+
+var _ observer.OperationFilter = (*DuplicateFilter)(nil)
+
+func TestDuplicateFilter(t *testing.T) {
+	storedOp := &operation.AnchoredOperation{
+		UniqueSuffix:     "abc",
+		Type:             operation.TypeUpdate,
+		OperationRequest: []byte(`{"type":"update","revealValue":"1"}`),
+		TransactionTime:  10,
+	}
+
+	store := mocks.NewMockOperationStore(nil)
+	require.NoError(t, store.Put(storedOp))
+
+	t.Run("success", func(t *testing.T) {
+		// The same operation anchored in a later transaction.
+		duplicateOp := &operation.AnchoredOperation{
+			UniqueSuffix:     "abc",
+			Type:             operation.TypeUpdate,
+			OperationRequest: []byte(`{"type":"update","revealValue":"1"}`),
+			TransactionTime:  20,
+		}
+
+		newOp := &operation.AnchoredOperation{
+			UniqueSuffix:     "abc",
+			Type:             operation.TypeUpdate,
+			OperationRequest: []byte(`{"type":"update","revealValue":"2"}`),
+			TransactionTime:  20,
+		}
+
+		f := NewDuplicateFilter(store)
+
+		filteredOps, err := f.Filter("abc", []*operation.AnchoredOperation{duplicateOp, newOp})
+		require.NoError(t, err)
+		require.Equal(t, []*operation.AnchoredOperation{newOp}, filteredOps)
+	})
+
+	t.Run("success - suffix not found", func(t *testing.T) {
+		ops := []*operation.AnchoredOperation{{UniqueSuffix: "xyz", Type: operation.TypeCreate}}
+
+		filteredOps, err := NewDuplicateFilter(store).Filter("xyz", ops)
+		require.NoError(t, err)
+		require.Equal(t, ops, filteredOps)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		errExpected := errors.New("injected store error")
+
+		filteredOps, err := NewDuplicateFilter(mocks.NewMockOperationStore(errExpected)).
+			Filter("abc", []*operation.AnchoredOperation{storedOp})
+		require.True(t, errors.Is(err, errExpected))
+		require.Nil(t, filteredOps)
+	})
+}
@@ -0,0 +1,240 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+)
+
+// revertingTxnProcessor is implemented by transaction processors that are able to remove the operations
+// of a reverted transaction.
+type revertingTxnProcessor interface {
+	Revert(sidetreeTxn *txn.SidetreeTxn) ([]string, error)
+}
+
+// revertPending reverts the transactions that are waiting on the given channel (if any) without blocking.
+func (o *Observer) revertPending(revertedCh <-chan []txn.SidetreeTxn) {
+	for {
+		select {
+		case txns, ok := <-revertedCh:
+			if !ok {
+				return
+			}
+
+			o.revert(txns)
+
+		default:
+			return
+		}
+	}
+}
+
+// errRevertPending is the cause of the failure of transactions that are received while an orphaned
+// transaction has yet to be reverted.
+var errRevertPending = errors.New("waiting for an orphaned transaction to be reverted")
+
+// revert removes the operations of the given transactions, which were orphaned by a ledger reorganization.
+// The transactions are reverted in reverse ledger order and the checkpoint is rewound to just before the
+// earliest reverted transaction.
+//
+// If a transaction can't be reverted then the transaction (along with the earlier transactions that have
+// yet to be reverted) is added to the failed transaction store so that it is reverted later, and the
+// checkpoint is only rewound past the transactions that were reverted.
+func (o *Observer) revert(txns []txn.SidetreeTxn) {
+	if len(txns) == 0 {
+		return
+	}
+
+	o.notifyTxnReverted(txns)
+
+	reverted := make([]txn.SidetreeTxn, len(txns))
+	copy(reverted, txns)
+
+	// Latest transaction first.
+	sort.SliceStable(reverted, func(i, j int) bool {
+		return checkpointFor(&reverted[j]).before(&reverted[i])
+	})
+
+	for i := range reverted {
+		sidetreeTxn := &reverted[i]
+
+		logger.Info("Reverting transaction that was orphaned by a ledger reorganization",
+			log.WithAnchorString(sidetreeTxn.AnchorString), log.WithTransactionTime(sidetreeTxn.TransactionTime),
+			log.WithTransactionNumber(sidetreeTxn.TransactionNumber))
+
+		// An orphaned transaction must not be retried.
+		if err := o.failedTxnStore.Delete(FailedTxnID(sidetreeTxn)); err != nil {
+			logger.Warn("Failed to delete reverted transaction from failed transaction store",
+				log.WithAnchorString(sidetreeTxn.AnchorString), log.WithError(err))
+		}
+
+		if err := o.revertTxn(sidetreeTxn); err != nil {
+			logger.Error("Failed to revert transaction", log.WithAnchorString(sidetreeTxn.AnchorString), log.WithError(err))
+
+			o.addRevertedTxns(reverted[i:], err)
+
+			if i > 0 {
+				o.rewindCheckpoint(&reverted[i-1])
+			}
+
+			return
+		}
+	}
+
+	o.rewindCheckpoint(&reverted[len(reverted)-1])
+}
+
+// addRevertedTxns adds the given orphaned transactions, which could not be reverted, to the failed
+// transaction store.
+func (o *Observer) addRevertedTxns(txns []txn.SidetreeTxn, cause error) {
+	now := time.Now()
+
+	for i := range txns {
+		failedTxn := &FailedTxn{
+			Txn:         txns[i],
+			Attempts:    1,
+			FirstFailed: now,
+			Reverted:    true,
+		}
+
+		o.updateFailedTxn(failedTxn, cause, now)
+	}
+}
+
+// retryRevertedTxns retries (in reverse ledger order) the orphaned transactions that could not be reverted.
+// Retrying stops at the first transaction that can't be reverted (or whose backoff hasn't elapsed) since
+// transactions must be reverted in reverse ledger order. Returns true if no orphaned transactions are waiting
+// to be reverted.
+func (o *Observer) retryRevertedTxns(failedTxns []*FailedTxn) bool {
+	pending := pendingRevertedTxns(failedTxns)
+
+	now := time.Now()
+
+	for _, failedTxn := range pending {
+		if now.Before(failedTxn.NextRetry) {
+			return false
+		}
+
+		sidetreeTxn := failedTxn.Txn
+
+		logger.Info("Retrying revert of orphaned transaction", log.WithAnchorString(sidetreeTxn.AnchorString),
+			log.WithAttempt(failedTxn.Attempts+1))
+
+		if err := o.revertTxn(&sidetreeTxn); err != nil {
+			failedTxn.Attempts++
+
+			o.updateFailedTxn(failedTxn, err, time.Now())
+
+			return false
+		}
+
+		if err := o.failedTxnStore.Delete(failedTxn.ID()); err != nil {
+			logger.Warn("Failed to delete reverted transaction from failed transaction store",
+				log.WithAnchorString(sidetreeTxn.AnchorString), log.WithError(err))
+		}
+
+		o.rewindCheckpoint(&sidetreeTxn)
+	}
+
+	return true
+}
+
+// hasPendingRevert returns true if an orphaned transaction is waiting to be reverted.
+func (o *Observer) hasPendingRevert() bool {
+	failedTxns, err := o.failedTxnStore.GetAll()
+	if err != nil {
+		logger.Warn("Failed to retrieve failed transactions", log.WithError(err))
+
+		return false
+	}
+
+	return len(pendingRevertedTxns(failedTxns)) > 0
+}
+
+// pendingRevertedTxns returns the orphaned transactions that are waiting to be reverted, latest first.
+func pendingRevertedTxns(failedTxns []*FailedTxn) []*FailedTxn {
+	var pending []*FailedTxn
+
+	for _, failedTxn := range failedTxns {
+		if failedTxn.Reverted && failedTxn.Status == FailedTxnStatusPending {
+			pending = append(pending, failedTxn)
+		}
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return checkpointFor(&pending[j].Txn).before(&pending[i].Txn)
+	})
+
+	return pending
+}
+
+func (o *Observer) revertTxn(sidetreeTxn *txn.SidetreeTxn) error {
+	tp, err := o.getTxnProcessor(sidetreeTxn)
+	if err != nil {
+		return err
+	}
+
+	rtp, ok := tp.(revertingTxnProcessor)
+	if !ok {
+		return fmt.Errorf("transaction processor for namespace [%s] does not support reverting transactions",
+			sidetreeTxn.Namespace)
+	}
+
+	suffixes, err := rtp.Revert(sidetreeTxn)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Successfully reverted transaction", log.WithAnchorString(sidetreeTxn.AnchorString),
+		log.WithSuffixes(suffixes...))
+
+	return nil
+}
+
+// rewindCheckpoint moves the checkpoint to just before the given (earliest reverted) transaction if the
+// transaction was already processed.
+func (o *Observer) rewindCheckpoint(earliest *txn.SidetreeTxn) {
+	if o.checkpoint != nil && !o.checkpoint.before(earliest) {
+		o.checkpoint = checkpointBefore(earliest)
+	}
+
+	checkpoint, err := o.checkpointStore.Get()
+	if err != nil {
+		if !errors.Is(err, ErrCheckpointNotFound) {
+			logger.Warn("Failed to load checkpoint", log.WithError(err))
+		}
+
+		return
+	}
+
+	if checkpoint.before(earliest) {
+		return
+	}
+
+	rewound := checkpointBefore(earliest)
+
+	logger.Info("Rewinding checkpoint", log.WithTransactionTime(rewound.TransactionTime),
+		log.WithTransactionNumber(rewound.TransactionNumber))
+
+	if err := o.checkpointStore.Put(rewound); err != nil {
+		logger.Warn("Failed to save checkpoint", log.WithTransactionTime(rewound.TransactionTime),
+			log.WithTransactionNumber(rewound.TransactionNumber), log.WithError(err))
+	}
+}
+
+func checkpointFor(sidetreeTxn *txn.SidetreeTxn) *Checkpoint {
+	return &Checkpoint{
+		TransactionTime:   sidetreeTxn.TransactionTime,
+		TransactionNumber: sidetreeTxn.TransactionNumber,
+	}
+}
@@ -0,0 +1,398 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprocessor"
+)
+
+var _ RevertibleOperationStore = (*mockRevertibleOperationStore)(nil)
+
+func TestReorg(t *testing.T) {
+	const namespace = "ns1"
+
+	txnA := txn.SidetreeTxn{Namespace: namespace, TransactionTime: 10, TransactionNumber: 0, AnchorString: "a"}
+	txnB := txn.SidetreeTxn{Namespace: namespace, TransactionTime: 11, TransactionNumber: 1, AnchorString: "b"}
+	txnC := txn.SidetreeTxn{Namespace: namespace, TransactionTime: 12, TransactionNumber: 2, AnchorString: "c"}
+
+	newProviders := func(ledger Ledger, opStore txnprocessor.OperationStore, opts ...txnprocessor.Option) *Providers {
+		tp := txnprocessor.New(&txnprocessor.Providers{
+			OpStore:                   opStore,
+			OperationProtocolProvider: &mockAnchorTxnOpsProvider{},
+		}, opts...)
+
+		pc := mocks.NewMockProtocolClient()
+		pc.Versions[0].TransactionProcessorReturns(tp)
+
+		return &Providers{
+			Ledger:                 ledger,
+			ProtocolClientProvider: mocks.NewMockProtocolClientProvider().WithProtocolClient(namespace, pc),
+		}
+	}
+
+	t.Run("fork and rollback", func(t *testing.T) {
+		ledger := newMockReorgLedger()
+		opStore := newMockRevertibleOperationStore()
+		rollbackHandler := &mockRollbackHandler{}
+		checkpointStore := &mockCheckpointStore{}
+		failedTxnStore := newMemFailedTxnStore()
+
+		o := New(newProviders(ledger, opStore, txnprocessor.WithRollbackHandler(rollbackHandler)),
+			WithCheckpointStore(checkpointStore), WithFailedTxnStore(failedTxnStore))
+
+		o.Start()
+		defer o.Stop()
+
+		ledger.txnsCh <- []txn.SidetreeTxn{txnA, txnB, txnC}
+
+		require.Eventually(t, func() bool { return len(opStore.getSuffixes()) == 3 }, time.Second, 10*time.Millisecond)
+
+		// The failed transaction store holds an (older) failed attempt of a transaction that will be orphaned.
+		require.NoError(t, failedTxnStore.Put(&FailedTxn{Txn: txnC, Status: FailedTxnStatusPending}))
+
+		// Transactions B and C are orphaned and replaced by transactions B' and C' on the new fork.
+		txnB2 := txn.SidetreeTxn{Namespace: namespace, TransactionTime: 11, TransactionNumber: 1, AnchorString: "b2"}
+		txnC2 := txn.SidetreeTxn{Namespace: namespace, TransactionTime: 12, TransactionNumber: 2, AnchorString: "c2"}
+
+		ledger.revertedCh <- []txn.SidetreeTxn{txnB, txnC}
+		ledger.txnsCh <- []txn.SidetreeTxn{txnB2, txnC2}
+
+		require.Eventually(t, func() bool {
+			return len(opStore.getSuffixes()) == 3 && opStore.getSuffixes()[2] == "c2"
+		}, time.Second, 10*time.Millisecond)
+
+		require.Equal(t, []string{"a", "b2", "c2"}, opStore.getSuffixes())
+
+		// Transactions are reverted in reverse ledger order.
+		require.Equal(t, []string{"c", "b"}, rollbackHandler.getSuffixes())
+
+		failedTxns, err := o.FailedTransactions()
+		require.NoError(t, err)
+		require.Empty(t, failedTxns)
+
+		checkpoint, err := checkpointStore.Get()
+		require.NoError(t, err)
+		require.Equal(t, &Checkpoint{TransactionTime: 12, TransactionNumber: 2}, checkpoint)
+	})
+
+	t.Run("revert without replacement", func(t *testing.T) {
+		ledger := newMockReorgLedger()
+		opStore := newMockRevertibleOperationStore()
+		checkpointStore := &mockCheckpointStore{}
+		listener := &mockTxnListener{}
+
+		o := New(newProviders(ledger, opStore), WithCheckpointStore(checkpointStore), WithTxnListeners(listener))
+
+		o.Start()
+		defer o.Stop()
+
+		ledger.txnsCh <- []txn.SidetreeTxn{txnA, txnB, txnC}
+
+		require.Eventually(t, func() bool { return len(opStore.getSuffixes()) == 3 }, time.Second, 10*time.Millisecond)
+
+		ledger.revertedCh <- []txn.SidetreeTxn{txnC}
+
+		require.Eventually(t, func() bool { return len(opStore.getSuffixes()) == 2 }, time.Second, 10*time.Millisecond)
+
+		require.Equal(t, []string{"a", "b"}, opStore.getSuffixes())
+
+		// Listeners are notified of the orphaned transaction.
+		require.Equal(t, []string{"c"}, listener.getReverted())
+
+		require.Eventually(t, func() bool {
+			checkpoint, err := checkpointStore.Get()
+
+			return err == nil && *checkpoint == Checkpoint{TransactionTime: 12, TransactionNumber: 1}
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("reverted transaction notification channel closed", func(t *testing.T) {
+		ledger := newMockReorgLedger()
+		opStore := newMockRevertibleOperationStore()
+
+		o := New(newProviders(ledger, opStore))
+
+		o.Start()
+		defer o.Stop()
+
+		close(ledger.revertedCh)
+
+		ledger.txnsCh <- []txn.SidetreeTxn{txnA, txnB}
+
+		require.Eventually(t, func() bool { return len(opStore.getSuffixes()) == 2 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("revert error", func(t *testing.T) {
+		ledger := newMockReorgLedger()
+		opStore := newMockRevertibleOperationStore()
+		checkpointStore := &mockCheckpointStore{}
+
+		// The failed transaction store is set so that the checkpoint isn't held back by failed transactions.
+		o := New(newProviders(ledger, opStore), WithCheckpointStore(checkpointStore),
+			WithFailedTxnStore(newMemFailedTxnStore()),
+			WithRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond}), WithRetryInterval(10*time.Millisecond))
+
+		o.Start()
+		defer o.Stop()
+
+		ledger.txnsCh <- []txn.SidetreeTxn{txnA, txnB, txnC}
+
+		require.Eventually(t, func() bool { return len(opStore.getSuffixes()) == 3 }, time.Second, 10*time.Millisecond)
+
+		// Transaction C is reverted but transaction B can't be reverted.
+		opStore.setDeleteErr(txnB.TransactionTime, errors.New("injected delete error"))
+
+		txnB2 := txn.SidetreeTxn{Namespace: namespace, TransactionTime: 11, TransactionNumber: 1, AnchorString: "b2"}
+		txnC2 := txn.SidetreeTxn{Namespace: namespace, TransactionTime: 12, TransactionNumber: 2, AnchorString: "c2"}
+
+		ledger.revertedCh <- []txn.SidetreeTxn{txnB, txnC}
+		ledger.txnsCh <- []txn.SidetreeTxn{txnB2, txnC2}
+
+		require.Eventually(t, func() bool {
+			failedTxns, err := o.FailedTransactions()
+
+			return err == nil && len(failedTxns) == 3
+		}, time.Second, 10*time.Millisecond)
+
+		// The checkpoint isn't rewound past the transaction that could not be reverted.
+		checkpoint, err := checkpointStore.Get()
+		require.NoError(t, err)
+		require.Equal(t, &Checkpoint{TransactionTime: 12, TransactionNumber: 1}, checkpoint)
+
+		// The transactions that replace the orphaned transactions are held back until B has been reverted.
+		require.Equal(t, []string{"a", "b"}, opStore.getSuffixes())
+
+		failedTxns, err := o.FailedTransactions()
+		require.NoError(t, err)
+
+		for _, failedTxn := range failedTxns {
+			require.Equal(t, FailedTxnStatusPending, failedTxn.Status)
+
+			if failedTxn.Txn.AnchorString == "b" {
+				require.True(t, failedTxn.Reverted)
+				require.Contains(t, failedTxn.LastError, "injected delete error")
+			} else {
+				require.False(t, failedTxn.Reverted)
+				require.Contains(t, failedTxn.LastError, errRevertPending.Error())
+			}
+		}
+
+		opStore.setDeleteErr(txnB.TransactionTime, nil)
+
+		require.Eventually(t, func() bool {
+			failedTxns, err := o.FailedTransactions()
+
+			return err == nil && len(failedTxns) == 0
+		}, time.Second, 10*time.Millisecond)
+
+		require.Equal(t, []string{"a", "b2", "c2"}, opStore.getSuffixes())
+
+		checkpoint, err = checkpointStore.Get()
+		require.NoError(t, err)
+		require.Equal(t, &Checkpoint{TransactionTime: 11, TransactionNumber: 0}, checkpoint)
+	})
+
+	t.Run("operation store does not support delete", func(t *testing.T) {
+		opStore := &mockOrderedOperationStore{}
+		checkpointStore := &mockCheckpointStore{checkpoint: &Checkpoint{TransactionTime: 12, TransactionNumber: 2}}
+
+		o := New(newProviders(newMockReorgLedger(), opStore), WithCheckpointStore(checkpointStore))
+
+		o.revert([]txn.SidetreeTxn{txnB})
+
+		// The checkpoint isn't rewound since the operations could not be removed.
+		checkpoint, err := checkpointStore.Get()
+		require.NoError(t, err)
+		require.Equal(t, &Checkpoint{TransactionTime: 12, TransactionNumber: 2}, checkpoint)
+
+		failedTxns, err := o.FailedTransactions()
+		require.NoError(t, err)
+		require.Len(t, failedTxns, 1)
+		require.True(t, failedTxns[0].Reverted)
+		require.Contains(t, failedTxns[0].LastError, "operation store does not support deleting operations")
+	})
+
+	t.Run("transaction processor does not support revert", func(t *testing.T) {
+		tp := &mocks.TxnProcessor{}
+
+		pc := mocks.NewMockProtocolClient()
+		pc.Versions[0].TransactionProcessorReturns(tp)
+
+		providers := &Providers{
+			Ledger:                 newMockReorgLedger(),
+			ProtocolClientProvider: mocks.NewMockProtocolClientProvider().WithProtocolClient(namespace, pc),
+		}
+
+		o := New(providers)
+
+		err := o.revertTxn(&txnB)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not support reverting transactions")
+	})
+
+	t.Run("protocol client error", func(t *testing.T) {
+		o := New(newProviders(newMockReorgLedger(), newMockRevertibleOperationStore()))
+
+		err := o.revertTxn(&txn.SidetreeTxn{Namespace: "unknown", AnchorString: "a"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get protocol client for namespace [unknown]")
+	})
+
+	t.Run("checkpoint before reverted transaction", func(t *testing.T) {
+		checkpointStore := &mockCheckpointStore{checkpoint: &Checkpoint{TransactionTime: 10, TransactionNumber: 0}}
+
+		o := New(newProviders(newMockReorgLedger(), newMockRevertibleOperationStore()),
+			WithCheckpointStore(checkpointStore))
+
+		o.checkpoint = &Checkpoint{TransactionTime: 10, TransactionNumber: 0}
+
+		o.revert([]txn.SidetreeTxn{txnB})
+
+		checkpoint, err := checkpointStore.Get()
+		require.NoError(t, err)
+		require.Equal(t, &Checkpoint{TransactionTime: 10, TransactionNumber: 0}, checkpoint)
+		require.Equal(t, &Checkpoint{TransactionTime: 10, TransactionNumber: 0}, o.checkpoint)
+	})
+
+	t.Run("checkpoint loaded at startup is rewound", func(t *testing.T) {
+		o := New(newProviders(newMockReorgLedger(), newMockRevertibleOperationStore()))
+
+		o.checkpoint = &Checkpoint{TransactionTime: 12, TransactionNumber: 2}
+
+		o.revert([]txn.SidetreeTxn{txnC, txnB})
+
+		require.Equal(t, &Checkpoint{TransactionTime: 11, TransactionNumber: 0}, o.checkpoint)
+	})
+}
+
+type mockReorgLedger struct {
+	txnsCh     chan []txn.SidetreeTxn
+	revertedCh chan []txn.SidetreeTxn
+}
+
+func newMockReorgLedger() *mockReorgLedger {
+	return &mockReorgLedger{
+		txnsCh:     make(chan []txn.SidetreeTxn, 100),
+		revertedCh: make(chan []txn.SidetreeTxn, 100),
+	}
+}
+
+func (m *mockReorgLedger) RegisterForSidetreeTxn() <-chan []txn.SidetreeTxn {
+	return m.txnsCh
+}
+
+func (m *mockReorgLedger) RegisterForRevertedSidetreeTxn() <-chan []txn.SidetreeTxn {
+	return m.revertedCh
+}
+
+// mockRevertibleOperationStore stores one operation per suffix.
+type mockRevertibleOperationStore struct {
+	mutex      sync.Mutex
+	ops        map[string]*operation.AnchoredOperation
+	deleteErrs map[uint64]error
+}
+
+func newMockRevertibleOperationStore() *mockRevertibleOperationStore {
+	return &mockRevertibleOperationStore{
+		ops:        make(map[string]*operation.AnchoredOperation),
+		deleteErrs: make(map[uint64]error),
+	}
+}
+
+// setDeleteErr sets the error that is returned when deleting the operations of the given transaction time.
+func (m *mockRevertibleOperationStore) setDeleteErr(txnTime uint64, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.deleteErrs[txnTime] = err
+}
+
+func (m *mockRevertibleOperationStore) Put(ops []*operation.AnchoredOperation) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, op := range ops {
+		m.ops[op.UniqueSuffix] = op
+	}
+
+	return nil
+}
+
+func (m *mockRevertibleOperationStore) DeleteByTransaction(txnTime, txnNumber uint64) ([]*operation.AnchoredOperation, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := m.deleteErrs[txnTime]; err != nil {
+		return nil, err
+	}
+
+	var deleted []*operation.AnchoredOperation
+
+	for suffix, op := range m.ops {
+		if op.TransactionTime == txnTime && op.TransactionNumber == txnNumber {
+			deleted = append(deleted, op)
+
+			delete(m.ops, suffix)
+		}
+	}
+
+	return deleted, nil
+}
+
+func (m *mockRevertibleOperationStore) getSuffixes() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var suffixes []string
+
+	for suffix := range m.ops {
+		suffixes = append(suffixes, suffix)
+	}
+
+	sort.Strings(suffixes)
+
+	return suffixes
+}
+
+// mockAnchorTxnOpsProvider returns a single operation whose suffix is the anchor string of the transaction.
+type mockAnchorTxnOpsProvider struct{}
+
+func (m *mockAnchorTxnOpsProvider) GetTxnOperations(sidetreeTxn *txn.SidetreeTxn) ([]*operation.AnchoredOperation, error) {
+	return []*operation.AnchoredOperation{{UniqueSuffix: sidetreeTxn.AnchorString, Type: operation.TypeUpdate}}, nil
+}
+
+type mockRollbackHandler struct {
+	mutex    sync.Mutex
+	suffixes []string
+}
+
+func (m *mockRollbackHandler) Rollback(uniqueSuffixes ...string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.suffixes = append(m.suffixes, uniqueSuffixes...)
+
+	return nil
+}
+
+func (m *mockRollbackHandler) getSuffixes() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]string(nil), m.suffixes...)
+}
@@ -0,0 +1,293 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+)
+
+const (
+	defaultRetryInterval  = 10 * time.Second
+	defaultInitialBackoff = 10 * time.Second
+	defaultMaxBackoff     = 10 * time.Minute
+	defaultBackoffFactor  = 2
+	defaultMaxAttempts    = 10
+)
+
+// FailedTxnStatus defines valid values for the status of a failed transaction.
+type FailedTxnStatus string
+
+const (
+	// FailedTxnStatusPending indicates that the transaction will be retried.
+	FailedTxnStatusPending FailedTxnStatus = "pending"
+
+	// FailedTxnStatusFailed indicates that the maximum number of attempts has been reached and the transaction
+	// will no longer be retried.
+	FailedTxnStatusFailed FailedTxnStatus = "failed"
+)
+
+// FailedTxn holds a Sidetree transaction that could not be processed along with its retry status.
+type FailedTxn struct {
+	Txn         txn.SidetreeTxn `json:"txn"`
+	Status      FailedTxnStatus `json:"status"`
+	Attempts    int             `json:"attempts"`
+	LastError   string          `json:"lastError"`
+	FirstFailed time.Time       `json:"firstFailed"`
+	NextRetry   time.Time       `json:"nextRetry,omitempty"`
+	// Reverted indicates that the transaction was orphaned by a ledger reorganization but could not be
+	// reverted. Retrying the transaction reverts it.
+	Reverted bool `json:"reverted,omitempty"`
+}
+
+// ID returns the ID of the failed transaction.
+func (t *FailedTxn) ID() string {
+	return FailedTxnID(&t.Txn)
+}
+
+// FailedTxnID returns the ID of the given transaction in the failed transaction store.
+func FailedTxnID(sidetreeTxn *txn.SidetreeTxn) string {
+	return fmt.Sprintf("%s-%d-%d-%s", sidetreeTxn.Namespace, sidetreeTxn.TransactionTime,
+		sidetreeTxn.TransactionNumber, sidetreeTxn.AnchorString)
+}
+
+// FailedTxnStore persists transactions that could not be processed.
+type FailedTxnStore interface {
+	// Put saves the failed transaction.
+	Put(failedTxn *FailedTxn) error
+	// GetAll returns all failed transactions.
+	GetAll() ([]*FailedTxn, error)
+	// Delete deletes the failed transaction with the given ID.
+	Delete(id string) error
+}
+
+// RetryPolicy defines how failed transactions are retried.
+//
+// A failed transaction is first retried after InitialBackoff. The backoff is multiplied by BackoffFactor after
+// every failed attempt (up to MaxBackoff). Once the transaction has been attempted MaxAttempts times it is marked
+// as permanently failed and is no longer retried.
+type RetryPolicy struct {
+	// InitialBackoff is the time to wait before retrying a failed transaction for the first time.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum time to wait between retries.
+	MaxBackoff time.Duration
+	// BackoffFactor is the multiplier applied to the backoff after every failed attempt.
+	BackoffFactor float64
+	// MaxAttempts is the number of times that a transaction is attempted (including the initial attempt)
+	// before it is marked as permanently failed.
+	MaxAttempts int
+}
+
+// WithFailedTxnStore sets the store that holds transactions that could not be processed. The store should be
+// durable (see failedtxn.FileStore) if a checkpoint store is set. If not set then an in-memory store is used and,
+// since failed transactions are then lost on a restart, the checkpoint is not advanced past the earliest failed
+// transaction (including transactions that have permanently failed) until it has been processed successfully.
+func WithFailedTxnStore(store FailedTxnStore) Option {
+	return func(opts *Observer) {
+		opts.failedTxnStore = store
+	}
+}
+
+// WithRetryPolicy sets the policy for retrying failed transactions. Zero values are replaced with defaults.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(opts *Observer) {
+		opts.retryPolicy = policy
+	}
+}
+
+// WithRetryInterval sets the interval at which failed transactions are checked and, if their backoff has
+// elapsed, retried.
+func WithRetryInterval(interval time.Duration) Option {
+	return func(opts *Observer) {
+		opts.retryInterval = interval
+	}
+}
+
+// FailedTransactions returns the transactions that could not be processed, including the transactions that
+// have permanently failed.
+func (o *Observer) FailedTransactions() ([]*FailedTxn, error) {
+	return o.failedTxnStore.GetAll()
+}
+
+func (o *Observer) addFailedTxn(sidetreeTxn *txn.SidetreeTxn, cause error) {
+	now := time.Now()
+
+	failedTxn := &FailedTxn{
+		Txn:         *sidetreeTxn,
+		Attempts:    1,
+		FirstFailed: now,
+	}
+
+	o.updateFailedTxn(failedTxn, cause, now)
+}
+
+func (o *Observer) retryFailedTxns() {
+	failedTxns, err := o.failedTxnStore.GetAll()
+	if err != nil {
+		logger.Warn("Failed to retrieve failed transactions", log.WithError(err))
+
+		return
+	}
+
+	// Transactions that were anchored after an orphaned transaction may only be processed once the orphaned
+	// transaction has been reverted.
+	if !o.retryRevertedTxns(failedTxns) {
+		return
+	}
+
+	// Retry in the order in which the transactions were anchored.
+	sort.SliceStable(failedTxns, func(i, j int) bool {
+		ti, tj := failedTxns[i].Txn, failedTxns[j].Txn
+		if ti.TransactionTime != tj.TransactionTime {
+			return ti.TransactionTime < tj.TransactionTime
+		}
+
+		return ti.TransactionNumber < tj.TransactionNumber
+	})
+
+	now := time.Now()
+
+	for _, failedTxn := range failedTxns {
+		if failedTxn.Reverted || failedTxn.Status != FailedTxnStatusPending || now.Before(failedTxn.NextRetry) {
+			continue
+		}
+
+		o.retryFailedTxn(failedTxn)
+	}
+}
+
+func (o *Observer) retryFailedTxn(failedTxn *FailedTxn) {
+	sidetreeTxn := failedTxn.Txn
+
+	logger.Info("Retrying failed transaction", log.WithAnchorString(sidetreeTxn.AnchorString),
+		log.WithAttempt(failedTxn.Attempts+1))
+
+	err := o.processTxn(&sidetreeTxn)
+	if err == nil {
+		logger.Info("Successfully processed failed transaction", log.WithAnchorString(sidetreeTxn.AnchorString))
+
+		if err := o.failedTxnStore.Delete(failedTxn.ID()); err != nil {
+			logger.Warn("Failed to delete transaction from failed transaction store",
+				log.WithAnchorString(sidetreeTxn.AnchorString), log.WithError(err))
+		}
+
+		return
+	}
+
+	failedTxn.Attempts++
+
+	o.updateFailedTxn(failedTxn, err, time.Now())
+}
+
+func (o *Observer) updateFailedTxn(failedTxn *FailedTxn, cause error, now time.Time) {
+	failedTxn.LastError = cause.Error()
+
+	if failedTxn.Attempts >= o.retryPolicy.MaxAttempts {
+		logger.Error("Transaction has permanently failed. It will no longer be retried.",
+			log.WithAnchorString(failedTxn.Txn.AnchorString), log.WithAttempt(failedTxn.Attempts), log.WithError(cause))
+
+		failedTxn.Status = FailedTxnStatusFailed
+		failedTxn.NextRetry = time.Time{}
+	} else {
+		backoff := o.retryPolicy.backoff(failedTxn.Attempts)
+
+		logger.Warn("Failed to process transaction. The transaction will be retried after backoff.",
+			log.WithAnchorString(failedTxn.Txn.AnchorString), log.WithAttempt(failedTxn.Attempts),
+			log.WithBackoff(backoff), log.WithError(cause))
+
+		failedTxn.Status = FailedTxnStatusPending
+		failedTxn.NextRetry = now.Add(backoff)
+	}
+
+	if err := o.failedTxnStore.Put(failedTxn); err != nil {
+		logger.Error("Failed to store failed transaction", log.WithAnchorString(failedTxn.Txn.AnchorString),
+			log.WithError(err))
+	}
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaultInitialBackoff
+	}
+
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultMaxBackoff
+	}
+
+	if p.BackoffFactor <= 0 {
+		p.BackoffFactor = defaultBackoffFactor
+	}
+
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultMaxAttempts
+	}
+
+	return p
+}
+
+// backoff returns the time to wait after the given number of failed attempts.
+func (p RetryPolicy) backoff(attempts int) time.Duration {
+	backoff := float64(p.InitialBackoff)
+
+	for i := 1; i < attempts; i++ {
+		backoff *= p.BackoffFactor
+
+		if backoff >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+
+	return time.Duration(backoff)
+}
+
+// memFailedTxnStore is the default, in-memory failed transaction store.
+type memFailedTxnStore struct {
+	mutex sync.RWMutex
+	txns  map[string]*FailedTxn
+}
+
+func newMemFailedTxnStore() *memFailedTxnStore {
+	return &memFailedTxnStore{txns: make(map[string]*FailedTxn)}
+}
+
+func (s *memFailedTxnStore) Put(failedTxn *FailedTxn) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	txnCopy := *failedTxn
+	s.txns[failedTxn.ID()] = &txnCopy
+
+	return nil
+}
+
+func (s *memFailedTxnStore) GetAll() ([]*FailedTxn, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	failedTxns := make([]*FailedTxn, 0, len(s.txns))
+
+	for _, failedTxn := range s.txns {
+		txnCopy := *failedTxn
+		failedTxns = append(failedTxns, &txnCopy)
+	}
+
+	return failedTxns, nil
+}
+
+func (s *memFailedTxnStore) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.txns, id)
+
+	return nil
+}
@@ -0,0 +1,226 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+)
+
+func TestRetryFailedTxns(t *testing.T) {
+	const namespace = "ns1"
+
+	newProviders := func(ch chan []txn.SidetreeTxn, tp *mocks.TxnProcessor) *Providers {
+		pc := mocks.NewMockProtocolClient()
+		pc.Versions[0].TransactionProcessorReturns(tp)
+
+		return &Providers{
+			Ledger:                 mockLedger{registerForSidetreeTxnValue: ch},
+			ProtocolClientProvider: mocks.NewMockProtocolClientProvider().WithProtocolClient(namespace, pc),
+		}
+	}
+
+	sidetreeTxn := txn.SidetreeTxn{Namespace: namespace, TransactionTime: 10, TransactionNumber: 1, AnchorString: "1.address"}
+
+	errExpected := errors.New("injected processing error")
+
+	t.Run("success after retry", func(t *testing.T) {
+		sidetreeTxnCh := make(chan []txn.SidetreeTxn, 100)
+
+		tp := &mocks.TxnProcessor{}
+		tp.ProcessReturnsOnCall(0, 0, errExpected)
+		tp.ProcessReturnsOnCall(1, 0, errExpected)
+
+		checkpointStore := &mockCheckpointStore{}
+
+		o := New(newProviders(sidetreeTxnCh, tp),
+			WithRetryInterval(10*time.Millisecond),
+			WithRetryPolicy(RetryPolicy{InitialBackoff: 50 * time.Millisecond}),
+			WithCheckpointStore(checkpointStore),
+		)
+
+		o.Start()
+		defer o.Stop()
+
+		sidetreeTxnCh <- []txn.SidetreeTxn{sidetreeTxn}
+		time.Sleep(5 * time.Millisecond)
+
+		failedTxns, err := o.FailedTransactions()
+		require.NoError(t, err)
+		require.Len(t, failedTxns, 1)
+		require.Equal(t, sidetreeTxn, failedTxns[0].Txn)
+		require.Equal(t, FailedTxnStatusPending, failedTxns[0].Status)
+		require.Equal(t, 1, failedTxns[0].Attempts)
+		require.Equal(t, errExpected.Error(), failedTxns[0].LastError)
+
+		time.Sleep(400 * time.Millisecond)
+
+		require.Equal(t, 3, tp.ProcessCallCount())
+
+		failedTxns, err = o.FailedTransactions()
+		require.NoError(t, err)
+		require.Empty(t, failedTxns)
+
+		// The checkpoint is only updated by transactions received from the ledger.
+		_, err = checkpointStore.Get()
+		require.ErrorIs(t, err, ErrCheckpointNotFound)
+	})
+
+	t.Run("permanent failure", func(t *testing.T) {
+		sidetreeTxnCh := make(chan []txn.SidetreeTxn, 100)
+
+		tp := &mocks.TxnProcessor{}
+		tp.ProcessReturns(0, errExpected)
+
+		o := New(newProviders(sidetreeTxnCh, tp),
+			WithRetryInterval(10*time.Millisecond),
+			WithRetryPolicy(RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxAttempts: 3}),
+		)
+
+		o.Start()
+		defer o.Stop()
+
+		sidetreeTxnCh <- []txn.SidetreeTxn{sidetreeTxn}
+		time.Sleep(300 * time.Millisecond)
+
+		require.Equal(t, 3, tp.ProcessCallCount())
+
+		failedTxns, err := o.FailedTransactions()
+		require.NoError(t, err)
+		require.Len(t, failedTxns, 1)
+		require.Equal(t, FailedTxnStatusFailed, failedTxns[0].Status)
+		require.Equal(t, 3, failedTxns[0].Attempts)
+		require.True(t, failedTxns[0].NextRetry.IsZero())
+	})
+
+	t.Run("retry error - protocol client not found", func(t *testing.T) {
+		o := New(newProviders(nil, &mocks.TxnProcessor{}),
+			WithRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond}),
+		)
+
+		o.addFailedTxn(&txn.SidetreeTxn{Namespace: "ns2"}, errExpected)
+
+		time.Sleep(5 * time.Millisecond)
+
+		o.retryFailedTxns()
+
+		failedTxns, err := o.FailedTransactions()
+		require.NoError(t, err)
+		require.Len(t, failedTxns, 1)
+		require.Equal(t, 2, failedTxns[0].Attempts)
+		require.Contains(t, failedTxns[0].LastError, "get protocol client for namespace [ns2]")
+	})
+
+	t.Run("checkpoint is not advanced past in-memory failed transaction", func(t *testing.T) {
+		txn1 := txn.SidetreeTxn{Namespace: namespace, TransactionTime: 10, TransactionNumber: 1, AnchorString: "1.a"}
+		txn2 := txn.SidetreeTxn{Namespace: namespace, TransactionTime: 11, TransactionNumber: 2, AnchorString: "1.b"}
+
+		tp := &mocks.TxnProcessor{}
+		tp.ProcessReturnsOnCall(0, 0, errExpected)
+
+		checkpointStore := &mockCheckpointStore{}
+
+		o := New(newProviders(nil, tp),
+			WithRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond}),
+			WithCheckpointStore(checkpointStore),
+		)
+
+		o.process([]txn.SidetreeTxn{txn1, txn2})
+
+		checkpoint, err := checkpointStore.Get()
+		require.NoError(t, err)
+		require.Equal(t, &Checkpoint{TransactionTime: 10, TransactionNumber: 0}, checkpoint)
+
+		time.Sleep(5 * time.Millisecond)
+
+		o.retryFailedTxns()
+
+		checkpoint, err = checkpointStore.Get()
+		require.NoError(t, err)
+		require.Equal(t, &Checkpoint{TransactionTime: 11, TransactionNumber: 2}, checkpoint)
+	})
+
+	t.Run("checkpoint is advanced past failed transaction in provided store", func(t *testing.T) {
+		txn1 := txn.SidetreeTxn{Namespace: namespace, TransactionTime: 10, TransactionNumber: 1, AnchorString: "1.a"}
+		txn2 := txn.SidetreeTxn{Namespace: namespace, TransactionTime: 11, TransactionNumber: 2, AnchorString: "1.b"}
+
+		tp := &mocks.TxnProcessor{}
+		tp.ProcessReturnsOnCall(0, 0, errExpected)
+
+		checkpointStore := &mockCheckpointStore{}
+
+		o := New(newProviders(nil, tp),
+			WithCheckpointStore(checkpointStore),
+			WithFailedTxnStore(newMemFailedTxnStore()),
+		)
+
+		o.process([]txn.SidetreeTxn{txn1, txn2})
+
+		checkpoint, err := checkpointStore.Get()
+		require.NoError(t, err)
+		require.Equal(t, &Checkpoint{TransactionTime: 11, TransactionNumber: 2}, checkpoint)
+	})
+
+	t.Run("failed transaction store errors", func(t *testing.T) {
+		tp := &mocks.TxnProcessor{}
+
+		store := &mockFailedTxnStore{err: errors.New("injected store error")}
+
+		o := New(newProviders(nil, tp), WithFailedTxnStore(store))
+
+		o.addFailedTxn(&sidetreeTxn, errExpected)
+		o.retryFailedTxns()
+
+		require.Zero(t, tp.ProcessCallCount())
+
+		store.err = nil
+		store.txns = []*FailedTxn{{Txn: sidetreeTxn, Status: FailedTxnStatusPending}}
+		store.deleteErr = errors.New("injected delete error")
+
+		o.retryFailedTxns()
+
+		require.Equal(t, 1, tp.ProcessCallCount())
+	})
+}
+
+func TestRetryPolicy(t *testing.T) {
+	p := RetryPolicy{}.withDefaults()
+	require.Equal(t, defaultInitialBackoff, p.InitialBackoff)
+	require.Equal(t, defaultMaxBackoff, p.MaxBackoff)
+	require.Equal(t, float64(defaultBackoffFactor), p.BackoffFactor)
+	require.Equal(t, defaultMaxAttempts, p.MaxAttempts)
+
+	p = RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, BackoffFactor: 3}.withDefaults()
+	require.Equal(t, time.Second, p.backoff(1))
+	require.Equal(t, 3*time.Second, p.backoff(2))
+	require.Equal(t, 5*time.Second, p.backoff(3))
+	require.Equal(t, 5*time.Second, p.backoff(10))
+}
+
+type mockFailedTxnStore struct {
+	txns      []*FailedTxn
+	err       error
+	deleteErr error
+}
+
+func (m *mockFailedTxnStore) Put(*FailedTxn) error {
+	return m.err
+}
+
+func (m *mockFailedTxnStore) GetAll() ([]*FailedTxn, error) {
+	return m.txns, m.err
+}
+
+func (m *mockFailedTxnStore) Delete(string) error {
+	return m.deleteErr
+}
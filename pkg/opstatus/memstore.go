@@ -0,0 +1,49 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opstatus
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MemStore is an in-memory operation status store.
+type MemStore struct {
+	mutex    sync.RWMutex
+	statuses map[string]*OperationStatus
+}
+
+// NewMemStore returns a new in-memory operation status store.
+func NewMemStore() *MemStore {
+	return &MemStore{statuses: make(map[string]*OperationStatus)}
+}
+
+// Put saves the given operation status.
+func (s *MemStore) Put(status *OperationStatus) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	statusCopy := *status
+	s.statuses[status.Hash] = &statusCopy
+
+	return nil
+}
+
+// Get returns the status for the given operation hash.
+func (s *MemStore) Get(hash string) (*OperationStatus, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	status, ok := s.statuses[hash]
+	if !ok {
+		return nil, fmt.Errorf("operation status for hash [%s] not found", hash)
+	}
+
+	statusCopy := *status
+
+	return &statusCopy, nil
+}
@@ -0,0 +1,211 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package opstatus tracks the status of an operation from the time it is submitted until it is anchored
+// and observed on the anchoring system.
+//
+// Operations are keyed by operation hash which is the base64url encoded SHA2-256 multihash of the
+// JCS canonicalized operation request (see GetOperationHash). The following status transitions are
+// supported:
+//
+// queued -> batched -> anchored -> confirmed
+//
+// An operation may transition to 'failed' from any state other than 'confirmed'. A failed operation may be
+// queued again (i.e. when the same request is re-submitted).
+//
+// An anchored or confirmed operation transitions to 'reverted' if its transaction is orphaned by a ledger
+// reorganization. A reverted operation may be confirmed again (i.e. when the transaction is included in the new
+// fork) or queued again.
+package opstatus
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/hashing"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+)
+
+var logger = log.New("sidetree-core-opstatus")
+
+const sha2_256 = 18
+
+// Status defines valid values for operation status.
+type Status string
+
+const (
+	// StatusQueued indicates that the operation has been validated and added to the batch queue.
+	StatusQueued Status = "queued"
+
+	// StatusBatched indicates that the operation has been written to batch files in CAS.
+	StatusBatched Status = "batched"
+
+	// StatusAnchored indicates that the anchor string for the batch containing the operation has been written
+	// to the anchoring system.
+	StatusAnchored Status = "anchored"
+
+	// StatusConfirmed indicates that the operation has been observed on the anchoring system and persisted
+	// to the operation store.
+	StatusConfirmed Status = "confirmed"
+
+	// StatusFailed indicates that the operation was discarded. The reason is provided in OperationStatus.Reason.
+	StatusFailed Status = "failed"
+
+	// StatusReverted indicates that the transaction containing the operation was orphaned by a ledger
+	// reorganization and the operation was removed from the operation store.
+	StatusReverted Status = "reverted"
+)
+
+// OperationStatus holds the status of an operation.
+type OperationStatus struct {
+	Hash               string         `json:"hash"`
+	UniqueSuffix       string         `json:"didSuffix,omitempty"`
+	Type               operation.Type `json:"type,omitempty"`
+	Status             Status         `json:"status"`
+	Reason             string         `json:"reason,omitempty"`
+	AnchorString       string         `json:"anchorString,omitempty"`
+	TransactionTime    uint64         `json:"transactionTime,omitempty"`
+	TransactionNumber  uint64         `json:"transactionNumber,omitempty"`
+	CanonicalReference string         `json:"canonicalReference,omitempty"`
+	Updated            time.Time      `json:"updated"`
+}
+
+// Store persists operation status.
+type Store interface {
+	// Put saves the given operation status.
+	Put(status *OperationStatus) error
+	// Get returns the status for the given operation hash. An error containing "not found" is returned
+	// if the operation is not in the store.
+	Get(hash string) (*OperationStatus, error)
+}
+
+// UpdateOption sets additional information when updating the status of an operation.
+type UpdateOption func(s *OperationStatus)
+
+// WithType sets the operation type.
+func WithType(opType operation.Type) UpdateOption {
+	return func(s *OperationStatus) {
+		s.Type = opType
+	}
+}
+
+// WithReason sets the reason for the status (e.g. the reason that the operation failed).
+func WithReason(reason string) UpdateOption {
+	return func(s *OperationStatus) {
+		s.Reason = reason
+	}
+}
+
+// WithAnchorString sets the anchor string of the batch that contains the operation.
+func WithAnchorString(anchorString string) UpdateOption {
+	return func(s *OperationStatus) {
+		s.AnchorString = anchorString
+	}
+}
+
+// WithTransaction sets the anchoring system transaction info for the operation.
+func WithTransaction(txnTime, txnNumber uint64, canonicalReference string) UpdateOption {
+	return func(s *OperationStatus) {
+		s.TransactionTime = txnTime
+		s.TransactionNumber = txnNumber
+		s.CanonicalReference = canonicalReference
+	}
+}
+
+// Tracker updates operation status in the underlying store.
+type Tracker struct {
+	store Store
+	mutex sync.Mutex
+}
+
+// New returns a new operation status tracker.
+func New(store Store) *Tracker {
+	return &Tracker{store: store}
+}
+
+// Update sets the status of the given operation request. Errors are logged since a failure to
+// track the status of an operation should not affect the processing of the operation.
+func (t *Tracker) Update(request []byte, suffix string, status Status, opts ...UpdateOption) {
+	hash, err := GetOperationHash(request)
+	if err != nil {
+		logger.Warn("Unable to compute operation hash for status update", log.WithSuffix(suffix), log.WithError(err))
+
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	current, err := t.store.Get(hash)
+	if err != nil {
+		current = &OperationStatus{Hash: hash}
+	} else if !isValidTransition(current.Status, status) {
+		logger.Debug("Ignoring invalid operation status transition", log.WithOperationID(hash), log.WithSuffix(suffix),
+			log.WithStatus(string(status)))
+
+		return
+	}
+
+	updated := *current
+	updated.UniqueSuffix = suffix
+	updated.Status = status
+	updated.Reason = ""
+	updated.Updated = time.Now()
+
+	for _, opt := range opts {
+		opt(&updated)
+	}
+
+	if err := t.store.Put(&updated); err != nil {
+		logger.Warn("Failed to store operation status", log.WithOperationID(hash), log.WithSuffix(suffix),
+			log.WithStatus(string(status)), log.WithError(err))
+
+		return
+	}
+
+	logger.Debug("Updated operation status", log.WithOperationID(hash), log.WithSuffix(suffix),
+		log.WithStatus(string(status)))
+}
+
+// Get returns the status of the operation with the given hash.
+func (t *Tracker) Get(hash string) (*OperationStatus, error) {
+	return t.store.Get(hash)
+}
+
+// GetOperationHash returns the operation hash for the given operation request. The request is canonicalized
+// first so that the same hash is computed for the submitted request and the request assembled from batch files.
+func GetOperationHash(request []byte) (string, error) {
+	hash, err := hashing.CalculateModelMultihash(request, sha2_256)
+	if err != nil {
+		return "", fmt.Errorf("calculate operation hash: %w", err)
+	}
+
+	return hash, nil
+}
+
+var statusRank = map[Status]int{
+	StatusQueued:    1,
+	StatusBatched:   2,
+	StatusAnchored:  3,
+	StatusConfirmed: 4,
+}
+
+func isValidTransition(from, to Status) bool {
+	switch {
+	case to == StatusReverted:
+		return from == StatusAnchored || from == StatusConfirmed || from == StatusReverted
+	case from == StatusConfirmed:
+		return to == StatusConfirmed
+	case to == StatusFailed:
+		return true
+	case from == StatusFailed, from == StatusReverted:
+		return to == StatusQueued || to == StatusConfirmed
+	default:
+		return statusRank[to] >= statusRank[from]
+	}
+}
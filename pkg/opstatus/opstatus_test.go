@@ -0,0 +1,223 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opstatus
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/client"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/model"
+)
+
+const suffix = "abc"
+
+func TestTracker_Update(t *testing.T) {
+	request := []byte(`{"type":"update","didSuffix":"abc","revealValue":"reveal"}`)
+
+	hash, err := GetOperationHash(request)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		tracker := New(NewMemStore())
+
+		tracker.Update(request, suffix, StatusQueued, WithType(operation.TypeUpdate))
+
+		status, err := tracker.Get(hash)
+		require.NoError(t, err)
+		require.Equal(t, hash, status.Hash)
+		require.Equal(t, suffix, status.UniqueSuffix)
+		require.Equal(t, operation.TypeUpdate, status.Type)
+		require.Equal(t, StatusQueued, status.Status)
+		require.False(t, status.Updated.IsZero())
+
+		tracker.Update(request, suffix, StatusBatched, WithAnchorString("1.anchor"))
+		tracker.Update(request, suffix, StatusAnchored, WithAnchorString("1.anchor"))
+		tracker.Update(request, suffix, StatusConfirmed, WithTransaction(10, 2, "ref"))
+
+		status, err = tracker.Get(hash)
+		require.NoError(t, err)
+		require.Equal(t, StatusConfirmed, status.Status)
+		require.Equal(t, operation.TypeUpdate, status.Type)
+		require.Equal(t, "1.anchor", status.AnchorString)
+		require.Equal(t, uint64(10), status.TransactionTime)
+		require.Equal(t, uint64(2), status.TransactionNumber)
+		require.Equal(t, "ref", status.CanonicalReference)
+	})
+
+	t.Run("invalid transition is ignored", func(t *testing.T) {
+		tracker := New(NewMemStore())
+
+		tracker.Update(request, suffix, StatusAnchored)
+		tracker.Update(request, suffix, StatusQueued)
+
+		status, err := tracker.Get(hash)
+		require.NoError(t, err)
+		require.Equal(t, StatusAnchored, status.Status)
+
+		tracker.Update(request, suffix, StatusConfirmed)
+		tracker.Update(request, suffix, StatusFailed, WithReason("expired"))
+
+		status, err = tracker.Get(hash)
+		require.NoError(t, err)
+		require.Equal(t, StatusConfirmed, status.Status)
+		require.Empty(t, status.Reason)
+	})
+
+	t.Run("failed and re-queued", func(t *testing.T) {
+		tracker := New(NewMemStore())
+
+		tracker.Update(request, suffix, StatusBatched)
+		tracker.Update(request, suffix, StatusFailed, WithReason("expired"))
+
+		status, err := tracker.Get(hash)
+		require.NoError(t, err)
+		require.Equal(t, StatusFailed, status.Status)
+		require.Equal(t, "expired", status.Reason)
+
+		tracker.Update(request, suffix, StatusQueued)
+
+		status, err = tracker.Get(hash)
+		require.NoError(t, err)
+		require.Equal(t, StatusQueued, status.Status)
+		require.Empty(t, status.Reason)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		store := NewMemStore()
+
+		New(store).Update([]byte("invalid"), suffix, StatusQueued)
+
+		require.Empty(t, store.statuses)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		tracker := New(&mockStore{err: errors.New("injected store error")})
+
+		tracker.Update(request, suffix, StatusQueued)
+
+		_, err := tracker.Get(hash)
+		require.Error(t, err)
+	})
+}
+
+func TestGetOperationHash(t *testing.T) {
+	t.Run("submitted and anchored requests have the same hash", func(t *testing.T) {
+		request, err := client.NewDeactivateRequest(&client.DeactivateRequestInfo{
+			DidSuffix:   suffix,
+			RevealValue: "reveal",
+			RecoveryKey: &jws.JWK{Kty: "EC", Crv: "P-256", X: "x", Y: "y"},
+			Signer:      &mockSigner{},
+		})
+		require.NoError(t, err)
+
+		deactivateRequest := &model.DeactivateRequest{}
+		require.NoError(t, json.Unmarshal(request, deactivateRequest))
+
+		anchoredOp, err := model.GetAnchoredOperation(&model.Operation{
+			Type:         operation.TypeDeactivate,
+			UniqueSuffix: deactivateRequest.DidSuffix,
+			RevealValue:  deactivateRequest.RevealValue,
+			SignedData:   deactivateRequest.SignedData,
+		})
+		require.NoError(t, err)
+
+		submittedHash, err := GetOperationHash(request)
+		require.NoError(t, err)
+
+		anchoredHash, err := GetOperationHash(anchoredOp.OperationRequest)
+		require.NoError(t, err)
+
+		require.Equal(t, submittedHash, anchoredHash)
+	})
+
+	t.Run("field order and whitespace are ignored", func(t *testing.T) {
+		hash1, err := GetOperationHash([]byte(`{"type":"update","didSuffix":"abc"}`))
+		require.NoError(t, err)
+
+		hash2, err := GetOperationHash([]byte(`{ "didSuffix": "abc", "type": "update" }`))
+		require.NoError(t, err)
+
+		require.Equal(t, hash1, hash2)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := GetOperationHash([]byte("invalid"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "calculate operation hash")
+	})
+}
+
+func TestIsValidTransition(t *testing.T) {
+	require.True(t, isValidTransition(StatusQueued, StatusQueued))
+	require.True(t, isValidTransition(StatusQueued, StatusBatched))
+	require.True(t, isValidTransition(StatusQueued, StatusConfirmed))
+	require.True(t, isValidTransition(StatusBatched, StatusAnchored))
+	require.True(t, isValidTransition(StatusAnchored, StatusFailed))
+	require.True(t, isValidTransition(StatusFailed, StatusQueued))
+	require.True(t, isValidTransition(StatusFailed, StatusConfirmed))
+	require.True(t, isValidTransition(StatusConfirmed, StatusConfirmed))
+	require.True(t, isValidTransition(StatusAnchored, StatusReverted))
+	require.True(t, isValidTransition(StatusConfirmed, StatusReverted))
+	require.True(t, isValidTransition(StatusReverted, StatusConfirmed))
+	require.True(t, isValidTransition(StatusReverted, StatusQueued))
+	require.True(t, isValidTransition(StatusReverted, StatusFailed))
+
+	require.False(t, isValidTransition(StatusBatched, StatusQueued))
+	require.False(t, isValidTransition(StatusAnchored, StatusBatched))
+	require.False(t, isValidTransition(StatusFailed, StatusBatched))
+	require.False(t, isValidTransition(StatusConfirmed, StatusFailed))
+	require.False(t, isValidTransition(StatusConfirmed, StatusQueued))
+	require.False(t, isValidTransition(StatusQueued, StatusReverted))
+	require.False(t, isValidTransition(StatusFailed, StatusReverted))
+	require.False(t, isValidTransition(StatusReverted, StatusBatched))
+}
+
+func TestMemStore(t *testing.T) {
+	store := NewMemStore()
+
+	_, err := store.Get("hash")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not found")
+
+	status := &OperationStatus{Hash: "hash", Status: StatusQueued}
+	require.NoError(t, store.Put(status))
+
+	// Modifying the original should not affect the stored value.
+	status.Status = StatusFailed
+
+	stored, err := store.Get("hash")
+	require.NoError(t, err)
+	require.Equal(t, StatusQueued, stored.Status)
+}
+
+type mockStore struct {
+	err error
+}
+
+func (m *mockStore) Put(*OperationStatus) error {
+	return m.err
+}
+
+func (m *mockStore) Get(hash string) (*OperationStatus, error) {
+	return nil, errors.New("not found")
+}
+
+type mockSigner struct{}
+
+func (s *mockSigner) Headers() jws.Headers {
+	return jws.Headers{jws.HeaderAlgorithm: "alg"}
+}
+
+func (s *mockSigner) Sign(data []byte) ([]byte, error) {
+	return []byte("signature"), nil
+}
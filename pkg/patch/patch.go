@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
@@ -103,7 +104,18 @@ func PatchesFromDocument(doc string) ([]Patch, error) {
 	var docPatches []Patch
 	var jsonPatches []string
 
-	for key, value := range parsed {
+	// Process the properties in a deterministic order so that the same document always results
+	// in the same patches (and therefore the same delta hash).
+	keys := make([]string, 0, len(parsed))
+	for key := range parsed {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := parsed[key]
+
 		jsonBytes, err := json.Marshal(value)
 		if err != nil {
 			return nil, err
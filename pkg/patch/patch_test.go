@@ -77,6 +77,16 @@ func TestPatchesFromDocument(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 3, len(patches))
 	})
+	t.Run("success - deterministic order", func(t *testing.T) {
+		expected, err := PatchesFromDocument(testDoc)
+		require.NoError(t, err)
+
+		for i := 0; i < 10; i++ {
+			patches, err := PatchesFromDocument(testDoc)
+			require.NoError(t, err)
+			require.Equal(t, expected, patches)
+		}
+	})
 	t.Run("success from new with also known as", func(t *testing.T) {
 		patches, err := PatchesFromDocument(testDocWithAlsoKnownAs)
 		require.NoError(t, err)
@@ -0,0 +1,201 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processor
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+// ResolutionCache is an LRU cache of resolution models keyed by unique suffix. When the cache is full, the least
+// recently used entry is evicted.
+//
+// The cache must be invalidated (see Invalidate) whenever operations for a suffix are added to (or removed from)
+// the operation store or the unpublished operation store. To avoid caching a stale resolution model that was
+// computed concurrently with an invalidation, a resolution model is only added to the cache if no invalidation
+// occurred since the generation (see Generation) was retrieved.
+type ResolutionCache struct {
+	maxEntries int
+
+	mutex      sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	generation uint64
+}
+
+type cacheEntry struct {
+	uniqueSuffix string
+	rm           *protocol.ResolutionModel
+}
+
+// NewResolutionCache returns a new resolution cache that holds at most maxEntries resolution models.
+func NewResolutionCache(maxEntries int) *ResolutionCache {
+	return &ResolutionCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Get returns a copy of the cached resolution model for the given suffix. The caller may modify the returned
+// resolution model (including the document) without affecting the cache.
+func (c *ResolutionCache) Get(uniqueSuffix string) (*protocol.ResolutionModel, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.entries[uniqueSuffix]
+	if !ok {
+		return nil, false
+	}
+
+	c.lru.MoveToFront(e)
+
+	return copyResolutionModel(e.Value.(*cacheEntry).rm), true
+}
+
+// Generation returns the current generation of the cache. The generation changes every time
+// the cache is invalidated.
+func (c *ResolutionCache) Generation() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generation
+}
+
+// Put adds a copy of the resolution model for the given suffix to the cache. The resolution model is not added if
+// the cache was invalidated after the given generation was retrieved, in which case false is returned.
+func (c *ResolutionCache) Put(uniqueSuffix string, rm *protocol.ResolutionModel, generation uint64) bool {
+	if c.maxEntries <= 0 {
+		return false
+	}
+
+	rm = copyResolutionModel(rm)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation != c.generation {
+		return false
+	}
+
+	if e, ok := c.entries[uniqueSuffix]; ok {
+		e.Value.(*cacheEntry).rm = rm
+		c.lru.MoveToFront(e)
+
+		return true
+	}
+
+	c.entries[uniqueSuffix] = c.lru.PushFront(&cacheEntry{uniqueSuffix: uniqueSuffix, rm: rm})
+
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).uniqueSuffix)
+	}
+
+	return true
+}
+
+// Invalidate removes the given suffixes from the cache.
+func (c *ResolutionCache) Invalidate(uniqueSuffixes ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+
+	for _, uniqueSuffix := range uniqueSuffixes {
+		if e, ok := c.entries[uniqueSuffix]; ok {
+			c.lru.Remove(e)
+			delete(c.entries, uniqueSuffix)
+		}
+	}
+}
+
+// Len returns the number of entries in the cache.
+func (c *ResolutionCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.lru.Len()
+}
+
+// copyResolutionModel returns a copy of the given resolution model. The document (along with any nested objects
+// and arrays) and the slices are copied so that they are not shared with the copy. The operations themselves
+// are not copied.
+func copyResolutionModel(rm *protocol.ResolutionModel) *protocol.ResolutionModel {
+	rmCopy := *rm
+
+	if rm.Doc != nil {
+		rmCopy.Doc = copyObject(rm.Doc)
+	}
+
+	rmCopy.AnchorOrigin = copyValue(rm.AnchorOrigin)
+
+	if rm.EquivalentReferences != nil {
+		rmCopy.EquivalentReferences = append([]string{}, rm.EquivalentReferences...)
+	}
+
+	if rm.PublishedOperations != nil {
+		rmCopy.PublishedOperations = append([]*operation.AnchoredOperation{}, rm.PublishedOperations...)
+	}
+
+	if rm.UnpublishedOperations != nil {
+		rmCopy.UnpublishedOperations = append([]*operation.AnchoredOperation{}, rm.UnpublishedOperations...)
+	}
+
+	if rm.Trace != nil {
+		rmCopy.Trace = append([]*protocol.OperationTrace{}, rm.Trace...)
+	}
+
+	return &rmCopy
+}
+
+func copyObject(obj map[string]interface{}) map[string]interface{} {
+	objCopy := make(map[string]interface{}, len(obj))
+
+	for k, v := range obj {
+		objCopy[k] = copyValue(v)
+	}
+
+	return objCopy
+}
+
+// copyValue returns a deep copy of the objects and arrays of a JSON value. Other values are immutable
+// and are returned as is.
+func copyValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return copyObject(value)
+	case document.Document:
+		return document.Document(copyObject(value))
+	case []interface{}:
+		valueCopy := make([]interface{}, len(value))
+
+		for i, e := range value {
+			valueCopy[i] = copyValue(e)
+		}
+
+		return valueCopy
+	case []map[string]interface{}:
+		valueCopy := make([]map[string]interface{}, len(value))
+
+		for i, e := range value {
+			valueCopy[i] = copyObject(e)
+		}
+
+		return valueCopy
+	case []string:
+		return append([]string{}, value...)
+	default:
+		return v
+	}
+}
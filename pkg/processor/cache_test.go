@@ -0,0 +1,124 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+func TestResolutionCache(t *testing.T) {
+	t.Run("LRU eviction", func(t *testing.T) {
+		c := NewResolutionCache(2)
+
+		require.True(t, c.Put("s1", &protocol.ResolutionModel{VersionID: "v1"}, c.Generation()))
+		require.True(t, c.Put("s2", &protocol.ResolutionModel{VersionID: "v2"}, c.Generation()))
+
+		// Access s1 so that s2 becomes the least recently used.
+		_, ok := c.Get("s1")
+		require.True(t, ok)
+
+		require.True(t, c.Put("s3", &protocol.ResolutionModel{VersionID: "v3"}, c.Generation()))
+		require.Equal(t, 2, c.Len())
+
+		_, ok = c.Get("s2")
+		require.False(t, ok)
+
+		rm, ok := c.Get("s1")
+		require.True(t, ok)
+		require.Equal(t, "v1", rm.VersionID)
+
+		// Replace existing entry.
+		require.True(t, c.Put("s1", &protocol.ResolutionModel{VersionID: "v1.1"}, c.Generation()))
+		require.Equal(t, 2, c.Len())
+
+		rm, ok = c.Get("s1")
+		require.True(t, ok)
+		require.Equal(t, "v1.1", rm.VersionID)
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		c := NewResolutionCache(10)
+
+		generation := c.Generation()
+
+		require.True(t, c.Put("s1", &protocol.ResolutionModel{}, generation))
+		require.True(t, c.Put("s2", &protocol.ResolutionModel{}, generation))
+
+		c.Invalidate("s1", "s3")
+
+		_, ok := c.Get("s1")
+		require.False(t, ok)
+
+		_, ok = c.Get("s2")
+		require.True(t, ok)
+
+		// A resolution model computed before the invalidation is not cached.
+		require.False(t, c.Put("s1", &protocol.ResolutionModel{}, generation))
+
+		_, ok = c.Get("s1")
+		require.False(t, ok)
+	})
+
+	t.Run("resolution models are copied", func(t *testing.T) {
+		c := NewResolutionCache(10)
+
+		rm := &protocol.ResolutionModel{
+			Doc: document.Document{
+				"id":                 "did:sidetree:abc",
+				"verificationMethod": []interface{}{map[string]interface{}{"id": "key-1"}},
+				"alsoKnownAs":        []string{"https://example.com"},
+			},
+			AnchorOrigin:          map[string]interface{}{"origin": "o1"},
+			EquivalentReferences:  []string{"ref1"},
+			PublishedOperations:   []*operation.AnchoredOperation{{UniqueSuffix: "abc"}},
+			UnpublishedOperations: []*operation.AnchoredOperation{{UniqueSuffix: "abc"}},
+			Trace:                 []*protocol.OperationTrace{{Applied: true}},
+		}
+
+		require.True(t, c.Put("abc", rm, c.Generation()))
+
+		// Changes to the resolution model that was added are not reflected in the cache.
+		rm.Doc["id"] = "did:sidetree:long-form"
+		rm.Doc["verificationMethod"].([]interface{})[0].(map[string]interface{})["id"] = "key-2"
+		rm.EquivalentReferences[0] = "ref2"
+
+		cached, ok := c.Get("abc")
+		require.True(t, ok)
+		require.Equal(t, "did:sidetree:abc", cached.Doc["id"])
+		require.Equal(t, "key-1", cached.Doc["verificationMethod"].([]interface{})[0].(map[string]interface{})["id"])
+		require.Equal(t, []string{"ref1"}, cached.EquivalentReferences)
+		require.Equal(t, []string{"https://example.com"}, cached.Doc["alsoKnownAs"])
+		require.Equal(t, map[string]interface{}{"origin": "o1"}, cached.AnchorOrigin)
+		require.Len(t, cached.PublishedOperations, 1)
+		require.Len(t, cached.UnpublishedOperations, 1)
+		require.Len(t, cached.Trace, 1)
+
+		// Changes to a resolution model that was returned by the cache are not reflected in the cache.
+		cached.Doc["id"] = "did:sidetree:other"
+		cached.Doc["alsoKnownAs"].([]string)[0] = "https://other.com"
+		cached.PublishedOperations[0] = nil
+
+		cached, ok = c.Get("abc")
+		require.True(t, ok)
+		require.Equal(t, "did:sidetree:abc", cached.Doc["id"])
+		require.Equal(t, []string{"https://example.com"}, cached.Doc["alsoKnownAs"])
+		require.NotNil(t, cached.PublishedOperations[0])
+	})
+
+	t.Run("zero entries", func(t *testing.T) {
+		c := NewResolutionCache(0)
+
+		require.False(t, c.Put("s1", &protocol.ResolutionModel{}, c.Generation()))
+		require.Zero(t, c.Len())
+	})
+}
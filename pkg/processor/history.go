@@ -0,0 +1,195 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processor
+
+import (
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+)
+
+const (
+	reasonCreateAlreadyApplied     = "a valid create operation has already been applied"
+	reasonCommitmentReused         = "operation commitment equals the next operation commitment"
+	reasonNextCommitmentUsed       = "next operation commitment has already been used"
+	reasonCommitmentAlreadyApplied = "another operation for the same commitment has already been applied"
+	reasonCreateNotApplied         = "a valid create operation was not found"
+	reasonDeactivated              = "document has been deactivated"
+	reasonBeforeCreate             = "operation was anchored before the create operation"
+	reasonCommitmentNotFound       = "operation does not match the commitment of the document"
+)
+
+// OperationOutcome holds an operation along with the outcome of applying the operation during resolution.
+type OperationOutcome struct {
+	Operation *operation.AnchoredOperation
+
+	// Published is true if the operation was retrieved from the operation store (as opposed to the
+	// unpublished operation store).
+	Published bool
+
+	// Applied is true if the operation was applied to the document.
+	Applied bool
+
+	// Reason holds the reason why the operation was not applied.
+	Reason string
+}
+
+// History returns every operation of the document with the given unique suffix along with the outcome of
+// applying the operation. Published operations are returned first, ordered by transaction time/number, followed
+// by unpublished operations.
+//
+// The outcome is determined by replaying all operations (i.e. the resolution cache and snapshots are not used). If
+// no operations are found for the document then a 'not found' error is returned. Note that a history is returned
+// even if the document cannot be resolved (e.g. if there is no valid 'create' operation).
+func (s *OperationProcessor) History(uniqueSuffix string) ([]*OperationOutcome, error) {
+	tracker := newOperationTracker()
+
+	_, err := s.resolveWithTracker(uniqueSuffix, tracker)
+	if err != nil && len(tracker.ops) == 0 {
+		return nil, err
+	}
+
+	return tracker.getOutcomes(), nil
+}
+
+// operationTracker records the outcome of operations during resolution. All functions may be called on a
+// nil tracker (in which case nothing is recorded).
+type operationTracker struct {
+	ops       []*operation.AnchoredOperation
+	published map[*operation.AnchoredOperation]bool
+	outcomes  map[*operation.AnchoredOperation]*OperationOutcome
+	state     *resolutionState
+
+	// fullOpStates holds the resolution models after each 'create', 'recover' and 'deactivate'
+	// operation was applied (in the order in which they were applied).
+	fullOpStates []*protocol.ResolutionModel
+}
+
+func newOperationTracker() *operationTracker {
+	return &operationTracker{outcomes: make(map[*operation.AnchoredOperation]*OperationOutcome)}
+}
+
+func (t *operationTracker) setOperations(published, unpublished []*operation.AnchoredOperation) {
+	if t == nil {
+		return
+	}
+
+	t.ops = append(append([]*operation.AnchoredOperation{}, published...), unpublished...)
+	t.published = make(map[*operation.AnchoredOperation]bool, len(published))
+
+	for _, op := range published {
+		t.published[op] = true
+	}
+}
+
+func (t *operationTracker) setState(state *resolutionState) {
+	if t == nil {
+		return
+	}
+
+	t.state = state
+}
+
+func (t *operationTracker) applied(op *operation.AnchoredOperation, rm *protocol.ResolutionModel) {
+	if t == nil {
+		return
+	}
+
+	t.outcomes[op] = &OperationOutcome{Operation: op, Applied: true}
+
+	if op.Type != operation.TypeUpdate {
+		t.fullOpStates = append(t.fullOpStates, rm)
+	}
+}
+
+func (t *operationTracker) rejected(op *operation.AnchoredOperation, reason string) {
+	if t == nil {
+		return
+	}
+
+	if _, ok := t.outcomes[op]; ok {
+		return
+	}
+
+	t.outcomes[op] = &OperationOutcome{Operation: op, Reason: reason}
+}
+
+// getOutcomes returns the outcomes of all operations. Operations that were never attempted are given a reason
+// according to the final resolution state.
+func (t *operationTracker) getOutcomes() []*OperationOutcome {
+	outcomes := make([]*OperationOutcome, len(t.ops))
+
+	for i, op := range t.ops {
+		outcome, ok := t.outcomes[op]
+		if !ok {
+			outcome = &OperationOutcome{Operation: op, Reason: t.reasonNotAttempted(op)}
+		}
+
+		outcome.Published = t.published[op]
+		outcomes[i] = outcome
+	}
+
+	return outcomes
+}
+
+// getTrace returns the outcomes of all operations as a resolution trace.
+func (t *operationTracker) getTrace() []*protocol.OperationTrace {
+	outcomes := t.getOutcomes()
+
+	trace := make([]*protocol.OperationTrace, len(outcomes))
+
+	for i, outcome := range outcomes {
+		trace[i] = &protocol.OperationTrace{
+			Operation: outcome.Operation,
+			Published: outcome.Published,
+			Applied:   outcome.Applied,
+			Reason:    outcome.Reason,
+		}
+	}
+
+	return trace
+}
+
+func (t *operationTracker) reasonNotAttempted(op *operation.AnchoredOperation) string {
+	switch {
+	case t.state == nil:
+		return reasonCreateNotApplied
+	case t.state.rm.Deactivated && isOpWithTxnGreaterThanOrUnpublished(op, t.state.fullOpTxnTime, t.state.fullOpTxnNumber):
+		return reasonDeactivated
+	case !isOpWithTxnGreaterThanOrUnpublished(op, t.fullOpStates[0].LastOperationTransactionTime,
+		t.fullOpStates[0].LastOperationTransactionNumber):
+		return reasonBeforeCreate
+	default:
+		return reasonCommitmentNotFound
+	}
+}
+
+// applyPriorUpdateOperations applies the 'update' operations that were anchored before the last 'create', 'recover'
+// or 'deactivate' operation. These operations are ignored during resolution (since the last full operation replaces
+// the document) but they are replayed here, starting from the state after each full operation, in order to determine
+// their outcome.
+func (s *OperationProcessor) applyPriorUpdateOperations(updateOps []*operation.AnchoredOperation,
+	tracker *operationTracker) {
+	for i := 0; i < len(tracker.fullOpStates)-1; i++ {
+		start, end := tracker.fullOpStates[i], tracker.fullOpStates[i+1]
+
+		var ops []*operation.AnchoredOperation
+
+		for _, op := range updateOps {
+			if op.CanonicalReference != "" &&
+				isTxnAfter(op.TransactionTime, op.TransactionNumber,
+					start.LastOperationTransactionTime, start.LastOperationTransactionNumber) &&
+				!isTxnAfter(op.TransactionTime, op.TransactionNumber,
+					end.LastOperationTransactionTime, end.LastOperationTransactionNumber) {
+				ops = append(ops, op)
+			}
+		}
+
+		if len(ops) > 0 {
+			s.applyOperations(ops, start, getUpdateCommitment, make(map[string]bool), tracker)
+		}
+	}
+}
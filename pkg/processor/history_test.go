@@ -0,0 +1,244 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processor
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+)
+
+func TestHistory(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		h := newTestHistory(t)
+		h.updates(2)
+		orphanBeforeRecover := h.add(newOrphanUpdate(t, h))
+		h.recover()
+		h.update()
+		orphan := h.add(newOrphanUpdate(t, h))
+
+		duplicateCreate := *h.ops[0]
+		h.add(&duplicateCreate)
+
+		h.deactivate()
+		h.update()
+
+		snapshotStore := NewMemSnapshotStore()
+
+		store := mocks.NewMockOperationStore(nil)
+		store.Validate = false
+
+		for _, op := range h.ops {
+			require.NoError(t, store.Put(op))
+		}
+
+		p := New("test", store, newMockProtocolClient(), WithSnapshotStore(snapshotStore), WithSnapshotInterval(1))
+
+		outcomes, err := p.History(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Len(t, outcomes, len(h.ops))
+
+		for i, outcome := range outcomes {
+			require.Equal(t, h.ops[i], outcome.Operation)
+			require.True(t, outcome.Published)
+		}
+
+		// Updates before the recover operation are also applied.
+		requireApplied(t, outcomes[0], outcomes[1], outcomes[2], outcomes[4], outcomes[5], outcomes[8])
+		requireRejected(t, reasonCommitmentNotFound, outcomes[3], outcomes[6])
+		require.Equal(t, orphanBeforeRecover, outcomes[3].Operation)
+		require.Equal(t, orphan, outcomes[6].Operation)
+		requireRejected(t, reasonCreateAlreadyApplied, outcomes[7])
+		requireRejected(t, reasonDeactivated, outcomes[9])
+
+		// Snapshots are not used for history.
+		snapshots, err := snapshotStore.Get(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Empty(t, snapshots)
+
+		// The resolution model isn't affected by tracking.
+		rm, err := p.Resolve(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.True(t, rm.Deactivated)
+	})
+
+	t.Run("unpublished operations", func(t *testing.T) {
+		h := newTestHistory(t)
+		h.updates(2)
+
+		unpublished := h.update()
+		unpublished.CanonicalReference = ""
+
+		p := New("test", newStoreWithOps(t, h.ops[:3]), newMockProtocolClient(),
+			WithUnpublishedOperationStore(&mockUnpublishedOpsStore{AnchoredOps: []*operation.AnchoredOperation{unpublished}}))
+
+		outcomes, err := p.History(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Len(t, outcomes, 4)
+		require.Equal(t, unpublished, outcomes[3].Operation)
+		require.True(t, outcomes[2].Published)
+		require.False(t, outcomes[3].Published)
+		requireApplied(t, outcomes...)
+	})
+
+	t.Run("operation anchored before create", func(t *testing.T) {
+		h := newTestHistory(t)
+		h.updates(2)
+
+		u := h.update()
+		u.TransactionTime = 0
+
+		p := New("test", newStoreWithOps(t, h.ops), newMockProtocolClient())
+
+		outcomes, err := p.History(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Len(t, outcomes, 4)
+		require.Equal(t, u, outcomes[0].Operation)
+		requireRejected(t, reasonBeforeCreate, outcomes[0])
+		requireApplied(t, outcomes[1:]...)
+	})
+
+	t.Run("valid create operation not found", func(t *testing.T) {
+		h := newTestHistory(t)
+		h.updates(2)
+
+		p := New("test", newStoreWithOps(t, h.ops[1:]), newMockProtocolClient())
+
+		outcomes, err := p.History(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Len(t, outcomes, 2)
+		requireRejected(t, reasonCreateNotApplied, outcomes...)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		p := New("test", newStoreWithOps(t, nil), newMockProtocolClient())
+
+		outcomes, err := p.History("suffix")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+		require.Nil(t, outcomes)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		errExpected := errors.New("injected store error")
+
+		p := New("test", &mockOperationStore{err: errExpected}, newMockProtocolClient())
+
+		outcomes, err := p.History("suffix")
+		require.True(t, errors.Is(err, errExpected))
+		require.Nil(t, outcomes)
+	})
+}
+
+func TestResolve_Trace(t *testing.T) {
+	h := newTestHistory(t)
+	h.updates(2)
+	orphan := h.add(newOrphanUpdate(t, h))
+	h.update()
+
+	cache := NewResolutionCache(10)
+
+	p := New("test", newStoreWithOps(t, h.ops), newMockProtocolClient(), WithResolutionCache(cache))
+
+	t.Run("success", func(t *testing.T) {
+		rm, err := p.Resolve(h.uniqueSuffix, document.WithTrace())
+		require.NoError(t, err)
+		require.Len(t, rm.Trace, len(h.ops))
+		require.Zero(t, cache.Len())
+
+		for i, trace := range rm.Trace {
+			require.Equal(t, h.ops[i], trace.Operation)
+			require.True(t, trace.Published)
+
+			if trace.Operation == orphan {
+				require.False(t, trace.Applied)
+				require.Equal(t, reasonCommitmentNotFound, trace.Reason)
+			} else {
+				require.True(t, trace.Applied)
+				require.Empty(t, trace.Reason)
+			}
+		}
+
+		// The resolution model is the same as without a trace.
+		rmNoTrace, err := p.Resolve(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Empty(t, rmNoTrace.Trace)
+		require.Equal(t, rmNoTrace.Doc, rm.Doc)
+		require.Equal(t, rmNoTrace.UpdateCommitment, rm.UpdateCommitment)
+	})
+
+	t.Run("with version ID", func(t *testing.T) {
+		rm, err := p.Resolve(h.uniqueSuffix, document.WithTrace(), document.WithVersionID(h.ops[1].CanonicalReference))
+		require.NoError(t, err)
+		require.Len(t, rm.Trace, 2)
+		requireTraceApplied(t, rm.Trace...)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		rm, err := p.Resolve("suffix", document.WithTrace())
+		require.Error(t, err)
+		require.Nil(t, rm)
+	})
+}
+
+func requireTraceApplied(t *testing.T, trace ...*protocol.OperationTrace) {
+	t.Helper()
+
+	for _, tr := range trace {
+		require.True(t, tr.Applied)
+		require.Empty(t, tr.Reason)
+	}
+}
+
+func requireApplied(t *testing.T, outcomes ...*OperationOutcome) {
+	t.Helper()
+
+	for _, outcome := range outcomes {
+		require.True(t, outcome.Applied)
+		require.Empty(t, outcome.Reason)
+	}
+}
+
+func requireRejected(t *testing.T, reason string, outcomes ...*OperationOutcome) {
+	t.Helper()
+
+	for _, outcome := range outcomes {
+		require.False(t, outcome.Applied)
+		require.Equal(t, reason, outcome.Reason)
+	}
+}
+
+// newOrphanUpdate returns an update operation that is signed with a key that isn't part of the update
+// commitment chain of the document.
+func newOrphanUpdate(t *testing.T, h *testHistory) *operation.AnchoredOperation {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	op, _, err := getAnchoredUpdateOperation(key, h.uniqueSuffix, h.txnTime+1)
+	require.NoError(t, err)
+
+	return op
+}
+
+type mockOperationStore struct {
+	err error
+}
+
+func (m *mockOperationStore) Get(string) ([]*operation.AnchoredOperation, error) {
+	return nil, m.err
+}
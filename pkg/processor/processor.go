@@ -29,6 +29,9 @@ type OperationProcessor struct {
 	pc    protocol.Client
 
 	unpublishedOperationStore unpublishedOperationStore
+	cache                     *ResolutionCache
+	snapshotStore             SnapshotStore
+	snapshotInterval          int
 	logger                    *log.Log
 }
 
@@ -48,7 +51,8 @@ func New(name string, store OperationStoreClient, pc protocol.Client, opts ...Op
 	op := &OperationProcessor{
 		store: store,
 		pc:    pc, unpublishedOperationStore: &noopUnpublishedOpsStore{},
-		logger: log.New(loggerModule, log.WithFields(log.WithNamespace(name))),
+		snapshotInterval: defaultSnapshotInterval,
+		logger:           log.New(loggerModule, log.WithFields(log.WithNamespace(name))),
 	}
 
 	// apply options
@@ -69,10 +73,111 @@ func WithUnpublishedOperationStore(store unpublishedOperationStore) Option {
 	}
 }
 
+// WithResolutionCache sets an optional cache for resolution models. The cache is bypassed if any resolution
+// options (e.g. versionId or versionTime) are provided.
+func WithResolutionCache(cache *ResolutionCache) Option {
+	return func(opts *OperationProcessor) {
+		opts.cache = cache
+	}
+}
+
 // Resolve document based on the given unique suffix.
 // Parameters:
 // uniqueSuffix - unique portion of ID to resolve. for example "abc123" in "did:sidetree:abc123".
 func (s *OperationProcessor) Resolve(uniqueSuffix string, opts ...document.ResolutionOption) (*protocol.ResolutionModel, error) {
+	if s.cache == nil || hasResolutionOptions(opts...) {
+		return s.resolve(uniqueSuffix, opts...)
+	}
+
+	if rm, ok := s.cache.Get(uniqueSuffix); ok {
+		s.logger.Debug("Returning cached resolution model", log.WithSuffix(uniqueSuffix))
+
+		// The cache returns a copy so the caller may modify the resolution model (e.g. the document ID is set
+		// by the document transformer).
+		return rm, nil
+	}
+
+	generation := s.cache.Generation()
+
+	rm, err := s.resolve(uniqueSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cache stores a copy so that the caller may modify the returned resolution model.
+	s.cache.Put(uniqueSuffix, rm, generation)
+
+	return rm, nil
+}
+
+// Rollback is invoked after published operations for the given suffixes were removed from the operation store
+// (e.g. because the transactions that anchored them were orphaned by a ledger reorganization). The snapshots and
+// cached resolution models for the suffixes are discarded and the documents are resolved again from the remaining
+// operations.
+func (s *OperationProcessor) Rollback(uniqueSuffixes ...string) error {
+	err := s.deleteSnapshots(uniqueSuffixes)
+
+	// Invalidate the cache even if the snapshots could not be deleted since the cached resolution
+	// models are stale in any case.
+	if s.cache != nil {
+		s.cache.Invalidate(uniqueSuffixes...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, uniqueSuffix := range uniqueSuffixes {
+		// The document may no longer exist if its 'create' operation was removed.
+		if _, err := s.Resolve(uniqueSuffix); err != nil {
+			s.logger.Info("Unable to resolve document after rollback", log.WithSuffix(uniqueSuffix), log.WithError(err))
+		}
+	}
+
+	return nil
+}
+
+func (s *OperationProcessor) deleteSnapshots(uniqueSuffixes []string) error {
+	if s.snapshotStore == nil {
+		return nil
+	}
+
+	for _, uniqueSuffix := range uniqueSuffixes {
+		if err := s.snapshotStore.Delete(uniqueSuffix); err != nil {
+			return fmt.Errorf("delete snapshots for suffix[%s]: %w", uniqueSuffix, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *OperationProcessor) resolve(uniqueSuffix string, opts ...document.ResolutionOption) (*protocol.ResolutionModel, error) {
+	resOpts, err := document.GetResolutionOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resOpts.Trace {
+		return s.resolveWithTracker(uniqueSuffix, nil, opts...)
+	}
+
+	tracker := newOperationTracker()
+
+	rm, err := s.resolveWithTracker(uniqueSuffix, tracker, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	rm.Trace = tracker.getTrace()
+
+	return rm, nil
+}
+
+// resolveWithTracker resolves the document and, if the given tracker is not nil, records the outcome of each
+// operation with the tracker. Snapshots are not used when tracking operations since every operation needs to be
+// replayed in order to determine its outcome.
+func (s *OperationProcessor) resolveWithTracker(uniqueSuffix string, tracker *operationTracker,
+	opts ...document.ResolutionOption) (*protocol.ResolutionModel, error) {
 	var unpublishedOps []*operation.AnchoredOperation
 
 	unpubOps, err := s.unpublishedOperationStore.Get(uniqueSuffix)
@@ -96,44 +201,99 @@ func (s *OperationProcessor) Resolve(uniqueSuffix string, opts ...document.Resol
 	// return all operations in response - versionId is considered just like view of information
 	rm := &protocol.ResolutionModel{PublishedOperations: publishedOps, UnpublishedOperations: unpublishedOps}
 
+	tracker.setOperations(publishedOps, unpublishedOps)
+
 	// split operations into 'create', 'update' and 'full' operations
 	createOps, updateOps, fullOps := splitOperations(filteredOps)
 	if len(createOps) == 0 {
 		return nil, fmt.Errorf("create operation not found")
 	}
 
-	// Ensure that all published 'create' operations are processed first (in case there are
-	// unpublished 'create' operations in the collection due to race condition).
-	sort.SliceStable(createOps, func(i, j int) bool {
-		return createOps[i].CanonicalReference != ""
-	})
+	var snapshots []*Snapshot
+	if tracker == nil {
+		snapshots = s.getSnapshots(uniqueSuffix)
+	}
+
+	state := s.stateFromSnapshot(snapshots, publishedOps, rm)
+	if state == nil {
+		// Ensure that all published 'create' operations are processed first (in case there are
+		// unpublished 'create' operations in the collection due to race condition). Only published
+		// operations are moved ahead of unpublished ones so that published operations remain in
+		// ledger order, i.e. the first published 'create' operation is applied.
+		sort.SliceStable(createOps, func(i, j int) bool {
+			return createOps[i].CanonicalReference != "" && createOps[j].CanonicalReference == ""
+		})
+
+		// apply 'create' operations first
+		rm = s.applyFirstValidCreateOperation(createOps, rm, tracker)
+		if rm == nil {
+			return nil, errors.New("valid create operation not found")
+		}
 
-	// apply 'create' operations first
-	rm = s.applyFirstValidCreateOperation(createOps, rm)
-	if rm == nil {
-		return nil, errors.New("valid create operation not found")
+		state = newResolutionState(rm)
 	}
 
+	s.applyFullAndUpdateOperations(uniqueSuffix, state, fullOps, updateOps, tracker)
+
+	if tracker != nil {
+		s.applyPriorUpdateOperations(updateOps, tracker)
+
+		tracker.setState(state)
+	}
+
+	// Snapshots may only be taken from published operations.
+	if tracker == nil && len(unpublishedOps) == 0 && !hasResolutionOptions(opts...) {
+		s.saveSnapshot(uniqueSuffix, publishedOps, snapshots, state)
+	}
+
+	return state.rm, nil
+}
+
+func (s *OperationProcessor) applyFullAndUpdateOperations(uniqueSuffix string, state *resolutionState,
+	fullOps, updateOps []*operation.AnchoredOperation, tracker *operationTracker) {
 	// apply 'full' operations first
 	if len(fullOps) > 0 {
 		s.logger.Debug("Applying full operations", log.WithTotal(len(fullOps)), log.WithSuffix(uniqueSuffix))
 
-		rm = s.applyOperations(fullOps, rm, getRecoveryCommitment)
-		if rm.Deactivated {
-			// document was deactivated, stop processing
-			return rm, nil
+		numApplied := len(state.recoveryCommitments)
+
+		state.rm = s.applyOperations(fullOps, state.rm, getRecoveryCommitment, state.recoveryCommitments, tracker)
+
+		if len(state.recoveryCommitments) > numApplied {
+			// a 'full' operation was applied so update operations before this operation no longer apply
+			state.fullOpTxnTime = state.rm.LastOperationTransactionTime
+			state.fullOpTxnNumber = state.rm.LastOperationTransactionNumber
+			state.updateCommitments = make(map[string]bool)
 		}
 	}
 
+	if state.rm.Deactivated {
+		// document was deactivated, stop processing
+		return
+	}
+
 	// next apply update ops since last 'full' transaction
-	filteredUpdateOps := getOpsWithTxnGreaterThanOrUnpublished(updateOps, rm.LastOperationTransactionTime, rm.LastOperationTransactionNumber)
+	filteredUpdateOps := getOpsWithTxnGreaterThanOrUnpublished(updateOps, state.fullOpTxnTime, state.fullOpTxnNumber)
 	if len(filteredUpdateOps) > 0 {
 		s.logger.Debug("Applying update operations after last full operation", log.WithTotal(len(filteredUpdateOps)),
 			log.WithSuffix(uniqueSuffix))
-		rm = s.applyOperations(filteredUpdateOps, rm, getUpdateCommitment)
+
+		state.rm = s.applyOperations(filteredUpdateOps, state.rm, getUpdateCommitment, state.updateCommitments, tracker)
 	}
+}
 
-	return rm, nil
+func hasResolutionOptions(opts ...document.ResolutionOption) bool {
+	if len(opts) == 0 {
+		return false
+	}
+
+	resOpts, err := document.GetResolutionOptions(opts...)
+	if err != nil {
+		// Let resolve deal with the error.
+		return true
+	}
+
+	return resOpts.VersionID != "" || resOpts.VersionTime != "" || len(resOpts.AdditionalOperations) > 0 || resOpts.Trace
 }
 
 func (s *OperationProcessor) processOperations(
@@ -259,7 +419,8 @@ func getCanonicalMap(published []*operation.AnchoredOperation) map[string]bool {
 	return canonicalMap
 }
 
-func (s *OperationProcessor) createOperationHashMap(ops []*operation.AnchoredOperation) map[string][]*operation.AnchoredOperation {
+func (s *OperationProcessor) createOperationHashMap(ops []*operation.AnchoredOperation,
+	tracker *operationTracker) map[string][]*operation.AnchoredOperation {
 	opMap := make(map[string][]*operation.AnchoredOperation)
 
 	for _, op := range ops {
@@ -269,6 +430,8 @@ func (s *OperationProcessor) createOperationHashMap(ops []*operation.AnchoredOpe
 				log.WithOperationType(string(op.Type)), log.WithTransactionTime(op.TransactionTime),
 				log.WithTransactionNumber(op.TransactionNumber), log.WithError(err))
 
+			tracker.rejected(op, err.Error())
+
 			continue
 		}
 
@@ -278,6 +441,8 @@ func (s *OperationProcessor) createOperationHashMap(ops []*operation.AnchoredOpe
 				log.WithOperationType(string(op.Type)), log.WithTransactionTime(op.TransactionTime),
 				log.WithTransactionNumber(op.TransactionNumber), log.WithError(err))
 
+			tracker.rejected(op, err.Error())
+
 			continue
 		}
 
@@ -336,17 +501,16 @@ func isOpWithTxnGreaterThanOrUnpublished(op *operation.AnchoredOperation, txnTim
 	return false
 }
 
+// applyOperations applies the chain of operations starting at the commitment returned by commitmentFnc. The given
+// commitment map holds the commitments that have already been applied and is updated with the applied commitments.
 func (s *OperationProcessor) applyOperations(ops []*operation.AnchoredOperation, rm *protocol.ResolutionModel,
-	commitmentFnc fnc) *protocol.ResolutionModel {
+	commitmentFnc fnc, commitmentMap map[string]bool, tracker *operationTracker) *protocol.ResolutionModel {
 	// suffix for logging
 	uniqueSuffix := ops[0].UniqueSuffix
 
 	state := rm
 
-	opMap := s.createOperationHashMap(ops)
-
-	// holds applied commitments
-	commitmentMap := make(map[string]bool)
+	opMap := s.createOperationHashMap(ops, tracker)
 
 	c := commitmentFnc(state)
 
@@ -357,7 +521,7 @@ func (s *OperationProcessor) applyOperations(ops []*operation.AnchoredOperation,
 		s.logger.Debug("Found operation(s) for commitment", log.WithTotal(len(commitmentOps)),
 			log.WithCommitment(c), log.WithSuffix(uniqueSuffix))
 
-		newState := s.applyFirstValidOperation(commitmentOps, state, c, commitmentMap)
+		newState := s.applyFirstValidOperation(commitmentOps, state, c, commitmentMap, tracker)
 
 		// can't find a valid operation to apply
 		if newState == nil {
@@ -406,8 +570,8 @@ func getRecoveryCommitment(rm *protocol.ResolutionModel) string {
 }
 
 func (s *OperationProcessor) applyFirstValidCreateOperation(createOps []*operation.AnchoredOperation,
-	rm *protocol.ResolutionModel) *protocol.ResolutionModel {
-	for _, op := range createOps {
+	rm *protocol.ResolutionModel, tracker *operationTracker) *protocol.ResolutionModel {
+	for i, op := range createOps {
 		var state *protocol.ResolutionModel
 		var err error
 
@@ -416,6 +580,8 @@ func (s *OperationProcessor) applyFirstValidCreateOperation(createOps []*operati
 				log.WithTransactionTime(op.TransactionTime), log.WithTransactionNumber(op.TransactionNumber),
 				log.WithError(err))
 
+			tracker.rejected(op, err.Error())
+
 			continue
 		}
 
@@ -423,6 +589,12 @@ func (s *OperationProcessor) applyFirstValidCreateOperation(createOps []*operati
 			log.WithOperation(op), log.WithRecoveryCommitment(state.RecoveryCommitment),
 			log.WithUpdateCommitment(state.UpdateCommitment), log.WithDocument(state.Doc))
 
+		tracker.applied(op, state)
+
+		for _, skippedOp := range createOps[i+1:] {
+			tracker.rejected(skippedOp, reasonCreateAlreadyApplied)
+		}
+
 		return state
 	}
 
@@ -431,8 +603,8 @@ func (s *OperationProcessor) applyFirstValidCreateOperation(createOps []*operati
 
 // this function should be used for update, recover and deactivate operations (create is handled differently).
 func (s *OperationProcessor) applyFirstValidOperation(ops []*operation.AnchoredOperation, rm *protocol.ResolutionModel,
-	currCommitment string, processedCommitments map[string]bool) *protocol.ResolutionModel {
-	for _, op := range ops {
+	currCommitment string, processedCommitments map[string]bool, tracker *operationTracker) *protocol.ResolutionModel {
+	for i, op := range ops {
 		var state *protocol.ResolutionModel
 		var err error
 
@@ -441,6 +613,8 @@ func (s *OperationProcessor) applyFirstValidOperation(ops []*operation.AnchoredO
 			s.logger.Info("Skipped bad operation", log.WithSuffix(op.UniqueSuffix), log.WithOperationType(string(op.Type)),
 				log.WithTransactionTime(op.TransactionTime), log.WithTransactionNumber(op.TransactionNumber), log.WithError(err))
 
+			tracker.rejected(op, err.Error())
+
 			continue
 		}
 
@@ -449,6 +623,8 @@ func (s *OperationProcessor) applyFirstValidOperation(ops []*operation.AnchoredO
 				log.WithSuffix(op.UniqueSuffix), log.WithOperationType(string(op.Type)),
 				log.WithTransactionTime(op.TransactionTime), log.WithTransactionNumber(op.TransactionNumber))
 
+			tracker.rejected(op, reasonCommitmentReused)
+
 			continue
 		}
 
@@ -460,6 +636,8 @@ func (s *OperationProcessor) applyFirstValidOperation(ops []*operation.AnchoredO
 					log.WithSuffix(op.UniqueSuffix), log.WithOperationType(string(op.Type)),
 					log.WithTransactionTime(op.TransactionTime), log.WithTransactionNumber(op.TransactionNumber))
 
+				tracker.rejected(op, reasonNextCommitmentUsed)
+
 				continue
 			}
 		}
@@ -469,9 +647,17 @@ func (s *OperationProcessor) applyFirstValidOperation(ops []*operation.AnchoredO
 				log.WithTransactionTime(op.TransactionTime), log.WithTransactionNumber(op.TransactionNumber),
 				log.WithError(err))
 
+			tracker.rejected(op, err.Error())
+
 			continue
 		}
 
+		tracker.applied(op, state)
+
+		for _, skippedOp := range ops[i+1:] {
+			tracker.rejected(skippedOp, reasonCommitmentAlreadyApplied)
+		}
+
 		s.logger.Debug("Applyied operation.", log.WithOperation(op), log.WithRecoveryCommitment(state.RecoveryCommitment),
 			log.WithUpdateCommitment(state.UpdateCommitment), log.WithDeactivated(state.Deactivated), log.WithDocument(state.Doc))
 
@@ -493,8 +679,8 @@ func (s *OperationProcessor) applyOperation(op *operation.AnchoredOperation,
 
 func sortOperations(ops []*operation.AnchoredOperation) {
 	sort.Slice(ops, func(i, j int) bool {
-		if ops[i].TransactionTime < ops[j].TransactionTime {
-			return true
+		if ops[i].TransactionTime != ops[j].TransactionTime {
+			return ops[i].TransactionTime < ops[j].TransactionTime
 		}
 
 		return ops[i].TransactionNumber < ops[j].TransactionNumber
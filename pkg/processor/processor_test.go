@@ -14,6 +14,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -64,6 +65,24 @@ func TestResolve(t *testing.T) {
 		require.NotNil(t, doc)
 	})
 
+	t.Run("success - duplicate published create operation", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(recoveryKey, updateKey)
+		store.Validate = false
+
+		createOps, err := store.Get(uniqueSuffix)
+		require.NoError(t, err)
+
+		duplicateCreateOp := *createOps[0]
+		duplicateCreateOp.TransactionTime = defaultBlockNumber + 5
+		require.NoError(t, store.Put(&duplicateCreateOp))
+
+		rm, err := New("test", store, pc).Resolve(uniqueSuffix)
+		require.NoError(t, err)
+
+		// The create operation that was published first is applied.
+		require.Equal(t, uint64(defaultBlockNumber), rm.CreatedTime)
+	})
+
 	t.Run("success - with additional operations", func(t *testing.T) {
 		store, uniqueSuffix := getDefaultStore(recoveryKey, updateKey)
 		op := New("test", store, pc)
@@ -281,6 +300,95 @@ func TestResolve(t *testing.T) {
 	})
 }
 
+func TestResolve_Cache(t *testing.T) {
+	recoveryKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	updateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	pc := newMockProtocolClient()
+
+	t.Run("success", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(recoveryKey, updateKey)
+
+		cache := NewResolutionCache(10)
+
+		countingStore := &mockCountingOperationStore{MockOperationStore: store}
+
+		p := New("test", countingStore, pc, WithResolutionCache(cache))
+
+		result, err := p.Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, 1, countingStore.getCount())
+		require.Equal(t, 1, cache.Len())
+
+		// Modifying the result should not modify the cached resolution model.
+		result.Deactivated = true
+
+		result, err = p.Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.False(t, result.Deactivated)
+		require.Equal(t, 1, countingStore.getCount())
+
+		// The cache is bypassed for resolution options.
+		_, err = p.Resolve(uniqueSuffix, document.WithVersionTime(time.Now().UTC().Format(time.RFC3339)))
+		require.NoError(t, err)
+		require.Equal(t, 2, countingStore.getCount())
+
+		// Add an update and invalidate the cache.
+		updateOp, _, err := getAnchoredUpdateOperation(updateKey, uniqueSuffix, 1)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(updateOp))
+
+		cache.Invalidate(uniqueSuffix)
+
+		result, err = p.Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, 3, countingStore.getCount())
+		require.Equal(t, "special1", document.DidDocumentFromJSONLDObject(result.Doc)["test"])
+	})
+
+	t.Run("resolution error is not cached", func(t *testing.T) {
+		cache := NewResolutionCache(10)
+
+		p := New("test", mocks.NewMockOperationStore(nil), pc, WithResolutionCache(cache))
+
+		_, err := p.Resolve(dummyUniqueSuffix)
+		require.Error(t, err)
+		require.Zero(t, cache.Len())
+	})
+
+	t.Run("empty resolution options", func(t *testing.T) {
+		require.False(t, hasResolutionOptions())
+		require.False(t, hasResolutionOptions(document.WithAdditionalOperations(nil)))
+		require.True(t, hasResolutionOptions(document.WithVersionID("abc")))
+		require.True(t, hasResolutionOptions(document.WithTrace()))
+	})
+}
+
+type mockCountingOperationStore struct {
+	*mocks.MockOperationStore
+
+	mutex sync.Mutex
+	count int
+}
+
+func (m *mockCountingOperationStore) Get(uniqueSuffix string) ([]*operation.AnchoredOperation, error) {
+	m.mutex.Lock()
+	m.count++
+	m.mutex.Unlock()
+
+	return m.MockOperationStore.Get(uniqueSuffix)
+}
+
+func (m *mockCountingOperationStore) getCount() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.count
+}
+
 func TestUpdateDocument(t *testing.T) {
 	recoveryKey, e := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	require.NoError(t, e)
@@ -1040,6 +1148,18 @@ func TestOpsWithTxnGreaterThan(t *testing.T) {
 	require.Equal(t, 1, len(txns))
 }
 
+func TestSortOperations(t *testing.T) {
+	op1 := &operation.AnchoredOperation{TransactionTime: 1, TransactionNumber: 5}
+	op2 := &operation.AnchoredOperation{TransactionTime: 2, TransactionNumber: 0}
+	op3 := &operation.AnchoredOperation{TransactionTime: 2, TransactionNumber: 3}
+	op4 := &operation.AnchoredOperation{TransactionTime: 3, TransactionNumber: 1}
+
+	ops := []*operation.AnchoredOperation{op1, op4, op3, op2}
+
+	sortOperations(ops)
+	require.Equal(t, []*operation.AnchoredOperation{op1, op2, op3, op4}, ops)
+}
+
 func getUpdateOperation(privateKey *ecdsa.PrivateKey, uniqueSuffix string, blockNum uint64) (*model.Operation, *ecdsa.PrivateKey, error) {
 	s := ecsigner.New(privateKey, "ES256", "")
 
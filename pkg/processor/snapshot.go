@@ -0,0 +1,297 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+)
+
+const defaultSnapshotInterval = 100
+
+// Snapshot holds the resolution state of a document after all published operations up to (and including) the
+// snapshot transaction were applied. Resolution starts from the latest snapshot that is consistent with the
+// operations being resolved instead of replaying every operation from the 'create' operation.
+//
+// Snapshots are only valid for as long as published operations are appended to the operation store. If published
+// operations are removed from the operation store then the snapshots for the affected suffixes must be deleted.
+type Snapshot struct {
+	UniqueSuffix      string `json:"didSuffix"`
+	TransactionTime   uint64 `json:"transactionTime"`
+	TransactionNumber uint64 `json:"transactionNumber"`
+
+	// OperationCount is the number of published operations with a transaction time/number less than or equal
+	// to the snapshot transaction. A snapshot is only used if the same number of published operations are found
+	// up to the snapshot transaction at resolution time.
+	OperationCount int `json:"operationCount"`
+
+	// Model is the resolution model. Published and unpublished operations are not included.
+	Model *protocol.ResolutionModel `json:"model"`
+
+	// RecoveryCommitments are the recovery commitments that have been used by 'recover' and 'deactivate' operations.
+	RecoveryCommitments []string `json:"recoveryCommitments,omitempty"`
+
+	// UpdateCommitments are the update commitments that have been used by 'update' operations since the last
+	// 'create' or 'recover' operation.
+	UpdateCommitments []string `json:"updateCommitments,omitempty"`
+
+	// FullOperationTransactionTime and FullOperationTransactionNumber identify the transaction of the last
+	// 'create', 'recover' or 'deactivate' operation that was applied.
+	FullOperationTransactionTime   uint64 `json:"fullOperationTransactionTime"`
+	FullOperationTransactionNumber uint64 `json:"fullOperationTransactionNumber"`
+}
+
+// SnapshotStore persists resolution snapshots.
+type SnapshotStore interface {
+	// Put saves the given snapshot.
+	Put(snapshot *Snapshot) error
+	// Get returns all snapshots for the given suffix (or an empty slice if there are none).
+	Get(uniqueSuffix string) ([]*Snapshot, error)
+	// Delete deletes all snapshots for the given suffix.
+	Delete(uniqueSuffix string) error
+}
+
+// WithSnapshotStore sets an optional store for resolution snapshots. If set, a snapshot is saved (at the
+// configured interval) when a document is resolved and subsequent resolutions start from the latest
+// consistent snapshot.
+func WithSnapshotStore(store SnapshotStore) Option {
+	return func(opts *OperationProcessor) {
+		opts.snapshotStore = store
+	}
+}
+
+// WithSnapshotInterval sets the minimum number of published operations between snapshots. Defaults to 100.
+func WithSnapshotInterval(interval int) Option {
+	return func(opts *OperationProcessor) {
+		opts.snapshotInterval = interval
+	}
+}
+
+// resolutionState holds the resolution model along with the state required to continue resolution
+// from a snapshot.
+type resolutionState struct {
+	rm                  *protocol.ResolutionModel
+	recoveryCommitments map[string]bool
+	updateCommitments   map[string]bool
+	fullOpTxnTime       uint64
+	fullOpTxnNumber     uint64
+}
+
+func newResolutionState(rm *protocol.ResolutionModel) *resolutionState {
+	return &resolutionState{
+		rm:                  rm,
+		recoveryCommitments: make(map[string]bool),
+		updateCommitments:   make(map[string]bool),
+		fullOpTxnTime:       rm.LastOperationTransactionTime,
+		fullOpTxnNumber:     rm.LastOperationTransactionNumber,
+	}
+}
+
+func (s *OperationProcessor) getSnapshots(uniqueSuffix string) []*Snapshot {
+	if s.snapshotStore == nil {
+		return nil
+	}
+
+	snapshots, err := s.snapshotStore.Get(uniqueSuffix)
+	if err != nil {
+		s.logger.Warn("Failed to retrieve snapshots", log.WithSuffix(uniqueSuffix), log.WithError(err))
+
+		return nil
+	}
+
+	// Latest snapshot first.
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return isTxnAfter(snapshots[i].TransactionTime, snapshots[i].TransactionNumber,
+			snapshots[j].TransactionTime, snapshots[j].TransactionNumber)
+	})
+
+	return snapshots
+}
+
+// stateFromSnapshot returns the resolution state from the latest snapshot that is consistent with the given
+// published operations, or nil if there is no such snapshot.
+func (s *OperationProcessor) stateFromSnapshot(snapshots []*Snapshot, publishedOps []*operation.AnchoredOperation,
+	rm *protocol.ResolutionModel) *resolutionState {
+	for _, snapshot := range snapshots {
+		if snapshot.Model == nil || snapshot.OperationCount == 0 ||
+			countOpsUpTo(publishedOps, snapshot.TransactionTime, snapshot.TransactionNumber) != snapshot.OperationCount {
+			continue
+		}
+
+		s.logger.Debug("Resolving from snapshot", log.WithSuffix(snapshot.UniqueSuffix),
+			log.WithTransactionTime(snapshot.TransactionTime), log.WithTransactionNumber(snapshot.TransactionNumber),
+			log.WithTotalOperations(snapshot.OperationCount))
+
+		snapshotRM := *snapshot.Model
+		snapshotRM.PublishedOperations = rm.PublishedOperations
+		snapshotRM.UnpublishedOperations = rm.UnpublishedOperations
+
+		return &resolutionState{
+			rm:                  &snapshotRM,
+			recoveryCommitments: toMap(snapshot.RecoveryCommitments),
+			updateCommitments:   toMap(snapshot.UpdateCommitments),
+			fullOpTxnTime:       snapshot.FullOperationTransactionTime,
+			fullOpTxnNumber:     snapshot.FullOperationTransactionNumber,
+		}
+	}
+
+	return nil
+}
+
+// saveSnapshot saves a snapshot of the given state if the number of published operations since the latest
+// snapshot has reached the snapshot interval. The given published operations must be sorted and the state must
+// have been resolved from published operations only.
+func (s *OperationProcessor) saveSnapshot(uniqueSuffix string, publishedOps []*operation.AnchoredOperation,
+	snapshots []*Snapshot, state *resolutionState) {
+	if s.snapshotStore == nil || len(publishedOps) == 0 {
+		return
+	}
+
+	lastCount := 0
+
+	for _, snapshot := range snapshots {
+		if snapshot.OperationCount > lastCount {
+			lastCount = snapshot.OperationCount
+		}
+	}
+
+	if len(publishedOps)-lastCount < s.snapshotInterval {
+		return
+	}
+
+	lastOp := publishedOps[len(publishedOps)-1]
+
+	rm := *state.rm
+	rm.PublishedOperations = nil
+	rm.UnpublishedOperations = nil
+
+	snapshot := &Snapshot{
+		UniqueSuffix:                   uniqueSuffix,
+		TransactionTime:                lastOp.TransactionTime,
+		TransactionNumber:              lastOp.TransactionNumber,
+		OperationCount:                 len(publishedOps),
+		Model:                          &rm,
+		RecoveryCommitments:            toSlice(state.recoveryCommitments),
+		UpdateCommitments:              toSlice(state.updateCommitments),
+		FullOperationTransactionTime:   state.fullOpTxnTime,
+		FullOperationTransactionNumber: state.fullOpTxnNumber,
+	}
+
+	if err := s.snapshotStore.Put(snapshot); err != nil {
+		s.logger.Warn("Failed to save snapshot", log.WithSuffix(uniqueSuffix), log.WithError(err))
+
+		return
+	}
+
+	s.logger.Debug("Saved snapshot", log.WithSuffix(uniqueSuffix), log.WithTransactionTime(snapshot.TransactionTime),
+		log.WithTransactionNumber(snapshot.TransactionNumber), log.WithTotalOperations(snapshot.OperationCount))
+}
+
+func countOpsUpTo(ops []*operation.AnchoredOperation, txnTime, txnNumber uint64) int {
+	count := 0
+
+	for _, op := range ops {
+		if !isTxnAfter(op.TransactionTime, op.TransactionNumber, txnTime, txnNumber) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func isTxnAfter(txnTime, txnNumber, otherTxnTime, otherTxnNumber uint64) bool {
+	if txnTime != otherTxnTime {
+		return txnTime > otherTxnTime
+	}
+
+	return txnNumber > otherTxnNumber
+}
+
+func toMap(values []string) map[string]bool {
+	m := make(map[string]bool, len(values))
+
+	for _, v := range values {
+		m[v] = true
+	}
+
+	return m
+}
+
+func toSlice(m map[string]bool) []string {
+	values := make([]string, 0, len(m))
+
+	for v := range m {
+		values = append(values, v)
+	}
+
+	sort.Strings(values)
+
+	return values
+}
+
+// MemSnapshotStore is an in-memory snapshot store.
+type MemSnapshotStore struct {
+	mutex     sync.RWMutex
+	snapshots map[string][][]byte
+}
+
+// NewMemSnapshotStore returns a new in-memory snapshot store.
+func NewMemSnapshotStore() *MemSnapshotStore {
+	return &MemSnapshotStore{snapshots: make(map[string][][]byte)}
+}
+
+// Put saves the given snapshot.
+func (m *MemSnapshotStore) Put(snapshot *Snapshot) error {
+	// Snapshots are stored in marshalled form so that the caller doesn't share the model with the store.
+	snapshotBytes, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("marshal snapshot: %w", err)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.snapshots[snapshot.UniqueSuffix] = append(m.snapshots[snapshot.UniqueSuffix], snapshotBytes)
+
+	return nil
+}
+
+// Get returns all snapshots for the given suffix.
+func (m *MemSnapshotStore) Get(uniqueSuffix string) ([]*Snapshot, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	snapshots := make([]*Snapshot, 0, len(m.snapshots[uniqueSuffix]))
+
+	for _, snapshotBytes := range m.snapshots[uniqueSuffix] {
+		snapshot := &Snapshot{}
+
+		if err := json.Unmarshal(snapshotBytes, snapshot); err != nil {
+			return nil, fmt.Errorf("unmarshal snapshot: %w", err)
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// Delete deletes all snapshots for the given suffix.
+func (m *MemSnapshotStore) Delete(uniqueSuffix string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.snapshots, uniqueSuffix)
+
+	return nil
+}
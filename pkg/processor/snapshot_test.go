@@ -0,0 +1,501 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processor
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+)
+
+func TestSnapshot_Consistency(t *testing.T) {
+	t.Run("long history", func(t *testing.T) {
+		h := newTestHistory(t)
+		h.updates(40)
+		h.recover()
+		h.updates(25)
+		h.recover()
+		h.recover()
+		h.updates(15)
+
+		snapshotStore := NewMemSnapshotStore()
+
+		// Resolve as the history grows so that snapshots are taken at various points.
+		for i := 1; i <= len(h.ops); i++ {
+			requireConsistentResolution(t, h.uniqueSuffix, h.ops[:i], nil, snapshotStore, 7)
+		}
+
+		snapshots, err := snapshotStore.Get(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Len(t, snapshots, len(h.ops)/7)
+
+		// Resolve at every version.
+		for _, op := range h.ops {
+			requireConsistentResolution(t, h.uniqueSuffix, h.ops, nil, snapshotStore, 7,
+				document.WithVersionID(op.CanonicalReference))
+
+			requireConsistentResolution(t, h.uniqueSuffix, h.ops, nil, snapshotStore, 7,
+				document.WithVersionTime(time.Unix(int64(op.TransactionTime), 0).UTC().Format(time.RFC3339)))
+		}
+	})
+
+	t.Run("deactivated", func(t *testing.T) {
+		h := newTestHistory(t)
+		h.updates(10)
+		h.deactivate()
+		h.updates(5)
+
+		snapshotStore := NewMemSnapshotStore()
+
+		for i := 1; i <= len(h.ops); i++ {
+			requireConsistentResolution(t, h.uniqueSuffix, h.ops[:i], nil, snapshotStore, 3)
+		}
+
+		rm := requireConsistentResolution(t, h.uniqueSuffix, h.ops, nil, snapshotStore, 3)
+		require.True(t, rm.Deactivated)
+	})
+
+	t.Run("out of order operations", func(t *testing.T) {
+		h := newTestHistory(t)
+		h.updates(5)
+
+		u1 := h.update()
+		h.update()
+		u3 := h.update()
+
+		h.updates(5)
+
+		// Operation u1 is anchored after u2 and u3 which means that u2 and u3 can't be applied until u1 is observed.
+		u1.TransactionTime, u3.TransactionTime = u3.TransactionTime, u1.TransactionTime
+
+		// Operations are observed in the order in which they were anchored.
+		ops := append([]*operation.AnchoredOperation{}, h.ops...)
+		sortOperations(ops)
+
+		snapshotStore := NewMemSnapshotStore()
+
+		for i := 1; i <= len(ops); i++ {
+			requireConsistentResolution(t, h.uniqueSuffix, ops[:i], nil, snapshotStore, 2)
+		}
+
+		rm := requireConsistentResolution(t, h.uniqueSuffix, ops, nil, snapshotStore, 2)
+		require.Equal(t, h.ops[len(h.ops)-1].CanonicalReference, rm.VersionID)
+	})
+
+	t.Run("operation inserted before snapshot", func(t *testing.T) {
+		h := newTestHistory(t)
+		h.updates(10)
+
+		h.update()
+		u2 := h.update()
+
+		snapshotStore := NewMemSnapshotStore()
+
+		// Take a snapshot without u1.
+		ops := append(append([]*operation.AnchoredOperation{}, h.ops[:11]...), u2)
+
+		requireConsistentResolution(t, h.uniqueSuffix, ops, nil, snapshotStore, 1)
+
+		snapshots, err := snapshotStore.Get(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Len(t, snapshots, 1)
+
+		// The snapshot is no longer consistent with the operations in the store and must be ignored.
+		rm := requireConsistentResolution(t, h.uniqueSuffix, h.ops, nil, snapshotStore, 1)
+		require.Equal(t, u2.CanonicalReference, rm.VersionID)
+	})
+
+	t.Run("unpublished operations", func(t *testing.T) {
+		h := newTestHistory(t)
+		h.updates(10)
+		h.recover()
+		h.update()
+
+		snapshotStore := NewMemSnapshotStore()
+
+		published := h.ops[:len(h.ops)-2]
+
+		for i := 1; i <= len(published); i++ {
+			requireConsistentResolution(t, h.uniqueSuffix, published[:i], nil, snapshotStore, 4)
+		}
+
+		snapshots, err := snapshotStore.Get(h.uniqueSuffix)
+		require.NoError(t, err)
+
+		numSnapshots := len(snapshots)
+
+		unpublished := h.ops[len(h.ops)-2:]
+		for _, op := range unpublished {
+			op.CanonicalReference = ""
+		}
+
+		rm := requireConsistentResolution(t, h.uniqueSuffix, published, unpublished, snapshotStore, 1)
+		require.Len(t, rm.UnpublishedOperations, 2)
+
+		// Snapshots are not taken when there are unpublished operations.
+		snapshots, err = snapshotStore.Get(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Len(t, snapshots, numSnapshots)
+	})
+}
+
+func TestSnapshot_Resolve(t *testing.T) {
+	pc := newMockProtocolClient()
+
+	t.Run("resolves from snapshot", func(t *testing.T) {
+		h := newTestHistory(t)
+		h.updates(5)
+
+		snapshotStore := NewMemSnapshotStore()
+
+		p := New("test", newStoreWithOps(t, h.ops), pc, WithSnapshotStore(snapshotStore), WithSnapshotInterval(5))
+
+		rm, err := p.Resolve(h.uniqueSuffix)
+		require.NoError(t, err)
+
+		snapshots, err := snapshotStore.Get(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Len(t, snapshots, 1)
+		require.Equal(t, len(h.ops), snapshots[0].OperationCount)
+		require.Equal(t, rm.VersionID, snapshots[0].Model.VersionID)
+		require.Nil(t, snapshots[0].Model.PublishedOperations)
+
+		// Modify the snapshot to show that resolution starts from the snapshot.
+		snapshots[0].Model.Doc = document.Document{"test": "snapshot"}
+
+		snapshotStore = NewMemSnapshotStore()
+		require.NoError(t, snapshotStore.Put(snapshots[0]))
+
+		p = New("test", newStoreWithOps(t, h.ops), pc, WithSnapshotStore(snapshotStore))
+
+		rm, err = p.Resolve(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, "snapshot", rm.Doc["test"])
+		require.Len(t, rm.PublishedOperations, len(h.ops))
+
+		// Subsequent updates are applied on top of the snapshot.
+		h.update()
+
+		p = New("test", newStoreWithOps(t, h.ops), pc, WithSnapshotStore(snapshotStore))
+
+		rm, err = p.Resolve(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("special%d", len(h.ops)), rm.Doc["test"])
+	})
+
+	t.Run("snapshot store error", func(t *testing.T) {
+		h := newTestHistory(t)
+		h.updates(3)
+
+		p := New("test", newStoreWithOps(t, h.ops), pc,
+			WithSnapshotStore(&mockSnapshotStore{getErr: errors.New("injected get error")}), WithSnapshotInterval(1))
+
+		rm, err := p.Resolve(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, h.ops[len(h.ops)-1].CanonicalReference, rm.VersionID)
+
+		p = New("test", newStoreWithOps(t, h.ops), pc,
+			WithSnapshotStore(&mockSnapshotStore{putErr: errors.New("injected put error")}), WithSnapshotInterval(1))
+
+		rm, err = p.Resolve(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, h.ops[len(h.ops)-1].CanonicalReference, rm.VersionID)
+	})
+
+	t.Run("snapshot not taken for resolution options", func(t *testing.T) {
+		h := newTestHistory(t)
+		h.updates(3)
+
+		snapshotStore := NewMemSnapshotStore()
+
+		p := New("test", newStoreWithOps(t, h.ops), pc, WithSnapshotStore(snapshotStore), WithSnapshotInterval(1))
+
+		_, err := p.Resolve(h.uniqueSuffix, document.WithVersionID(h.ops[len(h.ops)-1].CanonicalReference))
+		require.NoError(t, err)
+
+		snapshots, err := snapshotStore.Get(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Empty(t, snapshots)
+	})
+}
+
+func TestOperationProcessor_Rollback(t *testing.T) {
+	pc := newMockProtocolClient()
+
+	t.Run("success", func(t *testing.T) {
+		h := newTestHistory(t)
+		h.updates(2)
+
+		forkUpdateKey := h.updateKey
+
+		h.update()
+
+		store := newStoreWithOps(t, h.ops)
+		snapshotStore := NewMemSnapshotStore()
+		cache := NewResolutionCache(10)
+
+		p := New("test", store, pc, WithSnapshotStore(snapshotStore), WithSnapshotInterval(len(h.ops)),
+			WithResolutionCache(cache))
+
+		rm, err := p.Resolve(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, "ref-4", rm.VersionID)
+
+		snapshots, err := snapshotStore.Get(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Len(t, snapshots, 1)
+
+		// Simulate a fork where the last update is orphaned and a different update is anchored in its place.
+		orphanedOp := h.ops[len(h.ops)-1]
+
+		deletedOps, err := store.DeleteByTransaction(orphanedOp.TransactionTime, orphanedOp.TransactionNumber)
+		require.NoError(t, err)
+		require.Equal(t, []*operation.AnchoredOperation{orphanedOp}, deletedOps)
+
+		forkOp, _, err := getAnchoredUpdateOperation(forkUpdateKey, h.uniqueSuffix, orphanedOp.TransactionTime)
+		require.NoError(t, err)
+
+		forkOp.TransactionNumber = orphanedOp.TransactionNumber
+		forkOp.CanonicalReference = "fork-4"
+
+		require.NoError(t, store.Put(forkOp))
+
+		require.NoError(t, p.Rollback(h.uniqueSuffix))
+		require.Equal(t, 1, cache.Len())
+
+		rm, err = p.Resolve(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, "fork-4", rm.VersionID)
+
+		snapshots, err = snapshotStore.Get(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Len(t, snapshots, 1)
+		require.Equal(t, "fork-4", snapshots[0].Model.VersionID)
+	})
+
+	t.Run("success - document no longer exists", func(t *testing.T) {
+		h := newTestHistory(t)
+
+		store := newStoreWithOps(t, h.ops)
+		cache := NewResolutionCache(10)
+
+		p := New("test", store, pc, WithResolutionCache(cache))
+
+		_, err := p.Resolve(h.uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, 1, cache.Len())
+
+		_, err = store.DeleteByTransaction(h.ops[0].TransactionTime, h.ops[0].TransactionNumber)
+		require.NoError(t, err)
+
+		require.NoError(t, p.Rollback(h.uniqueSuffix))
+		require.Equal(t, 0, cache.Len())
+
+		_, err = p.Resolve(h.uniqueSuffix)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("snapshot store error", func(t *testing.T) {
+		cache := NewResolutionCache(10)
+		cache.Put("suffix", &protocol.ResolutionModel{}, cache.Generation())
+
+		p := New("test", mocks.NewMockOperationStore(nil), pc, WithResolutionCache(cache),
+			WithSnapshotStore(&mockSnapshotStore{deleteErr: errors.New("injected delete error")}))
+
+		err := p.Rollback("suffix")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "delete snapshots for suffix[suffix]: injected delete error")
+		require.Equal(t, 0, cache.Len())
+	})
+}
+
+func TestMemSnapshotStore(t *testing.T) {
+	s := NewMemSnapshotStore()
+
+	snapshots, err := s.Get("suffix")
+	require.NoError(t, err)
+	require.Empty(t, snapshots)
+
+	rm := &protocol.ResolutionModel{Doc: document.Document{"id": "doc1"}}
+
+	require.NoError(t, s.Put(&Snapshot{UniqueSuffix: "suffix", TransactionTime: 1, Model: rm}))
+
+	// Changes to the model after it was stored should not affect the store.
+	rm.Doc["id"] = "doc2"
+
+	snapshots, err = s.Get("suffix")
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	require.Equal(t, "doc1", snapshots[0].Model.Doc["id"])
+
+	require.NoError(t, s.Delete("suffix"))
+
+	snapshots, err = s.Get("suffix")
+	require.NoError(t, err)
+	require.Empty(t, snapshots)
+
+	t.Run("marshal error", func(t *testing.T) {
+		err := s.Put(&Snapshot{UniqueSuffix: "suffix", Model: &protocol.ResolutionModel{AnchorOrigin: make(chan int)}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "marshal snapshot")
+	})
+
+	t.Run("unmarshal error", func(t *testing.T) {
+		s.snapshots["invalid"] = [][]byte{[]byte("{")}
+
+		_, err := s.Get("invalid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal snapshot")
+	})
+}
+
+// requireConsistentResolution resolves the document with and without snapshots and ensures
+// that the results are identical.
+func requireConsistentResolution(t *testing.T, uniqueSuffix string, published, unpublished []*operation.AnchoredOperation,
+	snapshotStore SnapshotStore, interval int, opts ...document.ResolutionOption) *protocol.ResolutionModel {
+	t.Helper()
+
+	pc := newMockProtocolClient()
+
+	unpublishedOpsStore := &mockUnpublishedOpsStore{AnchoredOps: unpublished}
+
+	expected, err := New("full", newStoreWithOps(t, published), pc,
+		WithUnpublishedOperationStore(unpublishedOpsStore)).Resolve(uniqueSuffix, opts...)
+	require.NoError(t, err)
+
+	actual, err := New("snapshot", newStoreWithOps(t, published), pc,
+		WithUnpublishedOperationStore(unpublishedOpsStore),
+		WithSnapshotStore(snapshotStore), WithSnapshotInterval(interval)).Resolve(uniqueSuffix, opts...)
+	require.NoError(t, err)
+
+	require.Equal(t, expected, actual)
+
+	return actual
+}
+
+func newStoreWithOps(t *testing.T, ops []*operation.AnchoredOperation) *mocks.MockOperationStore {
+	t.Helper()
+
+	store := mocks.NewMockOperationStore(nil)
+
+	for _, op := range ops {
+		require.NoError(t, store.Put(op))
+	}
+
+	return store
+}
+
+// testHistory generates a chain of operations for a single document.
+type testHistory struct {
+	t            *testing.T
+	uniqueSuffix string
+	recoveryKey  *ecdsa.PrivateKey
+	updateKey    *ecdsa.PrivateKey
+	ops          []*operation.AnchoredOperation
+	txnTime      uint64
+}
+
+func newTestHistory(t *testing.T) *testHistory {
+	t.Helper()
+
+	recoveryKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	updateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	createOp, err := getAnchoredCreateOperation(recoveryKey, updateKey)
+	require.NoError(t, err)
+
+	h := &testHistory{
+		t:            t,
+		uniqueSuffix: createOp.UniqueSuffix,
+		recoveryKey:  recoveryKey,
+		updateKey:    updateKey,
+	}
+
+	h.add(createOp)
+
+	return h
+}
+
+func (h *testHistory) updates(n int) {
+	for i := 0; i < n; i++ {
+		h.update()
+	}
+}
+
+func (h *testHistory) update() *operation.AnchoredOperation {
+	op, nextUpdateKey, err := getAnchoredUpdateOperation(h.updateKey, h.uniqueSuffix, h.txnTime+1)
+	require.NoError(h.t, err)
+
+	h.updateKey = nextUpdateKey
+
+	return h.add(op)
+}
+
+func (h *testHistory) recover() *operation.AnchoredOperation {
+	updateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(h.t, err)
+
+	op, nextRecoveryKey, err := getAnchoredRecoverOperation(h.recoveryKey, updateKey, h.uniqueSuffix, h.txnTime+1)
+	require.NoError(h.t, err)
+
+	h.recoveryKey = nextRecoveryKey
+	h.updateKey = updateKey
+
+	return h.add(op)
+}
+
+func (h *testHistory) deactivate() *operation.AnchoredOperation {
+	op, err := getDeactivateOperation(h.recoveryKey, h.uniqueSuffix)
+	require.NoError(h.t, err)
+
+	return h.add(getAnchoredOperation(op, h.txnTime+1))
+}
+
+func (h *testHistory) add(op *operation.AnchoredOperation) *operation.AnchoredOperation {
+	h.txnTime++
+
+	op.TransactionTime = h.txnTime
+	op.TransactionNumber = h.txnTime % 3
+	op.CanonicalReference = fmt.Sprintf("ref-%d", h.txnTime)
+
+	h.ops = append(h.ops, op)
+
+	return op
+}
+
+type mockSnapshotStore struct {
+	getErr    error
+	putErr    error
+	deleteErr error
+}
+
+func (m *mockSnapshotStore) Put(*Snapshot) error {
+	return m.putErr
+}
+
+func (m *mockSnapshotStore) Get(string) ([]*Snapshot, error) {
+	return nil, m.getErr
+}
+
+func (m *mockSnapshotStore) Delete(string) error {
+	return m.deleteErr
+}
@@ -15,7 +15,12 @@ import (
 
 // WriteResponse writes a response to the response writer.
 func WriteResponse(rw http.ResponseWriter, status int, v interface{}) {
-	rw.Header().Set("Content-Type", "application/did+ld+json")
+	WriteResponseWithContentType(rw, status, "application/did+ld+json", v)
+}
+
+// WriteResponseWithContentType writes a response with the given content type to the response writer.
+func WriteResponseWithContentType(rw http.ResponseWriter, status int, contentType string, v interface{}) {
+	rw.Header().Set("Content-Type", contentType)
 	rw.WriteHeader(status)
 	err := json.NewEncoder(rw).Encode(v)
 	if err != nil {
@@ -23,6 +23,14 @@ func TestWriteResponse(t *testing.T) {
 	require.Equal(t, "application/did+ld+json", rw.Header().Get("content-type"))
 }
 
+func TestWriteResponseWithContentType(t *testing.T) {
+	rw := httptest.NewRecorder()
+	WriteResponseWithContentType(rw, http.StatusGone, "application/json", "content")
+	require.Equal(t, http.StatusGone, rw.Code)
+	require.Equal(t, "\"content\"\n", rw.Body.String())
+	require.Equal(t, "application/json", rw.Header().Get("content-type"))
+}
+
 func TestWriteError(t *testing.T) {
 	errExpected := errors.New("some error")
 
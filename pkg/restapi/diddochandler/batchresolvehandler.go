@@ -0,0 +1,31 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+)
+
+// BatchResolveHandler resolves multiple DID documents in a single request.
+type BatchResolveHandler struct {
+	*handler
+}
+
+// NewBatchResolveHandler returns a new DID document batch resolve handler.
+func NewBatchResolveHandler(basePath string, resolver dochandler.Resolver,
+	opts ...dochandler.BatchResolveOption) *BatchResolveHandler {
+	return &BatchResolveHandler{
+		handler: newHandler(
+			fmt.Sprintf("%s/batch", basePath),
+			http.MethodPost,
+			dochandler.NewBatchResolveHandler(resolver, opts...).Resolve,
+		),
+	}
+}
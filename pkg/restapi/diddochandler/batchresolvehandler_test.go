@@ -0,0 +1,34 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+)
+
+func TestBatchResolveHandler_Resolve(t *testing.T) {
+	docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace)
+	handler := NewBatchResolveHandler(resolutionPath, docHandler, dochandler.WithMaxBatchSize(1))
+	require.Equal(t, resolutionPath+"/batch", handler.Path())
+	require.Equal(t, http.MethodPost, handler.Method())
+	require.NotNil(t, handler.Handler())
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, resolutionPath+"/batch",
+		bytes.NewBufferString(`{"items":[{"id":"did:sidetree:1"},{"id":"did:sidetree:2"}]}`))
+	handler.Handler()(rw, req)
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+	require.Contains(t, rw.Body.String(), "exceeds the maximum of 1")
+}
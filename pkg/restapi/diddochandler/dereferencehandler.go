@@ -0,0 +1,31 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+)
+
+// DereferenceHandler dereferences DID URLs.
+type DereferenceHandler struct {
+	*handler
+}
+
+// NewDereferenceHandler returns a new DID URL dereference handler. The handler is registered at
+// {basePath}/{id}/dereference so that it doesn't clash with the resolve handler at {basePath}/{id}.
+func NewDereferenceHandler(basePath string, dereferencer dochandler.Dereferencer) *DereferenceHandler {
+	return &DereferenceHandler{
+		handler: newHandler(
+			fmt.Sprintf("%s/{id}/dereference", basePath),
+			http.MethodGet,
+			dochandler.NewDereferenceHandler(dereferencer).Dereference,
+		),
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
+	"github.com/trustbloc/sidetree-core-go/pkg/dereferencer"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+func TestDereferenceHandler_Dereference(t *testing.T) {
+	docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace)
+	handler := NewDereferenceHandler(resolutionPath, dereferencer.New(docHandler))
+	require.Equal(t, resolutionPath+"/{id}/dereference", handler.Path())
+	require.Equal(t, http.MethodGet, handler.Method())
+	require.NotNil(t, handler.Handler())
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, resolutionPath, nil)
+	handler.Handler()(rw, req)
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+	require.Contains(t, rw.Body.String(), "invalidDidUrl")
+}
+
+func TestDereferenceHandler_Routing(t *testing.T) {
+	docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace)
+
+	createRequest, err := getCreateRequest()
+	require.NoError(t, err)
+
+	request, err := canonicalizer.MarshalCanonical(createRequest)
+	require.NoError(t, err)
+
+	result, err := docHandler.ProcessOperation(request, 0)
+	require.NoError(t, err)
+
+	didID := result.Document.ID()
+
+	router := mux.NewRouter()
+
+	for _, handler := range []common.HTTPHandler{
+		NewResolveHandler(resolutionPath, docHandler, &mocks.MetricsProvider{}),
+		NewDereferenceHandler(resolutionPath, dereferencer.New(docHandler)),
+	} {
+		router.HandleFunc(handler.Path(), handler.Handler()).Methods(handler.Method())
+	}
+
+	t.Run("resolve", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, resolutionPath+"/"+didID, nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		resolutionResult := &document.ResolutionResult{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resolutionResult))
+		require.Equal(t, didID, resolutionResult.Document.ID())
+	})
+
+	t.Run("dereference", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, resolutionPath+"/"+didID+"/dereference", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		doc, err := document.FromBytes(rw.Body.Bytes())
+		require.NoError(t, err)
+		require.Equal(t, didID, doc.ID())
+	})
+}
@@ -0,0 +1,31 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+)
+
+// OperationHistoryHandler returns the operation history of a DID.
+type OperationHistoryHandler struct {
+	*handler
+}
+
+// NewOperationHistoryHandler returns a new operation history handler. The base path is normally the same
+// path that DIDs are resolved from (e.g. /sidetree/v1/identifiers).
+func NewOperationHistoryHandler(basePath string, provider dochandler.OperationHistoryProvider) *OperationHistoryHandler {
+	return &OperationHistoryHandler{
+		handler: newHandler(
+			fmt.Sprintf("%s/{id}/history", basePath),
+			http.MethodGet,
+			dochandler.NewOperationHistoryHandler(provider).GetHistory,
+		),
+	}
+}
@@ -0,0 +1,36 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+func TestOperationHistoryHandler(t *testing.T) {
+	handler := NewOperationHistoryHandler(resolutionPath, &mockHistoryProvider{})
+	require.Equal(t, resolutionPath+"/{id}/history", handler.Path())
+	require.Equal(t, http.MethodGet, handler.Method())
+	require.NotNil(t, handler.Handler())
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, resolutionPath, nil)
+	handler.Handler()(rw, req)
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+	require.Contains(t, rw.Body.String(), "DID is required")
+}
+
+type mockHistoryProvider struct{}
+
+func (m *mockHistoryProvider) GetOperationHistory(string, int, int) (*document.OperationHistory, error) {
+	return &document.OperationHistory{}, nil
+}
@@ -0,0 +1,31 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+)
+
+// OperationStatusHandler returns the status of an operation.
+type OperationStatusHandler struct {
+	*handler
+}
+
+// NewOperationStatusHandler returns a new operation status handler. The base path is normally the same
+// path that operations are posted to (e.g. /sidetree/v1/operations).
+func NewOperationStatusHandler(basePath string, provider dochandler.OperationStatusProvider) *OperationStatusHandler {
+	return &OperationStatusHandler{
+		handler: newHandler(
+			fmt.Sprintf("%s/{hash}", basePath),
+			http.MethodGet,
+			dochandler.NewOperationStatusHandler(provider).GetStatus,
+		),
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
 )
 
 const (
@@ -40,6 +41,7 @@ func TestRESTAPI(t *testing.T) {
 		url,
 		NewUpdateHandler(operationsPath, didDocHandler, pc, &mocks.MetricsProvider{}),
 		NewResolveHandler(resolutionPath, didDocHandler, &mocks.MetricsProvider{}),
+		NewBatchResolveHandler(resolutionPath, didDocHandler),
 	)
 	s.start()
 	defer s.stop()
@@ -78,6 +80,28 @@ func TestRESTAPI(t *testing.T) {
 
 		require.Equal(t, didID, result.Document["id"])
 	})
+	t.Run("Batch resolve DID docs", func(t *testing.T) {
+		createRequest, err := getCreateRequest()
+		require.NoError(t, err)
+
+		didID, err := getID(createRequest.SuffixData)
+		require.NoError(t, err)
+
+		request, err := json.Marshal(&dochandler.BatchResolveRequest{
+			Items: []*dochandler.BatchResolveRequestItem{{ID: didID}, {ID: namespace + ":notfound"}},
+		})
+		require.NoError(t, err)
+
+		resp, err := httpPost(t, clientURL+resolutionPath+"/batch", request)
+		require.NoError(t, err)
+
+		var response dochandler.BatchResolveResponse
+		require.NoError(t, json.Unmarshal(resp, &response))
+		require.Len(t, response.Results, 2)
+		require.Equal(t, http.StatusOK, response.Results[0].Status)
+		require.Equal(t, didID, response.Results[0].ResolutionResult.Document["id"])
+		require.Equal(t, http.StatusNotFound, response.Results[1].Status)
+	})
 }
 
 // httpPut sends a regular POST request to the sidetree-node
@@ -100,6 +124,19 @@ func httpPut(t *testing.T, url string, request []byte) ([]byte, error) {
 	return handleHTTPResp(t, resp)
 }
 
+// httpPost sends a POST request with a JSON body and expects a JSON response.
+func httpPost(t *testing.T, url string, request []byte) ([]byte, error) {
+	resp, err := invokeWithRetry(
+		func() (response *http.Response, e error) {
+			return http.Post(url, "application/json", bytes.NewReader(request))
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "application/json", resp.Header.Get("content-type"))
+
+	return handleHTTPResp(t, resp)
+}
+
 // httpGet send a regular GET request to the sidetree-node and expects 'side tree document' argument as a response.
 func httpGet(t *testing.T, url string) ([]byte, error) {
 	client := &http.Client{}
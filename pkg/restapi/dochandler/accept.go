@@ -0,0 +1,134 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MediaTypeDIDLDJSON is the media type of a DID document.
+	MediaTypeDIDLDJSON = "application/did+ld+json"
+
+	// MediaTypeDIDResolution is the media type of a DID resolution result.
+	MediaTypeDIDResolution = `application/ld+json;profile="https://w3id.org/did-resolution"`
+
+	acceptHeader = "Accept"
+
+	didResolutionProfile = "https://w3id.org/did-resolution"
+)
+
+type representation int
+
+const (
+	// representationDefault returns the resolution result with content type application/did+ld+json
+	// (for clients that don't specify an Accept header).
+	representationDefault representation = iota
+	representationDocument
+	representationResolutionResult
+)
+
+type mediaRange struct {
+	mediaType string
+	profile   string
+	q         float64
+}
+
+// negotiateRepresentation returns the representation of the resolution response according to the given
+// Accept header values. Media ranges are considered in order of preference (q-value). If none of the acceptable
+// media types are supported then the default representation is returned, unless the client explicitly excluded
+// it (with q=0), in which case an error is returned.
+func negotiateRepresentation(accept []string) (representation, error) {
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		return representationDefault, nil
+	}
+
+	for _, r := range ranges {
+		if r.q <= 0 {
+			// not acceptable
+			continue
+		}
+
+		switch r.mediaType {
+		case MediaTypeDIDLDJSON:
+			return representationDocument, nil
+		case "application/ld+json":
+			if r.profile == didResolutionProfile {
+				return representationResolutionResult, nil
+			}
+		case "application/json", "*/*", "application/*":
+			return representationDefault, nil
+		}
+	}
+
+	if isDefaultExcluded(ranges) {
+		return representationDefault, fmt.Errorf("unsupported representation %s; supported representations: %s, %s",
+			strings.Join(accept, ","), MediaTypeDIDLDJSON, MediaTypeDIDResolution)
+	}
+
+	return representationDefault, nil
+}
+
+// isDefaultExcluded returns true if the client marked a media range that matches the default representation
+// (application/did+ld+json) as not acceptable.
+func isDefaultExcluded(ranges []*mediaRange) bool {
+	for _, r := range ranges {
+		if r.q > 0 {
+			continue
+		}
+
+		switch r.mediaType {
+		case MediaTypeDIDLDJSON, "application/json", "*/*", "application/*":
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseAccept(accept []string) []*mediaRange {
+	var ranges []*mediaRange
+
+	for _, value := range accept {
+		for _, part := range strings.Split(value, ",") {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+
+			mediaType, params, err := mime.ParseMediaType(part)
+			if err != nil {
+				logger.Debug("Ignoring invalid media range in Accept header")
+
+				continue
+			}
+
+			r := &mediaRange{mediaType: mediaType, profile: params["profile"], q: 1}
+
+			if qValue, ok := params["q"]; ok {
+				q, err := strconv.ParseFloat(qValue, 64)
+				if err != nil {
+					continue
+				}
+
+				r.q = q
+			}
+
+			ranges = append(ranges, r)
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	return ranges
+}
@@ -0,0 +1,70 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateRepresentation(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   []string
+		expected representation
+		err      bool
+	}{
+		{name: "no accept header", expected: representationDefault},
+		{name: "any", accept: []string{"*/*"}, expected: representationDefault},
+		{name: "JSON", accept: []string{"application/json"}, expected: representationDefault},
+		{
+			name:     "JSON and DID document",
+			accept:   []string{"application/json;q=0.5, " + MediaTypeDIDLDJSON},
+			expected: representationDocument,
+		},
+		{name: "DID document", accept: []string{MediaTypeDIDLDJSON}, expected: representationDocument},
+		{name: "resolution result", accept: []string{MediaTypeDIDResolution}, expected: representationResolutionResult},
+		{
+			name:     "resolution result with whitespace",
+			accept:   []string{`application/ld+json; profile="https://w3id.org/did-resolution"`},
+			expected: representationResolutionResult,
+		},
+		{
+			name:     "q-value preference",
+			accept:   []string{MediaTypeDIDResolution + ";q=0.2", MediaTypeDIDLDJSON + ";q=0.8"},
+			expected: representationDocument,
+		},
+		{
+			name:     "unsupported and supported",
+			accept:   []string{"text/html, application/xhtml+xml, " + MediaTypeDIDLDJSON + ";q=0.1"},
+			expected: representationDocument,
+		},
+		{name: "q=0 is not acceptable", accept: []string{MediaTypeDIDLDJSON + ";q=0"}, err: true},
+		{name: "invalid q-value is ignored", accept: []string{MediaTypeDIDLDJSON + ";q=x"}, expected: representationDefault},
+		{name: "invalid media type is ignored", accept: []string{"/, "}, expected: representationDefault},
+		{name: "ld+json without profile", accept: []string{"application/ld+json"}, expected: representationDefault},
+		{name: "unsupported falls back to default", accept: []string{"text/html"}, expected: representationDefault},
+		{name: "unsupported and default excluded", accept: []string{"text/html, */*;q=0"}, err: true},
+		{name: "JSON excluded", accept: []string{"text/html, application/json;q=0"}, err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repr, err := negotiateRepresentation(tc.accept)
+			if tc.err {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "unsupported representation")
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, repr)
+		})
+	}
+}
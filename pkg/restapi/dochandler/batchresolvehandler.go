@@ -0,0 +1,270 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+const (
+	defaultMaxBatchSize   = 100
+	defaultMaxConcurrency = 10
+	defaultMaxItemSize    = 16 * 1024
+
+	contentTypeJSON = "application/json"
+)
+
+// BatchResolveRequest holds the DIDs to resolve in a batch resolution request.
+type BatchResolveRequest struct {
+	Items []*BatchResolveRequestItem `json:"items"`
+}
+
+// BatchResolveRequestItem holds a short or long-form DID along with optional resolution options.
+type BatchResolveRequestItem struct {
+	ID          string `json:"id"`
+	VersionID   string `json:"versionId,omitempty"`
+	VersionTime string `json:"versionTime,omitempty"`
+}
+
+// BatchResolveResponse holds the results of a batch resolution request. The results are in the same
+// order as the items in the request.
+type BatchResolveResponse struct {
+	Results []*BatchResolveResult `json:"results"`
+}
+
+// BatchResolveResult holds the result of resolving a single DID. Status is the HTTP status code that would have
+// been returned if the DID was resolved individually. If the DID could not be resolved then the resolution result
+// contains the error in the resolution metadata.
+type BatchResolveResult struct {
+	ID               string                     `json:"id"`
+	Status           int                        `json:"status"`
+	ResolutionResult *document.ResolutionResult `json:"resolutionResult"`
+}
+
+// BatchResolveOption is an option for the batch resolve handler.
+type BatchResolveOption func(h *BatchResolveHandler)
+
+// WithMaxBatchSize sets the maximum number of DIDs that may be resolved in a single request. Defaults to 100.
+func WithMaxBatchSize(size int) BatchResolveOption {
+	return func(h *BatchResolveHandler) {
+		h.maxBatchSize = size
+	}
+}
+
+// WithMaxConcurrency sets the maximum number of DIDs of a request that are resolved concurrently. Defaults to 10.
+func WithMaxConcurrency(n int) BatchResolveOption {
+	return func(h *BatchResolveHandler) {
+		h.maxConcurrency = n
+	}
+}
+
+// WithMaxItemSize sets the maximum size (in bytes) of a single item in a batch resolution request. The maximum
+// size of the request body is the maximum item size multiplied by the maximum batch size. Defaults to 16 KB,
+// which leaves room for long-form DIDs.
+func WithMaxItemSize(size int) BatchResolveOption {
+	return func(h *BatchResolveHandler) {
+		h.maxItemSize = size
+	}
+}
+
+// BatchResolveHandler resolves multiple documents in a single request.
+type BatchResolveHandler struct {
+	resolver       Resolver
+	maxBatchSize   int
+	maxConcurrency int
+	maxItemSize    int
+}
+
+// NewBatchResolveHandler returns a new batch resolve handler.
+func NewBatchResolveHandler(resolver Resolver, opts ...BatchResolveOption) *BatchResolveHandler {
+	h := &BatchResolveHandler{
+		resolver:       resolver,
+		maxBatchSize:   defaultMaxBatchSize,
+		maxConcurrency: defaultMaxConcurrency,
+		maxItemSize:    defaultMaxItemSize,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if h.maxConcurrency <= 0 {
+		h.maxConcurrency = 1
+	}
+
+	return h
+}
+
+// Resolve resolves the DIDs in the batch resolution request (see BatchResolveRequest). The DIDs are resolved
+// concurrently by a bounded pool of workers. A failure to resolve one DID does not fail the request; instead,
+// the error is returned in the result for that DID.
+func (h *BatchResolveHandler) Resolve(rw http.ResponseWriter, req *http.Request) {
+	request, err := h.readRequest(rw, req)
+	if err != nil {
+		status := http.StatusBadRequest
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			status = http.StatusRequestEntityTooLarge
+		}
+
+		common.WriteError(rw, status, err)
+
+		return
+	}
+
+	logger.Debug("Resolving batch of DID documents", log.WithTotal(len(request.Items)))
+
+	common.WriteResponseWithContentType(rw, http.StatusOK, contentTypeJSON,
+		&BatchResolveResponse{Results: h.resolveAll(request.Items)},
+	)
+}
+
+func (h *BatchResolveHandler) readRequest(rw http.ResponseWriter, req *http.Request) (*BatchResolveRequest, error) {
+	body := req.Body
+	if maxSize := h.maxRequestSize(); maxSize > 0 {
+		body = http.MaxBytesReader(rw, req.Body, maxSize)
+	}
+
+	reqBytes, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("read batch resolution request: %w", err)
+	}
+
+	request := &BatchResolveRequest{}
+
+	if err := json.Unmarshal(reqBytes, request); err != nil {
+		return nil, fmt.Errorf("invalid batch resolution request: %w", err)
+	}
+
+	if len(request.Items) == 0 {
+		return nil, errors.New("batch resolution request must contain at least one item")
+	}
+
+	if h.maxBatchSize > 0 && len(request.Items) > h.maxBatchSize {
+		return nil, fmt.Errorf("batch resolution request contains %d items which exceeds the maximum of %d",
+			len(request.Items), h.maxBatchSize)
+	}
+
+	for i, item := range request.Items {
+		if item == nil || item.ID == "" {
+			return nil, fmt.Errorf("missing ID in batch resolution request item %d", i)
+		}
+	}
+
+	return request, nil
+}
+
+// maxRequestSize returns the maximum size of the request body or zero if the size is not limited.
+func (h *BatchResolveHandler) maxRequestSize() int64 {
+	if h.maxBatchSize <= 0 || h.maxItemSize <= 0 {
+		return 0
+	}
+
+	return int64(h.maxBatchSize) * int64(h.maxItemSize)
+}
+
+func (h *BatchResolveHandler) resolveAll(items []*BatchResolveRequestItem) []*BatchResolveResult {
+	results := make([]*BatchResolveResult, len(items))
+
+	workers := h.maxConcurrency
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				results[i] = h.resolve(items[i])
+			}
+		}()
+	}
+
+	for i := range items {
+		indexes <- i
+	}
+
+	close(indexes)
+
+	wg.Wait()
+
+	return results
+}
+
+func (h *BatchResolveHandler) resolve(item *BatchResolveRequestItem) *BatchResolveResult {
+	opts, err := getItemResolutionOptions(item)
+	if err != nil {
+		return newBatchResolveErrorResult(item.ID,
+			newResolutionError(http.StatusBadRequest, document.InvalidOptionsError, err))
+	}
+
+	result, err := h.resolver.ResolveDocument(item.ID, opts...)
+	if err != nil {
+		return newBatchResolveErrorResult(item.ID, toResolutionError(item.ID, err))
+	}
+
+	status := http.StatusOK
+	if isDeactivated(result) {
+		status = http.StatusGone
+	}
+
+	result.ResolutionMetadata = document.Metadata{document.ContentTypeProperty: MediaTypeDIDLDJSON}
+
+	return &BatchResolveResult{
+		ID:               item.ID,
+		Status:           status,
+		ResolutionResult: result,
+	}
+}
+
+func newBatchResolveErrorResult(id string, e *resolutionError) *BatchResolveResult {
+	logResolutionError(e)
+
+	return &BatchResolveResult{
+		ID:     id,
+		Status: e.status,
+		ResolutionResult: &document.ResolutionResult{
+			Context:            didResolutionContext,
+			ResolutionMetadata: e.metadata(),
+		},
+	}
+}
+
+func getItemResolutionOptions(item *BatchResolveRequestItem) ([]document.ResolutionOption, error) {
+	if item.VersionID != "" && item.VersionTime != "" {
+		return nil, fmt.Errorf("cannot specify both '%s' and '%s'", versionIDParam, versionTimeParam)
+	}
+
+	var opts []document.ResolutionOption
+
+	if item.VersionID != "" {
+		opts = append(opts, document.WithVersionID(item.VersionID))
+	}
+
+	if item.VersionTime != "" {
+		opts = append(opts, document.WithVersionTime(item.VersionTime))
+	}
+
+	return opts, nil
+}
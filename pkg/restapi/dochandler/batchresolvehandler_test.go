@@ -0,0 +1,204 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+func TestBatchResolveHandler_Resolve(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		resolver := newMockBatchResolver()
+		resolver.results["did:sidetree:deactivated"] = &document.ResolutionResult{
+			Document:         document.Document{"id": "did:sidetree:deactivated"},
+			DocumentMetadata: document.Metadata{document.DeactivatedProperty: true},
+		}
+		resolver.errors["did:sidetree:notfound"] = errors.New("not found")
+		resolver.errors["did:sidetree:error"] = errors.New("injected resolver error")
+
+		handler := NewBatchResolveHandler(resolver)
+
+		rw := httptest.NewRecorder()
+		handler.Resolve(rw, newBatchResolveRequest(t, &BatchResolveRequest{
+			Items: []*BatchResolveRequestItem{
+				{ID: "did:sidetree:abc"},
+				{ID: "did:sidetree:notfound"},
+				{ID: "did:sidetree:xyz", VersionID: "v1"},
+				{ID: "did:sidetree:abc", VersionID: "v1", VersionTime: "2021-05-10T17:00:00Z"},
+				{ID: "did:sidetree:deactivated"},
+				{ID: "did:sidetree:error"},
+			},
+		}))
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, contentTypeJSON, rw.Header().Get("content-type"))
+
+		response := &BatchResolveResponse{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), response))
+		require.Len(t, response.Results, 6)
+
+		requireBatchResult(t, response.Results[0], "did:sidetree:abc", http.StatusOK, "")
+		require.Equal(t, "did:sidetree:abc", response.Results[0].ResolutionResult.Document.ID())
+		require.Equal(t, MediaTypeDIDLDJSON,
+			response.Results[0].ResolutionResult.ResolutionMetadata[document.ContentTypeProperty])
+
+		requireBatchResult(t, response.Results[1], "did:sidetree:notfound", http.StatusNotFound, document.NotFoundError)
+		requireBatchResult(t, response.Results[2], "did:sidetree:xyz", http.StatusOK, "")
+		requireBatchResult(t, response.Results[3], "did:sidetree:abc", http.StatusBadRequest, document.InvalidOptionsError)
+		requireBatchResult(t, response.Results[4], "did:sidetree:deactivated", http.StatusGone, "")
+		requireBatchResult(t, response.Results[5], "did:sidetree:error", http.StatusInternalServerError,
+			document.InternalError)
+
+		require.Equal(t, 1, resolver.optsCount["did:sidetree:xyz"])
+	})
+
+	t.Run("bounded concurrency", func(t *testing.T) {
+		resolver := newMockBatchResolver()
+		resolver.delay = 10 * time.Millisecond
+
+		handler := NewBatchResolveHandler(resolver, WithMaxConcurrency(3))
+
+		request := &BatchResolveRequest{}
+		for i := 0; i < 20; i++ {
+			request.Items = append(request.Items, &BatchResolveRequestItem{ID: fmt.Sprintf("did:sidetree:%d", i)})
+		}
+
+		rw := httptest.NewRecorder()
+		handler.Resolve(rw, newBatchResolveRequest(t, request))
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		response := &BatchResolveResponse{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), response))
+		require.Len(t, response.Results, 20)
+
+		for i, result := range response.Results {
+			requireBatchResult(t, result, fmt.Sprintf("did:sidetree:%d", i), http.StatusOK, "")
+		}
+
+		require.LessOrEqual(t, resolver.maxActive, 3)
+		require.Greater(t, resolver.maxActive, 1)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		handler := NewBatchResolveHandler(newMockBatchResolver(), WithMaxBatchSize(2), WithMaxConcurrency(0))
+
+		for _, body := range []string{
+			`{`,
+			`{}`,
+			`{"items":[]}`,
+			`{"items":[{"id":"did:sidetree:1"},{"id":"did:sidetree:2"},{"id":"did:sidetree:3"}]}`,
+			`{"items":[{"id":""}]}`,
+			`{"items":[null]}`,
+		} {
+			rw := httptest.NewRecorder()
+			handler.Resolve(rw, httptest.NewRequest(http.MethodPost, "/batch", bytes.NewBufferString(body)))
+			require.Equal(t, http.StatusBadRequest, rw.Code, body)
+		}
+
+		rw := httptest.NewRecorder()
+		handler.Resolve(rw, httptest.NewRequest(http.MethodPost, "/batch",
+			bytes.NewBufferString(`{"items":[{"id":"did:sidetree:1"},{"id":"did:sidetree:2"}]}`)))
+		require.Equal(t, http.StatusOK, rw.Code)
+	})
+
+	t.Run("request too large", func(t *testing.T) {
+		handler := NewBatchResolveHandler(newMockBatchResolver(), WithMaxBatchSize(2), WithMaxItemSize(50))
+
+		rw := httptest.NewRecorder()
+		handler.Resolve(rw, httptest.NewRequest(http.MethodPost, "/batch",
+			bytes.NewBufferString(`{"items":[{"id":"did:sidetree:1"},{"id":"did:sidetree:2"}]}`)))
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		rw = httptest.NewRecorder()
+		handler.Resolve(rw, httptest.NewRequest(http.MethodPost, "/batch",
+			bytes.NewBufferString(fmt.Sprintf(`{"items":[{"id":"did:sidetree:%s"}]}`, strings.Repeat("1", 100)))))
+		require.Equal(t, http.StatusRequestEntityTooLarge, rw.Code)
+	})
+}
+
+func requireBatchResult(t *testing.T, result *BatchResolveResult, id string, status int, errCode string) {
+	t.Helper()
+
+	require.Equal(t, id, result.ID)
+	require.Equal(t, status, result.Status)
+	require.NotNil(t, result.ResolutionResult)
+
+	if errCode == "" {
+		require.NotNil(t, result.ResolutionResult.Document)
+		require.Empty(t, result.ResolutionResult.ResolutionMetadata[document.ErrorProperty])
+	} else {
+		require.Nil(t, result.ResolutionResult.Document)
+		require.Equal(t, errCode, result.ResolutionResult.ResolutionMetadata[document.ErrorProperty])
+	}
+}
+
+func newBatchResolveRequest(t *testing.T, request *BatchResolveRequest) *http.Request {
+	t.Helper()
+
+	reqBytes, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	return httptest.NewRequest(http.MethodPost, "/batch", bytes.NewBuffer(reqBytes))
+}
+
+type mockBatchResolver struct {
+	mutex     sync.Mutex
+	results   map[string]*document.ResolutionResult
+	errors    map[string]error
+	optsCount map[string]int
+	delay     time.Duration
+	active    int
+	maxActive int
+}
+
+func newMockBatchResolver() *mockBatchResolver {
+	return &mockBatchResolver{
+		results:   make(map[string]*document.ResolutionResult),
+		errors:    make(map[string]error),
+		optsCount: make(map[string]int),
+	}
+}
+
+func (m *mockBatchResolver) ResolveDocument(id string, opts ...document.ResolutionOption) (*document.ResolutionResult, error) {
+	m.mutex.Lock()
+	m.active++
+	if m.active > m.maxActive {
+		m.maxActive = m.active
+	}
+	m.optsCount[id] = len(opts)
+	result, resultExists := m.results[id]
+	err := m.errors[id]
+	m.mutex.Unlock()
+
+	time.Sleep(m.delay)
+
+	m.mutex.Lock()
+	m.active--
+	m.mutex.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !resultExists {
+		result = &document.ResolutionResult{Document: document.Document{"id": id}}
+	}
+
+	return result, nil
+}
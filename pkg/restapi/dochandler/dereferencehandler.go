@@ -0,0 +1,137 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/dereferencer"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+// Dereferencer dereferences DID URLs.
+type Dereferencer interface {
+	Dereference(didURL string) (*document.DereferencingResult, error)
+}
+
+// DereferenceHandler dereferences DID URLs.
+type DereferenceHandler struct {
+	dereferencer Dereferencer
+}
+
+// NewDereferenceHandler returns a new DID URL dereference handler.
+func NewDereferenceHandler(dereferencer Dereferencer) *DereferenceHandler {
+	return &DereferenceHandler{
+		dereferencer: dereferencer,
+	}
+}
+
+// Dereference dereferences a DID URL. The DID URL is taken from the request path (a fragment must be
+// percent-encoded) and the query of the request is used as the query of the DID URL, for example:
+//
+//	GET /identifiers/did:sidetree:abc%23key-1/dereference
+//	GET /identifiers/did:sidetree:abc/dereference?service=hub&relativeRef=/foo
+//
+// If the dereferencing result is requested (application/ld+json;profile="https://w3id.org/did-resolution")
+// then the full dereferencing result is returned. Otherwise, a service endpoint URL results in a redirect (303)
+// to the URL and any other resource is returned as application/did+ld+json.
+func (h *DereferenceHandler) Dereference(rw http.ResponseWriter, req *http.Request) {
+	repr, err := negotiateRepresentation(req.Header.Values(acceptHeader))
+	if err != nil {
+		writeDereferencingError(rw, newResolutionError(http.StatusNotAcceptable, document.RepresentationNotSupportedError, err))
+
+		return
+	}
+
+	didURL := getID(req)
+	if req.URL.RawQuery != "" {
+		didURL += "?" + req.URL.RawQuery
+	}
+
+	logger.Debug("Dereferencing DID URL", log.WithID(didURL))
+
+	result, err := h.dereferencer.Dereference(didURL)
+	if err != nil {
+		writeDereferencingError(rw, toDereferencingError(didURL, err))
+
+		return
+	}
+
+	status := http.StatusOK
+	if isDeactivatedMetadata(result.ContentMetadata) {
+		status = http.StatusGone
+	}
+
+	if repr == representationResolutionResult {
+		common.WriteResponseWithContentType(rw, status, MediaTypeDIDResolution, result)
+
+		return
+	}
+
+	contentType, ok := result.DereferencingMetadata[document.ContentTypeProperty].(string)
+	if !ok || contentType == "" {
+		contentType = MediaTypeDIDLDJSON
+	}
+
+	if contentType != dereferencer.ContentTypeURIList {
+		common.WriteResponseWithContentType(rw, status, contentType, result.ContentStream)
+
+		return
+	}
+
+	writeURIList(rw, req, status, result.ContentStream)
+}
+
+func writeURIList(rw http.ResponseWriter, req *http.Request, status int, content interface{}) {
+	var urls []string
+
+	switch c := content.(type) {
+	case string:
+		if status == http.StatusOK {
+			http.Redirect(rw, req, c, http.StatusSeeOther)
+
+			return
+		}
+
+		urls = []string{c}
+	case []string:
+		urls = c
+	}
+
+	rw.Header().Set("Content-Type", dereferencer.ContentTypeURIList)
+	rw.WriteHeader(status)
+
+	if _, err := rw.Write([]byte(strings.Join(urls, "\r\n") + "\r\n")); err != nil {
+		log.WriteResponseBodyError(logger, err)
+	}
+}
+
+func toDereferencingError(didURL string, err error) *resolutionError {
+	switch {
+	case errors.Is(err, dereferencer.ErrInvalidDIDURL):
+		return newResolutionError(http.StatusBadRequest, document.InvalidDIDURLError, err)
+	case errors.Is(err, dereferencer.ErrNotFound):
+		return newResolutionError(http.StatusNotFound, document.NotFoundError, err)
+	default:
+		return toResolutionError(didURL, err)
+	}
+}
+
+func writeDereferencingError(rw http.ResponseWriter, e *resolutionError) {
+	logResolutionError(e)
+
+	common.WriteResponseWithContentType(rw, e.status, MediaTypeDIDResolution,
+		&document.DereferencingResult{
+			Context:               didResolutionContext,
+			DereferencingMetadata: e.metadata(),
+		},
+	)
+}
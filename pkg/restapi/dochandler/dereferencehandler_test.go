@@ -0,0 +1,160 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/dereferencer"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+const testDID = "did:sidetree:abc"
+
+func TestDereferenceHandler_Dereference(t *testing.T) {
+	getID = func(req *http.Request) string { return testDID + "#key-1" }
+
+	t.Run("verification method", func(t *testing.T) {
+		d := &mockDereferencer{result: newDereferencingResult(map[string]interface{}{"id": "#key-1"},
+			dereferencer.ContentTypeDIDLDJSON, false)}
+
+		rw := httptest.NewRecorder()
+		NewDereferenceHandler(d).Dereference(rw, httptest.NewRequest(http.MethodGet, "/document", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, MediaTypeDIDLDJSON, rw.Header().Get("content-type"))
+		require.Equal(t, testDID+"#key-1", d.didURL)
+
+		vm := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &vm))
+		require.Equal(t, "#key-1", vm["id"])
+	})
+
+	t.Run("query", func(t *testing.T) {
+		getID = func(req *http.Request) string { return testDID }
+		defer func() { getID = func(req *http.Request) string { return testDID + "#key-1" } }()
+
+		d := &mockDereferencer{result: newDereferencingResult("https://hub.example.com/foo",
+			dereferencer.ContentTypeURIList, false)}
+
+		rw := httptest.NewRecorder()
+		NewDereferenceHandler(d).Dereference(rw,
+			httptest.NewRequest(http.MethodGet, "/document?service=hub&relativeRef=%2Ffoo", nil))
+		require.Equal(t, http.StatusSeeOther, rw.Code)
+		require.Equal(t, "https://hub.example.com/foo", rw.Header().Get("Location"))
+		require.Equal(t, testDID+"?service=hub&relativeRef=%2Ffoo", d.didURL)
+	})
+
+	t.Run("multiple service endpoints", func(t *testing.T) {
+		d := &mockDereferencer{result: newDereferencingResult([]string{"https://a.example.com", "https://b.example.com"},
+			dereferencer.ContentTypeURIList, false)}
+
+		rw := httptest.NewRecorder()
+		NewDereferenceHandler(d).Dereference(rw, httptest.NewRequest(http.MethodGet, "/document", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, dereferencer.ContentTypeURIList, rw.Header().Get("content-type"))
+		require.Equal(t, "https://a.example.com\r\nhttps://b.example.com\r\n", rw.Body.String())
+	})
+
+	t.Run("deactivated", func(t *testing.T) {
+		d := &mockDereferencer{result: newDereferencingResult("https://hub.example.com",
+			dereferencer.ContentTypeURIList, true)}
+
+		rw := httptest.NewRecorder()
+		NewDereferenceHandler(d).Dereference(rw, httptest.NewRequest(http.MethodGet, "/document", nil))
+		require.Equal(t, http.StatusGone, rw.Code)
+		require.Equal(t, "https://hub.example.com\r\n", rw.Body.String())
+	})
+
+	t.Run("dereferencing result", func(t *testing.T) {
+		d := &mockDereferencer{result: newDereferencingResult("https://hub.example.com",
+			dereferencer.ContentTypeURIList, false)}
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/document", nil)
+		req.Header.Set("Accept", MediaTypeDIDResolution)
+		NewDereferenceHandler(d).Dereference(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, MediaTypeDIDResolution, rw.Header().Get("content-type"))
+
+		result := &document.DereferencingResult{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), result))
+		require.Equal(t, "https://hub.example.com", result.ContentStream)
+		require.Equal(t, dereferencer.ContentTypeURIList, result.DereferencingMetadata[document.ContentTypeProperty])
+	})
+}
+
+func TestDereferenceHandler_Errors(t *testing.T) {
+	getID = func(req *http.Request) string { return testDID + "#key-1" }
+
+	t.Run("representation not supported", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/document", nil)
+		req.Header.Set("Accept", "text/html, */*;q=0")
+		NewDereferenceHandler(&mockDereferencer{}).Dereference(rw, req)
+		require.Equal(t, http.StatusNotAcceptable, rw.Code)
+		requireDereferencingError(t, rw, document.RepresentationNotSupportedError)
+	})
+
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("%w: some error", dereferencer.ErrInvalidDIDURL), http.StatusBadRequest, document.InvalidDIDURLError},
+		{fmt.Errorf("%w: some error", dereferencer.ErrNotFound), http.StatusNotFound, document.NotFoundError},
+		{errors.New("bad request: must start with configured namespace"), http.StatusNotImplemented, document.MethodNotSupportedError},
+		{errors.New("not found"), http.StatusNotFound, document.NotFoundError},
+		{errors.New("injected error"), http.StatusInternalServerError, document.InternalError},
+	}
+
+	for _, tc := range tests {
+		rw := httptest.NewRecorder()
+		NewDereferenceHandler(&mockDereferencer{err: tc.err}).Dereference(rw,
+			httptest.NewRequest(http.MethodGet, "/document", nil))
+		require.Equal(t, tc.status, rw.Code, tc.err.Error())
+		requireDereferencingError(t, rw, tc.code)
+	}
+}
+
+func requireDereferencingError(t *testing.T, rw *httptest.ResponseRecorder, code string) {
+	t.Helper()
+
+	require.Equal(t, MediaTypeDIDResolution, rw.Header().Get("content-type"))
+
+	result := &document.DereferencingResult{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), result))
+	require.Nil(t, result.ContentStream)
+	require.Equal(t, code, result.DereferencingMetadata[document.ErrorProperty])
+	require.NotEmpty(t, result.DereferencingMetadata[document.ErrorMessageProperty])
+}
+
+func newDereferencingResult(content interface{}, contentType string, deactivated bool) *document.DereferencingResult {
+	return &document.DereferencingResult{
+		ContentStream:         content,
+		ContentMetadata:       document.Metadata{document.DeactivatedProperty: deactivated},
+		DereferencingMetadata: document.Metadata{document.ContentTypeProperty: contentType},
+	}
+}
+
+type mockDereferencer struct {
+	result *document.DereferencingResult
+	err    error
+	didURL string
+}
+
+func (m *mockDereferencer) Dereference(didURL string) (*document.DereferencingResult, error) {
+	m.didURL = didURL
+
+	return m.result, m.err
+}
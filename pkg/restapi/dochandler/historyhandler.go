@@ -0,0 +1,110 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+const (
+	offsetParam = "offset"
+	limitParam  = "limit"
+
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// OperationHistoryProvider returns the operation history of a DID.
+type OperationHistoryProvider interface {
+	GetOperationHistory(did string, offset, limit int) (*document.OperationHistory, error)
+}
+
+// OperationHistoryHandler returns the operation history of a DID.
+type OperationHistoryHandler struct {
+	provider OperationHistoryProvider
+}
+
+// NewOperationHistoryHandler returns a new operation history handler.
+func NewOperationHistoryHandler(provider OperationHistoryProvider) *OperationHistoryHandler {
+	return &OperationHistoryHandler{
+		provider: provider,
+	}
+}
+
+// GetHistory returns a page of the operation history of a DID. The page is selected with the optional 'offset'
+// (defaults to 0) and 'limit' (defaults to 100, maximum 1000) query parameters.
+func (h *OperationHistoryHandler) GetHistory(rw http.ResponseWriter, req *http.Request) {
+	id := getID(req)
+	if id == "" {
+		common.WriteError(rw, http.StatusBadRequest, errors.New("DID is required"))
+
+		return
+	}
+
+	offset, err := getIntParam(req, offsetParam, 0)
+	if err != nil {
+		common.WriteError(rw, http.StatusBadRequest, err)
+
+		return
+	}
+
+	limit, err := getIntParam(req, limitParam, defaultHistoryLimit)
+	if err != nil {
+		common.WriteError(rw, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if limit == 0 || limit > maxHistoryLimit {
+		common.WriteError(rw, http.StatusBadRequest,
+			fmt.Errorf("'%s' must be between 1 and %d", limitParam, maxHistoryLimit))
+
+		return
+	}
+
+	logger.Debug("Retrieving operation history", log.WithID(id))
+
+	history, err := h.provider.GetOperationHistory(id, offset, limit)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "bad request"):
+			common.WriteError(rw, http.StatusBadRequest, err)
+		case strings.Contains(err.Error(), "not found"):
+			common.WriteError(rw, http.StatusNotFound, errors.New("document not found"))
+		default:
+			logger.Error("Internal server error", log.WithError(err))
+
+			common.WriteError(rw, http.StatusInternalServerError, err)
+		}
+
+		return
+	}
+
+	common.WriteResponseWithContentType(rw, http.StatusOK, contentTypeJSON, history)
+}
+
+func getIntParam(req *http.Request, name string, defaultValue int) (int, error) {
+	value := req.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("'%s' must be a non-negative integer", name)
+	}
+
+	return i, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+func TestOperationHistoryHandler_GetHistory(t *testing.T) {
+	getID = func(req *http.Request) string { return testDID }
+
+	t.Run("success", func(t *testing.T) {
+		provider := &mockHistoryProvider{history: &document.OperationHistory{
+			ID:     testDID,
+			Total:  1,
+			Offset: 0,
+			Operations: []*document.OperationHistoryEntry{
+				{Type: operation.TypeCreate, Published: true, Applied: true, CanonicalReference: "ref-1"},
+			},
+		}}
+
+		rw := httptest.NewRecorder()
+		NewOperationHistoryHandler(provider).GetHistory(rw, httptest.NewRequest(http.MethodGet, "/history", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, contentTypeJSON, rw.Header().Get("content-type"))
+		require.Equal(t, testDID, provider.did)
+		require.Equal(t, 0, provider.offset)
+		require.Equal(t, defaultHistoryLimit, provider.limit)
+
+		history := &document.OperationHistory{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), history))
+		require.Equal(t, provider.history, history)
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		provider := &mockHistoryProvider{history: &document.OperationHistory{}}
+
+		rw := httptest.NewRecorder()
+		NewOperationHistoryHandler(provider).GetHistory(rw,
+			httptest.NewRequest(http.MethodGet, "/history?offset=20&limit=10", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, 20, provider.offset)
+		require.Equal(t, 10, provider.limit)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"offset=-1", "offset=abc", "limit=0", "limit=1001", "limit=x"} {
+			rw := httptest.NewRecorder()
+			NewOperationHistoryHandler(&mockHistoryProvider{}).GetHistory(rw,
+				httptest.NewRequest(http.MethodGet, "/history?"+query, nil))
+			require.Equal(t, http.StatusBadRequest, rw.Code, query)
+		}
+	})
+
+	t.Run("missing DID", func(t *testing.T) {
+		getID = func(req *http.Request) string { return "" }
+		defer func() { getID = func(req *http.Request) string { return testDID } }()
+
+		rw := httptest.NewRecorder()
+		NewOperationHistoryHandler(&mockHistoryProvider{}).GetHistory(rw, httptest.NewRequest(http.MethodGet, "/history", nil))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+	})
+
+	t.Run("provider errors", func(t *testing.T) {
+		tests := []struct {
+			err    error
+			status int
+		}{
+			{errors.New("bad request: invalid DID"), http.StatusBadRequest},
+			{errors.New("uniqueSuffix not found in the store"), http.StatusNotFound},
+			{errors.New("injected error"), http.StatusInternalServerError},
+		}
+
+		for _, tc := range tests {
+			rw := httptest.NewRecorder()
+			NewOperationHistoryHandler(&mockHistoryProvider{err: tc.err}).GetHistory(rw,
+				httptest.NewRequest(http.MethodGet, "/history", nil))
+			require.Equal(t, tc.status, rw.Code, tc.err.Error())
+		}
+	})
+}
+
+type mockHistoryProvider struct {
+	history *document.OperationHistory
+	err     error
+	did     string
+	offset  int
+	limit   int
+}
+
+func (m *mockHistoryProvider) GetOperationHistory(did string, offset, limit int) (*document.OperationHistory, error) {
+	m.did = did
+	m.offset = offset
+	m.limit = limit
+
+	return m.history, m.err
+}
@@ -0,0 +1,69 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/opstatus"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+// OperationStatusProvider returns the status of an operation.
+type OperationStatusProvider interface {
+	Get(hash string) (*opstatus.OperationStatus, error)
+}
+
+// OperationStatusHandler returns the status of an operation given the operation hash.
+type OperationStatusHandler struct {
+	provider OperationStatusProvider
+}
+
+// NewOperationStatusHandler returns a new operation status handler.
+func NewOperationStatusHandler(provider OperationStatusProvider) *OperationStatusHandler {
+	return &OperationStatusHandler{
+		provider: provider,
+	}
+}
+
+// GetStatus returns the status of an operation.
+func (h *OperationStatusHandler) GetStatus(rw http.ResponseWriter, req *http.Request) {
+	hash := getHash(req)
+	if hash == "" {
+		common.WriteError(rw, http.StatusBadRequest, errors.New("operation hash is required"))
+
+		return
+	}
+
+	logger.Debug("Retrieving operation status", log.WithOperationID(hash))
+
+	status, err := h.provider.Get(hash)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			common.WriteError(rw, http.StatusNotFound, errors.New("operation not found"))
+
+			return
+		}
+
+		logger.Error("Internal server error", log.WithError(err))
+
+		common.WriteError(rw, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	common.WriteResponse(rw, http.StatusOK, status)
+}
+
+var getHash = func(req *http.Request) string {
+	return mux.Vars(req)["hash"]
+}
@@ -0,0 +1,87 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/opstatus"
+)
+
+func TestOperationStatusHandler_GetStatus(t *testing.T) {
+	request := []byte(`{"type":"update","didSuffix":"abc"}`)
+
+	hash, err := opstatus.GetOperationHash(request)
+	require.NoError(t, err)
+
+	tracker := opstatus.New(opstatus.NewMemStore())
+	tracker.Update(request, "abc", opstatus.StatusQueued, opstatus.WithType(operation.TypeUpdate))
+
+	t.Run("success", func(t *testing.T) {
+		getHash = func(req *http.Request) string { return hash }
+
+		handler := NewOperationStatusHandler(tracker)
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/operations", nil)
+		handler.GetStatus(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		status := &opstatus.OperationStatus{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), status))
+		require.Equal(t, hash, status.Hash)
+		require.Equal(t, "abc", status.UniqueSuffix)
+		require.Equal(t, operation.TypeUpdate, status.Type)
+		require.Equal(t, opstatus.StatusQueued, status.Status)
+	})
+
+	t.Run("missing hash", func(t *testing.T) {
+		getHash = func(req *http.Request) string { return "" }
+
+		handler := NewOperationStatusHandler(tracker)
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/operations", nil)
+		handler.GetStatus(rw, req)
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		getHash = func(req *http.Request) string { return "unknown" }
+
+		handler := NewOperationStatusHandler(tracker)
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/operations", nil)
+		handler.GetStatus(rw, req)
+		require.Equal(t, http.StatusNotFound, rw.Code)
+		require.Contains(t, rw.Body.String(), "operation not found")
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		getHash = func(req *http.Request) string { return hash }
+
+		handler := NewOperationStatusHandler(&mockOperationStatusProvider{err: errors.New("store error")})
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/operations", nil)
+		handler.GetStatus(rw, req)
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+		require.Contains(t, rw.Body.String(), "store error")
+	})
+}
+
+type mockOperationStatusProvider struct {
+	err error
+}
+
+func (m *mockOperationStatusProvider) Get(string) (*opstatus.OperationStatus, error) {
+	return nil, m.err
+}
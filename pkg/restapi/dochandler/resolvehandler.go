@@ -9,6 +9,7 @@ package dochandler
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,6 +26,9 @@ var logger = log.New("sidetree-core-restapi-dochandler")
 const (
 	versionIDParam   = "versionId"
 	versionTimeParam = "versionTime"
+	traceParam       = "trace"
+
+	didResolutionContext = "https://w3id.org/did-resolution/v1"
 )
 
 // Resolver resolves documents.
@@ -51,6 +55,17 @@ func NewResolveHandler(resolver Resolver, metrics metricsResolveProvider) *Resol
 }
 
 // Resolve resolves a document.
+//
+// The representation of the response is negotiated using the Accept header (see the DID Resolution HTTP(S)
+// binding):
+//   - application/did+ld+json: the response body is the DID document
+//   - application/ld+json;profile="https://w3id.org/did-resolution": the response body is the DID resolution
+//     result including the DID document, document metadata and resolution metadata
+//
+// Otherwise (e.g. no Accept header, application/json or an unsupported media type) the resolution result is
+// returned with content type application/did+ld+json. A 406 (Not Acceptable) is only returned if the client
+// excludes this default with q=0. Errors are returned as a resolution result with the error code set in the
+// resolution metadata. A deactivated document is returned with status 410 (Gone).
 func (o *ResolveHandler) Resolve(rw http.ResponseWriter, req *http.Request) {
 	startTime := time.Now()
 
@@ -58,43 +73,141 @@ func (o *ResolveHandler) Resolve(rw http.ResponseWriter, req *http.Request) {
 		o.metrics.HTTPResolveTime(time.Since(startTime))
 	}()
 
+	repr, err := negotiateRepresentation(req.Header.Values(acceptHeader))
+	if err != nil {
+		writeResolutionError(rw, newResolutionError(http.StatusNotAcceptable, document.RepresentationNotSupportedError, err))
+
+		return
+	}
+
 	id := getID(req)
 	opts, err := getResolutionOptions(req)
 	if err != nil {
-		common.WriteError(rw, http.StatusBadRequest, err)
+		writeResolutionError(rw, newResolutionError(http.StatusBadRequest, document.InvalidOptionsError, err))
 
 		return
 	}
 
 	logger.Debug("Resolving DID document for ID", log.WithID(id))
-	response, err := o.doResolve(id, opts...)
-	if err != nil {
-		common.WriteError(rw, err.(*common.HTTPError).Status(), err)
+
+	response, resErr := o.doResolve(id, opts...)
+	if resErr != nil {
+		writeResolutionError(rw, resErr)
 
 		return
 	}
 
 	logger.Debug("... resolved DID document for ID", log.WithID(id), log.WithDocument(response.Document))
 
-	common.WriteResponse(rw, http.StatusOK, response)
+	status := http.StatusOK
+	if isDeactivated(response) {
+		status = http.StatusGone
+	}
+
+	switch repr {
+	case representationDocument:
+		common.WriteResponseWithContentType(rw, status, MediaTypeDIDLDJSON, response.Document)
+	case representationResolutionResult:
+		response.ResolutionMetadata = document.Metadata{document.ContentTypeProperty: MediaTypeDIDLDJSON}
+
+		common.WriteResponseWithContentType(rw, status, MediaTypeDIDResolution, response)
+	default:
+		common.WriteResponse(rw, status, response)
+	}
 }
 
-func (o *ResolveHandler) doResolve(id string, opts ...document.ResolutionOption) (*document.ResolutionResult, error) {
+func (o *ResolveHandler) doResolve(id string, opts ...document.ResolutionOption) (*document.ResolutionResult, *resolutionError) {
 	resolutionResult, err := o.resolver.ResolveDocument(id, opts...)
 	if err != nil {
-		if strings.Contains(err.Error(), "bad request") {
-			return nil, common.NewHTTPError(http.StatusBadRequest, err)
-		}
-		if strings.Contains(err.Error(), "not found") {
-			return nil, common.NewHTTPError(http.StatusNotFound, errors.New("document not found"))
+		return nil, toResolutionError(id, err)
+	}
+
+	return resolutionResult, nil
+}
+
+// toResolutionError maps the given resolver error to a resolution error.
+func toResolutionError(id string, err error) *resolutionError {
+	if strings.Contains(err.Error(), "bad request") {
+		if isDID(id) && strings.Contains(err.Error(), "namespace") {
+			return newResolutionError(http.StatusNotImplemented, document.MethodNotSupportedError, err)
 		}
 
-		logger.Error("Internal server error", log.WithError(err))
+		return newResolutionError(http.StatusBadRequest, document.InvalidDIDError, err)
+	}
 
-		return nil, common.NewHTTPError(http.StatusInternalServerError, err)
+	if strings.Contains(err.Error(), "not found") {
+		return newResolutionError(http.StatusNotFound, document.NotFoundError, errors.New("document not found"))
 	}
 
-	return resolutionResult, nil
+	logger.Error("Internal server error", log.WithError(err))
+
+	return newResolutionError(http.StatusInternalServerError, document.InternalError, err)
+}
+
+type resolutionError struct {
+	status int
+	code   string
+	err    error
+}
+
+func newResolutionError(status int, code string, err error) *resolutionError {
+	return &resolutionError{
+		status: status,
+		code:   code,
+		err:    err,
+	}
+}
+
+func writeResolutionError(rw http.ResponseWriter, e *resolutionError) {
+	logResolutionError(e)
+
+	common.WriteResponseWithContentType(rw, e.status, MediaTypeDIDResolution,
+		&document.ResolutionResult{
+			Context:            didResolutionContext,
+			ResolutionMetadata: e.metadata(),
+		},
+	)
+}
+
+func (e *resolutionError) metadata() document.Metadata {
+	return document.Metadata{
+		document.ErrorProperty:        e.code,
+		document.ErrorMessageProperty: e.err.Error(),
+	}
+}
+
+func logResolutionError(e *resolutionError) {
+	if e.status >= http.StatusInternalServerError {
+		logger.Warn("Returning resolution error", log.WithHTTPStatus(e.status), log.WithError(e.err))
+	} else {
+		logger.Debug("Returning resolution error", log.WithHTTPStatus(e.status), log.WithError(e.err))
+	}
+}
+
+func isDeactivated(result *document.ResolutionResult) bool {
+	return isDeactivatedMetadata(result.DocumentMetadata)
+}
+
+func isDeactivatedMetadata(metadata document.Metadata) bool {
+	deactivated, ok := metadata[document.DeactivatedProperty].(bool)
+
+	return ok && deactivated
+}
+
+// isDID returns true if the given ID has the form did:<method>:<method-specific-id>.
+func isDID(id string) bool {
+	parts := strings.SplitN(id, ":", 3)
+	if len(parts) != 3 || parts[0] != "did" || parts[1] == "" || parts[2] == "" {
+		return false
+	}
+
+	for _, c := range parts[1] {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return true
 }
 
 var getID = func(req *http.Request) string {
@@ -118,5 +231,17 @@ func getResolutionOptions(req *http.Request) ([]document.ResolutionOption, error
 		return nil, fmt.Errorf("cannot specify both '%s' and '%s'", versionIDParam, versionTimeParam)
 	}
 
+	traceValue := req.URL.Query().Get(traceParam)
+	if traceValue != "" {
+		trace, err := strconv.ParseBool(traceValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for '%s': %s", traceParam, traceValue)
+		}
+
+		if trace {
+			resolutionOpts = append(resolutionOpts, document.WithTrace())
+		}
+	}
+
 	return resolutionOpts, nil
 }
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package dochandler
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
 	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/encoder"
 	"github.com/trustbloc/sidetree-core-go/pkg/hashing"
@@ -137,6 +139,33 @@ func TestResolveHandler_Resolve(t *testing.T) {
 		require.Equal(t, http.StatusBadRequest, rw.Code)
 		require.Contains(t, rw.Body.String(), "cannot specify both 'versionId' and 'versionTime'")
 	})
+	t.Run("trace parameter", func(t *testing.T) {
+		docHandler := mocks.NewMockDocumentHandler().
+			WithNamespace(namespace)
+
+		create, err := getCreateRequest()
+		require.NoError(t, err)
+
+		bytes, err := canonicalizer.MarshalCanonical(create)
+		require.NoError(t, err)
+
+		result, err := docHandler.ProcessOperation(bytes, 0)
+		require.NoError(t, err)
+
+		getID = func(req *http.Request) string { return result.Document.ID() }
+		handler := NewResolveHandler(docHandler, &mocks.MetricsProvider{})
+
+		for _, value := range []string{"true", "false", "1"} {
+			rw := httptest.NewRecorder()
+			handler.Resolve(rw, httptest.NewRequest(http.MethodGet, "/document/"+result.Document.ID()+"?trace="+value, nil))
+			require.Equal(t, http.StatusOK, rw.Code)
+		}
+
+		rw := httptest.NewRecorder()
+		handler.Resolve(rw, httptest.NewRequest(http.MethodGet, "/document/"+result.Document.ID()+"?trace=yes", nil))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "invalid value for 'trace'")
+	})
 	t.Run("Invalid ID", func(t *testing.T) {
 		getID = func(req *http.Request) string { return "someid" }
 		docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace)
@@ -205,8 +234,175 @@ func TestResolveHandler_Resolve(t *testing.T) {
 		rw := httptest.NewRecorder()
 		req := httptest.NewRequest(http.MethodGet, "/document", nil)
 		handler.Resolve(rw, req)
+		require.Equal(t, http.StatusGone, rw.Code)
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/document", nil)
+		req.Header.Set("Accept", MediaTypeDIDResolution)
+		handler.Resolve(rw, req)
+		require.Equal(t, http.StatusGone, rw.Code)
+
+		resolutionResult := &document.ResolutionResult{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resolutionResult))
+		require.Equal(t, true, resolutionResult.DocumentMetadata[document.DeactivatedProperty])
+	})
+}
+
+func TestResolveHandler_Representation(t *testing.T) {
+	docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace)
+
+	create, err := getCreateRequest()
+	require.NoError(t, err)
+
+	bytes, err := canonicalizer.MarshalCanonical(create)
+	require.NoError(t, err)
+
+	result, err := docHandler.ProcessOperation(bytes, 0)
+	require.NoError(t, err)
+
+	getID = func(req *http.Request) string { return result.Document.ID() }
+	handler := NewResolveHandler(docHandler, &mocks.MetricsProvider{})
+
+	t.Run("DID document", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/document", nil)
+		req.Header.Set("Accept", MediaTypeDIDLDJSON)
+		handler.Resolve(rw, req)
 		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, MediaTypeDIDLDJSON, rw.Header().Get("content-type"))
+
+		doc, err := document.FromBytes(rw.Body.Bytes())
+		require.NoError(t, err)
+		require.Equal(t, result.Document.ID(), doc.ID())
 	})
+
+	t.Run("resolution result", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/document", nil)
+		req.Header.Set("Accept", MediaTypeDIDResolution)
+		handler.Resolve(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, MediaTypeDIDResolution, rw.Header().Get("content-type"))
+
+		resolutionResult := &document.ResolutionResult{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resolutionResult))
+		require.Equal(t, result.Document.ID(), resolutionResult.Document.ID())
+		require.Equal(t, MediaTypeDIDLDJSON, resolutionResult.ResolutionMetadata[document.ContentTypeProperty])
+	})
+
+	t.Run("preferred representation", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/document", nil)
+		req.Header.Set("Accept", MediaTypeDIDLDJSON+";q=0.5, "+MediaTypeDIDResolution)
+		handler.Resolve(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, MediaTypeDIDResolution, rw.Header().Get("content-type"))
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/document", nil)
+		req.Header.Set("Accept", "application/json")
+		handler.Resolve(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, MediaTypeDIDLDJSON, rw.Header().Get("content-type"))
+
+		resolutionResult := &document.ResolutionResult{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resolutionResult))
+		require.Equal(t, result.Document.ID(), resolutionResult.Document.ID())
+	})
+
+	t.Run("unsupported representation falls back to default", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/document", nil)
+		req.Header.Set("Accept", "text/html")
+		handler.Resolve(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, MediaTypeDIDLDJSON, rw.Header().Get("content-type"))
+	})
+
+	t.Run("representation not supported", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/document", nil)
+		req.Header.Set("Accept", "text/html, */*;q=0")
+		handler.Resolve(rw, req)
+		require.Equal(t, http.StatusNotAcceptable, rw.Code)
+		requireResolutionError(t, rw, document.RepresentationNotSupportedError)
+	})
+}
+
+func TestResolveHandler_ResolutionErrors(t *testing.T) {
+	docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace)
+	handler := NewResolveHandler(docHandler, &mocks.MetricsProvider{})
+
+	t.Run("invalid DID", func(t *testing.T) {
+		getID = func(req *http.Request) string { return "someid" }
+
+		rw := httptest.NewRecorder()
+		handler.Resolve(rw, httptest.NewRequest(http.MethodGet, "/document", nil))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		requireResolutionError(t, rw, document.InvalidDIDError)
+	})
+
+	t.Run("method not supported", func(t *testing.T) {
+		getID = func(req *http.Request) string { return "did:other:someid" }
+
+		rw := httptest.NewRecorder()
+		handler.Resolve(rw, httptest.NewRequest(http.MethodGet, "/document", nil))
+		require.Equal(t, http.StatusNotImplemented, rw.Code)
+		requireResolutionError(t, rw, document.MethodNotSupportedError)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		getID = func(req *http.Request) string { return namespace + docutil.NamespaceDelimiter + "someid" }
+
+		rw := httptest.NewRecorder()
+		handler.Resolve(rw, httptest.NewRequest(http.MethodGet, "/document", nil))
+		require.Equal(t, http.StatusNotFound, rw.Code)
+		requireResolutionError(t, rw, document.NotFoundError)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		getID = func(req *http.Request) string { return namespace + docutil.NamespaceDelimiter + "someid" }
+
+		rw := httptest.NewRecorder()
+		handler.Resolve(rw, httptest.NewRequest(http.MethodGet, "/document?versionId=abc&versionTime=xyz", nil))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		requireResolutionError(t, rw, document.InvalidOptionsError)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		getID = func(req *http.Request) string { return namespace + docutil.NamespaceDelimiter + "someid" }
+
+		handler := NewResolveHandler(mocks.NewMockDocumentHandler().WithNamespace(namespace).
+			WithError(errors.New("injected error")), &mocks.MetricsProvider{})
+
+		rw := httptest.NewRecorder()
+		handler.Resolve(rw, httptest.NewRequest(http.MethodGet, "/document", nil))
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+		requireResolutionError(t, rw, document.InternalError)
+	})
+}
+
+func TestIsDID(t *testing.T) {
+	require.True(t, isDID("did:sidetree:abc"))
+	require.True(t, isDID("did:sidetree:test:abc"))
+	require.False(t, isDID("did:Sidetree:abc"))
+	require.False(t, isDID("did:sidetree"))
+	require.False(t, isDID("did::abc"))
+	require.False(t, isDID("sidetree:abc:xyz"))
+}
+
+func requireResolutionError(t *testing.T, rw *httptest.ResponseRecorder, code string) {
+	t.Helper()
+
+	require.Equal(t, MediaTypeDIDResolution, rw.Header().Get("content-type"))
+
+	resolutionResult := &document.ResolutionResult{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resolutionResult))
+	require.Nil(t, resolutionResult.Document)
+	require.Equal(t, code, resolutionResult.ResolutionMetadata[document.ErrorProperty])
+	require.NotEmpty(t, resolutionResult.ResolutionMetadata[document.ErrorMessageProperty])
 }
 
 func getCreateRequest() (*model.CreateRequest, error) {
@@ -0,0 +1,350 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package restclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+)
+
+var logger = log.New("sidetree-core-restapi-client")
+
+const (
+	defaultMaxRetries   = 3
+	defaultRetryBackoff = 500 * time.Millisecond
+	backoffFactor       = 2
+
+	versionIDParam   = "versionId"
+	versionTimeParam = "versionTime"
+	traceParam       = "trace"
+
+	contentTypeJSON = "application/json"
+)
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client invokes the Sidetree REST API, i.e. the operations endpoint (see diddochandler.UpdateHandler) and
+// the resolution endpoint (see diddochandler.ResolveHandler).
+//
+// Resolution requests that fail with a transport error or a transient status (429, 502, 503 or 504) are retried
+// with exponential backoff until the maximum number of retries is reached or the context is done. Since submitting
+// an operation isn't idempotent, operation requests are only retried if the connection failed before the request
+// was sent or if the server rejected the request with 429 or 503 along with a Retry-After header. The delay given
+// by the Retry-After header is honored if it's longer than the backoff. Error responses are returned as *Error.
+type Client struct {
+	operationsURL string
+	resolutionURL string
+	httpClient    httpClient
+	authToken     string
+	maxRetries    int
+	retryBackoff  time.Duration
+}
+
+// Option is a REST client option.
+type Option func(c *Client)
+
+// WithHTTPClient sets the HTTP client.
+func WithHTTPClient(client httpClient) Option {
+	return func(c *Client) {
+		c.httpClient = client
+	}
+}
+
+// WithAuthToken sets the bearer token that is sent with each request.
+func WithAuthToken(token string) Option {
+	return func(c *Client) {
+		c.authToken = token
+	}
+}
+
+// WithMaxRetries sets the maximum number of times that a failed request is retried. Zero disables retries.
+// The default is 3.
+func WithMaxRetries(maxRetries int) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+	}
+}
+
+// WithRetryBackoff sets the time to wait before the first retry. The backoff is doubled after every retry.
+// The default is 500ms.
+func WithRetryBackoff(backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retryBackoff = backoff
+	}
+}
+
+// New returns a new REST client for the given operations URL (e.g. https://example.com/sidetree/v1/operations)
+// and resolution URL (e.g. https://example.com/sidetree/v1/identifiers).
+func New(operationsURL, resolutionURL string, opts ...Option) *Client {
+	c := &Client{
+		operationsURL: operationsURL,
+		resolutionURL: strings.TrimSuffix(resolutionURL, "/"),
+		httpClient:    &http.Client{},
+		maxRetries:    defaultMaxRetries,
+		retryBackoff:  defaultRetryBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Submit submits the given operation request (e.g. a create, update, recover or deactivate request created
+// by the versions/1_0/client package). The resolution result of the new document is returned for a create
+// request. Nil is returned for other operation types.
+func (c *Client) Submit(ctx context.Context, request []byte) (*document.ResolutionResult, error) {
+	resp, err := c.send(ctx, http.MethodPost, c.operationsURL, request, contentTypeJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.statusCode != http.StatusOK {
+		return nil, &Error{StatusCode: resp.statusCode, Message: strings.TrimSpace(string(resp.body))}
+	}
+
+	var result *document.ResolutionResult
+
+	err = json.Unmarshal(resp.body, &result)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	return result, nil
+}
+
+// Resolve resolves the given ID (short or long-form DID). The version ID, version time and trace resolution
+// options are supported. A deactivated document is returned along with its metadata (i.e. without an error).
+func (c *Client) Resolve(ctx context.Context, id string, opts ...document.ResolutionOption) (*document.ResolutionResult, error) {
+	query, err := getQuery(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	resolutionURL := c.resolutionURL + "/" + url.PathEscape(id)
+	if len(query) > 0 {
+		resolutionURL += "?" + query.Encode()
+	}
+
+	resp, err := c.send(ctx, http.MethodGet, resolutionURL, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.statusCode != http.StatusOK && resp.statusCode != http.StatusGone {
+		return nil, newResolutionError(resp)
+	}
+
+	result := &document.ResolutionResult{}
+
+	err = json.Unmarshal(resp.body, result)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal resolution result: %w", err)
+	}
+
+	return result, nil
+}
+
+type response struct {
+	statusCode int
+	retryAfter string
+	body       []byte
+}
+
+func (c *Client) send(ctx context.Context, method, reqURL string, body []byte, contentType string) (*response, error) {
+	backoff := c.retryBackoff
+
+	for attempt := 1; ; attempt++ {
+		// A request is considered to be sent once a connection was obtained for it.
+		var connected int32
+
+		traceCtx := httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			GotConn: func(httptrace.GotConnInfo) { atomic.StoreInt32(&connected, 1) },
+		})
+
+		req, err := c.newRequest(traceCtx, method, reqURL, body, contentType)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.do(req)
+		if attempt > c.maxRetries || ctx.Err() != nil {
+			return resp, err
+		}
+
+		retry := isTransient(resp, err)
+		if method != http.MethodGet {
+			retry = isRetryable(resp, err, atomic.LoadInt32(&connected) == 1)
+		}
+
+		if !retry {
+			return resp, err
+		}
+
+		delay := backoff
+		if d := retryAfter(resp); d > delay {
+			delay = d
+		}
+
+		logger.Debug("Request failed. Retrying after backoff.", log.WithURIString(reqURL),
+			log.WithAttempt(attempt), log.WithBackoff(delay), log.WithError(err))
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		backoff *= backoffFactor
+	}
+}
+
+func (c *Client) newRequest(ctx context.Context, method, reqURL string, body []byte, contentType string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	req.Header.Set("Accept", dochandler.MediaTypeDIDResolution)
+
+	if c.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.authToken)
+	}
+
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request) (*response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL, err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	return &response{statusCode: resp.StatusCode, retryAfter: resp.Header.Get("Retry-After"), body: respBytes}, nil
+}
+
+func isTransient(resp *response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch resp.statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryable returns true if a request that isn't idempotent may be retried, i.e. if the connection failed
+// before the request was sent or if the server explicitly asked for the request to be retried later.
+func isRetryable(resp *response, err error, sent bool) bool {
+	if err != nil {
+		return !sent
+	}
+
+	switch resp.statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return resp.retryAfter != ""
+	default:
+		return false
+	}
+}
+
+// retryAfter returns the delay given by the Retry-After header of the response (either in seconds or as an
+// HTTP date). Zero is returned if the header is missing or invalid.
+func retryAfter(resp *response) time.Duration {
+	if resp == nil || resp.retryAfter == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(resp.retryAfter); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(resp.retryAfter); err == nil {
+		return time.Until(t)
+	}
+
+	return 0
+}
+
+func getQuery(opts ...document.ResolutionOption) (url.Values, error) {
+	options, err := document.GetResolutionOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(options.AdditionalOperations) > 0 {
+		return nil, errors.New("additional operations are not supported by the REST API")
+	}
+
+	query := url.Values{}
+
+	if options.VersionID != "" {
+		query.Set(versionIDParam, options.VersionID)
+	}
+
+	if options.VersionTime != "" {
+		query.Set(versionTimeParam, options.VersionTime)
+	}
+
+	if options.Trace {
+		query.Set(traceParam, strconv.FormatBool(true))
+	}
+
+	return query, nil
+}
+
+// newResolutionError returns an Error with the error code and message from the resolution metadata of the
+// response. If the response isn't a resolution result (e.g. the resolution endpoint doesn't exist) then the
+// response body is used as the message.
+func newResolutionError(resp *response) *Error {
+	result := &document.ResolutionResult{}
+
+	if json.Unmarshal(resp.body, result) == nil {
+		code, ok := result.ResolutionMetadata[document.ErrorProperty].(string)
+		if ok {
+			message, _ := result.ResolutionMetadata[document.ErrorMessageProperty].(string)
+
+			return &Error{StatusCode: resp.statusCode, Code: code, Message: message}
+		}
+	}
+
+	return &Error{StatusCode: resp.statusCode, Message: strings.TrimSpace(string(resp.body))}
+}
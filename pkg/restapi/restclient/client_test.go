@@ -0,0 +1,459 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package restclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/diddochandler"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/client/keymanager"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/doccomposer"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationapplier"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationparser"
+)
+
+const (
+	namespace = "did:sidetree"
+	sha2_256  = 18
+
+	operationsPath = "/sidetree/v1/operations"
+	resolutionPath = "/sidetree/v1/identifiers"
+)
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(newRouter(t))
+	defer server.Close()
+
+	c := New(server.URL+operationsPath, server.URL+resolutionPath)
+
+	km := keymanager.New(keymanager.NewMemStore(), sha2_256)
+
+	didSuffix, createRequest, err := km.Create(`{"test":"value"}`)
+	require.NoError(t, err)
+
+	did := namespace + ":" + didSuffix
+
+	t.Run("success", func(t *testing.T) {
+		result, err := c.Submit(context.Background(), createRequest)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.Equal(t, did, result.Document["id"])
+
+		result, err = c.Resolve(context.Background(), did)
+		require.NoError(t, err)
+		require.Equal(t, did, result.Document["id"])
+		require.Equal(t, "value", result.Document["test"])
+
+		jsonPatch, err := patch.NewJSONPatch(`[{"op":"replace","path":"/test","value":"updated"}]`)
+		require.NoError(t, err)
+
+		updateRequest, err := km.Update(didSuffix, []patch.Patch{jsonPatch})
+		require.NoError(t, err)
+
+		_, err = c.Submit(context.Background(), updateRequest)
+		require.NoError(t, err)
+
+		result, err = c.Resolve(context.Background(), did)
+		require.NoError(t, err)
+		require.Equal(t, "updated", result.Document["test"])
+	})
+
+	t.Run("success - deactivated", func(t *testing.T) {
+		km := keymanager.New(keymanager.NewMemStore(), sha2_256)
+
+		suffix, request, err := km.Create(`{"test":"value"}`)
+		require.NoError(t, err)
+
+		_, err = c.Submit(context.Background(), request)
+		require.NoError(t, err)
+
+		request, err = km.Deactivate(suffix)
+		require.NoError(t, err)
+
+		_, err = c.Submit(context.Background(), request)
+		require.NoError(t, err)
+
+		result, err := c.Resolve(context.Background(), namespace+":"+suffix)
+		require.NoError(t, err)
+		require.Equal(t, true, result.DocumentMetadata[document.DeactivatedProperty])
+	})
+
+	t.Run("resolution options", func(t *testing.T) {
+		var query string
+
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.RawQuery
+
+			_, err := w.Write([]byte(`{"didDocument":{"id":"did:sidetree:123"}}`))
+			require.NoError(t, err)
+		}))
+		defer s.Close()
+
+		c := New(s.URL, s.URL)
+
+		_, err := c.Resolve(context.Background(), "did:sidetree:123", document.WithVersionID("abc"))
+		require.NoError(t, err)
+		require.Equal(t, "versionId=abc", query)
+
+		_, err = c.Resolve(context.Background(), "did:sidetree:123",
+			document.WithVersionTime("2021-05-10T17:00:00Z"), document.WithTrace())
+		require.NoError(t, err)
+		require.Equal(t, "trace=true&versionTime=2021-05-10T17%3A00%3A00Z", query)
+
+		_, err = c.Resolve(context.Background(), "did:sidetree:123",
+			document.WithAdditionalOperations([]*operation.AnchoredOperation{{}}))
+		require.EqualError(t, err, "additional operations are not supported by the REST API")
+	})
+
+	t.Run("error - not found", func(t *testing.T) {
+		result, err := c.Resolve(context.Background(), namespace+":EiDOQXC2GnoVyHwIRbjhLx_cNc6vmZaS04SZjZdlLLAPRg")
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.True(t, errors.Is(err, ErrNotFound))
+		require.False(t, errors.Is(err, ErrBadRequest))
+
+		var e *Error
+		require.True(t, errors.As(err, &e))
+		require.Equal(t, http.StatusNotFound, e.StatusCode)
+		require.Equal(t, document.NotFoundError, e.Code)
+		require.Equal(t, "document not found", e.Message)
+		require.Equal(t, "server returned status 404 [notFound]: document not found", e.Error())
+	})
+
+	t.Run("error - method not supported", func(t *testing.T) {
+		result, err := c.Resolve(context.Background(), "did:other:123")
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.True(t, errors.Is(err, ErrMethodNotSupported))
+	})
+
+	t.Run("error - invalid operation", func(t *testing.T) {
+		result, err := c.Submit(context.Background(), []byte(`{"type":"invalid"}`))
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.True(t, errors.Is(err, ErrBadRequest))
+		require.False(t, errors.Is(err, ErrNotFound))
+		require.Contains(t, err.Error(), "server returned status 400: bad request: operation type [invalid] not supported")
+	})
+
+	t.Run("error - endpoint not found", func(t *testing.T) {
+		c := New(server.URL+"/invalid", server.URL+"/invalid")
+
+		result, err := c.Resolve(context.Background(), did)
+		require.True(t, errors.Is(err, ErrNotFound))
+		require.EqualError(t, err, "server returned status 404: 404 page not found")
+		require.Nil(t, result)
+
+		result, err = c.Submit(context.Background(), createRequest)
+		require.True(t, errors.Is(err, ErrNotFound))
+		require.Nil(t, result)
+	})
+
+	t.Run("error - invalid response", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(`{`))
+			require.NoError(t, err)
+		}))
+		defer s.Close()
+
+		c := New(s.URL, s.URL)
+
+		result, err := c.Resolve(context.Background(), did)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal resolution result")
+		require.Nil(t, result)
+
+		result, err = c.Submit(context.Background(), createRequest)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal response")
+		require.Nil(t, result)
+	})
+
+	t.Run("error - invalid URL", func(t *testing.T) {
+		result, err := New(" http://invalid", " http://invalid").Submit(context.Background(), createRequest)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create request")
+		require.Nil(t, result)
+	})
+}
+
+func TestClient_Retry(t *testing.T) {
+	router := newRouter(t)
+
+	var attempts int32
+
+	// Fail the first two attempts with a transient status.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		router.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	_, createRequest, err := keymanager.New(keymanager.NewMemStore(), sha2_256).Create(`{"test":"value"}`)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+
+		c := New(server.URL+operationsPath, server.URL+resolutionPath,
+			WithAuthToken("token"), WithRetryBackoff(time.Millisecond))
+
+		result, err := c.Submit(context.Background(), createRequest)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("error - max retries reached", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+
+		c := New(server.URL+operationsPath, server.URL+resolutionPath,
+			WithAuthToken("token"), WithMaxRetries(1), WithRetryBackoff(time.Millisecond))
+
+		result, err := c.Submit(context.Background(), createRequest)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+
+		var e *Error
+		require.True(t, errors.As(err, &e))
+		require.Equal(t, http.StatusServiceUnavailable, e.StatusCode)
+	})
+
+	t.Run("error - not retried", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+
+		c := New(server.URL+operationsPath, server.URL+resolutionPath, WithRetryBackoff(time.Millisecond))
+
+		result, err := c.Submit(context.Background(), createRequest)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "server returned status 401")
+		require.Nil(t, result)
+		require.Zero(t, atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("error - HTTP client error", func(t *testing.T) {
+		errExpected := errors.New("injected HTTP client error")
+
+		httpClient := &mockHTTPClient{err: errExpected}
+
+		c := New(server.URL+operationsPath, server.URL+resolutionPath,
+			WithHTTPClient(httpClient), WithRetryBackoff(time.Millisecond))
+
+		result, err := c.Resolve(context.Background(), "did:sidetree:123")
+		require.True(t, errors.Is(err, errExpected))
+		require.Nil(t, result)
+		require.Equal(t, int32(defaultMaxRetries+1), atomic.LoadInt32(&httpClient.attempts))
+	})
+
+	t.Run("error - context canceled", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+
+		c := New(server.URL+operationsPath, server.URL+resolutionPath,
+			WithAuthToken("token"), WithRetryBackoff(time.Minute))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		result, err := c.Submit(ctx, createRequest)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.Nil(t, result)
+		require.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+	})
+}
+
+func TestClient_RetrySubmit(t *testing.T) {
+	_, createRequest, err := keymanager.New(keymanager.NewMemStore(), sha2_256).Create(`{"test":"value"}`)
+	require.NoError(t, err)
+
+	var attempts int32
+
+	t.Run("transient status without Retry-After is not retried", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		c := New(server.URL+operationsPath, server.URL+resolutionPath, WithRetryBackoff(time.Millisecond))
+
+		_, err := c.Submit(context.Background(), createRequest)
+
+		var e *Error
+		require.True(t, errors.As(err, &e))
+		require.Equal(t, http.StatusServiceUnavailable, e.StatusCode)
+		require.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+
+		// Resolution requests are idempotent so they're retried.
+		atomic.StoreInt32(&attempts, 0)
+
+		_, err = c.Resolve(context.Background(), "did:sidetree:123")
+		require.Error(t, err)
+		require.Equal(t, int32(defaultMaxRetries+1), atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("bad gateway is not retried", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		c := New(server.URL+operationsPath, server.URL+resolutionPath, WithRetryBackoff(time.Millisecond))
+
+		_, err := c.Submit(context.Background(), createRequest)
+		require.Error(t, err)
+		require.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("too many requests with Retry-After is retried", func(t *testing.T) {
+		router := newRouter(t)
+
+		atomic.StoreInt32(&attempts, 0)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+
+				return
+			}
+
+			router.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+
+		c := New(server.URL+operationsPath, server.URL+resolutionPath, WithRetryBackoff(time.Millisecond))
+
+		result, err := c.Submit(context.Background(), createRequest)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("connection error before the request is sent is retried", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		httpClient := &countingHTTPClient{client: &http.Client{}}
+
+		c := New(server.URL+operationsPath, server.URL+resolutionPath,
+			WithHTTPClient(httpClient), WithRetryBackoff(time.Millisecond))
+
+		_, err := c.Submit(context.Background(), createRequest)
+		require.Error(t, err)
+		require.Equal(t, int32(defaultMaxRetries+1), atomic.LoadInt32(&httpClient.attempts))
+	})
+
+	t.Run("connection error after the request is sent is not retried", func(t *testing.T) {
+		// Close the connection without responding.
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			require.NoError(t, conn.Close())
+		}))
+		defer server.Close()
+
+		httpClient := &countingHTTPClient{client: &http.Client{}}
+
+		c := New(server.URL+operationsPath, server.URL+resolutionPath,
+			WithHTTPClient(httpClient), WithRetryBackoff(time.Millisecond))
+
+		_, err := c.Submit(context.Background(), createRequest)
+		require.Error(t, err)
+		require.Equal(t, int32(1), atomic.LoadInt32(&httpClient.attempts))
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	require.Zero(t, retryAfter(nil))
+	require.Zero(t, retryAfter(&response{}))
+	require.Zero(t, retryAfter(&response{retryAfter: "invalid"}))
+	require.Equal(t, 2*time.Second, retryAfter(&response{retryAfter: "2"}))
+
+	d := retryAfter(&response{retryAfter: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)})
+	require.True(t, d > 50*time.Second && d <= time.Minute)
+}
+
+func newRouter(t *testing.T) *mux.Router {
+	t.Helper()
+
+	pc := mocks.NewMockProtocolClient()
+	parser := operationparser.New(pc.Protocol)
+	dc := doccomposer.New()
+
+	pv := pc.CurrentVersion
+	pv.OperationParserReturns(parser)
+	pv.OperationApplierReturns(operationapplier.New(pc.Protocol, parser, dc))
+	pv.DocumentComposerReturns(dc)
+
+	docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace).WithProtocolClient(pc)
+
+	router := mux.NewRouter()
+
+	for _, handler := range []common.HTTPHandler{
+		diddochandler.NewUpdateHandler(operationsPath, docHandler, pc, &mocks.MetricsProvider{}),
+		diddochandler.NewResolveHandler(resolutionPath, docHandler, &mocks.MetricsProvider{}),
+	} {
+		router.HandleFunc(handler.Path(), handler.Handler()).Methods(handler.Method())
+	}
+
+	return router
+}
+
+type mockHTTPClient struct {
+	err      error
+	attempts int32
+}
+
+func (m *mockHTTPClient) Do(*http.Request) (*http.Response, error) {
+	atomic.AddInt32(&m.attempts, 1)
+
+	return nil, m.err
+}
+
+type countingHTTPClient struct {
+	client   *http.Client
+	attempts int32
+}
+
+func (m *countingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&m.attempts, 1)
+
+	return m.client.Do(req)
+}
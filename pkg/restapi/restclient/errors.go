@@ -0,0 +1,64 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package restclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+var (
+	// ErrNotFound is matched (using errors.Is) by an Error for a document that was not found.
+	ErrNotFound = errors.New("not found")
+
+	// ErrBadRequest is matched (using errors.Is) by an Error for an invalid operation request, an invalid ID
+	// or invalid resolution options.
+	ErrBadRequest = errors.New("bad request")
+
+	// ErrMethodNotSupported is matched (using errors.Is) by an Error for a DID whose method (namespace)
+	// is not supported by the server.
+	ErrMethodNotSupported = errors.New("method not supported")
+)
+
+// Error is returned when the server responds with an error status.
+type Error struct {
+	// StatusCode is the HTTP status code.
+	StatusCode int
+
+	// Code is the resolution error code (e.g. notFound). It is empty for operation requests since the
+	// server returns a plain text error message.
+	Code string
+
+	// Message is the error message returned by the server.
+	Message string
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("server returned status %d [%s]: %s", e.StatusCode, e.Code, e.Message)
+	}
+
+	return fmt.Sprintf("server returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// Is returns true if the target is the sentinel error that corresponds to the error code or status.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Code == document.NotFoundError || e.StatusCode == http.StatusNotFound
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrMethodNotSupported:
+		return e.Code == document.MethodNotSupportedError
+	default:
+		return false
+	}
+}
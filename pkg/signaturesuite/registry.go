@@ -0,0 +1,86 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signaturesuite
+
+import (
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+// Option is a registry instance option.
+type Option func(opts *Registry)
+
+// Registry contains signature suites.
+type Registry struct {
+	suites []Suite
+}
+
+// Suite defines signature verification for a JWS algorithm.
+type Suite interface {
+	// Accept returns true if the suite verifies signatures for the given JWS algorithm ("alg" header).
+	Accept(alg string) bool
+
+	// Verify verifies the signature of msg against the public key in JWK format.
+	Verify(jwk *jws.JWK, signature, msg []byte) error
+}
+
+// New returns new instance of signature suite registry.
+func New(opts ...Option) *Registry {
+	registry := &Registry{}
+
+	// apply options
+	for _, opt := range opts {
+		opt(registry)
+	}
+
+	return registry
+}
+
+// Verify verifies the signature of msg using the suite for the specified algorithm.
+func (r *Registry) Verify(alg string, jwk *jws.JWK, signature, msg []byte) error {
+	suite, err := r.resolveSuite(alg)
+	if err != nil {
+		return err
+	}
+
+	return suite.Verify(jwk, signature, msg)
+}
+
+// Supports returns true if a signature suite is registered for the specified algorithm.
+func (r *Registry) Supports(alg string) bool {
+	_, err := r.resolveSuite(alg)
+
+	return err == nil
+}
+
+func (r *Registry) resolveSuite(alg string) (Suite, error) {
+	// Suites that were added last take precedence so that a built-in suite may be overridden.
+	for i := len(r.suites) - 1; i >= 0; i-- {
+		if r.suites[i].Accept(alg) {
+			return r.suites[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("signature algorithm '%s' not supported", alg)
+}
+
+// WithSuite adds signature suite to the list of available suites.
+func WithSuite(suite Suite) Option {
+	return func(opts *Registry) {
+		opts.suites = append(opts.suites, suite)
+	}
+}
+
+// WithDefaultSuites adds the built-in signature suites (EdDSA, ES256, ES384, ES512, ES256K and ES256K-R)
+// to the list of available suites.
+func WithDefaultSuites() Option {
+	return func(opts *Registry) {
+		opts.suites = append(opts.suites,
+			NewEdDSA(), NewES256(), NewES384(), NewES512(), NewES256K(), NewES256KR())
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signaturesuite
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+func TestRegistry_Verify(t *testing.T) {
+	vectors := readTestVectors(t)
+
+	t.Run("success - default suites", func(t *testing.T) {
+		registry := New(WithDefaultSuites())
+
+		for _, v := range vectors {
+			require.True(t, registry.Supports(v.Alg))
+			require.NoError(t, registry.Verify(v.Alg, v.JWK, v.signature, v.message), v.Alg)
+		}
+	})
+
+	t.Run("error - algorithm not supported", func(t *testing.T) {
+		registry := New()
+
+		require.False(t, registry.Supports(AlgorithmES256))
+
+		err := registry.Verify(AlgorithmES256, vectors[1].JWK, vectors[1].signature, vectors[1].message)
+		require.EqualError(t, err, "signature algorithm 'ES256' not supported")
+	})
+
+	t.Run("success - additional suite", func(t *testing.T) {
+		registry := New(WithDefaultSuites(), WithSuite(&mockSuite{alg: "PQ"}))
+
+		require.True(t, registry.Supports("PQ"))
+		require.NoError(t, registry.Verify("PQ", &jws.JWK{}, nil, nil))
+	})
+
+	t.Run("built-in suite may be overridden", func(t *testing.T) {
+		errExpected := errors.New("injected verify error")
+
+		registry := New(WithDefaultSuites(), WithSuite(&mockSuite{alg: AlgorithmES256, err: errExpected}))
+
+		err := registry.Verify(AlgorithmES256, vectors[1].JWK, vectors[1].signature, vectors[1].message)
+		require.True(t, errors.Is(err, errExpected))
+	})
+}
+
+type mockSuite struct {
+	alg string
+	err error
+}
+
+func (m *mockSuite) Accept(alg string) bool {
+	return alg == m.alg
+}
+
+func (m *mockSuite) Verify(*jws.JWK, []byte, []byte) error {
+	return m.err
+}
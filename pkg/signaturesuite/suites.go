@@ -0,0 +1,215 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signaturesuite
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	internal "github.com/trustbloc/sidetree-core-go/pkg/internal/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+const (
+	// AlgorithmEdDSA is the JWS algorithm for Ed25519 signatures.
+	AlgorithmEdDSA = "EdDSA"
+
+	// AlgorithmES256 is the JWS algorithm for ECDSA signatures using P-256 and SHA-256.
+	AlgorithmES256 = "ES256"
+
+	// AlgorithmES384 is the JWS algorithm for ECDSA signatures using P-384 and SHA-384.
+	AlgorithmES384 = "ES384"
+
+	// AlgorithmES512 is the JWS algorithm for ECDSA signatures using P-521 and SHA-512.
+	AlgorithmES512 = "ES512"
+
+	// AlgorithmES256K is the JWS algorithm for ECDSA signatures using secp256k1 and SHA-256.
+	AlgorithmES256K = "ES256K"
+
+	// AlgorithmES256KR is the JWS algorithm for recoverable ECDSA signatures using secp256k1 and SHA-256.
+	// The signature is the concatenation of R, S and the recovery ID (V).
+	AlgorithmES256KR = "ES256K-R"
+)
+
+const (
+	p256KeySize      = 32
+	p384KeySize      = 48
+	p521KeySize      = 66
+	secp256k1KeySize = 32
+
+	// compactSigMagicOffset is added to the recovery ID in compact signatures (as used by btcec).
+	compactSigMagicOffset = 27
+)
+
+// EdDSA verifies Ed25519 signatures.
+type EdDSA struct{}
+
+// NewEdDSA returns the EdDSA signature suite.
+func NewEdDSA() *EdDSA {
+	return &EdDSA{}
+}
+
+// Accept returns true for the EdDSA algorithm.
+func (s *EdDSA) Accept(alg string) bool {
+	return alg == AlgorithmEdDSA
+}
+
+// Verify verifies an Ed25519 signature.
+func (s *EdDSA) Verify(jwk *jws.JWK, signature, msg []byte) error {
+	if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" {
+		return fmt.Errorf("ed25519: key type '%s' and curve '%s' are not supported", jwk.Kty, jwk.Crv)
+	}
+
+	pubKey, err := internal.GetED25519PublicKey(jwk)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(pubKey, msg, signature) {
+		return errors.New("ed25519: invalid signature")
+	}
+
+	return nil
+}
+
+// ECDSA verifies ECDSA signatures for a given curve and hash.
+type ECDSA struct {
+	alg     string
+	crv     string
+	keySize int
+	hash    crypto.Hash
+}
+
+// NewES256 returns the ES256 signature suite.
+func NewES256() *ECDSA {
+	return &ECDSA{alg: AlgorithmES256, crv: "P-256", keySize: p256KeySize, hash: crypto.SHA256}
+}
+
+// NewES384 returns the ES384 signature suite.
+func NewES384() *ECDSA {
+	return &ECDSA{alg: AlgorithmES384, crv: "P-384", keySize: p384KeySize, hash: crypto.SHA384}
+}
+
+// NewES512 returns the ES512 signature suite.
+func NewES512() *ECDSA {
+	return &ECDSA{alg: AlgorithmES512, crv: "P-521", keySize: p521KeySize, hash: crypto.SHA512}
+}
+
+// NewES256K returns the ES256K signature suite.
+func NewES256K() *ECDSA {
+	return &ECDSA{alg: AlgorithmES256K, crv: "secp256k1", keySize: secp256k1KeySize, hash: crypto.SHA256}
+}
+
+// Accept returns true for the algorithm of the suite.
+func (s *ECDSA) Accept(alg string) bool {
+	return alg == s.alg
+}
+
+// Verify verifies an ECDSA signature (R and S concatenated).
+func (s *ECDSA) Verify(jwk *jws.JWK, signature, msg []byte) error {
+	pubKey, err := getECDSAPublicKey(jwk, s.alg, s.crv)
+	if err != nil {
+		return err
+	}
+
+	if len(signature) != 2*s.keySize {
+		return errors.New("ecdsa: invalid signature size")
+	}
+
+	hash, err := hashMessage(s.hash, msg)
+	if err != nil {
+		return err
+	}
+
+	r := big.NewInt(0).SetBytes(signature[:s.keySize])
+	sv := big.NewInt(0).SetBytes(signature[s.keySize:])
+
+	if !ecdsa.Verify(pubKey, hash, r, sv) {
+		return errors.New("ecdsa: invalid signature")
+	}
+
+	return nil
+}
+
+// ES256KR verifies recoverable secp256k1 signatures. The public key is recovered from the signature and
+// compared with the given key.
+type ES256KR struct{}
+
+// NewES256KR returns the ES256K-R signature suite.
+func NewES256KR() *ES256KR {
+	return &ES256KR{}
+}
+
+// Accept returns true for the ES256K-R algorithm.
+func (s *ES256KR) Accept(alg string) bool {
+	return alg == AlgorithmES256KR
+}
+
+// Verify verifies a recoverable secp256k1 signature (R, S and V concatenated).
+func (s *ES256KR) Verify(jwk *jws.JWK, signature, msg []byte) error {
+	pubKey, err := getECDSAPublicKey(jwk, AlgorithmES256KR, "secp256k1")
+	if err != nil {
+		return err
+	}
+
+	if len(signature) != 2*secp256k1KeySize+1 {
+		return errors.New("ecdsa: invalid signature size")
+	}
+
+	recoveryID := signature[2*secp256k1KeySize]
+	if recoveryID >= compactSigMagicOffset {
+		recoveryID -= compactSigMagicOffset
+	}
+
+	if recoveryID > 1 {
+		return errors.New("ecdsa: invalid recovery ID")
+	}
+
+	hash, err := hashMessage(crypto.SHA256, msg)
+	if err != nil {
+		return err
+	}
+
+	compactSig := append([]byte{compactSigMagicOffset + recoveryID}, signature[:2*secp256k1KeySize]...)
+
+	recoveredKey, _, err := btcec.RecoverCompact(btcec.S256(), compactSig, hash)
+	if err != nil {
+		return fmt.Errorf("ecdsa: recover public key: %w", err)
+	}
+
+	if recoveredKey.X.Cmp(pubKey.X) != 0 || recoveredKey.Y.Cmp(pubKey.Y) != 0 {
+		return errors.New("ecdsa: invalid signature")
+	}
+
+	return nil
+}
+
+func getECDSAPublicKey(jwk *jws.JWK, alg, crv string) (*ecdsa.PublicKey, error) {
+	if jwk.Kty != "EC" || jwk.Crv != crv {
+		return nil, fmt.Errorf("ecdsa: key type '%s' and curve '%s' are not supported by algorithm '%s'",
+			jwk.Kty, jwk.Crv, alg)
+	}
+
+	return internal.GetECDSAPublicKey(jwk)
+}
+
+func hashMessage(h crypto.Hash, msg []byte) ([]byte, error) {
+	hasher := h.New()
+
+	_, err := hasher.Write(msg)
+	if err != nil {
+		return nil, errors.New("ecdsa: hash error")
+	}
+
+	return hasher.Sum(nil), nil
+}
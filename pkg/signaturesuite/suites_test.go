@@ -0,0 +1,149 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signaturesuite
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+func TestSuites(t *testing.T) {
+	vectors := readTestVectors(t)
+
+	suites := map[string]Suite{
+		AlgorithmEdDSA:   NewEdDSA(),
+		AlgorithmES256:   NewES256(),
+		AlgorithmES384:   NewES384(),
+		AlgorithmES512:   NewES512(),
+		AlgorithmES256K:  NewES256K(),
+		AlgorithmES256KR: NewES256KR(),
+	}
+
+	require.Len(t, vectors, len(suites))
+
+	for _, v := range vectors {
+		v := v
+
+		suite, ok := suites[v.Alg]
+		require.True(t, ok, v.Alg)
+
+		t.Run(v.Alg, func(t *testing.T) {
+			require.True(t, suite.Accept(v.Alg))
+
+			for alg := range suites {
+				if alg != v.Alg {
+					require.False(t, suite.Accept(alg))
+				}
+			}
+
+			t.Run("success", func(t *testing.T) {
+				require.NoError(t, suite.Verify(v.JWK, v.signature, v.message))
+			})
+
+			t.Run("error - modified message", func(t *testing.T) {
+				msg := append([]byte{}, v.message...)
+				msg[0] ^= 0xff
+
+				err := suite.Verify(v.JWK, v.signature, msg)
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "invalid signature")
+			})
+
+			t.Run("error - modified signature", func(t *testing.T) {
+				sig := append([]byte{}, v.signature...)
+				sig[len(sig)/2] ^= 0xff
+
+				require.Error(t, suite.Verify(v.JWK, sig, v.message))
+			})
+
+			t.Run("error - invalid signature size", func(t *testing.T) {
+				require.Error(t, suite.Verify(v.JWK, v.signature[1:], v.message))
+			})
+
+			t.Run("error - key from another suite", func(t *testing.T) {
+				for _, other := range vectors {
+					if other.JWK.Crv == v.JWK.Crv {
+						continue
+					}
+
+					err := suite.Verify(other.JWK, v.signature, v.message)
+					require.Error(t, err)
+					require.Contains(t, err.Error(), "not supported")
+				}
+			})
+		})
+	}
+
+	t.Run("ES256K-R - invalid recovery ID", func(t *testing.T) {
+		v := vectors[len(vectors)-1]
+		require.Equal(t, AlgorithmES256KR, v.Alg)
+
+		sig := append([]byte{}, v.signature...)
+		sig[len(sig)-1] = 5
+
+		err := NewES256KR().Verify(v.JWK, sig, v.message)
+		require.EqualError(t, err, "ecdsa: invalid recovery ID")
+
+		// The recovery ID may also be offset by 27.
+		sig[len(sig)-1] = v.signature[len(sig)-1] + compactSigMagicOffset
+
+		require.NoError(t, NewES256KR().Verify(v.JWK, sig, v.message))
+	})
+
+	t.Run("error - invalid key", func(t *testing.T) {
+		jwk := &jws.JWK{Kty: "EC", Crv: "P-256", X: "invalid", Y: "invalid"}
+
+		require.Error(t, NewES256().Verify(jwk, vectors[1].signature, vectors[1].message))
+
+		jwk = &jws.JWK{Kty: "OKP", Crv: "Ed25519", X: "invalid"}
+
+		require.Error(t, NewEdDSA().Verify(jwk, vectors[0].signature, vectors[0].message))
+
+		jwk = &jws.JWK{Kty: "EC", Crv: "secp256k1", X: "invalid", Y: "invalid"}
+
+		require.Error(t, NewES256KR().Verify(jwk, vectors[5].signature, vectors[5].message))
+	})
+}
+
+// testVector holds a signature of a message along with the public key that is used to verify the signature.
+// The message and signature are base64url encoded.
+type testVector struct {
+	Alg       string   `json:"alg"`
+	Comment   string   `json:"comment"`
+	JWK       *jws.JWK `json:"jwk"`
+	Message   string   `json:"message"`
+	Signature string   `json:"signature"`
+
+	message   []byte
+	signature []byte
+}
+
+func readTestVectors(t *testing.T) []*testVector {
+	t.Helper()
+
+	vectorBytes, err := os.ReadFile("testdata/vectors.json")
+	require.NoError(t, err)
+
+	var vectors []*testVector
+	require.NoError(t, json.Unmarshal(vectorBytes, &vectors))
+
+	for _, v := range vectors {
+		v.message, err = base64.RawURLEncoding.DecodeString(v.Message)
+		require.NoError(t, err)
+
+		v.signature, err = base64.RawURLEncoding.DecodeString(v.Signature)
+		require.NoError(t, err)
+	}
+
+	return vectors
+}
@@ -0,0 +1,183 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keystoresigner
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+
+	internal "github.com/trustbloc/sidetree-core-go/pkg/internal/jws"
+)
+
+const (
+	keystoreVersion = 1
+	kdfScrypt       = "scrypt"
+	cipherA256GCM   = "A256GCM"
+
+	defaultScryptN = 1 << 15
+	scryptR        = 8
+	scryptP        = 1
+	saltSize       = 32
+	keySize        = 32
+)
+
+// Keystore is a password-protected private key. The private key is stored as an encrypted JWK. The encryption
+// key is derived from the password using scrypt.
+type Keystore struct {
+	Version    int       `json:"version"`
+	KDF        string    `json:"kdf"`
+	KDFParams  KDFParams `json:"kdfParams"`
+	Cipher     string    `json:"cipher"`
+	Nonce      string    `json:"nonce"`
+	Ciphertext string    `json:"ciphertext"`
+}
+
+// KDFParams holds the scrypt parameters. The salt is base64url encoded.
+type KDFParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"`
+}
+
+type encryptOptions struct {
+	scryptN int
+}
+
+// EncryptOption is an option for encrypting a private key.
+type EncryptOption func(opts *encryptOptions)
+
+// WithScryptN sets the scrypt CPU/memory cost parameter (a power of two). The default is 32768.
+func WithScryptN(n int) EncryptOption {
+	return func(opts *encryptOptions) {
+		opts.scryptN = n
+	}
+}
+
+// Encrypt encrypts the given private key (*ecdsa.PrivateKey or ed25519.PrivateKey) with the given password
+// and returns the keystore in JSON format.
+func Encrypt(privateKey crypto.PrivateKey, password []byte, opts ...EncryptOption) ([]byte, error) {
+	options := &encryptOptions{scryptN: defaultScryptN}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	jwkBytes, err := internal.MarshalPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	salt, err := randomBytes(saltSize)
+	if err != nil {
+		return nil, err
+	}
+
+	params := KDFParams{
+		N:    options.scryptN,
+		R:    scryptR,
+		P:    scryptP,
+		Salt: base64.RawURLEncoding.EncodeToString(salt),
+	}
+
+	aead, err := newAEAD(password, salt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := randomBytes(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&Keystore{
+		Version:    keystoreVersion,
+		KDF:        kdfScrypt,
+		KDFParams:  params,
+		Cipher:     cipherA256GCM,
+		Nonce:      base64.RawURLEncoding.EncodeToString(nonce),
+		Ciphertext: base64.RawURLEncoding.EncodeToString(aead.Seal(nil, nonce, jwkBytes, nil)),
+	})
+}
+
+// Decrypt decrypts the private key in the given keystore (JSON format) with the given password.
+func Decrypt(keystoreBytes, password []byte) (crypto.PrivateKey, error) {
+	ks := &Keystore{}
+
+	err := json.Unmarshal(keystoreBytes, ks)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal keystore: %w", err)
+	}
+
+	if ks.Version != keystoreVersion || ks.KDF != kdfScrypt || ks.Cipher != cipherA256GCM {
+		return nil, fmt.Errorf("unsupported keystore: version [%d], kdf [%s], cipher [%s]", ks.Version, ks.KDF, ks.Cipher)
+	}
+
+	salt, err := base64.RawURLEncoding.DecodeString(ks.KDFParams.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("decode salt: %w", err)
+	}
+
+	nonce, err := base64.RawURLEncoding.DecodeString(ks.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("decode nonce: %w", err)
+	}
+
+	ciphertext, err := base64.RawURLEncoding.DecodeString(ks.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("decode ciphertext: %w", err)
+	}
+
+	aead, err := newAEAD(password, salt, ks.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid nonce size")
+	}
+
+	jwkBytes, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("decrypt keystore: invalid password or corrupted keystore")
+	}
+
+	return internal.UnmarshalPrivateKey(jwkBytes)
+}
+
+func newAEAD(password, salt []byte, params KDFParams) (cipher.AEAD, error) {
+	key, err := scrypt.Key(password, salt, params.N, params.R, params.P, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
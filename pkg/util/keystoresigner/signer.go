@@ -0,0 +1,55 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keystoresigner
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"fmt"
+	"os"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/edsigner"
+)
+
+type signer interface {
+	Sign(data []byte) ([]byte, error)
+	Headers() jws.Headers
+}
+
+// Signer implements signer interface using a private key from a password-protected keystore.
+type Signer struct {
+	signer
+}
+
+// New returns a signer for the private key in the given keystore (JSON format).
+func New(keystore, password []byte, alg, kid string) (*Signer, error) {
+	privateKey, err := Decrypt(keystore, password)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		return &Signer{signer: ecsigner.New(key, alg, kid)}, nil
+	case ed25519.PrivateKey:
+		return &Signer{signer: edsigner.New(key, alg, kid)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type: %T", privateKey)
+	}
+}
+
+// NewFromFile returns a signer for the private key in the given keystore file.
+func NewFromFile(path string, password []byte, alg, kid string) (*Signer, error) {
+	keystore, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("read keystore file: %w", err)
+	}
+
+	return New(keystore, password, alg, kid)
+}
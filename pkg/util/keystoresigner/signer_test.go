@@ -0,0 +1,201 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keystoresigner
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/signaturesuite"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+// Use a low scrypt cost in order to speed up tests.
+const testScryptN = 1 << 10
+
+var password = []byte("password")
+
+func TestSigner(t *testing.T) {
+	suites := signaturesuite.New(signaturesuite.WithDefaultSuites())
+	msg := []byte("test message")
+
+	t.Run("success", func(t *testing.T) {
+		for alg, curve := range map[string]elliptic.Curve{
+			"ES256":  elliptic.P256(),
+			"ES384":  elliptic.P384(),
+			"ES512":  elliptic.P521(),
+			"ES256K": btcec.S256(),
+		} {
+			privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+			require.NoError(t, err)
+
+			requireSignAndVerify(t, suites, privateKey, &privateKey.PublicKey, alg, msg)
+		}
+
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		requireSignAndVerify(t, suites, privateKey, publicKey, "EdDSA", msg)
+	})
+
+	t.Run("success - from file", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		keystore, err := Encrypt(privateKey, password, WithScryptN(testScryptN))
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "keystore.json")
+		require.NoError(t, os.WriteFile(path, keystore, 0o600))
+
+		signer, err := NewFromFile(path, password, "ES256", "kid")
+		require.NoError(t, err)
+
+		kid, ok := signer.Headers().KeyID()
+		require.True(t, ok)
+		require.Equal(t, "kid", kid)
+
+		signature, err := signer.Sign(msg)
+		require.NoError(t, err)
+
+		jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+		require.NoError(t, err)
+
+		require.NoError(t, suites.Verify("ES256", jwk, signature, msg))
+
+		signer, err = NewFromFile(filepath.Join(t.TempDir(), "missing.json"), password, "ES256", "kid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "read keystore file")
+		require.Nil(t, signer)
+	})
+
+	t.Run("error - wrong password", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		keystore, err := Encrypt(privateKey, password, WithScryptN(testScryptN))
+		require.NoError(t, err)
+
+		signer, err := New(keystore, []byte("wrong"), "ES256", "kid")
+		require.EqualError(t, err, "decrypt keystore: invalid password or corrupted keystore")
+		require.Nil(t, signer)
+	})
+}
+
+func TestEncrypt(t *testing.T) {
+	t.Run("success - private key isn't stored in clear text", func(t *testing.T) {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		keystoreBytes, err := Encrypt(privateKey, password, WithScryptN(testScryptN))
+		require.NoError(t, err)
+
+		ks := &Keystore{}
+		require.NoError(t, json.Unmarshal(keystoreBytes, ks))
+		require.Equal(t, keystoreVersion, ks.Version)
+		require.Equal(t, kdfScrypt, ks.KDF)
+		require.Equal(t, cipherA256GCM, ks.Cipher)
+		require.Equal(t, testScryptN, ks.KDFParams.N)
+		require.NotContains(t, string(keystoreBytes), `"d"`)
+
+		decrypted, err := Decrypt(keystoreBytes, password)
+		require.NoError(t, err)
+		require.Equal(t, privateKey, decrypted)
+	})
+
+	t.Run("error - unsupported key type", func(t *testing.T) {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+
+		keystore, err := Encrypt(rsaKey, password)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported private key type")
+		require.Nil(t, keystore)
+	})
+
+	t.Run("error - invalid scrypt parameter", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		keystore, err := Encrypt(privateKey, password, WithScryptN(3))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "derive key")
+		require.Nil(t, keystore)
+	})
+}
+
+func TestDecrypt(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	keystoreBytes, err := Encrypt(privateKey, password, WithScryptN(testScryptN))
+	require.NoError(t, err)
+
+	t.Run("error - invalid keystore", func(t *testing.T) {
+		key, err := Decrypt([]byte("{"), password)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal keystore")
+		require.Nil(t, key)
+	})
+
+	for name, update := range map[string]func(ks *Keystore){
+		"unsupported keystore": func(ks *Keystore) { ks.Cipher = "A128CBC" },
+		"decode salt":          func(ks *Keystore) { ks.KDFParams.Salt = "!" },
+		"decode nonce":         func(ks *Keystore) { ks.Nonce = "!" },
+		"decode ciphertext":    func(ks *Keystore) { ks.Ciphertext = "!" },
+		"invalid nonce size":   func(ks *Keystore) { ks.Nonce = "AAAA" },
+		"derive key":           func(ks *Keystore) { ks.KDFParams.N = 3 },
+		"corrupted keystore":   func(ks *Keystore) { ks.Ciphertext = ks.Ciphertext[1:] },
+	} {
+		name, update := name, update
+
+		t.Run("error - "+name, func(t *testing.T) {
+			ks := &Keystore{}
+			require.NoError(t, json.Unmarshal(keystoreBytes, ks))
+
+			update(ks)
+
+			ksBytes, err := json.Marshal(ks)
+			require.NoError(t, err)
+
+			key, err := Decrypt(ksBytes, password)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), name)
+			require.Nil(t, key)
+		})
+	}
+}
+
+func requireSignAndVerify(t *testing.T, suites *signaturesuite.Registry, privateKey crypto.PrivateKey,
+	publicKey crypto.PublicKey, alg string, msg []byte) {
+	t.Helper()
+
+	keystore, err := Encrypt(privateKey, password, WithScryptN(testScryptN))
+	require.NoError(t, err)
+
+	signer, err := New(keystore, password, alg, "kid")
+	require.NoError(t, err)
+
+	signature, err := signer.Sign(msg)
+	require.NoError(t, err)
+
+	jwk, err := pubkey.GetPublicKeyJWK(publicKey)
+	require.NoError(t, err)
+
+	require.NoError(t, suites.Verify(alg, jwk, signature, msg), alg)
+}
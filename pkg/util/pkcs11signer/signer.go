@@ -0,0 +1,112 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package pkcs11signer adapts a PKCS#11 token to the Sidetree client signer interface. The package does not
+// include a PKCS#11 binding: the caller supplies a Token that is backed by a binding (e.g.
+// github.com/miekg/pkcs11) and that manages the module, session and login. The signer maps the JWS algorithm
+// to a PKCS#11 mechanism and hashes the message where the mechanism requires it.
+package pkcs11signer
+
+import (
+	"crypto"
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+// PKCS#11 signing mechanisms (see the PKCS#11 specification).
+const (
+	// MechanismECDSA is CKM_ECDSA. The data to sign is the message digest and the signature is R and S concatenated.
+	MechanismECDSA uint = 0x00001041
+
+	// MechanismEdDSA is CKM_EDDSA. The data to sign is the message.
+	MechanismEdDSA uint = 0x00001057
+)
+
+// Token signs data with a private key that is stored in a PKCS#11 token (e.g. an HSM or SoftHSM). An
+// implementation is typically a thin adapter around a PKCS#11 binding (such as github.com/miekg/pkcs11)
+// that holds an open, logged-in session.
+type Token interface {
+	// Sign finds the private key with the given label (CKA_LABEL) and signs data using the given
+	// mechanism (C_SignInit followed by C_Sign).
+	Sign(mechanism uint, keyLabel string, data []byte) ([]byte, error)
+}
+
+// Signer implements signer interface. The private key never leaves the PKCS#11 token.
+type Signer struct {
+	token    Token
+	keyLabel string
+	alg      string
+	kid      string
+}
+
+// New returns a PKCS#11 signer for the key with the given label. The algorithm must be one of EdDSA, ES256,
+// ES384, ES512 or ES256K.
+func New(token Token, keyLabel, alg, kid string) *Signer {
+	return &Signer{token: token, keyLabel: keyLabel, alg: alg, kid: kid}
+}
+
+// Headers provides required JWS protected headers. It provides information about signing key and algorithm.
+func (signer *Signer) Headers() jws.Headers {
+	headers := make(jws.Headers)
+
+	if signer.alg != "" {
+		headers[jws.HeaderAlgorithm] = signer.alg
+	}
+
+	if signer.kid != "" {
+		headers[jws.HeaderKeyID] = signer.kid
+	}
+
+	return headers
+}
+
+// Sign signs msg and returns signature value.
+func (signer *Signer) Sign(msg []byte) ([]byte, error) {
+	params, ok := algorithms[signer.alg]
+	if !ok {
+		return nil, fmt.Errorf("algorithm '%s' is not supported by the PKCS#11 signer", signer.alg)
+	}
+
+	data := msg
+
+	if params.hash != 0 {
+		hasher := params.hash.New()
+
+		_, err := hasher.Write(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		data = hasher.Sum(nil)
+	}
+
+	signature, err := signer.token.Sign(params.mechanism, signer.keyLabel, data)
+	if err != nil {
+		return nil, fmt.Errorf("PKCS#11 sign with key '%s': %w", signer.keyLabel, err)
+	}
+
+	if len(signature) != params.signatureSize {
+		return nil, fmt.Errorf("PKCS#11 token returned a signature of size %d; expecting %d",
+			len(signature), params.signatureSize)
+	}
+
+	return signature, nil
+}
+
+type algorithm struct {
+	mechanism     uint
+	hash          crypto.Hash
+	signatureSize int
+}
+
+var algorithms = map[string]*algorithm{
+	"EdDSA":  {mechanism: MechanismEdDSA, signatureSize: 64},
+	"ES256":  {mechanism: MechanismECDSA, hash: crypto.SHA256, signatureSize: 64},
+	"ES384":  {mechanism: MechanismECDSA, hash: crypto.SHA384, signatureSize: 96},
+	"ES512":  {mechanism: MechanismECDSA, hash: crypto.SHA512, signatureSize: 132},
+	"ES256K": {mechanism: MechanismECDSA, hash: crypto.SHA256, signatureSize: 64},
+}
@@ -0,0 +1,143 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/signaturesuite"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+func TestSigner_Sign(t *testing.T) {
+	token := newStubToken()
+	suites := signaturesuite.New(signaturesuite.WithDefaultSuites())
+	msg := []byte("test message")
+
+	t.Run("success", func(t *testing.T) {
+		for alg, curve := range map[string]elliptic.Curve{
+			"ES256":  elliptic.P256(),
+			"ES384":  elliptic.P384(),
+			"ES512":  elliptic.P521(),
+			"ES256K": btcec.S256(),
+		} {
+			privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+			require.NoError(t, err)
+
+			token.keys[alg] = privateKey
+
+			signature, err := New(token, alg, alg, "kid").Sign(msg)
+			require.NoError(t, err, alg)
+
+			jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+			require.NoError(t, err)
+
+			require.NoError(t, suites.Verify(alg, jwk, signature, msg), alg)
+		}
+
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		token.keys["ed-key"] = privateKey
+
+		signature, err := New(token, "ed-key", "EdDSA", "kid").Sign(msg)
+		require.NoError(t, err)
+
+		jwk, err := pubkey.GetPublicKeyJWK(publicKey)
+		require.NoError(t, err)
+
+		require.NoError(t, suites.Verify("EdDSA", jwk, signature, msg))
+	})
+
+	t.Run("error - unsupported algorithm", func(t *testing.T) {
+		signature, err := New(token, "ES256", "RS256", "kid").Sign(msg)
+		require.EqualError(t, err, "algorithm 'RS256' is not supported by the PKCS#11 signer")
+		require.Nil(t, signature)
+	})
+
+	t.Run("error - key not found", func(t *testing.T) {
+		signature, err := New(token, "unknown", "ES256", "kid").Sign(msg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "PKCS#11 sign with key 'unknown': key not found")
+		require.Nil(t, signature)
+	})
+
+	t.Run("error - unexpected signature size", func(t *testing.T) {
+		// The key is a P-384 key whereas ES256 expects a P-256 key.
+		signature, err := New(token, "ES384", "ES256", "kid").Sign(msg)
+		require.EqualError(t, err, "PKCS#11 token returned a signature of size 96; expecting 64")
+		require.Nil(t, signature)
+	})
+}
+
+func TestSigner_Headers(t *testing.T) {
+	headers := New(newStubToken(), "label", "ES256", "kid").Headers()
+
+	alg, ok := headers.Algorithm()
+	require.True(t, ok)
+	require.Equal(t, "ES256", alg)
+
+	kid, ok := headers.KeyID()
+	require.True(t, ok)
+	require.Equal(t, "kid", kid)
+
+	require.Empty(t, New(newStubToken(), "label", "", "").Headers())
+}
+
+// stubToken emulates the CKM_ECDSA and CKM_EDDSA mechanisms of a PKCS#11 token.
+type stubToken struct {
+	keys map[string]crypto.PrivateKey
+}
+
+func newStubToken() *stubToken {
+	return &stubToken{keys: make(map[string]crypto.PrivateKey)}
+}
+
+func (s *stubToken) Sign(mechanism uint, keyLabel string, data []byte) ([]byte, error) {
+	key, ok := s.keys[keyLabel]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		if mechanism != MechanismECDSA {
+			return nil, fmt.Errorf("mechanism %d not supported for EC key", mechanism)
+		}
+
+		r, sv, err := ecdsa.Sign(rand.Reader, k, data)
+		if err != nil {
+			return nil, err
+		}
+
+		size := (k.Curve.Params().BitSize + 7) / 8
+
+		signature := make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		sv.FillBytes(signature[size:])
+
+		return signature, nil
+	case ed25519.PrivateKey:
+		if mechanism != MechanismEdDSA {
+			return nil, fmt.Errorf("mechanism %d not supported for Ed25519 key", mechanism)
+		}
+
+		return ed25519.Sign(k, data), nil
+	default:
+		return nil, errors.New("unsupported key")
+	}
+}
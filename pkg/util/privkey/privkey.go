@@ -0,0 +1,101 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package privkey
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	internal "github.com/trustbloc/sidetree-core-go/pkg/internal/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/signaturesuite"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/edsigner"
+)
+
+type signer interface {
+	Sign(data []byte) ([]byte, error)
+	Headers() jws.Headers
+}
+
+// Signer implements signer interface using the given private key and the algorithm that corresponds to the key.
+type Signer struct {
+	signer
+}
+
+// NewSigner returns a signer for the given private key (*ecdsa.PrivateKey or ed25519.PrivateKey). The signing
+// algorithm is derived from the key (see GetSigningAlgorithm).
+func NewSigner(privateKey crypto.PrivateKey, kid string) (*Signer, error) {
+	alg, err := GetSigningAlgorithm(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		return &Signer{signer: ecsigner.New(key, alg, kid)}, nil
+	case ed25519.PrivateKey:
+		return &Signer{signer: edsigner.New(key, alg, kid)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type: %T", privateKey)
+	}
+}
+
+// GetPrivateKeyJWK returns the given private key (*ecdsa.PrivateKey or ed25519.PrivateKey) in JWK format.
+func GetPrivateKeyJWK(privateKey crypto.PrivateKey) ([]byte, error) {
+	return internal.MarshalPrivateKey(privateKey)
+}
+
+// ParsePrivateKeyJWK parses a private key (*ecdsa.PrivateKey or ed25519.PrivateKey) in JWK format.
+func ParsePrivateKeyJWK(jwk []byte) (crypto.PrivateKey, error) {
+	return internal.UnmarshalPrivateKey(jwk)
+}
+
+// GetPublicKey returns the public key (*ecdsa.PublicKey or ed25519.PublicKey) of the given private key.
+func GetPublicKey(privateKey crypto.PrivateKey) (crypto.PublicKey, error) {
+	switch key := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		return &key.PublicKey, nil
+	case ed25519.PrivateKey:
+		return key.Public(), nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type: %T", privateKey)
+	}
+}
+
+// GetSigningAlgorithm returns the JWS algorithm for signing with the given private key, e.g. ES256 for
+// a P-256 key and EdDSA for an Ed25519 key.
+func GetSigningAlgorithm(privateKey crypto.PrivateKey) (string, error) {
+	switch key := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		return getECDSAAlgorithm(key.Curve)
+	case ed25519.PrivateKey:
+		return signaturesuite.AlgorithmEdDSA, nil
+	default:
+		return "", fmt.Errorf("unsupported private key type: %T", privateKey)
+	}
+}
+
+func getECDSAAlgorithm(curve elliptic.Curve) (string, error) {
+	switch curve {
+	case btcec.S256():
+		return signaturesuite.AlgorithmES256K, nil
+	case elliptic.P256():
+		return signaturesuite.AlgorithmES256, nil
+	case elliptic.P384():
+		return signaturesuite.AlgorithmES384, nil
+	case elliptic.P521():
+		return signaturesuite.AlgorithmES512, nil
+	default:
+		return "", fmt.Errorf("unsupported curve: %s", curve.Params().Name)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package privkey
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/signaturesuite"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+func TestPrivateKey(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		for alg, curve := range map[string]elliptic.Curve{
+			"ES256":  elliptic.P256(),
+			"ES384":  elliptic.P384(),
+			"ES512":  elliptic.P521(),
+			"ES256K": btcec.S256(),
+		} {
+			privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+			require.NoError(t, err)
+
+			requirePrivateKey(t, privateKey, &privateKey.PublicKey, alg)
+		}
+
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		requirePrivateKey(t, privateKey, publicKey, "EdDSA")
+	})
+
+	t.Run("error - unsupported key type", func(t *testing.T) {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+
+		jwk, err := GetPrivateKeyJWK(rsaKey)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported private key type")
+		require.Nil(t, jwk)
+
+		publicKey, err := GetPublicKey(rsaKey)
+		require.EqualError(t, err, "unsupported private key type: *rsa.PrivateKey")
+		require.Nil(t, publicKey)
+
+		alg, err := GetSigningAlgorithm(rsaKey)
+		require.EqualError(t, err, "unsupported private key type: *rsa.PrivateKey")
+		require.Empty(t, alg)
+
+		signer, err := NewSigner(rsaKey, "kid")
+		require.EqualError(t, err, "unsupported private key type: *rsa.PrivateKey")
+		require.Nil(t, signer)
+	})
+
+	t.Run("error - unsupported curve", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+		require.NoError(t, err)
+
+		alg, err := GetSigningAlgorithm(privateKey)
+		require.EqualError(t, err, "unsupported curve: P-224")
+		require.Empty(t, alg)
+	})
+
+	t.Run("error - invalid JWK", func(t *testing.T) {
+		privateKey, err := ParsePrivateKeyJWK([]byte(`{"kty":"EC"}`))
+		require.Error(t, err)
+		require.Nil(t, privateKey)
+	})
+}
+
+func requirePrivateKey(t *testing.T, privateKey crypto.PrivateKey, publicKey crypto.PublicKey, alg string) {
+	t.Helper()
+
+	jwk, err := GetPrivateKeyJWK(privateKey)
+	require.NoError(t, err)
+
+	parsed, err := ParsePrivateKeyJWK(jwk)
+	require.NoError(t, err)
+	require.Equal(t, privateKey, parsed)
+
+	pk, err := GetPublicKey(parsed)
+	require.NoError(t, err)
+	require.Equal(t, publicKey, pk)
+
+	a, err := GetSigningAlgorithm(parsed)
+	require.NoError(t, err)
+	require.Equal(t, alg, a)
+
+	signer, err := NewSigner(parsed, "kid")
+	require.NoError(t, err)
+
+	headerAlg, ok := signer.Headers().Algorithm()
+	require.True(t, ok)
+	require.Equal(t, alg, headerAlg)
+
+	msg := []byte("test message")
+
+	signature, err := signer.Sign(msg)
+	require.NoError(t, err)
+
+	publicKeyJWK, err := pubkey.GetPublicKeyJWK(publicKey)
+	require.NoError(t, err)
+
+	require.NoError(t, signaturesuite.New(signaturesuite.WithDefaultSuites()).Verify(alg, publicKeyJWK, signature, msg))
+}
@@ -0,0 +1,163 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package remotesigner
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+// SignRequest is the request that is posted to the remote signing service.
+type SignRequest struct {
+	// KeyID identifies the key within the remote signing service.
+	KeyID string `json:"keyId"`
+
+	// Algorithm is the JWS algorithm (e.g. ES256, EdDSA).
+	Algorithm string `json:"alg"`
+
+	// Message is the base64url encoded message to sign.
+	Message string `json:"message"`
+}
+
+// SignResponse is the response of the remote signing service.
+type SignResponse struct {
+	// Signature is the base64url encoded signature in JWS format (e.g. R and S concatenated for ECDSA).
+	Signature string `json:"signature"`
+}
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Signer implements signer interface. The signature is created by a remote signing service (e.g. a KMS)
+// so that the private key never leaves the service.
+type Signer struct {
+	endpoint   string
+	keyID      string
+	alg        string
+	kid        string
+	authToken  string
+	httpClient httpClient
+}
+
+// Option is a remote signer option.
+type Option func(opts *Signer)
+
+// WithHTTPClient sets the HTTP client used to invoke the remote signing service.
+func WithHTTPClient(client httpClient) Option {
+	return func(opts *Signer) {
+		opts.httpClient = client
+	}
+}
+
+// WithAuthToken sets the bearer token that is sent to the remote signing service.
+func WithAuthToken(token string) Option {
+	return func(opts *Signer) {
+		opts.authToken = token
+	}
+}
+
+// New returns a signer that signs with the key identified by keyID at the given endpoint of a remote
+// signing service.
+func New(endpoint, keyID, alg, kid string, opts ...Option) *Signer {
+	signer := &Signer{
+		endpoint:   endpoint,
+		keyID:      keyID,
+		alg:        alg,
+		kid:        kid,
+		httpClient: &http.Client{},
+	}
+
+	// apply options
+	for _, opt := range opts {
+		opt(signer)
+	}
+
+	return signer
+}
+
+// Headers provides required JWS protected headers. It provides information about signing key and algorithm.
+func (signer *Signer) Headers() jws.Headers {
+	headers := make(jws.Headers)
+
+	if signer.alg != "" {
+		headers[jws.HeaderAlgorithm] = signer.alg
+	}
+
+	if signer.kid != "" {
+		headers[jws.HeaderKeyID] = signer.kid
+	}
+
+	return headers
+}
+
+// Sign signs msg and returns signature value.
+func (signer *Signer) Sign(msg []byte) ([]byte, error) {
+	reqBytes, err := json.Marshal(&SignRequest{
+		KeyID:     signer.keyID,
+		Algorithm: signer.alg,
+		Message:   base64.RawURLEncoding.EncodeToString(msg),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal sign request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, signer.endpoint, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, fmt.Errorf("create sign request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if signer.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+signer.authToken)
+	}
+
+	resp, err := signer.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("invoke remote signer: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read sign response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote signer returned status %d: %s", resp.StatusCode, respBytes)
+	}
+
+	signResp := &SignResponse{}
+
+	err = json.Unmarshal(respBytes, signResp)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal sign response: %w", err)
+	}
+
+	if signResp.Signature == "" {
+		return nil, errors.New("remote signer returned an empty signature")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(signResp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("decode signature: %w", err)
+	}
+
+	return signature, nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package remotesigner
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/signaturesuite"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+const (
+	keyID     = "recovery-key"
+	authToken = "token"
+)
+
+func TestSigner_Sign(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(newStubSigningService(t, privateKey))
+	defer server.Close()
+
+	msg := []byte("test message")
+
+	t.Run("success", func(t *testing.T) {
+		signer := New(server.URL, keyID, "ES256", "kid", WithAuthToken(authToken))
+
+		signature, err := signer.Sign(msg)
+		require.NoError(t, err)
+
+		suites := signaturesuite.New(signaturesuite.WithDefaultSuites())
+		require.NoError(t, suites.Verify("ES256", jwk, signature, msg))
+	})
+
+	t.Run("error - unauthorized", func(t *testing.T) {
+		signer := New(server.URL, keyID, "ES256", "kid")
+
+		signature, err := signer.Sign(msg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "remote signer returned status 401")
+		require.Nil(t, signature)
+	})
+
+	t.Run("error - unknown key", func(t *testing.T) {
+		signer := New(server.URL, "unknown", "ES256", "kid", WithAuthToken(authToken))
+
+		signature, err := signer.Sign(msg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "remote signer returned status 404: key not found")
+		require.Nil(t, signature)
+	})
+
+	t.Run("error - HTTP client error", func(t *testing.T) {
+		errExpected := errors.New("injected HTTP client error")
+
+		signer := New(server.URL, keyID, "ES256", "kid", WithHTTPClient(&mockHTTPClient{err: errExpected}))
+
+		signature, err := signer.Sign(msg)
+		require.True(t, errors.Is(err, errExpected))
+		require.Nil(t, signature)
+	})
+
+	t.Run("error - invalid URL", func(t *testing.T) {
+		signature, err := New(" http://invalid", keyID, "ES256", "kid").Sign(msg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create sign request")
+		require.Nil(t, signature)
+	})
+
+	t.Run("error - invalid response", func(t *testing.T) {
+		for body, errMsg := range map[string]string{
+			`{`:                 "unmarshal sign response",
+			`{}`:                "remote signer returned an empty signature",
+			`{"signature":"!"}`: "decode signature",
+		} {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, err := w.Write([]byte(body))
+				require.NoError(t, err)
+			}))
+
+			signature, err := New(s.URL, keyID, "ES256", "kid").Sign(msg)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), errMsg)
+			require.Nil(t, signature)
+
+			s.Close()
+		}
+	})
+}
+
+func TestSigner_Headers(t *testing.T) {
+	headers := New("https://kms.example.com/sign", keyID, "ES256", "kid").Headers()
+
+	alg, ok := headers.Algorithm()
+	require.True(t, ok)
+	require.Equal(t, "ES256", alg)
+
+	kid, ok := headers.KeyID()
+	require.True(t, ok)
+	require.Equal(t, "kid", kid)
+
+	require.Empty(t, New("https://kms.example.com/sign", keyID, "", "").Headers())
+}
+
+// newStubSigningService returns a handler that emulates a remote signing service with a single key.
+func newStubSigningService(t *testing.T, privateKey *ecdsa.PrivateKey) http.HandlerFunc {
+	t.Helper()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+authToken {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		req := &SignRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(req))
+
+		if req.KeyID != keyID {
+			w.WriteHeader(http.StatusNotFound)
+
+			_, err := w.Write([]byte("key not found"))
+			require.NoError(t, err)
+
+			return
+		}
+
+		msg, err := base64.RawURLEncoding.DecodeString(req.Message)
+		require.NoError(t, err)
+
+		signature, err := ecsigner.New(privateKey, req.Algorithm, "").Sign(msg)
+		require.NoError(t, err)
+
+		require.NoError(t, json.NewEncoder(w).Encode(&SignResponse{
+			Signature: base64.RawURLEncoding.EncodeToString(signature),
+		}))
+	}
+}
+
+type mockHTTPClient struct {
+	err error
+}
+
+func (m *mockHTTPClient) Do(*http.Request) (*http.Response, error) {
+	return nil, m.err
+}
@@ -0,0 +1,423 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keymanager
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/commitment"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/privkey"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/client"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/model"
+)
+
+// ErrRequestPending is returned if a request is created for a DID that has a pending request.
+var ErrRequestPending = errors.New("request pending")
+
+// ErrNoPendingRequest is returned by Confirm and Rollback if the DID has no pending request.
+var ErrNoPendingRequest = errors.New("no pending request")
+
+// KeyGenerator generates a new private key (*ecdsa.PrivateKey or ed25519.PrivateKey).
+type KeyGenerator func() (crypto.PrivateKey, error)
+
+// Manager manages the update and recovery key chain of DIDs. For each request it generates the next key,
+// computes the commitment of the next key and the reveal value of the current key, signs the request and
+// persists the next key alongside the current key.
+//
+// The request is pending until it is either confirmed (see Confirm), once the operation has been anchored,
+// or rolled back (see Rollback), if the operation was rejected. The current key is only replaced by the next
+// key when the request is confirmed. No other request may be created for the DID while a request is pending.
+type Manager struct {
+	store         Store
+	multihashCode uint
+	generateKey   KeyGenerator
+	mutex         sync.Mutex
+}
+
+// Option is a key manager option.
+type Option func(m *Manager)
+
+// WithKeyGenerator sets the generator for new update and recovery keys. The default generator
+// creates ECDSA P-256 keys.
+func WithKeyGenerator(generator KeyGenerator) Option {
+	return func(m *Manager) {
+		m.generateKey = generator
+	}
+}
+
+// New returns a new key manager. The given multihash code is used for commitments and reveal values.
+func New(store Store, multihashCode uint, opts ...Option) *Manager {
+	m := &Manager{
+		store:         store,
+		multihashCode: multihashCode,
+		generateKey:   generateP256Key,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Create generates the initial update and recovery keys and returns the DID suffix along with
+// the create request for the given opaque document.
+func (m *Manager) Create(opaqueDocument string) (string, []byte, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	recoveryKey, err := m.newKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	updateKey, err := m.newKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	request, err := client.NewCreateRequest(&client.CreateRequestInfo{
+		OpaqueDocument:     opaqueDocument,
+		RecoveryCommitment: recoveryKey.commitment,
+		UpdateCommitment:   updateKey.commitment,
+		MultihashCode:      m.multihashCode,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	didSuffix, err := m.getUniqueSuffix(request)
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = m.putState(&State{
+		DIDSuffix:   didSuffix,
+		UpdateKey:   updateKey.privateKeyJWK,
+		RecoveryKey: recoveryKey.privateKeyJWK,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return didSuffix, request, nil
+}
+
+// Update returns an update request for the given patches. The request is signed with the current update key
+// and commits to a newly generated update key.
+func (m *Manager) Update(didSuffix string, patches []patch.Patch) ([]byte, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state, err := m.getState(didSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	currentKey, err := m.getKey(state.UpdateKey)
+	if err != nil {
+		return nil, fmt.Errorf("update key for DID [%s]: %w", didSuffix, err)
+	}
+
+	nextKey, err := m.newKey()
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := client.NewUpdateRequest(&client.UpdateRequestInfo{
+		DidSuffix:        didSuffix,
+		Patches:          patches,
+		UpdateCommitment: nextKey.commitment,
+		UpdateKey:        currentKey.publicKey,
+		MultihashCode:    m.multihashCode,
+		Signer:           currentKey.signer,
+		RevealValue:      currentKey.revealValue,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	state.Pending = operation.TypeUpdate
+	state.NextUpdateKey = nextKey.privateKeyJWK
+
+	err = m.putState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// Recover returns a recover request for the given opaque document. The request is signed with the current
+// recovery key and commits to newly generated update and recovery keys.
+func (m *Manager) Recover(didSuffix, opaqueDocument string) ([]byte, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state, err := m.getState(didSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	currentKey, err := m.getKey(state.RecoveryKey)
+	if err != nil {
+		return nil, fmt.Errorf("recovery key for DID [%s]: %w", didSuffix, err)
+	}
+
+	nextRecoveryKey, err := m.newKey()
+	if err != nil {
+		return nil, err
+	}
+
+	nextUpdateKey, err := m.newKey()
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := client.NewRecoverRequest(&client.RecoverRequestInfo{
+		DidSuffix:          didSuffix,
+		RecoveryKey:        currentKey.publicKey,
+		OpaqueDocument:     opaqueDocument,
+		RecoveryCommitment: nextRecoveryKey.commitment,
+		UpdateCommitment:   nextUpdateKey.commitment,
+		MultihashCode:      m.multihashCode,
+		Signer:             currentKey.signer,
+		RevealValue:        currentKey.revealValue,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	state.Pending = operation.TypeRecover
+	state.NextRecoveryKey = nextRecoveryKey.privateKeyJWK
+	state.NextUpdateKey = nextUpdateKey.privateKeyJWK
+
+	err = m.putState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// Deactivate returns a deactivate request signed with the current recovery key. The keys of the DID are
+// discarded when the request is confirmed and no further requests may be created for it.
+func (m *Manager) Deactivate(didSuffix string) ([]byte, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state, err := m.getState(didSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	currentKey, err := m.getKey(state.RecoveryKey)
+	if err != nil {
+		return nil, fmt.Errorf("recovery key for DID [%s]: %w", didSuffix, err)
+	}
+
+	request, err := client.NewDeactivateRequest(&client.DeactivateRequestInfo{
+		DidSuffix:   didSuffix,
+		RecoveryKey: currentKey.publicKey,
+		Signer:      currentKey.signer,
+		RevealValue: currentKey.revealValue,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	state.Pending = operation.TypeDeactivate
+
+	err = m.putState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// Confirm confirms the pending request of the given DID, i.e. the operation was anchored. The current keys
+// are replaced by the keys that the request committed to (or discarded if the DID was deactivated).
+func (m *Manager) Confirm(didSuffix string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state, err := m.getPendingState(didSuffix)
+	if err != nil {
+		return err
+	}
+
+	switch state.Pending {
+	case operation.TypeDeactivate:
+		state = &State{DIDSuffix: didSuffix, Deactivated: true}
+	case operation.TypeRecover:
+		state.RecoveryKey = state.NextRecoveryKey
+		state.UpdateKey = state.NextUpdateKey
+	default:
+		state.UpdateKey = state.NextUpdateKey
+	}
+
+	return m.putState(clearPending(state))
+}
+
+// Rollback discards the pending request of the given DID, i.e. the operation was rejected (or was never
+// submitted). The keys that the request committed to are discarded and the current keys remain in use.
+func (m *Manager) Rollback(didSuffix string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state, err := m.getPendingState(didSuffix)
+	if err != nil {
+		return err
+	}
+
+	return m.putState(clearPending(state))
+}
+
+func (m *Manager) getState(didSuffix string) (*State, error) {
+	state, err := m.getCurrentState(didSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.Pending != "" {
+		return nil, fmt.Errorf("DID [%s] has a pending %s request: %w", didSuffix, state.Pending, ErrRequestPending)
+	}
+
+	return state, nil
+}
+
+func (m *Manager) getPendingState(didSuffix string) (*State, error) {
+	state, err := m.getCurrentState(didSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.Pending == "" {
+		return nil, fmt.Errorf("DID [%s]: %w", didSuffix, ErrNoPendingRequest)
+	}
+
+	return state, nil
+}
+
+func (m *Manager) getCurrentState(didSuffix string) (*State, error) {
+	state, err := m.store.Get(didSuffix)
+	if err != nil {
+		return nil, fmt.Errorf("get key state for DID [%s]: %w", didSuffix, err)
+	}
+
+	if state.Deactivated {
+		return nil, fmt.Errorf("DID [%s] is deactivated", didSuffix)
+	}
+
+	return state, nil
+}
+
+func (m *Manager) putState(state *State) error {
+	err := m.store.Put(state)
+	if err != nil {
+		return fmt.Errorf("store key state for DID [%s]: %w", state.DIDSuffix, err)
+	}
+
+	return nil
+}
+
+func clearPending(state *State) *State {
+	state.Pending = ""
+	state.NextUpdateKey = nil
+	state.NextRecoveryKey = nil
+
+	return state
+}
+
+func (m *Manager) getUniqueSuffix(createRequest []byte) (string, error) {
+	request := &model.CreateRequest{}
+
+	err := json.Unmarshal(createRequest, request)
+	if err != nil {
+		return "", err
+	}
+
+	return model.GetUniqueSuffix(request.SuffixData, []uint{m.multihashCode})
+}
+
+type key struct {
+	privateKeyJWK json.RawMessage
+	publicKey     *jws.JWK
+	signer        client.Signer
+	commitment    string
+	revealValue   string
+}
+
+func (m *Manager) newKey() (*key, error) {
+	privateKey, err := m.generateKey()
+	if err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+
+	privateKeyJWK, err := privkey.GetPrivateKeyJWK(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.getKey(privateKeyJWK)
+}
+
+func (m *Manager) getKey(privateKeyJWK json.RawMessage) (*key, error) {
+	privateKey, err := privkey.ParsePrivateKeyJWK(privateKeyJWK)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := privkey.GetPublicKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := privkey.NewSigner(privateKey, "")
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeyJWK, err := pubkey.GetPublicKeyJWK(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := commitment.GetCommitment(publicKeyJWK, m.multihashCode)
+	if err != nil {
+		return nil, err
+	}
+
+	rv, err := commitment.GetRevealValue(publicKeyJWK, m.multihashCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key{
+		privateKeyJWK: privateKeyJWK,
+		publicKey:     publicKeyJWK,
+		signer:        signer,
+		commitment:    c,
+		revealValue:   rv,
+	}, nil
+}
+
+func generateP256Key() (crypto.PrivateKey, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
@@ -0,0 +1,373 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keymanager
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/doccomposer"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/model"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationapplier"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationparser"
+)
+
+const (
+	sha2_256  = 18
+	namespace = "did:sidetree"
+
+	opaqueDoc = `{"test":"value"}`
+)
+
+var p = protocol.Protocol{
+	GenesisTime:                  0,
+	MultihashAlgorithms:          []uint{sha2_256},
+	MaxOperationCount:            2,
+	MaxOperationSize:             2000,
+	MaxOperationHashLength:       100,
+	MaxDeltaSize:                 1000,
+	MaxCasURILength:              100,
+	CompressionAlgorithm:         "GZIP",
+	MaxChunkFileSize:             1024,
+	MaxProvisionalIndexFileSize:  1024,
+	MaxCoreIndexFileSize:         1024,
+	MaxProofFileSize:             1024,
+	SignatureAlgorithms:          []string{"EdDSA", "ES256", "ES384", "ES256K"},
+	KeyAlgorithms:                []string{"Ed25519", "P-256", "P-384", "secp256k1"},
+	Patches:                      []string{"replace", "ietf-json-patch"},
+	MaxOperationTimeDelta:        600,
+	NonceSize:                    16,
+	MaxMemoryDecompressionFactor: 3,
+}
+
+func TestManager(t *testing.T) {
+	for name, generator := range map[string]KeyGenerator{
+		"default": nil,
+		"Ed25519": func() (crypto.PrivateKey, error) {
+			_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+
+			return privateKey, err
+		},
+		"P-384": func() (crypto.PrivateKey, error) {
+			return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		},
+		"secp256k1": func() (crypto.PrivateKey, error) {
+			return ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+		},
+	} {
+		generator := generator
+
+		t.Run("success - "+name, func(t *testing.T) {
+			var opts []Option
+			if generator != nil {
+				opts = append(opts, WithKeyGenerator(generator))
+			}
+
+			m := New(NewMemStore(), sha2_256, opts...)
+
+			parser := operationparser.New(p)
+			applier := operationapplier.New(p, parser, doccomposer.New())
+
+			didSuffix, request, err := m.Create(opaqueDoc)
+			require.NoError(t, err)
+
+			rm := applyRequest(t, applier, parser, request, didSuffix, &protocol.ResolutionModel{})
+			require.Equal(t, "value", rm.Doc["test"])
+
+			// Consecutive updates must each reveal the key committed to by the previous operation.
+			for _, value := range []string{"special1", "special2"} {
+				jsonPatch, err := patch.NewJSONPatch(`[{"op":"replace","path":"/test","value":"` + value + `"}]`)
+				require.NoError(t, err)
+
+				request, err = m.Update(didSuffix, []patch.Patch{jsonPatch})
+				require.NoError(t, err)
+
+				rm = applyRequest(t, applier, parser, request, didSuffix, rm)
+				require.Equal(t, value, rm.Doc["test"])
+
+				require.NoError(t, m.Confirm(didSuffix))
+			}
+
+			request, err = m.Recover(didSuffix, `{"test":"recovered"}`)
+			require.NoError(t, err)
+
+			rm = applyRequest(t, applier, parser, request, didSuffix, rm)
+			require.Equal(t, "recovered", rm.Doc["test"])
+
+			require.NoError(t, m.Confirm(didSuffix))
+
+			// The update key is replaced on recovery.
+			jsonPatch, err := patch.NewJSONPatch(`[{"op":"replace","path":"/test","value":"updated"}]`)
+			require.NoError(t, err)
+
+			request, err = m.Update(didSuffix, []patch.Patch{jsonPatch})
+			require.NoError(t, err)
+
+			rm = applyRequest(t, applier, parser, request, didSuffix, rm)
+			require.Equal(t, "updated", rm.Doc["test"])
+
+			require.NoError(t, m.Confirm(didSuffix))
+
+			request, err = m.Deactivate(didSuffix)
+			require.NoError(t, err)
+
+			rm = applyRequest(t, applier, parser, request, didSuffix, rm)
+			require.True(t, rm.Deactivated)
+
+			require.NoError(t, m.Confirm(didSuffix))
+
+			request, err = m.Update(didSuffix, []patch.Patch{jsonPatch})
+			require.EqualError(t, err, "DID ["+didSuffix+"] is deactivated")
+			require.Nil(t, request)
+
+			request, err = m.Recover(didSuffix, opaqueDoc)
+			require.EqualError(t, err, "DID ["+didSuffix+"] is deactivated")
+			require.Nil(t, request)
+
+			request, err = m.Deactivate(didSuffix)
+			require.EqualError(t, err, "DID ["+didSuffix+"] is deactivated")
+			require.Nil(t, request)
+		})
+	}
+
+	t.Run("rollback", func(t *testing.T) {
+		m := New(NewMemStore(), sha2_256)
+
+		parser := operationparser.New(p)
+		applier := operationapplier.New(p, parser, doccomposer.New())
+
+		didSuffix, request, err := m.Create(opaqueDoc)
+		require.NoError(t, err)
+
+		rm := applyRequest(t, applier, parser, request, didSuffix, &protocol.ResolutionModel{})
+
+		// The update request is rejected (e.g. it was never anchored).
+		_, err = m.Update(didSuffix, []patch.Patch{newJSONPatch(t)})
+		require.NoError(t, err)
+
+		// No other request may be created while a request is pending.
+		request, err = m.Recover(didSuffix, opaqueDoc)
+		require.True(t, errors.Is(err, ErrRequestPending))
+		require.Nil(t, request)
+
+		require.NoError(t, m.Rollback(didSuffix))
+
+		// The recover and deactivate requests are rejected as well. The current keys remain in use.
+		_, err = m.Recover(didSuffix, `{"test":"recovered"}`)
+		require.NoError(t, err)
+
+		require.NoError(t, m.Rollback(didSuffix))
+
+		_, err = m.Deactivate(didSuffix)
+		require.NoError(t, err)
+
+		require.NoError(t, m.Rollback(didSuffix))
+
+		request, err = m.Update(didSuffix, []patch.Patch{newJSONPatch(t)})
+		require.NoError(t, err)
+
+		rm = applyRequest(t, applier, parser, request, didSuffix, rm)
+		require.Equal(t, "special", rm.Doc["test"])
+
+		require.NoError(t, m.Confirm(didSuffix))
+
+		request, err = m.Recover(didSuffix, `{"test":"recovered"}`)
+		require.NoError(t, err)
+
+		rm = applyRequest(t, applier, parser, request, didSuffix, rm)
+		require.Equal(t, "recovered", rm.Doc["test"])
+
+		err = m.Rollback("unknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+
+		require.NoError(t, m.Confirm(didSuffix))
+
+		err = m.Confirm(didSuffix)
+		require.True(t, errors.Is(err, ErrNoPendingRequest))
+
+		err = m.Rollback(didSuffix)
+		require.True(t, errors.Is(err, ErrNoPendingRequest))
+	})
+
+	t.Run("error - DID not found", func(t *testing.T) {
+		m := New(NewMemStore(), sha2_256)
+
+		request, err := m.Update("unknown", nil)
+		require.EqualError(t, err, "get key state for DID [unknown]: key state for DID [unknown] not found")
+		require.Nil(t, request)
+
+		request, err = m.Recover("unknown", opaqueDoc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+		require.Nil(t, request)
+
+		request, err = m.Deactivate("unknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+		require.Nil(t, request)
+	})
+
+	t.Run("error - key generator error", func(t *testing.T) {
+		errExpected := errors.New("injected key generator error")
+
+		m := New(NewMemStore(), sha2_256, WithKeyGenerator(func() (crypto.PrivateKey, error) {
+			return nil, errExpected
+		}))
+
+		didSuffix, request, err := m.Create(opaqueDoc)
+		require.True(t, errors.Is(err, errExpected))
+		require.Empty(t, didSuffix)
+		require.Nil(t, request)
+	})
+
+	t.Run("error - unsupported key", func(t *testing.T) {
+		m := New(NewMemStore(), sha2_256, WithKeyGenerator(func() (crypto.PrivateKey, error) {
+			return rsa.GenerateKey(rand.Reader, 1024)
+		}))
+
+		_, _, err := m.Create(opaqueDoc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported private key type")
+
+		m = New(NewMemStore(), sha2_256, WithKeyGenerator(func() (crypto.PrivateKey, error) {
+			return ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+		}))
+
+		_, _, err = m.Create(opaqueDoc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported")
+	})
+
+	t.Run("error - invalid request", func(t *testing.T) {
+		m := New(NewMemStore(), sha2_256)
+
+		_, _, err := m.Create("")
+		require.EqualError(t, err, "either opaque document or patches have to be supplied")
+
+		didSuffix, _, err := m.Create(opaqueDoc)
+		require.NoError(t, err)
+
+		request, err := m.Recover(didSuffix, "")
+		require.EqualError(t, err, "either opaque document or patches have to be supplied")
+		require.Nil(t, request)
+
+		request, err = m.Update(didSuffix, nil)
+		require.EqualError(t, err, "missing update information")
+		require.Nil(t, request)
+
+		// The key state must not change when a request fails.
+		request, err = m.Deactivate(didSuffix)
+		require.NoError(t, err)
+		require.NotNil(t, request)
+	})
+
+	t.Run("error - store error", func(t *testing.T) {
+		errExpected := errors.New("injected store error")
+
+		store := &mockStore{MemStore: NewMemStore()}
+
+		m := New(store, sha2_256)
+
+		didSuffix, _, err := m.Create(opaqueDoc)
+		require.NoError(t, err)
+
+		store.putErr = errExpected
+
+		_, _, err = m.Create(opaqueDoc)
+		require.True(t, errors.Is(err, errExpected))
+
+		request, err := m.Update(didSuffix, []patch.Patch{newJSONPatch(t)})
+		require.True(t, errors.Is(err, errExpected))
+		require.Contains(t, err.Error(), "store key state for DID ["+didSuffix+"]")
+		require.Nil(t, request)
+
+		request, err = m.Recover(didSuffix, opaqueDoc)
+		require.True(t, errors.Is(err, errExpected))
+		require.Nil(t, request)
+
+		request, err = m.Deactivate(didSuffix)
+		require.True(t, errors.Is(err, errExpected))
+		require.Nil(t, request)
+	})
+
+	t.Run("error - corrupted key state", func(t *testing.T) {
+		store := NewMemStore()
+
+		require.NoError(t, store.Put(&State{DIDSuffix: "corrupted", UpdateKey: []byte(`{}`), RecoveryKey: []byte(`{}`)}))
+
+		m := New(store, sha2_256)
+
+		request, err := m.Update("corrupted", []patch.Patch{newJSONPatch(t)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "update key for DID [corrupted]")
+		require.Nil(t, request)
+
+		request, err = m.Recover("corrupted", opaqueDoc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "recovery key for DID [corrupted]")
+		require.Nil(t, request)
+
+		request, err = m.Deactivate("corrupted")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "recovery key for DID [corrupted]")
+		require.Nil(t, request)
+	})
+}
+
+func applyRequest(t *testing.T, applier *operationapplier.Applier, parser *operationparser.Parser, request []byte,
+	didSuffix string, rm *protocol.ResolutionModel) *protocol.ResolutionModel {
+	t.Helper()
+
+	op, err := parser.ParseOperation(namespace, request, false)
+	require.NoError(t, err)
+	require.Equal(t, didSuffix, op.UniqueSuffix)
+
+	anchoredOp, err := model.GetAnchoredOperation(op)
+	require.NoError(t, err)
+
+	anchoredOp.TransactionTime = uint64(time.Now().Unix())
+
+	result, err := applier.Apply(anchoredOp, rm)
+	require.NoError(t, err)
+
+	return result
+}
+
+func newJSONPatch(t *testing.T) patch.Patch {
+	t.Helper()
+
+	jsonPatch, err := patch.NewJSONPatch(`[{"op":"replace","path":"/test","value":"special"}]`)
+	require.NoError(t, err)
+
+	return jsonPatch
+}
+
+type mockStore struct {
+	*MemStore
+	putErr error
+}
+
+func (s *mockStore) Put(state *State) error {
+	if s.putErr != nil {
+		return s.putErr
+	}
+
+	return s.MemStore.Put(state)
+}
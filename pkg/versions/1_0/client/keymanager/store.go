@@ -0,0 +1,77 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keymanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+)
+
+// State is the key state of a DID. The keys are private keys in JWK format and must therefore be protected
+// by the store.
+type State struct {
+	DIDSuffix   string          `json:"didSuffix"`
+	UpdateKey   json.RawMessage `json:"updateKey,omitempty"`
+	RecoveryKey json.RawMessage `json:"recoveryKey,omitempty"`
+	Deactivated bool            `json:"deactivated,omitempty"`
+
+	// Pending is the type of the request that was created but not yet confirmed (if any).
+	Pending operation.Type `json:"pending,omitempty"`
+	// NextUpdateKey and NextRecoveryKey are the keys that the pending request commits to. They replace the
+	// current keys when the request is confirmed.
+	NextUpdateKey   json.RawMessage `json:"nextUpdateKey,omitempty"`
+	NextRecoveryKey json.RawMessage `json:"nextRecoveryKey,omitempty"`
+}
+
+// Store persists the key state of DIDs.
+type Store interface {
+	// Put saves the given key state.
+	Put(state *State) error
+	// Get returns the key state for the given DID suffix. An error containing "not found" is returned
+	// if the DID is not in the store.
+	Get(didSuffix string) (*State, error)
+}
+
+// MemStore is an in-memory key state store.
+type MemStore struct {
+	mutex  sync.RWMutex
+	states map[string]*State
+}
+
+// NewMemStore returns a new in-memory key state store.
+func NewMemStore() *MemStore {
+	return &MemStore{states: make(map[string]*State)}
+}
+
+// Put saves the given key state.
+func (s *MemStore) Put(state *State) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stateCopy := *state
+	s.states[state.DIDSuffix] = &stateCopy
+
+	return nil
+}
+
+// Get returns the key state for the given DID suffix.
+func (s *MemStore) Get(didSuffix string) (*State, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	state, ok := s.states[didSuffix]
+	if !ok {
+		return nil, fmt.Errorf("key state for DID [%s] not found", didSuffix)
+	}
+
+	stateCopy := *state
+
+	return &stateCopy, nil
+}
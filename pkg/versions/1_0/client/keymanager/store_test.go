@@ -0,0 +1,35 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keymanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemStore(t *testing.T) {
+	s := NewMemStore()
+
+	state, err := s.Get("suffix")
+	require.EqualError(t, err, "key state for DID [suffix] not found")
+	require.Nil(t, state)
+
+	require.NoError(t, s.Put(&State{DIDSuffix: "suffix", UpdateKey: []byte(`{"kty":"EC"}`)}))
+
+	state, err = s.Get("suffix")
+	require.NoError(t, err)
+	require.Equal(t, "suffix", state.DIDSuffix)
+	require.Equal(t, `{"kty":"EC"}`, string(state.UpdateKey))
+
+	// Modifying the returned state must not modify the stored state.
+	state.Deactivated = true
+
+	state, err = s.Get("suffix")
+	require.NoError(t, err)
+	require.False(t, state.Deactivated)
+}
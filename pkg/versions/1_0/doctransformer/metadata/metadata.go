@@ -91,6 +91,10 @@ func (t *Metadata) CreateDocumentMetadata(rm *protocol.ResolutionModel, info pro
 		methodMetadata[document.PublishedOperationsProperty] = getPublishedOperations(rm.PublishedOperations)
 	}
 
+	if len(rm.Trace) > 0 {
+		methodMetadata[document.TraceProperty] = getTrace(rm.Trace)
+	}
+
 	docMetadata := make(document.Metadata)
 	docMetadata[document.MethodProperty] = methodMetadata
 
@@ -181,6 +185,31 @@ func getUnpublishedOperations(ops []*operation.AnchoredOperation) []*Unpublished
 	return unpublishedOps
 }
 
+// getTrace returns the resolution trace in the order in which the operations were considered during resolution.
+func getTrace(trace []*protocol.OperationTrace) []*OperationTrace {
+	entries := make([]*OperationTrace, len(trace))
+
+	for i, t := range trace {
+		verdict := VerdictRejected
+		if t.Applied {
+			verdict = VerdictApplied
+		}
+
+		entries[i] = &OperationTrace{
+			Type:               t.Operation.Type,
+			Published:          t.Published,
+			TransactionTime:    t.Operation.TransactionTime,
+			TransactionNumber:  t.Operation.TransactionNumber,
+			ProtocolVersion:    t.Operation.ProtocolVersion,
+			CanonicalReference: t.Operation.CanonicalReference,
+			Verdict:            verdict,
+			Reason:             t.Reason,
+		}
+	}
+
+	return entries
+}
+
 // PublishedOperation defines an published operation for metadata. It is a subset of anchored operation.
 type PublishedOperation struct {
 
@@ -227,3 +256,39 @@ type UnpublishedOperation struct {
 	// AnchorOrigin is anchor origin.
 	AnchorOrigin interface{} `json:"anchorOrigin,omitempty"`
 }
+
+const (
+	// VerdictApplied indicates that the operation was applied to the document.
+	VerdictApplied = "applied"
+
+	// VerdictRejected indicates that the operation was not applied to the document.
+	VerdictRejected = "rejected"
+)
+
+// OperationTrace defines the outcome of an operation that was considered during resolution.
+type OperationTrace struct {
+
+	// Type defines operation type.
+	Type operation.Type `json:"type"`
+
+	// Published is true if the operation has been anchored.
+	Published bool `json:"published"`
+
+	// TransactionTime is the logical anchoring time.
+	TransactionTime uint64 `json:"transactionTime"`
+
+	// TransactionNumber is the transaction number of the transaction this operation was batched within.
+	TransactionNumber uint64 `json:"transactionNumber"`
+
+	// ProtocolVersion is the genesis time of the protocol that was used for this operation.
+	ProtocolVersion uint64 `json:"protocolVersion"`
+
+	// CanonicalReference contains canonical reference that applies to this operation.
+	CanonicalReference string `json:"canonicalReference,omitempty"`
+
+	// Verdict is either 'applied' or 'rejected'.
+	Verdict string `json:"verdict"`
+
+	// Reason holds the reason why the operation was rejected.
+	Reason string `json:"reason,omitempty"`
+}
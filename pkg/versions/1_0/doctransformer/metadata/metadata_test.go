@@ -73,6 +73,7 @@ func TestPopulateDocumentMetadata(t *testing.T) {
 		require.Equal(t, true, methodMetadata[document.PublishedProperty])
 		require.Equal(t, "recovery", methodMetadata[document.RecoveryCommitmentProperty])
 		require.Equal(t, "update", methodMetadata[document.UpdateCommitmentProperty])
+		require.Nil(t, methodMetadata[document.TraceProperty])
 	})
 
 	t.Run("success - include operations (published/unpublished)", func(t *testing.T) {
@@ -123,6 +124,49 @@ func TestPopulateDocumentMetadata(t *testing.T) {
 		require.Equal(t, 1, len(methodMetadata[document.UnpublishedOperationsProperty].([]*UnpublishedOperation)))
 	})
 
+	t.Run("success - trace", func(t *testing.T) {
+		info := make(protocol.TransformationInfo)
+		info[document.IDProperty] = testDID
+		info[document.PublishedProperty] = true
+
+		createOp := &operation.AnchoredOperation{Type: "create", CanonicalReference: "ref1", TransactionTime: 1}
+		updateOp := &operation.AnchoredOperation{Type: "update", TransactionTime: 2, TransactionNumber: 1}
+
+		rm := &protocol.ResolutionModel{
+			Doc: doc,
+			Trace: []*protocol.OperationTrace{
+				{Operation: createOp, Published: true, Applied: true},
+				{Operation: updateOp, Reason: "failed to check signature"},
+			},
+		}
+
+		documentMetadata, err := New().CreateDocumentMetadata(rm, info)
+		require.NoError(t, err)
+
+		methodMetadata, ok := documentMetadata[document.MethodProperty].(document.Metadata)
+		require.True(t, ok)
+
+		trace, ok := methodMetadata[document.TraceProperty].([]*OperationTrace)
+		require.True(t, ok)
+		require.Len(t, trace, 2)
+
+		require.Equal(t, &OperationTrace{
+			Type:               "create",
+			Published:          true,
+			TransactionTime:    1,
+			CanonicalReference: "ref1",
+			Verdict:            VerdictApplied,
+		}, trace[0])
+
+		require.Equal(t, &OperationTrace{
+			Type:              "update",
+			TransactionTime:   2,
+			TransactionNumber: 1,
+			Verdict:           VerdictRejected,
+			Reason:            "failed to check signature",
+		}, trace[1])
+	})
+
 	t.Run("success - deactivated, commitments empty", func(t *testing.T) {
 		internal2 := &protocol.ResolutionModel{
 			Doc:         doc,
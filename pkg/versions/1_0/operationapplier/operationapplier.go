@@ -17,6 +17,8 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/hashing"
 	internal "github.com/trustbloc/sidetree-core-go/pkg/internal/jws"
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/signaturesuite"
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/model"
 )
 
@@ -29,6 +31,22 @@ type Applier struct {
 	protocol.Protocol
 	OperationParser
 	protocol.DocumentComposer
+	signatureSuites *signaturesuite.Registry
+}
+
+// Option is an applier instance option.
+type Option func(opts *Applier)
+
+// WithSignatureSuites sets optional signature suites that are used to verify the signatures of operations. If set,
+// the suite is selected by the algorithm in the protected headers of the signed data. Otherwise, the signature is
+// verified according to the key type. Since this changes which operations are valid, signature suites should only
+// be set for a new protocol version.
+func WithSignatureSuites(suites *signaturesuite.Registry) Option {
+	return func(opts *Applier) {
+		if suites != nil {
+			opts.signatureSuites = suites
+		}
+	}
 }
 
 // OperationParser defines the functions for parsing operations.
@@ -47,12 +65,19 @@ type OperationParser interface {
 // New returns a new operation applier for the given protocol.
 //
 //nolint:gocritic
-func New(p protocol.Protocol, parser OperationParser, dc protocol.DocumentComposer) *Applier {
-	return &Applier{
+func New(p protocol.Protocol, parser OperationParser, dc protocol.DocumentComposer, opts ...Option) *Applier {
+	applier := &Applier{
 		Protocol:         p,
 		OperationParser:  parser,
 		DocumentComposer: dc,
 	}
+
+	// apply options
+	for _, opt := range opts {
+		opt(applier)
+	}
+
+	return applier
 }
 
 // Apply applies the given anchored operation.
@@ -160,7 +185,7 @@ func (s *Applier) applyUpdateOperation(anchoredOp *operation.AnchoredOperation,
 	}
 
 	// verify signature
-	_, err = internal.VerifyJWS(op.SignedData, signedDataModel.UpdateKey)
+	err = s.verifySignature(op.SignedData, signedDataModel.UpdateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check signature: %s", err.Error())
 	}
@@ -239,7 +264,7 @@ func (s *Applier) applyDeactivateOperation(anchoredOp *operation.AnchoredOperati
 	}
 
 	// verify signature
-	_, err = internal.VerifyJWS(op.SignedData, signedDataModel.RecoveryKey)
+	err = s.verifySignature(op.SignedData, signedDataModel.RecoveryKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check signature: %s", err.Error())
 	}
@@ -288,7 +313,7 @@ func (s *Applier) applyRecoverOperation(anchoredOp *operation.AnchoredOperation,
 	}
 
 	// verify signature
-	_, err = internal.VerifyJWS(op.SignedData, signedDataModel.RecoveryKey)
+	err = s.verifySignature(op.SignedData, signedDataModel.RecoveryKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check signature: %s", err.Error())
 	}
@@ -383,3 +408,15 @@ func (s *Applier) getAnchorUntil(from, until int64) int64 {
 
 	return until
 }
+
+func (s *Applier) verifySignature(signedData string, jwk *jws.JWK) error {
+	var opts []internal.ParseOpt
+
+	if s.signatureSuites != nil {
+		opts = append(opts, internal.WithSignatureVerifier(s.signatureSuites))
+	}
+
+	_, err := internal.VerifyJWS(signedData, jwk, opts...)
+
+	return err
+}
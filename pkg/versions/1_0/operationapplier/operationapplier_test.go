@@ -27,6 +27,7 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/signutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
 	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/signaturesuite"
 	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
 	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/client"
@@ -230,6 +231,28 @@ func TestUpdateDocument(t *testing.T) {
 		require.Equal(t, "special2", didDoc["test"])
 	})
 
+	t.Run("signature suites", func(t *testing.T) {
+		rm, err := New(p, parser, dc).Apply(createOp, &protocol.ResolutionModel{})
+		require.NoError(t, err)
+
+		updateOp, _, err := getAnchoredUpdateOperation(updateKey, uniqueSuffix, 1)
+		require.NoError(t, err)
+
+		// No suite for ES256.
+		applier := New(p, parser, dc, WithSignatureSuites(signaturesuite.New(signaturesuite.WithSuite(signaturesuite.NewES384()))))
+
+		result, err := applier.Apply(updateOp, rm)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "failed to check signature: signature algorithm 'ES256' not supported")
+
+		applier = New(p, parser, dc, WithSignatureSuites(signaturesuite.New(signaturesuite.WithSuite(signaturesuite.NewES256()))))
+
+		result, err = applier.Apply(updateOp, rm)
+		require.NoError(t, err)
+		require.Equal(t, "special1", document.DidDocumentFromJSONLDObject(result.Doc)["test"])
+	})
+
 	t.Run("error -  operation with reused next commitment", func(t *testing.T) {
 		applier := New(p, parser, dc)
 
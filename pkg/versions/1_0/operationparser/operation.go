@@ -15,6 +15,7 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/signaturesuite"
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/model"
 )
 
@@ -25,6 +26,7 @@ type Parser struct {
 	protocol.Protocol
 	anchorOriginValidator ObjectValidator
 	anchorTimeValidator   TimeValidator
+	signatureSuites       *signaturesuite.Registry
 }
 
 // New returns a new operation parser.
@@ -67,6 +69,17 @@ func WithAnchorOriginValidator(v ObjectValidator) Option {
 	}
 }
 
+// WithSignatureSuites sets optional signature suites. If set, the algorithm of signed data must be supported by
+// one of the suites (in addition to being allowed by the protocol). Since this changes which operations are valid,
+// signature suites should only be set for a new protocol version.
+func WithSignatureSuites(suites *signaturesuite.Registry) Option {
+	return func(opts *Parser) {
+		if suites != nil {
+			opts.signatureSuites = suites
+		}
+	}
+}
+
 // ErrOperationExpired is thrown if anchor until time is less then reference time(e.g. server time or anchoring time).
 var ErrOperationExpired = errors.New("operation expired")
 
@@ -173,6 +173,10 @@ func (p *Parser) validateProtectedHeaders(headers jws.Headers, allowedAlgorithms
 		return errors.Errorf("algorithm '%s' is not in the allowed list %v", alg, allowedAlgorithms)
 	}
 
+	if p.signatureSuites != nil && !p.signatureSuites.Supports(alg) {
+		return errors.Errorf("algorithm '%s' is not supported", alg)
+	}
+
 	return nil
 }
 
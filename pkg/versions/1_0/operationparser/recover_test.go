@@ -20,6 +20,7 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/signutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/jws"
 	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/signaturesuite"
 	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/model"
 )
 
@@ -478,6 +479,30 @@ func TestValidateProtectedHeader(t *testing.T) {
 		require.Error(t, err)
 		require.Equal(t, "algorithm 'alg-other' is not in the allowed list [alg-1 alg-2]", err.Error())
 	})
+	t.Run("signature suites", func(t *testing.T) {
+		parser := New(protocol.Protocol{}, WithSignatureSuites(signaturesuite.New(
+			signaturesuite.WithSuite(&mockSignatureSuite{alg: "alg-1"}),
+		)))
+
+		err := parser.validateProtectedHeaders(getHeaders("alg-1", "kid"), algs)
+		require.NoError(t, err)
+
+		err = parser.validateProtectedHeaders(getHeaders("alg-2", "kid"), algs)
+		require.Error(t, err)
+		require.Equal(t, "algorithm 'alg-2' is not supported", err.Error())
+	})
+}
+
+type mockSignatureSuite struct {
+	alg string
+}
+
+func (m *mockSignatureSuite) Accept(alg string) bool {
+	return alg == m.alg
+}
+
+func (m *mockSignatureSuite) Verify(*jws.JWK, []byte, []byte) error {
+	return nil
 }
 
 func getHeaders(alg, kid string) jws.Headers {
@@ -15,6 +15,7 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/opstatus"
 )
 
 var logger = log.New("sidetree-core-observer")
@@ -24,11 +25,33 @@ type OperationStore interface {
 	Put(ops []*operation.AnchoredOperation) error
 }
 
+// revertibleOperationStore has the same method set as the extension in observer.RevertibleOperationStore.
+type revertibleOperationStore interface {
+	DeleteByTransaction(txnTime, txnNumber uint64) ([]*operation.AnchoredOperation, error)
+}
+
 type unpublishedOperationStore interface {
 	// DeleteAll deletes unpublished operations.
 	DeleteAll(ops []*operation.AnchoredOperation) error
 }
 
+type resolutionCacheInvalidator interface {
+	Invalidate(uniqueSuffixes ...string)
+}
+
+type rollbackHandler interface {
+	Rollback(uniqueSuffixes ...string) error
+}
+
+// operationFilter has the same method set as observer.OperationFilter.
+type operationFilter interface {
+	Filter(uniqueSuffix string, ops []*operation.AnchoredOperation) ([]*operation.AnchoredOperation, error)
+}
+
+type operationStatusUpdater interface {
+	Update(request []byte, suffix string, status opstatus.Status, opts ...opstatus.UpdateOption)
+}
+
 // Providers contains the providers required by the TxnProcessor.
 type Providers struct {
 	OpStore                   OperationStore
@@ -41,6 +64,11 @@ type TxnProcessor struct {
 
 	unpublishedOperationStore unpublishedOperationStore
 	unpublishedOperationTypes []operation.Type
+
+	statusUpdater    operationStatusUpdater
+	cacheInvalidator resolutionCacheInvalidator
+	rollbackHandler  rollbackHandler
+	operationFilters []operationFilter
 }
 
 // New returns a new document operation processor.
@@ -50,6 +78,9 @@ func New(providers *Providers, opts ...Option) *TxnProcessor {
 
 		unpublishedOperationStore: &noopUnpublishedOpsStore{},
 		unpublishedOperationTypes: []operation.Type{},
+		statusUpdater:             &noopOperationStatusUpdater{},
+		cacheInvalidator:          &noopResolutionCacheInvalidator{},
+		rollbackHandler:           &noopRollbackHandler{},
 	}
 
 	// apply options
@@ -71,18 +102,108 @@ func WithUnpublishedOperationStore(store unpublishedOperationStore, opTypes []op
 	}
 }
 
+// WithOperationStatusUpdater sets an optional updater which is notified when operations are
+// confirmed (persisted to the operation store), discarded or reverted.
+func WithOperationStatusUpdater(updater operationStatusUpdater) Option {
+	return func(opts *TxnProcessor) {
+		opts.statusUpdater = updater
+	}
+}
+
+// WithResolutionCacheInvalidator sets an optional resolution cache invalidator which is invoked with the
+// suffixes of the operations that were persisted.
+func WithResolutionCacheInvalidator(invalidator resolutionCacheInvalidator) Option {
+	return func(opts *TxnProcessor) {
+		opts.cacheInvalidator = invalidator
+	}
+}
+
+// WithRollbackHandler sets an optional handler which is invoked with the suffixes of the operations that were
+// removed from the operation store when a transaction is reverted (see processor.OperationProcessor.Rollback).
+func WithRollbackHandler(handler rollbackHandler) Option {
+	return func(opts *TxnProcessor) {
+		opts.rollbackHandler = handler
+	}
+}
+
+// WithOperationFilters adds filters that are invoked for each suffix of a transaction before its operations are
+// persisted. Filters are chained in the order in which they are added, i.e. each filter receives the operations
+// returned by the previous filter. Operations that are filtered out are not persisted and their status is set
+// to failed. If a filter returns an error then none of the operations of the transaction are persisted.
+func WithOperationFilters(filters ...operationFilter) Option {
+	return func(opts *TxnProcessor) {
+		opts.operationFilters = append(opts.operationFilters, filters...)
+	}
+}
+
 // Process persists all the operations for the given anchor.
 //
 //nolint:gocritic
 func (p *TxnProcessor) Process(sidetreeTxn txn.SidetreeTxn, suffixes ...string) (int, error) {
 	logger.Debug("Processing sidetree txn for suffixes", log.WithSidetreeTxn(sidetreeTxn), log.WithSuffixes(suffixes...))
 
-	txnOps, err := p.OperationProtocolProvider.GetTxnOperations(&sidetreeTxn)
+	txnOps, err := p.Prepare(&sidetreeTxn)
+	if err != nil {
+		return 0, err
+	}
+
+	return p.Commit(&sidetreeTxn, txnOps)
+}
+
+// Prepare retrieves the operations for the given anchor from the batch files. The operations are not persisted
+// until Commit is called. Prepare may be called concurrently for different transactions.
+func (p *TxnProcessor) Prepare(sidetreeTxn *txn.SidetreeTxn) ([]*operation.AnchoredOperation, error) {
+	txnOps, err := p.OperationProtocolProvider.GetTxnOperations(sidetreeTxn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve operations for anchor string[%s]: %s", sidetreeTxn.AnchorString, err)
+	}
+
+	return txnOps, nil
+}
+
+// Commit persists the operations (returned from Prepare) for the given anchor. Transactions must be committed
+// in the order in which they were anchored.
+func (p *TxnProcessor) Commit(sidetreeTxn *txn.SidetreeTxn, txnOps []*operation.AnchoredOperation) (int, error) {
+	return p.processTxnOperations(txnOps, sidetreeTxn)
+}
+
+// Revert removes the operations of the given transaction, which was orphaned by a ledger reorganization, from
+// the operation store and returns the unique suffixes of the removed operations. The operation store must
+// implement observer.RevertibleOperationStore.
+func (p *TxnProcessor) Revert(sidetreeTxn *txn.SidetreeTxn) ([]string, error) {
+	store, ok := p.OpStore.(revertibleOperationStore)
+	if !ok {
+		return nil, fmt.Errorf("operation store does not support deleting operations for anchor string[%s]",
+			sidetreeTxn.AnchorString)
+	}
+
+	ops, err := store.DeleteByTransaction(sidetreeTxn.TransactionTime, sidetreeTxn.TransactionNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete operations for anchor string[%s]: %w", sidetreeTxn.AnchorString, err)
+	}
+
+	if len(ops) == 0 {
+		return nil, nil
+	}
+
+	suffixes := getUniqueSuffixes(ops)
+
+	logger.Info("Removed operations of reverted transaction", log.WithAnchorString(sidetreeTxn.AnchorString),
+		log.WithTotal(len(ops)), log.WithSuffixes(suffixes...))
+
+	for _, op := range ops {
+		p.statusUpdater.Update(op.OperationRequest, op.UniqueSuffix, opstatus.StatusReverted,
+			opstatus.WithType(op.Type), opstatus.WithReason("transaction was orphaned by a ledger reorganization"))
+	}
+
+	p.cacheInvalidator.Invalidate(suffixes...)
+
+	err = p.rollbackHandler.Rollback(suffixes...)
 	if err != nil {
-		return 0, fmt.Errorf("failed to retrieve operations for anchor string[%s]: %s", sidetreeTxn.AnchorString, err)
+		return suffixes, fmt.Errorf("failed to roll back suffixes for anchor string[%s]: %w", sidetreeTxn.AnchorString, err)
 	}
 
-	return p.processTxnOperations(txnOps, &sidetreeTxn)
+	return suffixes, nil
 }
 
 func (p *TxnProcessor) processTxnOperations(txnOps []*operation.AnchoredOperation, sidetreeTxn *txn.SidetreeTxn) (int, error) {
@@ -99,6 +220,9 @@ func (p *TxnProcessor) processTxnOperations(txnOps []*operation.AnchoredOperatio
 			logger.Warn("Duplicate suffix found in transaction operations: discarding operation",
 				log.WithNamespace(sidetreeTxn.Namespace), log.WithSuffix(op.UniqueSuffix), log.WithOperation(op))
 
+			p.statusUpdater.Update(op.OperationRequest, op.UniqueSuffix, opstatus.StatusFailed,
+				opstatus.WithType(op.Type), opstatus.WithReason("duplicate suffix found in transaction operations"))
+
 			continue
 		}
 
@@ -115,11 +239,27 @@ func (p *TxnProcessor) processTxnOperations(txnOps []*operation.AnchoredOperatio
 		}
 	}
 
-	err := p.OpStore.Put(ops)
+	ops, err := p.filterOperations(ops)
+	if err != nil {
+		return 0, fmt.Errorf("failed to filter operations from anchor string[%s]: %w", sidetreeTxn.AnchorString, err)
+	}
+
+	// Invalidate the cached resolution models even if the store fails since some of the operations may
+	// have been stored.
+	defer p.invalidateCache(ops)
+
+	err = p.OpStore.Put(ops)
 	if err != nil {
 		return 0, errors.Wrapf(err, "failed to store operation from anchor string[%s]", sidetreeTxn.AnchorString)
 	}
 
+	for _, op := range ops {
+		p.statusUpdater.Update(op.OperationRequest, op.UniqueSuffix, opstatus.StatusConfirmed,
+			opstatus.WithType(op.Type), opstatus.WithAnchorString(sidetreeTxn.AnchorString),
+			opstatus.WithTransaction(sidetreeTxn.TransactionTime, sidetreeTxn.TransactionNumber,
+				sidetreeTxn.CanonicalReference))
+	}
+
 	err = p.unpublishedOperationStore.DeleteAll(unpublishedOps)
 	if err != nil {
 		return 0, fmt.Errorf("failed to delete unpublished operations for anchor string[%s]: %w", sidetreeTxn.AnchorString, err)
@@ -128,6 +268,77 @@ func (p *TxnProcessor) processTxnOperations(txnOps []*operation.AnchoredOperatio
 	return len(ops), nil
 }
 
+// filterOperations passes the operations of each suffix through the chain of operation filters. The given
+// operations contain at most one operation per suffix since duplicate suffixes have already been discarded.
+func (p *TxnProcessor) filterOperations(ops []*operation.AnchoredOperation) ([]*operation.AnchoredOperation, error) {
+	if len(p.operationFilters) == 0 {
+		return ops, nil
+	}
+
+	var filteredOps []*operation.AnchoredOperation
+
+	for _, op := range ops {
+		suffixOps := []*operation.AnchoredOperation{op}
+
+		for _, filter := range p.operationFilters {
+			var err error
+
+			suffixOps, err = filter.Filter(op.UniqueSuffix, suffixOps)
+			if err != nil {
+				return nil, fmt.Errorf("filter operations for suffix[%s]: %w", op.UniqueSuffix, err)
+			}
+
+			if len(suffixOps) == 0 {
+				break
+			}
+		}
+
+		if len(suffixOps) == 0 {
+			logger.Info("Operation was filtered out: discarding operation", log.WithSuffix(op.UniqueSuffix),
+				log.WithOperationType(string(op.Type)))
+
+			p.statusUpdater.Update(op.OperationRequest, op.UniqueSuffix, opstatus.StatusFailed,
+				opstatus.WithType(op.Type), opstatus.WithReason("operation was filtered out"))
+
+			continue
+		}
+
+		filteredOps = append(filteredOps, suffixOps...)
+	}
+
+	return filteredOps, nil
+}
+
+func (p *TxnProcessor) invalidateCache(ops []*operation.AnchoredOperation) {
+	if len(ops) == 0 {
+		return
+	}
+
+	suffixes := make([]string, len(ops))
+
+	for i, op := range ops {
+		suffixes[i] = op.UniqueSuffix
+	}
+
+	p.cacheInvalidator.Invalidate(suffixes...)
+}
+
+func getUniqueSuffixes(ops []*operation.AnchoredOperation) []string {
+	var suffixes []string
+
+	added := make(map[string]bool)
+
+	for _, op := range ops {
+		if !added[op.UniqueSuffix] {
+			added[op.UniqueSuffix] = true
+
+			suffixes = append(suffixes, op.UniqueSuffix)
+		}
+	}
+
+	return suffixes
+}
+
 func updateAnchoredOperation(op *operation.AnchoredOperation, sidetreeTxn *txn.SidetreeTxn) *operation.AnchoredOperation {
 	//  The logical anchoring time that this operation was anchored on
 	op.TransactionTime = sidetreeTxn.TransactionTime
@@ -154,3 +365,19 @@ type noopUnpublishedOpsStore struct{}
 func (noop *noopUnpublishedOpsStore) DeleteAll(_ []*operation.AnchoredOperation) error {
 	return nil
 }
+
+type noopOperationStatusUpdater struct{}
+
+func (noop *noopOperationStatusUpdater) Update([]byte, string, opstatus.Status, ...opstatus.UpdateOption) {
+}
+
+type noopResolutionCacheInvalidator struct{}
+
+func (noop *noopResolutionCacheInvalidator) Invalidate(...string) {
+}
+
+type noopRollbackHandler struct{}
+
+func (noop *noopRollbackHandler) Rollback(...string) error {
+	return nil
+}
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package txnprocessor
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -14,6 +15,7 @@ import (
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/opstatus"
 )
 
 const anchorString = "1.coreIndexURI"
@@ -121,6 +123,334 @@ func TestProcessTxnOperations(t *testing.T) {
 	})
 }
 
+func TestProcessTxnOperations_Status(t *testing.T) {
+	tracker := opstatus.New(opstatus.NewMemStore())
+
+	providers := &Providers{
+		OperationProtocolProvider: &mockTxnOpsProvider{},
+		OpStore:                   &mockOperationStore{},
+	}
+
+	p := New(providers, WithOperationStatusUpdater(tracker))
+
+	op1 := &operation.AnchoredOperation{
+		UniqueSuffix:     "abc",
+		Type:             operation.TypeUpdate,
+		OperationRequest: []byte(`{"type":"update","didSuffix":"abc","revealValue":"1"}`),
+	}
+
+	op2 := &operation.AnchoredOperation{
+		UniqueSuffix:     "abc",
+		Type:             operation.TypeUpdate,
+		OperationRequest: []byte(`{"type":"update","didSuffix":"abc","revealValue":"2"}`),
+	}
+
+	sidetreeTxn := &txn.SidetreeTxn{
+		AnchorString:       anchorString,
+		TransactionTime:    10,
+		TransactionNumber:  2,
+		CanonicalReference: "ref",
+	}
+
+	numProcessed, err := p.processTxnOperations([]*operation.AnchoredOperation{op1, op2}, sidetreeTxn)
+	require.NoError(t, err)
+	require.Equal(t, 1, numProcessed)
+
+	hash, err := opstatus.GetOperationHash(op1.OperationRequest)
+	require.NoError(t, err)
+
+	status, err := tracker.Get(hash)
+	require.NoError(t, err)
+	require.Equal(t, opstatus.StatusConfirmed, status.Status)
+	require.Equal(t, anchorString, status.AnchorString)
+	require.Equal(t, uint64(10), status.TransactionTime)
+	require.Equal(t, uint64(2), status.TransactionNumber)
+	require.Equal(t, "ref", status.CanonicalReference)
+
+	hash, err = opstatus.GetOperationHash(op2.OperationRequest)
+	require.NoError(t, err)
+
+	status, err = tracker.Get(hash)
+	require.NoError(t, err)
+	require.Equal(t, opstatus.StatusFailed, status.Status)
+	require.Contains(t, status.Reason, "duplicate suffix")
+}
+
+func TestProcessTxnOperations_InvalidateCache(t *testing.T) {
+	ops := []*operation.AnchoredOperation{
+		{UniqueSuffix: "abc", Type: operation.TypeUpdate},
+		{UniqueSuffix: "xyz", Type: operation.TypeCreate},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		cacheInvalidator := &mockResolutionCacheInvalidator{}
+
+		providers := &Providers{
+			OperationProtocolProvider: &mockTxnOpsProvider{},
+			OpStore:                   &mockOperationStore{},
+		}
+
+		p := New(providers, WithResolutionCacheInvalidator(cacheInvalidator))
+
+		_, err := p.processTxnOperations(ops, &txn.SidetreeTxn{AnchorString: anchorString})
+		require.NoError(t, err)
+		require.Equal(t, []string{"abc", "xyz"}, cacheInvalidator.suffixes)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		cacheInvalidator := &mockResolutionCacheInvalidator{}
+
+		providers := &Providers{
+			OperationProtocolProvider: &mockTxnOpsProvider{},
+			OpStore: &mockOperationStore{putFunc: func(ops []*operation.AnchoredOperation) error {
+				return fmt.Errorf("put error")
+			}},
+		}
+
+		p := New(providers, WithResolutionCacheInvalidator(cacheInvalidator))
+
+		_, err := p.processTxnOperations(ops, &txn.SidetreeTxn{AnchorString: anchorString})
+		require.Error(t, err)
+		require.Equal(t, []string{"abc", "xyz"}, cacheInvalidator.suffixes)
+	})
+
+	t.Run("no operations", func(t *testing.T) {
+		cacheInvalidator := &mockResolutionCacheInvalidator{}
+
+		providers := &Providers{
+			OperationProtocolProvider: &mockTxnOpsProvider{},
+			OpStore:                   &mockOperationStore{},
+		}
+
+		p := New(providers, WithResolutionCacheInvalidator(cacheInvalidator))
+
+		_, err := p.processTxnOperations(nil, &txn.SidetreeTxn{AnchorString: anchorString})
+		require.NoError(t, err)
+		require.Empty(t, cacheInvalidator.suffixes)
+	})
+}
+
+func TestProcessTxnOperations_Filters(t *testing.T) {
+	op1 := &operation.AnchoredOperation{
+		UniqueSuffix:     "abc",
+		Type:             operation.TypeUpdate,
+		OperationRequest: []byte(`{"type":"update","didSuffix":"abc"}`),
+	}
+
+	op2 := &operation.AnchoredOperation{
+		UniqueSuffix:     "xyz",
+		Type:             operation.TypeCreate,
+		OperationRequest: []byte(`{"type":"create"}`),
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var storedOps []*operation.AnchoredOperation
+
+		providers := &Providers{
+			OperationProtocolProvider: &mockTxnOpsProvider{},
+			OpStore: &mockOperationStore{putFunc: func(ops []*operation.AnchoredOperation) error {
+				storedOps = ops
+
+				return nil
+			}},
+		}
+
+		tracker := opstatus.New(opstatus.NewMemStore())
+		cacheInvalidator := &mockResolutionCacheInvalidator{}
+
+		var filtered []string
+
+		recordingFilter := &mockOperationFilter{filterFunc: func(uniqueSuffix string,
+			ops []*operation.AnchoredOperation) ([]*operation.AnchoredOperation, error) {
+			filtered = append(filtered, uniqueSuffix)
+
+			return ops, nil
+		}}
+
+		blockingFilter := &mockOperationFilter{filterFunc: func(uniqueSuffix string,
+			ops []*operation.AnchoredOperation) ([]*operation.AnchoredOperation, error) {
+			if uniqueSuffix == "xyz" {
+				return nil, nil
+			}
+
+			return ops, nil
+		}}
+
+		p := New(providers,
+			WithOperationFilters(blockingFilter),
+			WithOperationFilters(recordingFilter),
+			WithOperationStatusUpdater(tracker),
+			WithResolutionCacheInvalidator(cacheInvalidator),
+		)
+
+		numProcessed, err := p.processTxnOperations([]*operation.AnchoredOperation{op1, op2},
+			&txn.SidetreeTxn{AnchorString: anchorString})
+		require.NoError(t, err)
+		require.Equal(t, 1, numProcessed)
+		require.Equal(t, []*operation.AnchoredOperation{op1}, storedOps)
+		require.Equal(t, []string{"abc"}, cacheInvalidator.suffixes)
+
+		// Filters are chained so the second filter isn't invoked for operations filtered out by the first.
+		require.Equal(t, []string{"abc"}, filtered)
+
+		hash, err := opstatus.GetOperationHash(op2.OperationRequest)
+		require.NoError(t, err)
+
+		status, err := tracker.Get(hash)
+		require.NoError(t, err)
+		require.Equal(t, opstatus.StatusFailed, status.Status)
+		require.Equal(t, "operation was filtered out", status.Reason)
+	})
+
+	t.Run("filter error", func(t *testing.T) {
+		errExpected := fmt.Errorf("injected filter error")
+
+		var stored bool
+
+		providers := &Providers{
+			OperationProtocolProvider: &mockTxnOpsProvider{},
+			OpStore: &mockOperationStore{putFunc: func(ops []*operation.AnchoredOperation) error {
+				stored = true
+
+				return nil
+			}},
+		}
+
+		p := New(providers, WithOperationFilters(&mockOperationFilter{filterFunc: func(string,
+			[]*operation.AnchoredOperation) ([]*operation.AnchoredOperation, error) {
+			return nil, errExpected
+		}}))
+
+		_, err := p.processTxnOperations([]*operation.AnchoredOperation{op1, op2}, &txn.SidetreeTxn{AnchorString: anchorString})
+		require.True(t, errors.Is(err, errExpected))
+		require.Contains(t, err.Error(), "filter operations for suffix[abc]")
+		require.False(t, stored)
+	})
+}
+
+func TestTxnProcessor_Revert(t *testing.T) {
+	sidetreeTxn := &txn.SidetreeTxn{AnchorString: anchorString, TransactionTime: 20, TransactionNumber: 2}
+
+	deletedOps := []*operation.AnchoredOperation{
+		{UniqueSuffix: "abc", Type: operation.TypeCreate},
+		{UniqueSuffix: "xyz", Type: operation.TypeUpdate},
+		{UniqueSuffix: "abc", Type: operation.TypeUpdate},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		cacheInvalidator := &mockResolutionCacheInvalidator{}
+		rollbackHandler := &mockRollbackHandler{}
+
+		store := &mockRevertibleOperationStore{
+			deleteFunc: func(txnTime, txnNumber uint64) ([]*operation.AnchoredOperation, error) {
+				require.Equal(t, uint64(20), txnTime)
+				require.Equal(t, uint64(2), txnNumber)
+
+				return deletedOps, nil
+			},
+		}
+
+		p := New(&Providers{OpStore: store, OperationProtocolProvider: &mockTxnOpsProvider{}},
+			WithResolutionCacheInvalidator(cacheInvalidator), WithRollbackHandler(rollbackHandler))
+
+		suffixes, err := p.Revert(sidetreeTxn)
+		require.NoError(t, err)
+		require.Equal(t, []string{"abc", "xyz"}, suffixes)
+		require.Equal(t, []string{"abc", "xyz"}, cacheInvalidator.suffixes)
+		require.Equal(t, []string{"abc", "xyz"}, rollbackHandler.suffixes)
+	})
+
+	t.Run("success - operation status", func(t *testing.T) {
+		tracker := opstatus.New(opstatus.NewMemStore())
+
+		op := &operation.AnchoredOperation{
+			UniqueSuffix:     "abc",
+			Type:             operation.TypeUpdate,
+			OperationRequest: []byte(`{"type":"update","didSuffix":"abc","revealValue":"1"}`),
+		}
+
+		p := New(&Providers{
+			OpStore: &mockRevertibleOperationStore{
+				deleteFunc: func(uint64, uint64) ([]*operation.AnchoredOperation, error) {
+					return []*operation.AnchoredOperation{op}, nil
+				},
+			},
+			OperationProtocolProvider: &mockTxnOpsProvider{},
+		}, WithOperationStatusUpdater(tracker))
+
+		tracker.Update(op.OperationRequest, op.UniqueSuffix, opstatus.StatusConfirmed,
+			opstatus.WithAnchorString(sidetreeTxn.AnchorString))
+
+		_, err := p.Revert(sidetreeTxn)
+		require.NoError(t, err)
+
+		hash, err := opstatus.GetOperationHash(op.OperationRequest)
+		require.NoError(t, err)
+
+		status, err := tracker.Get(hash)
+		require.NoError(t, err)
+		require.Equal(t, opstatus.StatusReverted, status.Status)
+		require.Contains(t, status.Reason, "ledger reorganization")
+	})
+
+	t.Run("success - no operations", func(t *testing.T) {
+		rollbackHandler := &mockRollbackHandler{}
+
+		p := New(&Providers{OpStore: &mockRevertibleOperationStore{}, OperationProtocolProvider: &mockTxnOpsProvider{}},
+			WithRollbackHandler(rollbackHandler))
+
+		suffixes, err := p.Revert(sidetreeTxn)
+		require.NoError(t, err)
+		require.Empty(t, suffixes)
+		require.Empty(t, rollbackHandler.suffixes)
+	})
+
+	t.Run("store does not support delete", func(t *testing.T) {
+		p := New(&Providers{OpStore: &mockOperationStore{}, OperationProtocolProvider: &mockTxnOpsProvider{}})
+
+		suffixes, err := p.Revert(sidetreeTxn)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "operation store does not support deleting operations")
+		require.Empty(t, suffixes)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		errExpected := errors.New("injected delete error")
+
+		store := &mockRevertibleOperationStore{
+			deleteFunc: func(uint64, uint64) ([]*operation.AnchoredOperation, error) {
+				return nil, errExpected
+			},
+		}
+
+		p := New(&Providers{OpStore: store, OperationProtocolProvider: &mockTxnOpsProvider{}})
+
+		suffixes, err := p.Revert(sidetreeTxn)
+		require.True(t, errors.Is(err, errExpected))
+		require.Empty(t, suffixes)
+	})
+
+	t.Run("rollback error", func(t *testing.T) {
+		errExpected := errors.New("injected rollback error")
+
+		cacheInvalidator := &mockResolutionCacheInvalidator{}
+
+		store := &mockRevertibleOperationStore{
+			deleteFunc: func(uint64, uint64) ([]*operation.AnchoredOperation, error) {
+				return deletedOps, nil
+			},
+		}
+
+		p := New(&Providers{OpStore: store, OperationProtocolProvider: &mockTxnOpsProvider{}},
+			WithResolutionCacheInvalidator(cacheInvalidator), WithRollbackHandler(&mockRollbackHandler{err: errExpected}))
+
+		suffixes, err := p.Revert(sidetreeTxn)
+		require.True(t, errors.Is(err, errExpected))
+		require.Equal(t, []string{"abc", "xyz"}, suffixes)
+		require.Equal(t, []string{"abc", "xyz"}, cacheInvalidator.suffixes)
+	})
+}
+
 func TestUpdateOperation(t *testing.T) {
 	t.Run("test success", func(t *testing.T) {
 		updatedOps := updateAnchoredOperation(&operation.AnchoredOperation{UniqueSuffix: "abc"},
@@ -151,6 +481,20 @@ func (m *mockOperationStore) Get(suffix string) ([]*operation.AnchoredOperation,
 	return nil, nil
 }
 
+type mockRevertibleOperationStore struct {
+	mockOperationStore
+
+	deleteFunc func(txnTime, txnNumber uint64) ([]*operation.AnchoredOperation, error)
+}
+
+func (m *mockRevertibleOperationStore) DeleteByTransaction(txnTime, txnNumber uint64) ([]*operation.AnchoredOperation, error) {
+	if m.deleteFunc != nil {
+		return m.deleteFunc(txnTime, txnNumber)
+	}
+
+	return nil, nil
+}
+
 type mockTxnOpsProvider struct {
 	err error
 }
@@ -175,3 +519,31 @@ type mockUnpublishedOpsStore struct {
 func (m *mockUnpublishedOpsStore) DeleteAll(_ []*operation.AnchoredOperation) error {
 	return m.DeleteAllErr
 }
+
+type mockResolutionCacheInvalidator struct {
+	suffixes []string
+}
+
+func (m *mockResolutionCacheInvalidator) Invalidate(uniqueSuffixes ...string) {
+	m.suffixes = append(m.suffixes, uniqueSuffixes...)
+}
+
+type mockRollbackHandler struct {
+	suffixes []string
+	err      error
+}
+
+func (m *mockRollbackHandler) Rollback(uniqueSuffixes ...string) error {
+	m.suffixes = append(m.suffixes, uniqueSuffixes...)
+
+	return m.err
+}
+
+type mockOperationFilter struct {
+	filterFunc func(uniqueSuffix string, ops []*operation.AnchoredOperation) ([]*operation.AnchoredOperation, error)
+}
+
+func (m *mockOperationFilter) Filter(uniqueSuffix string,
+	ops []*operation.AnchoredOperation) ([]*operation.AnchoredOperation, error) {
+	return m.filterFunc(uniqueSuffix, ops)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txnprovider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+)
+
+// FileSize holds the size (in bytes) of a batch file.
+type FileSize struct {
+	// Size is the size of the compressed file (as stored in CAS).
+	Size int `json:"size"`
+	// UncompressedSize is the size of the file before compression.
+	UncompressedSize int `json:"uncompressedSize"`
+}
+
+// BatchFileSizes holds the sizes of the batch files keyed by file type (see CoreIndexFileType, etc.). Files that
+// would not be created for the operations are not included.
+type BatchFileSizes map[string]*FileSize
+
+// EstimateBatchFileSizes returns the sizes of the batch files that PrepareTxnFiles would create for the given
+// operations. The files are not written to CAS. Since the URIs of the files referenced by the index files are not
+// known in advance, the URIs are assumed to be 64 characters long, which is at least as long as the content
+// addresses of common CAS implementations.
+func (h *OperationHandler) EstimateBatchFileSizes(ops []*operation.QueuedOperation) (BatchFileSizes, error) {
+	parsedOps, _, err := h.parseOperations(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make(BatchFileSizes)
+
+	provisionalIndexURI := ""
+	if len(parsedOps.Deactivate) != len(ops) {
+		chunkURI, err := h.estimateFileSize(sizes, ChunkFileType, models.CreateChunkFile(parsedOps))
+		if err != nil {
+			return nil, err
+		}
+
+		provisionalProofURI := ""
+		if len(parsedOps.Update) > 0 {
+			provisionalProofURI, err = h.estimateFileSize(sizes, ProvisionalProofFileType,
+				models.CreateProvisionalProofFile(parsedOps.Update))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		provisionalIndexURI, err = h.estimateFileSize(sizes, ProvisionalIndexFileType,
+			models.CreateProvisionalIndexFile([]string{chunkURI}, provisionalProofURI, parsedOps.Update))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	coreProofURI := ""
+	if len(parsedOps.Recover)+len(parsedOps.Deactivate) > 0 {
+		coreProofURI, err = h.estimateFileSize(sizes, CoreProofFileType,
+			models.CreateCoreProofFile(parsedOps.Recover, parsedOps.Deactivate))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = h.estimateFileSize(sizes, CoreIndexFileType,
+		models.CreateCoreIndexFile(coreProofURI, provisionalIndexURI, parsedOps))
+	if err != nil {
+		return nil, err
+	}
+
+	return sizes, nil
+}
+
+// ExceedsMaxFileSize returns true if any of the batch files that would be created for the given operations exceeds
+// the maximum file size (or the maximum decompressed file size) allowed by the protocol. Observers reject batches
+// with files that exceed these limits.
+func (h *OperationHandler) ExceedsMaxFileSize(ops []*operation.QueuedOperation) (bool, error) {
+	sizes, err := h.EstimateBatchFileSizes(ops)
+	if err != nil {
+		return false, err
+	}
+
+	for fileType, size := range sizes {
+		maxSize := h.maxFileSize(fileType)
+		maxUncompressedSize := maxSize * h.protocol.MaxMemoryDecompressionFactor
+
+		if size.Size > int(maxSize) || size.UncompressedSize > int(maxUncompressedSize) {
+			logger.Debug("Batch file exceeds maximum size", log.WithAlias(fileType), log.WithTotal(len(ops)),
+				log.WithSize(size.Size), log.WithMaxSize(int(maxSize)))
+
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// MaxFileSizeRatio returns the largest ratio (over all batch files) between the size of a batch file that would be
+// created for the given operation alone and the maximum size of that file. Since a batch file for a set of operations
+// is smaller than the files created for each of the operations separately put together, the batch files for a set of
+// operations do not exceed the maximum file sizes if the sum of the ratios of the operations is at most 1. This
+// allows the size of a batch to be tracked incrementally as operations are added.
+func (h *OperationHandler) MaxFileSizeRatio(op *operation.QueuedOperation) (float64, error) {
+	sizes, err := h.EstimateBatchFileSizes([]*operation.QueuedOperation{op})
+	if err != nil {
+		return 0, err
+	}
+
+	var ratio float64
+
+	for fileType, size := range sizes {
+		maxSize := float64(h.maxFileSize(fileType))
+		maxUncompressedSize := maxSize * float64(h.protocol.MaxMemoryDecompressionFactor)
+
+		if r := float64(size.Size) / maxSize; r > ratio {
+			ratio = r
+		}
+
+		if r := float64(size.UncompressedSize) / maxUncompressedSize; r > ratio {
+			ratio = r
+		}
+	}
+
+	return ratio, nil
+}
+
+func (h *OperationHandler) maxFileSize(fileType string) uint {
+	switch fileType {
+	case CoreIndexFileType:
+		return h.protocol.MaxCoreIndexFileSize
+	case ProvisionalIndexFileType:
+		return h.protocol.MaxProvisionalIndexFileSize
+	case ChunkFileType:
+		return h.protocol.MaxChunkFileSize
+	default:
+		return h.protocol.MaxProofFileSize
+	}
+}
+
+// estimateFileSize adds the size of the given file model to the given sizes and returns a placeholder URI
+// for the file.
+func (h *OperationHandler) estimateFileSize(sizes BatchFileSizes, fileType string, m interface{}) (string, error) {
+	bytes, err := docutil.MarshalCanonical(m)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s file: %s", fileType, err.Error())
+	}
+
+	compressedBytes, err := h.cp.Compress(h.protocol.CompressionAlgorithm, bytes)
+	if err != nil {
+		return "", err
+	}
+
+	sizes[fileType] = &FileSize{Size: len(compressedBytes), UncompressedSize: len(bytes)}
+
+	// The hex encoded hash is used so that the placeholder URI doesn't compress any better than a real URI.
+	hash := sha256.Sum256(compressedBytes)
+
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,323 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txnprovider
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationparser"
+)
+
+func TestOperationHandler_EstimateBatchFileSizes(t *testing.T) {
+	newHandler := func(p protocol.Protocol) *OperationHandler {
+		return NewOperationHandler(
+			p,
+			mocks.NewMockCasClient(nil),
+			compression.New(compression.WithDefaultAlgorithms()),
+			operationparser.New(p),
+			&mocks.MetricsProvider{})
+	}
+
+	fileTypes := map[string]string{
+		"core index file":        CoreIndexFileType,
+		"core proof file":        CoreProofFileType,
+		"provisional index file": ProvisionalIndexFileType,
+		"provisional proof file": ProvisionalProofFileType,
+		"chunk file":             ChunkFileType,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		handler := newHandler(mocks.NewMockProtocolClient().Protocol)
+
+		ops := getTestOperations(2, 1, 1, 1)
+
+		sizes, err := handler.EstimateBatchFileSizes(ops)
+		require.NoError(t, err)
+		require.Len(t, sizes, 5)
+
+		anchoringInfo, err := handler.PrepareTxnFiles(ops)
+		require.NoError(t, err)
+		require.Len(t, anchoringInfo.Artifacts, 5)
+
+		for _, artifact := range anchoringInfo.Artifacts {
+			fileType, ok := fileTypes[artifact.Desc]
+			require.True(t, ok)
+
+			content, err := handler.cas.Read(artifact.ID)
+			require.NoError(t, err)
+
+			size := sizes[fileType]
+			require.NotNil(t, size)
+
+			switch fileType {
+			case CoreIndexFileType, ProvisionalIndexFileType:
+				// The actual URIs of the referenced files may be shorter than the placeholder URIs.
+				require.GreaterOrEqual(t, size.Size, len(content))
+			default:
+				require.Equal(t, len(content), size.Size)
+			}
+
+			require.Greater(t, size.UncompressedSize, 0)
+		}
+	})
+
+	t.Run("deactivate operations only", func(t *testing.T) {
+		handler := newHandler(mocks.NewMockProtocolClient().Protocol)
+
+		sizes, err := handler.EstimateBatchFileSizes(getTestOperations(0, 0, 2, 0))
+		require.NoError(t, err)
+		require.Len(t, sizes, 2)
+		require.NotNil(t, sizes[CoreIndexFileType])
+		require.NotNil(t, sizes[CoreProofFileType])
+	})
+
+	t.Run("create operations only", func(t *testing.T) {
+		handler := newHandler(mocks.NewMockProtocolClient().Protocol)
+
+		sizes, err := handler.EstimateBatchFileSizes(getTestOperations(2, 0, 0, 0))
+		require.NoError(t, err)
+		require.Len(t, sizes, 3)
+		require.NotNil(t, sizes[CoreIndexFileType])
+		require.NotNil(t, sizes[ProvisionalIndexFileType])
+		require.NotNil(t, sizes[ChunkFileType])
+	})
+
+	t.Run("error - no operations", func(t *testing.T) {
+		handler := newHandler(mocks.NewMockProtocolClient().Protocol)
+
+		sizes, err := handler.EstimateBatchFileSizes(nil)
+		require.Error(t, err)
+		require.Nil(t, sizes)
+		require.Contains(t, err.Error(), "prepare txn operations called without operations")
+	})
+
+	t.Run("error - invalid operation", func(t *testing.T) {
+		handler := newHandler(mocks.NewMockProtocolClient().Protocol)
+
+		sizes, err := handler.EstimateBatchFileSizes([]*operation.QueuedOperation{
+			{Namespace: defaultNS, OperationRequest: []byte("invalid")},
+		})
+		require.True(t, errors.Is(err, protocol.ErrInvalidOperation))
+		require.Nil(t, sizes)
+	})
+
+	t.Run("error - compression error", func(t *testing.T) {
+		p := mocks.NewMockProtocolClient().Protocol
+		p.CompressionAlgorithm = "invalid"
+
+		sizes, err := newHandler(p).EstimateBatchFileSizes(getTestOperations(1, 1, 1, 1))
+		require.Error(t, err)
+		require.Nil(t, sizes)
+		require.Contains(t, err.Error(), "compression algorithm 'invalid' not supported")
+	})
+
+	t.Run("error - marshal error", func(t *testing.T) {
+		handler := newHandler(mocks.NewMockProtocolClient().Protocol)
+
+		uri, err := handler.estimateFileSize(make(BatchFileSizes), ChunkFileType, "test")
+		require.Error(t, err)
+		require.Empty(t, uri)
+		require.Contains(t, err.Error(), "failed to marshal chunk file")
+	})
+}
+
+func TestOperationHandler_ExceedsMaxFileSize(t *testing.T) {
+	newHandler := func(p protocol.Protocol) *OperationHandler {
+		return NewOperationHandler(
+			p,
+			mocks.NewMockCasClient(nil),
+			compression.New(compression.WithDefaultAlgorithms()),
+			operationparser.New(p),
+			&mocks.MetricsProvider{})
+	}
+
+	ops := getTestOperations(3, 2, 2, 2)
+
+	sizes, err := newHandler(mocks.NewMockProtocolClient().Protocol).EstimateBatchFileSizes(ops)
+	require.NoError(t, err)
+
+	// setMaxSize sets the maximum size of the given file type and leaves the maximum sizes of the other file
+	// types large enough for the test operations.
+	setMaxSize := func(p *protocol.Protocol, fileType string, maxSize uint) {
+		switch fileType {
+		case CoreIndexFileType:
+			p.MaxCoreIndexFileSize = maxSize
+		case ProvisionalIndexFileType:
+			p.MaxProvisionalIndexFileSize = maxSize
+		case ChunkFileType:
+			p.MaxChunkFileSize = maxSize
+		default:
+			p.MaxProofFileSize = maxSize
+		}
+	}
+
+	t.Run("default limits", func(t *testing.T) {
+		exceeds, err := newHandler(mocks.NewMockProtocolClient().Protocol).ExceedsMaxFileSize(ops)
+		require.NoError(t, err)
+		require.False(t, exceeds)
+	})
+
+	for _, fileType := range []string{
+		CoreIndexFileType, CoreProofFileType, ProvisionalIndexFileType, ProvisionalProofFileType, ChunkFileType,
+	} {
+		fileType := fileType
+		size := sizes[fileType]
+
+		t.Run(fileType+" - at maximum size", func(t *testing.T) {
+			p := mocks.NewMockProtocolClient().Protocol
+
+			maxSize := uint(size.Size)
+			if fileType == CoreProofFileType || fileType == ProvisionalProofFileType {
+				// Both proof files have the same maximum size.
+				maxSize = uint(sizes[CoreProofFileType].Size)
+				if sizes[ProvisionalProofFileType].Size > sizes[CoreProofFileType].Size {
+					maxSize = uint(sizes[ProvisionalProofFileType].Size)
+				}
+			}
+
+			setMaxSize(&p, fileType, maxSize)
+			p.MaxMemoryDecompressionFactor = uint(size.UncompressedSize)
+
+			exceeds, err := newHandler(p).ExceedsMaxFileSize(ops)
+			require.NoError(t, err)
+			require.False(t, exceeds)
+		})
+
+		t.Run(fileType+" - one byte over maximum size", func(t *testing.T) {
+			p := mocks.NewMockProtocolClient().Protocol
+
+			setMaxSize(&p, fileType, uint(size.Size-1))
+			p.MaxMemoryDecompressionFactor = uint(size.UncompressedSize)
+
+			exceeds, err := newHandler(p).ExceedsMaxFileSize(ops)
+			require.NoError(t, err)
+			require.True(t, exceeds)
+		})
+	}
+
+	t.Run("decompressed size - at maximum size", func(t *testing.T) {
+		size := sizes[ChunkFileType]
+		require.Less(t, size.Size, size.UncompressedSize)
+
+		p := mocks.NewMockProtocolClient().Protocol
+		p.MaxChunkFileSize = uint(size.UncompressedSize)
+		p.MaxMemoryDecompressionFactor = 1
+
+		exceeds, err := newHandler(p).ExceedsMaxFileSize(ops)
+		require.NoError(t, err)
+		require.False(t, exceeds)
+	})
+
+	t.Run("decompressed size - one byte over maximum size", func(t *testing.T) {
+		size := sizes[ChunkFileType]
+		require.Less(t, size.Size, size.UncompressedSize-1)
+
+		p := mocks.NewMockProtocolClient().Protocol
+		p.MaxChunkFileSize = uint(size.UncompressedSize - 1)
+		p.MaxMemoryDecompressionFactor = 1
+
+		exceeds, err := newHandler(p).ExceedsMaxFileSize(ops)
+		require.NoError(t, err)
+		require.True(t, exceeds)
+	})
+
+	t.Run("error - invalid operation", func(t *testing.T) {
+		exceeds, err := newHandler(mocks.NewMockProtocolClient().Protocol).ExceedsMaxFileSize(
+			[]*operation.QueuedOperation{{Namespace: defaultNS, OperationRequest: []byte("invalid")}},
+		)
+		require.Error(t, err)
+		require.False(t, exceeds)
+	})
+}
+
+func TestOperationHandler_MaxFileSizeRatio(t *testing.T) {
+	newHandler := func(p protocol.Protocol) *OperationHandler {
+		return NewOperationHandler(
+			p,
+			mocks.NewMockCasClient(nil),
+			compression.New(compression.WithDefaultAlgorithms()),
+			operationparser.New(p),
+			&mocks.MetricsProvider{})
+	}
+
+	t.Run("success", func(t *testing.T) {
+		handler := newHandler(mocks.NewMockProtocolClient().Protocol)
+
+		ops := getTestOperations(3, 2, 2, 2)
+
+		batchSizes, err := handler.EstimateBatchFileSizes(ops)
+		require.NoError(t, err)
+
+		var total float64
+
+		sums := make(BatchFileSizes)
+
+		for _, op := range ops {
+			ratio, err := handler.MaxFileSizeRatio(op)
+			require.NoError(t, err)
+			require.Greater(t, ratio, 0.0)
+
+			total += ratio
+
+			sizes, err := handler.EstimateBatchFileSizes([]*operation.QueuedOperation{op})
+			require.NoError(t, err)
+
+			for fileType, size := range sizes {
+				sum, ok := sums[fileType]
+				if !ok {
+					sum = &FileSize{}
+					sums[fileType] = sum
+				}
+
+				sum.Size += size.Size
+				sum.UncompressedSize += size.UncompressedSize
+			}
+		}
+
+		// The batch files are smaller than the files for the individual operations put together.
+		for fileType, size := range batchSizes {
+			require.LessOrEqual(t, size.Size, sums[fileType].Size)
+			require.LessOrEqual(t, size.UncompressedSize, sums[fileType].UncompressedSize)
+		}
+
+		require.Less(t, total, 1.0)
+
+		exceeds, err := handler.ExceedsMaxFileSize(ops)
+		require.NoError(t, err)
+		require.False(t, exceeds)
+	})
+
+	t.Run("operation at maximum size", func(t *testing.T) {
+		op := getTestOperations(1, 0, 0, 0)[0]
+
+		sizes, err := newHandler(mocks.NewMockProtocolClient().Protocol).EstimateBatchFileSizes(
+			[]*operation.QueuedOperation{op})
+		require.NoError(t, err)
+
+		p := mocks.NewMockProtocolClient().Protocol
+		p.MaxChunkFileSize = uint(sizes[ChunkFileType].Size)
+
+		ratio, err := newHandler(p).MaxFileSizeRatio(op)
+		require.NoError(t, err)
+		require.Equal(t, 1.0, ratio)
+	})
+
+	t.Run("error - invalid operation", func(t *testing.T) {
+		ratio, err := newHandler(mocks.NewMockProtocolClient().Protocol).MaxFileSizeRatio(
+			&operation.QueuedOperation{Namespace: defaultNS, OperationRequest: []byte("invalid")},
+		)
+		require.Error(t, err)
+		require.Zero(t, ratio)
+	})
+}
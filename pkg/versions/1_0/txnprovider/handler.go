@@ -169,7 +169,7 @@ func (h *OperationHandler) parseOperations(ops []*operation.QueuedOperation) (*m
 
 			// operations are already validated/parsed at REST so any error at this point
 			// will result in rejecting whole batch
-			return nil, nil, e
+			return nil, nil, fmt.Errorf("%w [%s]: %s", protocol.ErrInvalidOperation, queuedOperation.UniqueSuffix, e.Error())
 		}
 
 		_, ok := batchSuffixes[op.UniqueSuffix]
@@ -297,7 +297,7 @@ func (h *OperationHandler) writeModelToCAS(m interface{}, alias string) (string,
 	// make file available in CAS
 	address, err := h.cas.Write(compressedBytes)
 	if err != nil {
-		return "", fmt.Errorf("failed to store %s file: %s", alias, err.Error())
+		return "", fmt.Errorf("failed to store %s file: %w", alias, err)
 	}
 
 	h.metrics.CASWriteSize(alias, len(compressedBytes))
@@ -301,6 +301,7 @@ func TestOperationHandler_PrepareTxnFiles(t *testing.T) {
 		require.Error(t, err)
 		require.Empty(t, anchoringInfo)
 		require.Contains(t, err.Error(), "parse operation: operation type [] not supported")
+		require.Contains(t, err.Error(), "invalid operation [suffix]")
 	})
 
 	t.Run("error - write to CAS error for chunk file", func(t *testing.T) {
@@ -0,0 +1,391 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txnprovider
+
+import (
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/model"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+)
+
+// Batch file types reported by the inspector.
+const (
+	CoreIndexFileType        = "coreIndex"
+	CoreProofFileType        = "coreProof"
+	ProvisionalIndexFileType = "provisionalIndex"
+	ProvisionalProofFileType = "provisionalProof"
+	ChunkFileType            = "chunk"
+)
+
+// InspectionReport contains the result of inspecting the batch files of an anchor.
+type InspectionReport struct {
+	AnchorString       string             `json:"anchorString"`
+	NumberOfOperations int                `json:"numberOfOperations"`
+	Files              []*FileReport      `json:"files"`
+	Operations         []*OperationReport `json:"operations"`
+
+	// Errors contains the batch level validation errors (e.g. operation counts that don't match between files).
+	Errors []string `json:"errors,omitempty"`
+}
+
+// FileReport contains the result of inspecting a single batch file.
+type FileReport struct {
+	Type string `json:"type"`
+	URI  string `json:"uri"`
+
+	// Size is the size of the decompressed file content.
+	Size  int    `json:"size,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// OperationReport contains the result of inspecting a single operation. Index is the index of the operation
+// within the operations of the same type.
+type OperationReport struct {
+	Type         operation.Type `json:"type"`
+	Index        int            `json:"index"`
+	UniqueSuffix string         `json:"didSuffix"`
+	Errors       []string       `json:"errors,omitempty"`
+}
+
+// Valid returns true if neither the batch files nor any of the operations failed validation.
+func (r *InspectionReport) Valid() bool {
+	if len(r.Errors) > 0 {
+		return false
+	}
+
+	for _, f := range r.Files {
+		if f.Error != "" {
+			return false
+		}
+	}
+
+	for _, op := range r.Operations {
+		if len(op.Errors) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Inspect reads the batch files referenced by the anchor string of the given transaction and runs the same
+// validations as GetTxnOperations. Unlike GetTxnOperations, it doesn't stop at the first error: every file
+// and every operation that could be read is validated and the reason for each failure is recorded in the report.
+func (h *OperationProvider) Inspect(t *txn.SidetreeTxn) *InspectionReport {
+	i := &inspector{
+		OperationProvider: h,
+		alternateSources:  t.AlternateSources,
+		report:            &InspectionReport{AnchorString: t.AnchorString},
+	}
+
+	i.inspect()
+
+	return i.report
+}
+
+type inspector struct {
+	*OperationProvider
+
+	alternateSources []string
+	report           *InspectionReport
+	files            batchFiles
+}
+
+func (i *inspector) inspect() {
+	anchorData, err := ParseAnchorData(i.report.AnchorString)
+	if err != nil {
+		i.addError(err)
+
+		return
+	}
+
+	i.report.NumberOfOperations = anchorData.NumberOfOperations
+
+	if !i.inspectFiles(anchorData.CoreIndexFileURI) {
+		return
+	}
+
+	i.inspectOperations()
+
+	if len(i.report.Operations) != anchorData.NumberOfOperations {
+		i.addError(fmt.Errorf("number of txn ops[%d] doesn't match anchor string num of ops[%d]",
+			len(i.report.Operations), anchorData.NumberOfOperations))
+	}
+}
+
+// inspectFiles reads and validates all batch files. It returns false if the operations can't be inspected
+// because the core index file could not be read.
+func (i *inspector) inspectFiles(coreIndexURI string) bool {
+	ok := i.inspectFile(CoreIndexFileType, coreIndexURI, i.MaxCoreIndexFileSize,
+		func(content []byte) (err error) {
+			i.files.CoreIndex, err = models.ParseCoreIndexFile(content)
+
+			return err
+		},
+		func() error { return i.validateCoreIndexFile(i.files.CoreIndex) },
+	)
+	if !ok {
+		return false
+	}
+
+	allFilesRead := true
+
+	if uri := i.files.CoreIndex.CoreProofFileURI; uri != "" {
+		allFilesRead = i.inspectFile(CoreProofFileType, uri, i.MaxProofFileSize,
+			func(content []byte) (err error) {
+				i.files.CoreProof, err = models.ParseCoreProofFile(content)
+
+				return err
+			},
+			func() error { return i.validateCoreProofFile(i.files.CoreProof) },
+		)
+	}
+
+	if uri := i.files.CoreIndex.ProvisionalIndexFileURI; uri != "" {
+		allFilesRead = i.inspectProvisionalFiles(uri) && allFilesRead
+	}
+
+	if allFilesRead {
+		if err := validateBatchFileCounts(&i.files); err != nil {
+			i.addError(err)
+		}
+	}
+
+	return true
+}
+
+func (i *inspector) inspectProvisionalFiles(provisionalIndexURI string) bool {
+	ok := i.inspectFile(ProvisionalIndexFileType, provisionalIndexURI, i.MaxProvisionalIndexFileSize,
+		func(content []byte) (err error) {
+			i.files.ProvisionalIndex, err = models.ParseProvisionalIndexFile(content)
+
+			return err
+		},
+		func() error { return i.validateProvisionalIndexFile(i.files.ProvisionalIndex) },
+	)
+	if !ok {
+		return false
+	}
+
+	if uri := i.files.ProvisionalIndex.ProvisionalProofFileURI; uri != "" {
+		ok = i.inspectFile(ProvisionalProofFileType, uri, i.MaxProofFileSize,
+			func(content []byte) (err error) {
+				i.files.ProvisionalProof, err = models.ParseProvisionalProofFile(content)
+
+				return err
+			},
+			func() error { return i.validateProvisionalProofFile(i.files.ProvisionalProof) },
+		)
+	}
+
+	if len(i.files.ProvisionalIndex.Chunks) == 0 {
+		i.addError(fmt.Errorf("provisional index file is missing chunk file URI"))
+
+		return false
+	}
+
+	return i.inspectFile(ChunkFileType, i.files.ProvisionalIndex.Chunks[0].ChunkFileURI, i.MaxChunkFileSize,
+		func(content []byte) (err error) {
+			i.files.Chunk, err = models.ParseChunkFile(content)
+
+			return err
+		},
+		func() error { return i.validateChunkFile(i.files.Chunk) },
+	) && ok
+}
+
+// inspectFile reads and parses the given batch file and records the result in the report. It returns false
+// if the file could not be read or parsed. A file that fails validation is still returned so that its
+// operations can be inspected.
+func (i *inspector) inspectFile(fileType, uri string, maxSize uint,
+	parse func(content []byte) error, validate func() error) bool {
+	fr := &FileReport{Type: fileType, URI: uri}
+
+	i.report.Files = append(i.report.Files, fr)
+
+	content, err := i.readFromCAS(uri, maxSize, i.alternateSources...)
+	if err != nil {
+		fr.Error = err.Error()
+
+		return false
+	}
+
+	fr.Size = len(content)
+
+	err = parse(content)
+	if err != nil {
+		fr.Error = fmt.Sprintf("failed to parse content: %s", err.Error())
+
+		return false
+	}
+
+	err = validate()
+	if err != nil {
+		fr.Error = err.Error()
+	}
+
+	return true
+}
+
+// inspectOperations validates every operation in the order in which GetTxnOperations assembles them:
+// create, recover, update and deactivate. Deltas are assigned to create, recover and update operations
+// in that order.
+func (i *inspector) inspectOperations() {
+	var suffixes []string
+
+	var recoverSignedData, deactivateSignedData, updateSignedData []string
+
+	if cpf := i.files.CoreProof; cpf != nil {
+		recoverSignedData = cpf.Operations.Recover
+		deactivateSignedData = cpf.Operations.Deactivate
+	}
+
+	if ppf := i.files.ProvisionalProof; ppf != nil {
+		updateSignedData = ppf.Operations.Update
+	}
+
+	deltaIndex := 0
+
+	if ops := i.files.CoreIndex.Operations; ops != nil {
+		for idx, op := range ops.Create {
+			r := i.inspectCreate(idx, op)
+			i.inspectDelta(r, deltaIndex)
+
+			deltaIndex++
+
+			suffixes = append(suffixes, r.UniqueSuffix)
+		}
+
+		for idx, op := range ops.Recover {
+			r := i.inspectReference(operation.TypeRecover, idx, op, recoverSignedData, i.parseSignedDataForRecover)
+			i.inspectDelta(r, deltaIndex)
+
+			deltaIndex++
+
+			suffixes = append(suffixes, r.UniqueSuffix)
+		}
+	}
+
+	if pif := i.files.ProvisionalIndex; pif != nil && pif.Operations != nil {
+		for idx, op := range pif.Operations.Update {
+			r := i.inspectReference(operation.TypeUpdate, idx, op, updateSignedData, i.parseSignedDataForUpdate)
+			i.inspectDelta(r, deltaIndex)
+
+			deltaIndex++
+
+			suffixes = append(suffixes, r.UniqueSuffix)
+		}
+	}
+
+	if ops := i.files.CoreIndex.Operations; ops != nil {
+		for idx, op := range ops.Deactivate {
+			r := i.inspectReference(operation.TypeDeactivate, idx, op, deactivateSignedData, i.parseSignedDataForDeactivate)
+
+			suffixes = append(suffixes, r.UniqueSuffix)
+		}
+	}
+
+	if err := checkForDuplicates(suffixes); err != nil {
+		i.addError(fmt.Errorf("check for duplicate suffixes in core/provisional index files: %s", err.Error()))
+	}
+}
+
+func (i *inspector) inspectCreate(idx int, op models.CreateReference) *OperationReport {
+	r := i.addOperation(operation.TypeCreate, idx)
+
+	suffix, err := model.GetUniqueSuffix(op.SuffixData, i.MultihashAlgorithms)
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("failed to calculate unique suffix: %s", err.Error()))
+	}
+
+	r.UniqueSuffix = suffix
+
+	err = i.parser.ValidateSuffixData(op.SuffixData)
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("failed to validate suffix data: %s", err.Error()))
+	}
+
+	return r
+}
+
+// inspectReference validates the operation reference along with the signed data at the same index
+// in the corresponding proof file.
+func (i *inspector) inspectReference(opType operation.Type, idx int, op models.OperationReference,
+	signedData []string, parseSignedData func(string) error) *OperationReport {
+	r := i.addOperation(opType, idx)
+	r.UniqueSuffix = op.DidSuffix
+
+	err := i.validateOperationReference(op)
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("failed to validate operation reference: %s", err.Error()))
+	}
+
+	if idx >= len(signedData) {
+		r.Errors = append(r.Errors, "signed data not available")
+
+		return r
+	}
+
+	err = parseSignedData(signedData[idx])
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("failed to validate signed data: %s", err.Error()))
+	}
+
+	return r
+}
+
+func (i *inspector) parseSignedDataForRecover(signedData string) error {
+	_, err := i.parser.ParseSignedDataForRecover(signedData)
+
+	return err
+}
+
+func (i *inspector) parseSignedDataForDeactivate(signedData string) error {
+	_, err := i.parser.ParseSignedDataForDeactivate(signedData)
+
+	return err
+}
+
+func (i *inspector) parseSignedDataForUpdate(signedData string) error {
+	_, err := i.parser.ParseSignedDataForUpdate(signedData)
+
+	return err
+}
+
+func (i *inspector) inspectDelta(r *OperationReport, idx int) {
+	if i.files.Chunk == nil {
+		r.Errors = append(r.Errors, "delta not available")
+
+		return
+	}
+
+	if idx >= len(i.files.Chunk.Deltas) {
+		r.Errors = append(r.Errors, fmt.Sprintf("missing delta[%d]", idx))
+
+		return
+	}
+
+	err := i.parser.ValidateDelta(i.files.Chunk.Deltas[idx])
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("failed to validate delta[%d]: %s", idx, err.Error()))
+	}
+}
+
+func (i *inspector) addOperation(opType operation.Type, idx int) *OperationReport {
+	r := &OperationReport{Type: opType, Index: idx}
+
+	i.report.Operations = append(i.report.Operations, r)
+
+	return r
+}
+
+func (i *inspector) addError(err error) {
+	i.report.Errors = append(i.report.Errors, err.Error())
+}
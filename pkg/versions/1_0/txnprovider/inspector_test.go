@@ -0,0 +1,286 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txnprovider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/cas"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/operationparser"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+)
+
+func TestOperationProvider_Inspect(t *testing.T) {
+	const (
+		createOpsNum     = 2
+		updateOpsNum     = 3
+		deactivateOpsNum = 2
+		recoverOpsNum    = 2
+	)
+
+	pc := mocks.NewMockProtocolClient()
+	parser := operationparser.New(pc.Protocol)
+	cp := compression.New(compression.WithDefaultAlgorithms())
+
+	casClient := mocks.NewMockCasClient(nil)
+	handler := NewOperationHandler(pc.Protocol, casClient, cp, parser, &mocks.MetricsProvider{})
+
+	anchoringInfo, err := handler.PrepareTxnFiles(getTestOperations(createOpsNum, updateOpsNum, deactivateOpsNum, recoverOpsNum))
+	require.NoError(t, err)
+
+	fileURIs := make(map[string]string)
+	for _, artifact := range anchoringInfo.Artifacts {
+		fileURIs[artifact.Desc] = artifact.ID
+	}
+
+	t.Run("success", func(t *testing.T) {
+		provider := NewOperationProvider(pc.Protocol, parser, casClient, cp)
+
+		report := provider.Inspect(&txn.SidetreeTxn{AnchorString: anchoringInfo.AnchorString})
+		require.True(t, report.Valid())
+		require.Empty(t, report.Errors)
+		require.Equal(t, anchoringInfo.AnchorString, report.AnchorString)
+		require.Equal(t, len(anchoringInfo.OperationReferences), report.NumberOfOperations)
+
+		require.Len(t, report.Files, 5)
+		require.Equal(t, CoreIndexFileType, report.Files[0].Type)
+		require.Equal(t, fileURIs["core index file"], report.Files[0].URI)
+		require.Equal(t, CoreProofFileType, report.Files[1].Type)
+		require.Equal(t, ProvisionalIndexFileType, report.Files[2].Type)
+		require.Equal(t, ProvisionalProofFileType, report.Files[3].Type)
+		require.Equal(t, ChunkFileType, report.Files[4].Type)
+
+		for _, f := range report.Files {
+			require.Empty(t, f.Error)
+			require.NotZero(t, f.Size)
+		}
+
+		require.Len(t, report.Operations, createOpsNum+updateOpsNum+deactivateOpsNum+recoverOpsNum)
+
+		// Operations are reported in the same order as they are returned from GetTxnOperations.
+		txnOps, err := provider.GetTxnOperations(&txn.SidetreeTxn{AnchorString: anchoringInfo.AnchorString})
+		require.NoError(t, err)
+
+		for i, op := range report.Operations {
+			require.Empty(t, op.Errors)
+			require.Equal(t, txnOps[i].Type, op.Type)
+			require.Equal(t, txnOps[i].UniqueSuffix, op.UniqueSuffix)
+		}
+
+		require.Equal(t, 1, report.Operations[createOpsNum+recoverOpsNum+1].Index)
+	})
+
+	t.Run("error - invalid anchor string", func(t *testing.T) {
+		provider := NewOperationProvider(pc.Protocol, parser, casClient, cp)
+
+		report := provider.Inspect(&txn.SidetreeTxn{AnchorString: "invalid"})
+		require.False(t, report.Valid())
+		require.Len(t, report.Errors, 1)
+		require.Contains(t, report.Errors[0], "expecting [2] parts, got [1] parts")
+		require.Empty(t, report.Files)
+		require.Empty(t, report.Operations)
+	})
+
+	t.Run("error - core index file not found", func(t *testing.T) {
+		provider := NewOperationProvider(pc.Protocol, parser, casClient, cp)
+
+		report := provider.Inspect(&txn.SidetreeTxn{AnchorString: "1.invalid"})
+		require.False(t, report.Valid())
+		require.Len(t, report.Files, 1)
+		require.Equal(t, "invalid", report.Files[0].URI)
+		require.Contains(t, report.Files[0].Error, "retrieve CAS content at uri[invalid]: not found")
+		require.Empty(t, report.Operations)
+	})
+
+	t.Run("error - invalid core index file", func(t *testing.T) {
+		uri, err := casClient.Write([]byte("invalid"))
+		require.NoError(t, err)
+
+		provider := NewOperationProvider(pc.Protocol, parser, casClient, cp)
+
+		report := provider.Inspect(&txn.SidetreeTxn{AnchorString: "1." + uri})
+		require.False(t, report.Valid())
+		require.Len(t, report.Files, 1)
+		require.Contains(t, report.Files[0].Error, "decompress CAS uri")
+	})
+
+	t.Run("error - chunk file not available", func(t *testing.T) {
+		provider := NewOperationProvider(pc.Protocol, parser,
+			newFailingCAS(casClient, fileURIs["chunk file"]), cp)
+
+		report := provider.Inspect(&txn.SidetreeTxn{AnchorString: anchoringInfo.AnchorString})
+		require.False(t, report.Valid())
+		require.Empty(t, report.Errors)
+		require.Len(t, report.Files, 5)
+		require.Contains(t, report.Files[4].Error, "injected read error")
+
+		for _, op := range report.Operations {
+			if op.Type == operation.TypeDeactivate {
+				require.Empty(t, op.Errors)
+			} else {
+				require.Equal(t, []string{"delta not available"}, op.Errors)
+			}
+		}
+	})
+
+	t.Run("error - proof files not available", func(t *testing.T) {
+		provider := NewOperationProvider(pc.Protocol, parser,
+			newFailingCAS(casClient, fileURIs["core proof file"], fileURIs["provisional proof file"]), cp)
+
+		report := provider.Inspect(&txn.SidetreeTxn{AnchorString: anchoringInfo.AnchorString})
+		require.False(t, report.Valid())
+		require.Len(t, report.Files, 5)
+		require.Contains(t, report.Files[1].Error, "injected read error")
+		require.Contains(t, report.Files[3].Error, "injected read error")
+
+		for _, op := range report.Operations {
+			if op.Type == operation.TypeCreate {
+				require.Empty(t, op.Errors)
+			} else {
+				require.Equal(t, []string{"signed data not available"}, op.Errors)
+			}
+		}
+	})
+
+	t.Run("error - delta exceeds maximum delta size", func(t *testing.T) {
+		p := mocks.GetDefaultProtocolParameters()
+		p.MaxDeltaSize = 50
+
+		provider := NewOperationProvider(p, operationparser.New(p), casClient, cp)
+
+		report := provider.Inspect(&txn.SidetreeTxn{AnchorString: anchoringInfo.AnchorString})
+		require.False(t, report.Valid())
+		require.Empty(t, report.Errors)
+		require.Contains(t, report.Files[4].Error, "failed to validate delta[0]")
+
+		for i, op := range report.Operations {
+			if op.Type == operation.TypeDeactivate {
+				require.Empty(t, op.Errors)
+
+				continue
+			}
+
+			require.Len(t, op.Errors, 1)
+			require.Contains(t, op.Errors[0], fmt.Sprintf("failed to validate delta[%d]: delta size", i))
+		}
+	})
+
+	t.Run("error - invalid operation references", func(t *testing.T) {
+		p := mocks.GetDefaultProtocolParameters()
+		p.MaxOperationHashLength = 10
+
+		provider := NewOperationProvider(p, operationparser.New(p), casClient, cp)
+
+		report := provider.Inspect(&txn.SidetreeTxn{AnchorString: anchoringInfo.AnchorString})
+		require.False(t, report.Valid())
+		require.Contains(t, report.Files[0].Error, "failed to validate suffix data for create[0]")
+		require.Contains(t, report.Files[2].Error, "failed to validate operation reference for update[0]")
+
+		for _, op := range report.Operations {
+			if op.Type == operation.TypeCreate {
+				require.Contains(t, op.Errors[0], "failed to validate suffix data: recovery commitment length")
+			} else {
+				require.Contains(t, op.Errors[0], "failed to validate operation reference:")
+				require.Contains(t, op.Errors[0], "exceeds maximum hash length[10]")
+			}
+		}
+	})
+
+	t.Run("error - number of operations doesn't match", func(t *testing.T) {
+		ad, err := ParseAnchorData(anchoringInfo.AnchorString)
+		require.NoError(t, err)
+
+		ad.NumberOfOperations = 7
+
+		provider := NewOperationProvider(pc.Protocol, parser, casClient, cp)
+
+		report := provider.Inspect(&txn.SidetreeTxn{AnchorString: ad.GetAnchorString()})
+		require.False(t, report.Valid())
+		require.Equal(t, []string{"number of txn ops[9] doesn't match anchor string num of ops[7]"}, report.Errors)
+	})
+
+	t.Run("error - batch file counts don't match", func(t *testing.T) {
+		p := mocks.GetDefaultProtocolParameters()
+
+		// a batch without update operations
+		anchoringInfo, err := NewOperationHandler(p, casClient, cp, parser, &mocks.MetricsProvider{}).
+			PrepareTxnFiles(getTestOperations(1, 0, 0, 1))
+		require.NoError(t, err)
+
+		provider := NewOperationProvider(p, parser, &extraDeltaCAS{Client: casClient}, cp)
+
+		report := provider.Inspect(&txn.SidetreeTxn{AnchorString: anchoringInfo.AnchorString})
+		require.False(t, report.Valid())
+		require.Contains(t, report.Errors,
+			"number of create+recover+update operations[2] doesn't match number of deltas[3]")
+	})
+}
+
+// failingCAS fails to read the given URIs.
+type failingCAS struct {
+	cas.Client
+	uris map[string]bool
+}
+
+func newFailingCAS(client cas.Client, uris ...string) *failingCAS {
+	c := &failingCAS{Client: client, uris: make(map[string]bool)}
+
+	for _, uri := range uris {
+		c.uris[uri] = true
+	}
+
+	return c
+}
+
+func (c *failingCAS) Read(uri string) ([]byte, error) {
+	if c.uris[uri] {
+		return nil, fmt.Errorf("injected read error")
+	}
+
+	return c.Client.Read(uri)
+}
+
+// extraDeltaCAS adds a delta to every chunk file that it returns.
+type extraDeltaCAS struct {
+	cas.Client
+}
+
+func (c *extraDeltaCAS) Read(uri string) ([]byte, error) {
+	content, err := c.Client.Read(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := compression.New(compression.WithDefaultAlgorithms())
+
+	decompressed, err := cp.Decompress(compressionAlgorithm, content)
+	if err != nil {
+		return nil, err
+	}
+
+	cf, err := models.ParseChunkFile(decompressed)
+	if err != nil || len(cf.Deltas) == 0 {
+		return content, nil //nolint:nilerr
+	}
+
+	cf.Deltas = append(cf.Deltas, cf.Deltas[0])
+
+	b, err := canonicalizer.MarshalCanonical(cf)
+	if err != nil {
+		return nil, err
+	}
+
+	return cp.Compress(compressionAlgorithm, b)
+}
@@ -8,7 +8,7 @@
 set -e
 
 # Packages to exclude
-PKGS=`go list github.com/trustbloc/sidetree-core-go/pkg/... 2> /dev/null | \
+PKGS=`go list github.com/trustbloc/sidetree-core-go/pkg/... github.com/trustbloc/sidetree-core-go/cmd/... 2> /dev/null | \
                                                    grep -v /mocks | \
                                                    grep -v /api/`
 echo "Running pkg unit tests..."